@@ -18,25 +18,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Detect format
 	f, err := os.Open(os.Args[1])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	defer f.Close()
 
-	var lines []string
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	f.Close()
-
-	format := parser.DetectFormat(lines)
-	fmt.Printf("📋 Detected format: %s (%d lines)\n\n", format, len(lines))
-
-	// Parse and render each line
-	p := parser.NewAutoParser()
 	renderer := tui.NewRenderer(tui.RenderConfig{
 		TimestampFormat: tui.TimestampRelative,
 		Theme:           tui.ThemeDark,
@@ -46,7 +34,20 @@ func main() {
 		ANSIMode:        tui.ANSIStrip,
 	})
 
-	for _, line := range lines {
+	// Detect the format as lines stream in: each line is parsed on its own
+	// until the detector settles, then the detected format is pinned.
+	p := parser.NewAutoParser()
+	detector := parser.NewStreamingDetector(0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !detector.Stable() {
+			detector.Observe(line)
+			if detector.Stable() {
+				fmt.Printf("📋 Detected format: %s (after %d lines)\n\n", detector.Current(), detector.Lines())
+				p = p.Pin(detector.Current())
+			}
+		}
 		entry := p.Parse(line)
 		rendered := renderer.RenderEntry(entry)
 		fmt.Println(rendered)
@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
 	"syscall"
+	"text/template"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/config"
+	"github.com/clarabennettdev/logpilot/internal/enrich"
 	"github.com/clarabennettdev/logpilot/internal/parser"
 	"github.com/clarabennettdev/logpilot/internal/source"
 	"github.com/clarabennettdev/logpilot/internal/tui"
+	"github.com/clarabennettdev/logpilot/pkg/logpilot"
 )
 
 var (
@@ -20,68 +28,611 @@ var (
 	date    = "unknown"
 )
 
+// options holds the parsed command-line flags.
+type options struct {
+	version    bool
+	noFollow   bool
+	noMouse    bool
+	bell       bool
+	keepCursor bool
+	quitPrompt bool
+	interact   bool
+	strict     bool
+	alertLevel string
+	followMode source.FollowMode
+	geoDB      string
+	maxLineLen int
+	globDepth  int
+	from       string
+	rescan     time.Duration
+	framing    source.FramingMode
+	encoding   source.Encoding
+	head       int
+	tail       int
+	context    int
+	groupField string
+	groupNorm  tui.KeyNormalization
+	gapMarker  time.Duration
+	sourceTZ   *time.Location
+	stateFile  string
+	exportHTML string
+	authHeader string
+	k8sSel     string
+	k8sNS      string
+	window     parser.TimeWindow
+	render     tui.RenderConfig
+	filters    []tui.EntryFilter
+	preset     *config.Preset
+	schema     *parser.Validator
+	tmpl       *template.Template
+	output     logpilot.OutputFormat
+	keymap     tui.KeyMap
+	parserCmd  []string
+	files      []string
+}
+
+// stringList is a flag that may be given several times.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// DefaultTailLines is how many lines before the end of each file the TUI
+// starts from without --from.
+const DefaultTailLines = 1000
+
+// readFrom returns where to read files from: the --from position, or def
+// (with tailLines for source.ReadLastN) without one.
+func (o options) readFrom(def source.ReadFrom, tailLines int) (source.ReadFrom, int) {
+	if o.from == "" {
+		return def, tailLines
+	}
+	from, n, _ := source.ParseReadFrom(o.from) // checked by parseFlags
+	return from, n
+}
+
+// rescanInterval returns the FileConfig.RescanInterval for --rescan-interval,
+// where 0 disables rescanning.
+func (o options) rescanInterval() time.Duration {
+	if o.rescan <= 0 {
+		return -1
+	}
+	return o.rescan
+}
+
+// url returns the URL given instead of files, if any.
+func (o options) url() string {
+	if len(o.files) == 1 && source.IsURL(o.files[0]) {
+		return o.files[0]
+	}
+	return ""
+}
+
+// urlSource returns the source reading the URL given instead of files.
+func (o options) urlSource() *source.URLSource {
+	return source.NewURLSource(source.URLConfig{
+		URL:           o.url(),
+		Auth:          o.authHeader,
+		MaxLineLength: o.maxLineLen,
+	})
+}
+
+// keep reports whether an entry passes every filter.
+func (o options) keep(e parser.LogEntry) bool {
+	for _, f := range o.filters {
+		if !f(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFlags parses command-line arguments (without the program name).
+// Settings from cfg act as defaults that flags override. Relative
+// --since/--until values are resolved against the current time.
+func parseFlags(args []string, cfg config.Config) (options, error) {
+	return parseFlagsAt(args, time.Now(), cfg)
+}
+
+// parseFlagsAt is parseFlags with an explicit current time.
+func parseFlagsAt(args []string, now time.Time, cfg config.Config) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("logpilot", flag.ContinueOnError)
+	fs.String("config", "", "read settings from `file` (default "+config.DefaultPath()+")")
+	fs.BoolVar(&opts.version, "version", false, "print version information and exit")
+	fs.BoolVar(&opts.noFollow, "no-follow", false, "print the files' current content and exit instead of tailing")
+	fs.StringVar(&opts.from, "from", "", "read files from `position`: start, end (only lines appended from now on) or a number of lines before the end (default 1000 in the TUI, start with --no-follow)")
+	followName := fs.Bool("follow-name", false, "on rotation, reopen the file by name (default, like tail -F)")
+	followDescriptor := fs.Bool("follow-descriptor", false, "on rotation, keep reading the original file (like tail -f)")
+	fs.BoolVar(&opts.noMouse, "no-mouse", false, "leave the mouse to the terminal (text selection) instead of clicking and scrolling in the TUI")
+	fs.StringVar(&opts.geoDB, "geoip-db", cfg.GeoIPDB, "annotate IP fields with country/ASN from local CSV `databases` (comma-separated, e.g. GeoLite2 ASN and Country blocks)")
+	fs.StringVar(&opts.stateFile, "state-file", "", "remember how far each file was read in `file` and resume from there on the next run")
+	fs.StringVar(&opts.authHeader, "auth-header", "", "send `value` as the Authorization header when reading a URL")
+	fs.StringVar(&opts.k8sSel, "k8s-selector", "", "follow the logs of every pod matching the label `selector`, across restarts (uses kubectl)")
+	fs.StringVar(&opts.k8sNS, "k8s-namespace", "", "with --k8s-selector, watch pods in `namespace` instead of the current one")
+	fs.BoolVar(&opts.strict, "strict-levels", false, "in plain-text lines, only take a level from a delimited word near the start, such as [ERROR] or INFO:")
+	fs.IntVar(&opts.maxLineLen, "max-line-length", 0, "truncate lines longer than `bytes` (0 keeps lines of any length)")
+	fs.IntVar(&opts.globDepth, "glob-depth", 0, "let a ** in a file pattern descend at most `n` directories (0 means no limit)")
+	fs.DurationVar(&opts.rescan, "rescan-interval", source.DefaultRescanInterval, "look for new files matching the patterns every `interval` while following (0 disables)")
+	fs.IntVar(&opts.head, "head", 0, "print only the first `n` entries, then stop reading")
+	fs.IntVar(&opts.tail, "tail", 0, "print only the last `n` entries once the input ends")
+	fs.StringVar(&opts.exportHTML, "export-html", "", "when piping, also write the printed entries to `file` as an HTML report once the input ends")
+	encoding := fs.String("encoding", "auto", "read files as `encoding`: auto (UTF-16 when the file starts with its byte order mark, else UTF-8), utf-8, utf-16le or utf-16be")
+	framing := fs.String("framing", "line", "split piped input into records by `mode`: line, length (4-byte big-endian length prefix) or null (NUL-separated)")
+	separator := fs.String("separator", cfg.Separator, "put `text` between the level, timestamp, message and fields (default \" │ \")")
+	compact := fs.Bool("compact", cfg.Compact, "pack lines tighter: one-letter levels, short relative times, no padding around the separator")
+	colorKeys := fs.Bool("color-keys", cfg.ColorKeys, "give each field key its own color, the same on every line")
+	markStderr := fs.Bool("mark-stderr", cfg.MarkStderr, "put a red bar in front of entries written to stderr, such as container runtime logs record")
+	layout := fs.String("layout", orDefault(cfg.Layout, "inline"), "entry `layout`: inline, or table to align the time, level, message and field_order fields from the config in columns (key L toggles)")
+	precision := fs.Int("relative-precision", cfg.TimePrecision, "show relative times in `n` units, such as 2h5m ago for 2 (default 1)")
+	justNow := fs.Duration("just-now", cfg.JustNow, "show relative times within `duration` of now as \"just now\" (default 1s)")
+	includeFields := fs.String("fields", "", "show only the comma-separated `fields` inline and in the detail pane (implies showing fields inline)")
+	excludeFields := fs.String("exclude-fields", "", "hide the comma-separated `fields` inline and in the detail pane (wins over --fields)")
+	tabWidth := fs.Int("tab-width", cfg.TabWidth, "expand tabs in messages and fields to tab stops every `n` columns (default 8)")
+	maxFieldLen := fs.Int("max-field-length", 0, "shorten inline field values to `n` characters; the detail pane shows them in full (0 means no limit)")
+	fs.IntVar(&opts.context, "context-lines", tui.DefaultContextLines, "show `n` lines before and after the selected entry in the context view (key X)")
+	fs.StringVar(&opts.groupField, "group-field", tui.DefaultGroupField, "group the entries sharing the selected entry's value of `field` in the group view (key T)")
+	fs.BoolVar(&opts.groupNorm.IgnoreCase, "group-ignore-case", false, "in the group view, treat values differing only in case as equal")
+	fs.BoolVar(&opts.groupNorm.CollapseWhitespace, "group-collapse-space", false, "in the group view, treat values differing only in whitespace as equal")
+	groupStrip := fs.String("group-strip", "", "in the group view, ignore the parts of values matching the regular expression `pattern`, such as request IDs")
+	since := fs.String("since", "", "only show entries at or after `time` (RFC 3339, or a duration ago like 15m)")
+	until := fs.String("until", "", "only show entries at or before `time` (RFC 3339, or a duration ago like 5m)")
+	fs.BoolVar(&opts.window.ExcludeUntimed, "exclude-untimed", false, "with --since/--until, also hide entries without a timestamp")
+	theme := fs.String("theme", orDefault(cfg.Theme, "auto"), "color `theme`: auto (dark or light, following the terminal), dark, light, solarized-dark, solarized-light or high-contrast")
+	timestamps := fs.String("timestamp", orDefault(cfg.TimestampFormat, "local"), "timestamp `format`: relative, iso or local")
+	displayTZ := fs.String("tz", "", "show timestamps in time `zone`: local, an IANA name such as UTC or Europe/Berlin, or an offset such as +09:00 (default: the zone each was logged in)")
+	sourceTZ := fs.String("source-tz", "", "read timestamps that carry no zone as being in time `zone`, given as for --tz (default UTC)")
+	fs.StringVar(&opts.alertLevel, "alert-level", tui.DefaultAlertLevel, "count entries at or above `level` that arrive while scrolled up (none to disable)")
+	fs.DurationVar(&opts.gapMarker, "gap-marker", 0, "show a separator row between entries more than `duration` apart (e.g. 1m)")
+	fs.BoolVar(&opts.interact, "interactive", false, "with input piped to stdin, show it in the TUI instead of printing it (keys are read from the terminal)")
+	fs.BoolVar(&opts.quitPrompt, "confirm-quit", cfg.ConfirmQuit, "ask for confirmation before q or ctrl+c quits the TUI (a second ctrl+c quits at once)")
+	fs.BoolVar(&opts.keepCursor, "smart-follow", false, "while following new lines, keep the cursor where it is unless it is on the last line")
+	fs.BoolVar(&opts.bell, "bell", false, "ring the terminal bell when such entries arrive")
+	level := fs.String("level", cfg.Level, "hide entries below `level` (trace, debug, info, warn, error, fatal)")
+	output := fs.String("output", "styled", "print entries in `format` when not showing the TUI: styled, plain, json, or message for the message text alone")
+	tmpl := fs.String("template", "", "print each entry through the Go text/template `text` instead of the usual layout, e.g. '{{upper .Level}} {{.Message}} {{field . \"host\"}}'")
+	parserCmd := fs.String("parser-cmd", "", "parse each line with `command`, a long-running program answering every line written to its stdin with a JSON entry on its stdout")
+	preset := fs.String("preset", "", "start the TUI with the saved view preset `name` (key P saves presets, p picks one)")
+	var grep, grepV, grepFields stringList
+	fs.Var(&grep, "grep", "only show entries matching the regular expression `pattern` (repeatable; all must match)")
+	fs.Var(&grepV, "grep-v", "hide entries matching the regular expression `pattern` (repeatable)")
+	fs.Var(&grepFields, "grep-field", "apply --grep/--grep-v to this `field` instead of the message (repeatable or comma-separated; any may match)")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	var err error
+	opts.render = cfg.RenderConfig()
+	if opts.render.Theme, err = tui.ParseTheme(*theme); err != nil {
+		return opts, fmt.Errorf("--theme: %w", err)
+	}
+	if opts.render.TimestampFormat, err = tui.ParseTimestampFormat(*timestamps); err != nil {
+		return opts, fmt.Errorf("--timestamp: %w", err)
+	}
+	if *displayTZ != "" {
+		if opts.render.DisplayLocation, err = parser.ParseLocation(*displayTZ); err != nil {
+			return opts, fmt.Errorf("--tz: %w", err)
+		}
+	}
+	if *sourceTZ != "" {
+		if opts.sourceTZ, err = parser.ParseLocation(*sourceTZ); err != nil {
+			return opts, fmt.Errorf("--source-tz: %w", err)
+		}
+	}
+	if opts.render.Layout, err = tui.ParseLayoutMode(*layout); err != nil {
+		return opts, fmt.Errorf("--layout: %w", err)
+	}
+	if opts.framing, err = source.ParseFramingMode(*framing); err != nil {
+		return opts, fmt.Errorf("--framing: %w", err)
+	}
+	if *maxFieldLen < 0 {
+		return opts, fmt.Errorf("--max-field-length must not be negative")
+	}
+	opts.render.MaxFieldValueLen = *maxFieldLen
+	if *tabWidth < 0 {
+		return opts, fmt.Errorf("--tab-width must not be negative")
+	}
+	opts.render.TabWidth = *tabWidth
+	opts.render.Separator = *separator
+	opts.render.CompactMode = *compact
+	if *precision < 0 {
+		return opts, fmt.Errorf("--relative-precision must not be negative")
+	}
+	opts.render.RelativeTimePrecision = *precision
+	if *justNow < 0 {
+		return opts, fmt.Errorf("--just-now must not be negative")
+	}
+	opts.render.JustNowThreshold = *justNow
+	opts.render.ColorKeysByName = *colorKeys
+	opts.render.MarkStderr = *markStderr
+	if opts.render.IncludeFields = splitList(*includeFields); len(opts.render.IncludeFields) > 0 {
+		opts.render.ShowAllFields = true
+	}
+	opts.render.ExcludeFields = splitList(*excludeFields)
+	if opts.schema, err = cfg.Schema.Validator(); err != nil {
+		return opts, fmt.Errorf("schema: %w", err)
+	}
+	if opts.keymap, err = cfg.KeyBindings(); err != nil {
+		return opts, fmt.Errorf("keymap: %w", err)
+	}
+	if opts.alertLevel == "none" {
+		opts.alertLevel = ""
+	} else if !tui.ValidLevel(opts.alertLevel) {
+		return opts, fmt.Errorf("--alert-level: unknown level %q", opts.alertLevel)
+	}
+	if *level != "" {
+		if !tui.ValidLevel(*level) {
+			return opts, fmt.Errorf("--level: unknown level %q", *level)
+		}
+		opts.filters = append(opts.filters, tui.MinLevelFilter(*level))
+	}
+
+	if *groupStrip != "" {
+		if opts.groupNorm.StripPattern, err = regexp.Compile(*groupStrip); err != nil {
+			return opts, fmt.Errorf("--group-strip: %w", err)
+		}
+	}
+
+	if opts.window.Since, err = parser.ParseTimeBound(*since, now); err != nil {
+		return opts, fmt.Errorf("--since: %w", err)
+	}
+	if opts.window.Until, err = parser.ParseTimeBound(*until, now); err != nil {
+		return opts, fmt.Errorf("--until: %w", err)
+	}
+	if !opts.window.Since.IsZero() && !opts.window.Until.IsZero() && opts.window.Until.Before(opts.window.Since) {
+		return opts, fmt.Errorf("--until is before --since")
+	}
+	if opts.window.Active() {
+		opts.filters = append(opts.filters, tui.TimeWindowFilter(opts.window))
+	}
+
+	var fields []string
+	for _, f := range grepFields {
+		fields = append(fields, strings.Split(f, ",")...)
+	}
+	for _, g := range []struct {
+		flag     string
+		patterns []string
+		negate   bool
+	}{{"--grep", grep, false}, {"--grep-v", grepV, true}} {
+		for _, pattern := range g.patterns {
+			fm, err := parser.NewFieldMatcher(pattern, fields, g.negate)
+			if err != nil {
+				return opts, fmt.Errorf("%s: %w", g.flag, err)
+			}
+			opts.filters = append(opts.filters, fm.Match)
+		}
+	}
+
+	opts.parserCmd = strings.Fields(*parserCmd)
+
+	if opts.output, err = logpilot.ParseOutputFormat(*output); err != nil {
+		return opts, fmt.Errorf("--output: %w", err)
+	}
+	if *tmpl != "" {
+		if opts.tmpl, err = parseTemplate(*tmpl); err != nil {
+			return opts, fmt.Errorf("--template: %w", err)
+		}
+	}
+
+	if opts.encoding, err = source.ParseEncoding(*encoding); err != nil {
+		return opts, fmt.Errorf("--encoding: %w", err)
+	}
+
+	if *preset != "" {
+		p, err := config.FindPreset(*preset)
+		if err != nil {
+			return opts, fmt.Errorf("--preset: %w", err)
+		}
+		opts.preset = &p
+	}
+
+	if opts.maxLineLen < 0 {
+		return opts, fmt.Errorf("--max-line-length must not be negative")
+	}
+	if opts.context < 0 {
+		return opts, fmt.Errorf("--context-lines must not be negative")
+	}
+	if opts.gapMarker < 0 {
+		return opts, fmt.Errorf("--gap-marker must not be negative")
+	}
+	if opts.from != "" {
+		from, _, err := source.ParseReadFrom(opts.from)
+		if err != nil {
+			return opts, fmt.Errorf("--from: %w", err)
+		}
+		if from == source.ReadEnd && opts.noFollow {
+			return opts, fmt.Errorf("--from end shows nothing with --no-follow")
+		}
+	}
+	if opts.globDepth < 0 {
+		return opts, fmt.Errorf("--glob-depth must not be negative")
+	}
+	if opts.head < 0 || opts.tail < 0 {
+		return opts, fmt.Errorf("--head and --tail must not be negative")
+	}
+	if opts.head > 0 && opts.tail > 0 {
+		return opts, fmt.Errorf("--head and --tail are mutually exclusive")
+	}
+
+	if *followName && *followDescriptor {
+		return opts, fmt.Errorf("--follow-name and --follow-descriptor are mutually exclusive")
+	}
+	if cfg.FollowMode != "" {
+		opts.followMode, _ = source.ParseFollowMode(cfg.FollowMode)
+	}
+	if *followName {
+		opts.followMode = source.FollowName
+	}
+	if *followDescriptor {
+		opts.followMode = source.FollowDescriptor
+	}
+	opts.files = fs.Args()
+	if opts.k8sSel != "" && len(opts.files) > 0 {
+		return opts, fmt.Errorf("--k8s-selector cannot be combined with files")
+	}
+	for _, f := range opts.files {
+		if source.IsURL(f) && len(opts.files) > 1 {
+			return opts, fmt.Errorf("a URL cannot be combined with other files")
+		}
+	}
+	if opts.k8sNS != "" && opts.k8sSel == "" {
+		return opts, fmt.Errorf("--k8s-namespace requires --k8s-selector")
+	}
+	return opts, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// orDefault returns s, or def when s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// loadConfig loads the file named by a --config argument, or the default
+// configuration file if there is none.
+func loadConfig(args []string) (config.Config, error) {
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		for _, name := range []string{"-config", "--config"} {
+			if a == name && i+1 < len(args) {
+				return config.Load(args[i+1])
+			}
+			if path, ok := strings.CutPrefix(a, name+"="); ok {
+				return config.Load(path)
+			}
+		}
+	}
+	return config.LoadDefault()
+}
+
 func main() {
-	if len(os.Args) > 1 && os.Args[1] == "--version" {
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	opts, err := parseFlags(os.Args[1:], cfg)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if opts.version {
 		fmt.Printf("logpilot %s (%s) built %s\n", version, commit, date)
 		os.Exit(0)
 	}
 
-	// If stdin is a pipe, run in streaming mode (no TUI).
-	if source.IsPipe() {
-		if err := runPipeMode(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-		return
+	autoParser, err := newParser(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	// TUI mode — files given as args.
-	files := os.Args[1:]
-	if err := runTUIMode(files); err != nil {
+	err = chooseMode(opts, source.IsPipe())(opts, autoParser)
+	reportPanics(autoParser)
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-// runTUIMode starts the interactive TUI with file sources.
-func runTUIMode(files []string) error {
+// reportPanics warns about lines that made a parser panic. They were shown
+// unparsed, so the output alone does not tell.
+func reportPanics(p *parser.AutoParser) {
+	if n, err := p.Panics(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d line(s) could not be parsed and are shown as-is; last: %v\n", n, err)
+	}
+}
+
+// newParser creates the line parser, with any enrichment the flags ask for.
+func newParser(opts options) (*parser.AutoParser, error) {
+	p := parser.NewAutoParser()
+	p.SetStrictLevels(opts.strict)
+	if opts.sourceTZ != nil {
+		p.SetTimeConfig(parser.TimeConfig{Location: opts.sourceTZ})
+	}
+	if opts.geoDB != "" {
+		db, err := enrich.OpenGeoDatabase(splitList(opts.geoDB)...)
+		if err != nil {
+			return nil, err
+		}
+		p.AddEnricher(enrich.NewGeoEnricher(db))
+	}
+	if len(opts.parserCmd) > 0 {
+		ep := parser.NewExecParser(parser.ExecConfig{Command: opts.parserCmd})
+		if err := ep.Start(); err != nil {
+			return nil, err
+		}
+		p.SetExecParser(ep)
+	}
+	if opts.schema != nil {
+		p.AddEnricher(opts.schema)
+	}
+	return p, nil
+}
+
+// chooseMode returns the mode to run in, given whether stdin is a pipe.
+func chooseMode(opts options, stdinPipe bool) func(options, *parser.AutoParser) error {
+	switch {
+	case opts.noFollow && len(opts.files) > 0:
+		// Dump files and exit.
+		return runDumpMode
+	case stdinPipe && opts.interact:
+		return runInteractiveMode
+	case stdinPipe:
+		// If stdin is a pipe, run in streaming mode (no TUI).
+		return runPipeMode
+	default:
+		return runTUIMode // TUI mode — files given as args.
+	}
+}
+
+// runTUIMode starts the interactive TUI with file, URL or Kubernetes sources.
+func runTUIMode(opts options, autoParser *parser.AutoParser) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	sourceName := "no source"
 	var src source.Source
 
-	if len(files) > 0 {
-		sourceName = strings.Join(files, ", ")
+	if u := opts.url(); u != "" {
+		sourceName = u
+		urlSrc := opts.urlSource()
+		if err := urlSrc.Start(ctx); err != nil {
+			return fmt.Errorf("starting URL source: %w", err)
+		}
+		defer urlSrc.Stop()
+		src = urlSrc
+	} else if len(opts.files) > 0 {
+		sourceName = strings.Join(opts.files, ", ")
+		readFrom, tailLines := opts.readFrom(source.ReadLastN, DefaultTailLines)
 		fileSrc := source.NewFileSource(source.FileConfig{
-			Patterns:  files,
-			TailLines: 1000,
+			Patterns:       opts.files,
+			MaxGlobDepth:   opts.globDepth,
+			RescanInterval: opts.rescanInterval(),
+			ReadFrom:       readFrom,
+			TailLines:      tailLines,
+			FollowMode:     opts.followMode,
+			MaxLineLength:  opts.maxLineLen,
+			StateFile:      opts.stateFile,
+			Encoding:       opts.encoding,
 		})
 		if err := fileSrc.Start(ctx); err != nil {
 			return fmt.Errorf("starting file source: %w", err)
 		}
 		defer fileSrc.Stop()
 		src = fileSrc
+	} else if opts.k8sSel != "" {
+		sourceName = "k8s: " + opts.k8sSel
+		// The pod watch ends when the API server closes it; watch again,
+		// resuming each pod's log where it stopped.
+		positions := &source.PodPositions{}
+		k8sSrc := source.NewRetryingSource(source.RetryConfig{
+			Name: sourceName,
+			New: func() (source.Source, error) {
+				return source.NewK8sSource(source.K8sConfig{
+					Namespace:     opts.k8sNS,
+					Selector:      opts.k8sSel,
+					MaxLineLength: opts.maxLineLen,
+					Positions:     positions,
+				}), nil
+			},
+		})
+		if err := k8sSrc.Start(ctx); err != nil {
+			return fmt.Errorf("starting k8s source: %w", err)
+		}
+		defer k8sSrc.Stop()
+		src = k8sSrc
 	}
+	return runTUI(opts, autoParser, src, sourceName)
+}
 
-	model := tui.NewModelWithSource(src, sourceName)
-	p := tea.NewProgram(model, tea.WithAltScreen())
+// runInteractiveMode runs the TUI on the lines piped to stdin (--interactive),
+// reading keys from the controlling terminal instead. Without a terminal to
+// open it warns and falls back to pipe mode.
+func runInteractiveMode(opts options, autoParser *parser.AutoParser) error {
+	tty, err := openTTY()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: --interactive needs a terminal for input (%v); printing the input instead\n", err)
+		return runPipeMode(opts, autoParser)
+	}
+	defer tty.Close()
 
-	// Wire source lines into the TUI via Program.Send.
-	if src != nil {
-		autoParser := parser.NewAutoParser()
-		renderer := tui.NewRenderer(tui.DefaultConfig())
-		tui.ListenForLines(src, autoParser, renderer, p)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := source.NewStdinSource(source.WithMaxLineLength(opts.maxLineLen), source.WithFraming(opts.framing))
+	go src.Start(ctx)
+	defer src.Stop()
+	return runTUI(opts, autoParser, src, "stdin", tea.WithInput(tty))
+}
+
+// openTTY opens the controlling terminal, which --interactive reads keys
+// from while stdin is a pipe. Tests replace it.
+var openTTY = func() (*os.File, error) {
+	return os.Open("/dev/tty")
+}
+
+// runProgram runs the TUI program until it quits. Tests replace it.
+var runProgram = func(p *tea.Program) error {
+	_, err := p.Run()
+	return err
+}
+
+// runTUI shows the lines of src, which may be nil, in the TUI until it is
+// quit. extra is added to the program's options.
+func runTUI(opts options, autoParser *parser.AutoParser, src source.Source, sourceName string, extra ...tea.ProgramOption) error {
+	renderer := tui.NewRenderer(opts.render)
+	modelOpts := []tui.ModelOption{tui.WithRenderer(renderer), tui.WithKeyMap(opts.keymap), tui.WithContextLines(opts.context), tui.WithGroupField(opts.groupField),
+		tui.WithGroupNormalization(opts.groupNorm),
+		tui.WithAlertLevel(opts.alertLevel), tui.WithAlertBell(opts.bell), tui.WithGapMarker(opts.gapMarker),
+		tui.WithSmartFollow(opts.keepCursor), tui.WithQuitConfirm(opts.quitPrompt),
+		tui.WithPresetStore(config.PresetStore{DefaultTheme: renderer.Config().Theme})}
+	for _, f := range opts.filters {
+		modelOpts = append(modelOpts, tui.WithFilter(f))
+	}
+	model := tui.NewModelWithSource(src, sourceName, modelOpts...)
+	if opts.preset != nil {
+		if err := model.ApplyPreset(opts.preset.TUI(renderer.Config().Theme)); err != nil {
+			return err
+		}
 	}
+	programOpts := []tea.ProgramOption{tea.WithAltScreen()}
+	if !opts.noMouse {
+		programOpts = append(programOpts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(model, append(programOpts, extra...)...)
 
-	if _, err := p.Run(); err != nil {
-		return err
+	// Wire source lines into the TUI via Program.Send. The model renders
+	// the lines it shows itself.
+	if src != nil {
+		tui.StreamLines(src, autoParser, nil, p)
 	}
-	return nil
+	return runProgram(p)
 }
 
 // runPipeMode reads from stdin, parses each line, and renders output to stdout.
-func runPipeMode() error {
+func runPipeMode(opts options, autoParser *parser.AutoParser) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -93,25 +644,144 @@ func runPipeMode() error {
 		cancel()
 	}()
 
-	src := source.NewStdinSource()
-	autoParser := parser.NewAutoParser()
-	renderer := tui.NewRenderer(tui.DefaultConfig())
-
-	// Start reading stdin in a goroutine.
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- src.Start(ctx)
-	}()
+	src := source.NewStdinSource(source.WithMaxLineLength(opts.maxLineLen), source.WithFraming(opts.framing))
+	if err := printLines(ctx, src, autoParser, opts); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
 
-	// Consume lines and render them.
-	for entry := range src.Lines() {
-		parsed := autoParser.Parse(entry.Line)
-		fmt.Println(renderer.RenderEntry(parsed))
+// runDumpMode prints the current content of the given files, or the body of
+// the given URL, and exits.
+func runDumpMode(opts options, p *parser.AutoParser) error {
+	if opts.url() != "" {
+		return printLines(context.Background(), opts.urlSource(), p, opts)
 	}
+	readFrom, tailLines := opts.readFrom(source.ReadStart, 0)
+	src := source.NewFileSource(source.FileConfig{
+		Patterns:      opts.files,
+		MaxGlobDepth:  opts.globDepth,
+		ReadFrom:      readFrom,
+		TailLines:     tailLines,
+		NoFollow:      true,
+		MaxLineLength: opts.maxLineLen,
+		StateFile:     opts.stateFile,
+		Encoding:      opts.encoding,
+	})
+	return printLines(context.Background(), src, p, opts)
+}
 
-	// Check for read errors.
-	if err := <-errCh; err != nil && ctx.Err() == nil {
+// printLines parses and renders every line from src to stdout until the
+// source is exhausted or ctx is cancelled. Entries rejected by opts' filters
+// are skipped. With --head it stops reading after that many entries; with
+// --tail it holds back all but the last entries and prints them at the end.
+// With --export-html the printed entries are also written as an HTML report.
+func printLines(ctx context.Context, src source.Source, p *parser.AutoParser, opts options) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pl, err := logpilot.NewPipeline(src,
+		logpilot.WithParser(p),
+		logpilot.WithFilter(opts.keep),
+		logpilot.WithOutput(opts.output),
+		logpilot.WithRenderConfig(opts.render))
+	if err != nil {
 		return err
 	}
+	done := make(chan error, 1)
+	go func() { done <- pl.Run(ctx) }()
+
+	r := tui.NewRenderer(opts.render) // redacts the entries --template sees
+	var tail []string                 // ring buffer of the last opts.tail entries
+	var tailEntries []parser.LogEntry // the entries of tail, for --export-html
+	var exported []parser.LogEntry
+	n := 0
+	for res := range pl.Results() {
+		line := res.Rendered
+		if opts.tmpl != nil {
+			if line, err = executeTemplate(opts.tmpl, r.Redact(res.Entry)); err != nil {
+				return fmt.Errorf("--template: %w", err)
+			}
+		}
+		switch {
+		case opts.tail > 0:
+			if len(tail) < opts.tail {
+				tail = append(tail, line)
+				tailEntries = append(tailEntries, res.Entry)
+			} else {
+				tail[n%opts.tail] = line
+				tailEntries[n%opts.tail] = res.Entry
+			}
+		default:
+			fmt.Println(line)
+			if opts.exportHTML != "" {
+				exported = append(exported, res.Entry)
+			}
+		}
+		n++
+		if n == opts.head {
+			// The deferred cancel stops the source.
+			return writeHTMLReport(opts, exported)
+		}
+	}
+	err = <-done
+	for i := range tail {
+		fmt.Println(tail[(n+i)%len(tail)])
+		exported = append(exported, tailEntries[(n+i)%len(tail)])
+	}
+	if herr := writeHTMLReport(opts, exported); err == nil {
+		err = herr
+	}
+	return err
+}
+
+// writeHTMLReport writes entries to the --export-html file, if one was
+// given.
+func writeHTMLReport(opts options, entries []parser.LogEntry) error {
+	if opts.exportHTML == "" {
+		return nil
+	}
+	f, err := os.Create(opts.exportHTML)
+	if err != nil {
+		return fmt.Errorf("--export-html: %w", err)
+	}
+	if err := tui.ExportHTML(f, entries, tui.NewRenderer(opts.render)); err != nil {
+		f.Close()
+		return fmt.Errorf("--export-html: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("--export-html: %w", err)
+	}
 	return nil
 }
+
+// templateFuncs are the functions available to --template besides the
+// text/template built-ins.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// field returns the named field of an entry, or "" if it has none.
+	"field": func(e parser.LogEntry, key string) string { return e.Fields[key] },
+	// relative describes a timestamp relative to now, e.g. "5m ago", or
+	// returns "" for an entry without one.
+	"relative": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return tui.RelativeTime(t, time.Now())
+	},
+}
+
+// parseTemplate compiles the --template text. Its data is a
+// parser.LogEntry: .Level, .Timestamp, .Message, .Fields, .Raw and so on.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("entry").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// executeTemplate renders entry with t.
+func executeTemplate(t *template.Template, entry parser.LogEntry) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, entry); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -2,9 +2,25 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/config"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+	"github.com/clarabennettdev/logpilot/internal/tui"
+	"github.com/clarabennettdev/logpilot/pkg/logpilot"
 )
 
 func TestPipeMode_JSON(t *testing.T) {
@@ -79,3 +95,837 @@ func TestPipeMode_LongLine(t *testing.T) {
 		t.Error("expected long line to be processed")
 	}
 }
+
+func TestPipeMode_MaxLineLength(t *testing.T) {
+	cmd := exec.Command("go", "run", ".", "--max-line-length", "10")
+	cmd.Stdin = strings.NewReader(strings.Repeat("x", 2<<20) + "\nafter\n")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), source.TruncatedMarker) || !strings.Contains(out.String(), "after") {
+		t.Errorf("expected truncated line followed by the next one, got: %q", out.String())
+	}
+}
+
+func TestNoFollow_DumpsFileAndExits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	content := `{"level":"info","msg":"first"}
+level=warn msg="second"
+third plain line
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "run", ".", "--no-follow", path)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+
+	done := make(chan error, 1)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+	case <-time.After(60 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("--no-follow did not exit")
+	}
+
+	for _, want := range []string{"first", "second", "third plain line"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got: %q", want, out.String())
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	opts, err := parseFlags([]string{"--no-follow", "a.log", "b.log"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.noFollow {
+		t.Error("expected noFollow to be set")
+	}
+	if len(opts.files) != 2 || opts.files[0] != "a.log" {
+		t.Errorf("files = %v, want [a.log b.log]", opts.files)
+	}
+	if opts.render.Theme != tui.ThemeAuto {
+		t.Errorf("theme = %v, want ThemeAuto without --theme", opts.render.Theme)
+	}
+}
+
+func TestParseFlags_FollowMode(t *testing.T) {
+	opts, err := parseFlags([]string{"a.log"}, config.Config{})
+	if err != nil || opts.followMode != source.FollowName {
+		t.Errorf("default follow mode = %v (err %v), want FollowName", opts.followMode, err)
+	}
+	opts, err = parseFlags([]string{"--follow-descriptor", "a.log"}, config.Config{})
+	if err != nil || opts.followMode != source.FollowDescriptor {
+		t.Errorf("follow mode = %v (err %v), want FollowDescriptor", opts.followMode, err)
+	}
+	if _, err := parseFlags([]string{"--follow-name", "--follow-descriptor"}, config.Config{}); err == nil {
+		t.Error("expected error for conflicting follow flags")
+	}
+}
+
+func TestParseFlags_TimeWindow(t *testing.T) {
+	now := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+	opts, err := parseFlagsAt([]string{"--since", "15m", "--until", "2026-02-17T19:55:00Z", "--exclude-untimed"}, now, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(-15 * time.Minute); !opts.window.Since.Equal(want) {
+		t.Errorf("since = %v, want %v", opts.window.Since, want)
+	}
+	if want := now.Add(-5 * time.Minute); !opts.window.Until.Equal(want) {
+		t.Errorf("until = %v, want %v", opts.window.Until, want)
+	}
+	if !opts.window.ExcludeUntimed {
+		t.Error("expected ExcludeUntimed")
+	}
+
+	if _, err := parseFlagsAt([]string{"--since", "soon"}, now, config.Config{}); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+	if _, err := parseFlagsAt([]string{"--since", "5m", "--until", "10m"}, now, config.Config{}); err == nil {
+		t.Error("expected error when --until is before --since")
+	}
+}
+
+func TestPipeMode_TimeWindow(t *testing.T) {
+	input := `{"ts":"2026-02-17T10:00:00Z","level":"info","msg":"too early"}
+{"ts":"2026-02-17T11:00:00Z","level":"info","msg":"in window"}
+{"ts":"2026-02-17T12:00:00Z","level":"info","msg":"at the edge"}
+{"ts":"2026-02-17T13:00:00Z","level":"info","msg":"too late"}
+no timestamp here
+`
+	run := func(args ...string) string {
+		cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+		cmd.Stdin = strings.NewReader(input)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &bytes.Buffer{}
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+		return out.String()
+	}
+
+	out := run("--since", "2026-02-17T11:00:00Z", "--until", "2026-02-17T12:00:00Z")
+	for _, want := range []string{"in window", "at the edge", "no timestamp here"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output: %q", want, out)
+		}
+	}
+	for _, unwanted := range []string{"too early", "too late"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("did not expect %q in output: %q", unwanted, out)
+		}
+	}
+
+	out = run("--since", "2026-02-17T11:00:00Z", "--exclude-untimed")
+	if strings.Contains(out, "no timestamp here") {
+		t.Errorf("untimed line should be excluded: %q", out)
+	}
+}
+
+func TestPipeMode_Template(t *testing.T) {
+	input := `{"ts":"2026-02-17T10:00:00Z","level":"info","msg":"started","host":"web-1"}
+level=error msg="disk full" host=db-2
+plain text line
+`
+	cmd := exec.Command("go", "run", ".", "--template",
+		`{{upper .Level}} {{if not .Timestamp.IsZero}}{{.Timestamp.UTC.Format "15:04"}} {{end}}{{.Message}} [{{field . "host"}}] {{index .Fields "host"}}`)
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	want := "INFO 10:00 started [web-1] web-1\nERROR disk full [db-2] db-2\n plain text line [] \n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPipeMode_RedactsJSONAndTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lp.yaml")
+	if err := os.WriteFile(path, []byte("redact:\n  defaults: true\n  fields: [session]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := `{"level":"info","msg":"login by ann@example.com","password":"hunter2","session":"s3cr3t"}` + "\n"
+	for _, args := range [][]string{
+		{"--output", "json"},
+		{"--template", `{{.Message}} {{field . "password"}} {{field . "session"}} {{.Raw}}`},
+	} {
+		cmd := exec.Command("go", append([]string{"run", ".", "--config", path}, args...)...)
+		cmd.Stdin = strings.NewReader(input)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &bytes.Buffer{}
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("%v: command failed: %v", args, err)
+		}
+		for _, secret := range []string{"ann@example.com", "hunter2", "s3cr3t"} {
+			if strings.Contains(out.String(), secret) {
+				t.Errorf("%v: %q leaked: %q", args, secret, out.String())
+			}
+		}
+		if !strings.Contains(out.String(), "***") {
+			t.Errorf("%v: output should hold the mask: %q", args, out.String())
+		}
+	}
+}
+
+func TestPipeMode_OutputMessage(t *testing.T) {
+	input := `{"ts":"2026-02-17T10:00:00Z","level":"info","msg":"started","host":"web-1"}
+level=error msg="disk full" host=db-2
+cache \x1b[31mcold\x1b[0m
+{"level":"debug","event":"no message key"}
+plain text line
+`
+	cmd := exec.Command("go", "run", ".", "--output", "message")
+	cmd.Stdin = strings.NewReader(strings.ReplaceAll(input, `\x1b`, "\x1b"))
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	want := "started\ndisk full\ncache cold\nno message key\nplain text line\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPipeMode_TimeZones(t *testing.T) {
+	input := `{"ts":"2026-02-17T19:00:00+09:00","msg":"tokyo"}
+{"ts":"2026-02-17T05:00:00-05:00","msg":"new york"}
+{"ts":"2026-02-17 10:30:00","msg":"zoneless"}
+`
+	cmd := exec.Command("go", "run", ".", "--output", "plain", "--timestamp", "iso", "--tz", "UTC", "--source-tz", "+02:00")
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	// Both zoned entries happened at 10:00 UTC; the zoneless one is read
+	// as +02:00.
+	for want, n := range map[string]int{"2026-02-17T10:00:00Z": 2, "2026-02-17T08:30:00Z": 1} {
+		if got := strings.Count(out.String(), want); got != n {
+			t.Errorf("%q appears %d times, want %d, in:\n%s", want, got, n, out.String())
+		}
+	}
+}
+
+func TestParseFlags_TimeZones(t *testing.T) {
+	opts, err := parseFlags([]string{"--tz", "Asia/Tokyo", "--source-tz", "local"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc := opts.render.DisplayLocation; loc == nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("display location = %v, want Asia/Tokyo", loc)
+	}
+	if opts.sourceTZ != time.Local {
+		t.Errorf("source zone = %v, want Local", opts.sourceTZ)
+	}
+	for _, flag := range []string{"--tz", "--source-tz"} {
+		if _, err := parseFlags([]string{flag, "Mars/Olympus"}, config.Config{}); err == nil || !strings.Contains(err.Error(), flag) {
+			t.Errorf("err = %v, want a %s error", err, flag)
+		}
+	}
+}
+
+func TestParseFlags_Output(t *testing.T) {
+	for arg, want := range map[string]logpilot.OutputFormat{
+		"styled":  logpilot.OutputStyled,
+		"plain":   logpilot.OutputPlain,
+		"JSON":    logpilot.OutputJSON,
+		"message": logpilot.OutputMessage,
+	} {
+		opts, err := parseFlags([]string{"--output", arg}, config.Config{})
+		if err != nil || opts.output != want {
+			t.Errorf("--output %s: %v, %v; want %v", arg, opts.output, err, want)
+		}
+	}
+	if _, err := parseFlags([]string{"--output", "xml"}, config.Config{}); err == nil || !strings.Contains(err.Error(), "--output") {
+		t.Errorf("err = %v, want an --output error", err)
+	}
+}
+
+func TestParseFlags_RelativeTime(t *testing.T) {
+	opts, err := parseFlags([]string{"--relative-precision", "2", "--just-now", "5s"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.RelativeTimePrecision != 2 || opts.render.JustNowThreshold != 5*time.Second {
+		t.Errorf("precision %d, just now %v; want 2, 5s", opts.render.RelativeTimePrecision, opts.render.JustNowThreshold)
+	}
+	opts, err = parseFlags(nil, config.Config{TimePrecision: 3, JustNow: time.Minute})
+	if err != nil || opts.render.RelativeTimePrecision != 3 || opts.render.JustNowThreshold != time.Minute {
+		t.Errorf("from config: precision %d, just now %v, %v; want 3, 1m", opts.render.RelativeTimePrecision, opts.render.JustNowThreshold, err)
+	}
+	if _, err := parseFlags([]string{"--relative-precision", "-1"}, config.Config{}); err == nil {
+		t.Error("a negative --relative-precision should be an error")
+	}
+}
+
+func TestParseFlags_GroupNormalization(t *testing.T) {
+	opts, err := parseFlags([]string{"--group-ignore-case", "--group-collapse-space", "--group-strip", `id=\d+`}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := opts.groupNorm
+	if !n.IgnoreCase || !n.CollapseWhitespace || n.StripPattern == nil || n.StripPattern.String() != `id=\d+` {
+		t.Errorf("group normalization = %+v", n)
+	}
+	if _, err := parseFlags([]string{"--group-strip", "("}, config.Config{}); err == nil || !strings.Contains(err.Error(), "--group-strip") {
+		t.Errorf("err = %v, want a --group-strip error", err)
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	if _, err := parseFlags([]string{"--template", "{{.Message"}, config.Config{}); err == nil || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("err = %v, want a --template error", err)
+	}
+
+	tmpl, err := parseTemplate(`{{lower .Level}}|{{relative .Timestamp}}|{{field . "missing"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := executeTemplate(tmpl, parser.LogEntry{Level: "WARN", Timestamp: time.Now().Add(-5 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "warn|5m ago|" {
+		t.Errorf("got %q", got)
+	}
+	if got, _ := executeTemplate(tmpl, parser.LogEntry{}); got != "||" {
+		t.Errorf("empty entry: got %q", got)
+	}
+}
+
+func TestChooseMode(t *testing.T) {
+	parse := func(args ...string) options {
+		t.Helper()
+		opts, err := parseFlags(args, config.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+	name := func(f func(options, *parser.AutoParser) error) uintptr { return reflect.ValueOf(f).Pointer() }
+	tests := []struct {
+		opts  options
+		pipe  bool
+		want  func(options, *parser.AutoParser) error
+		label string
+	}{
+		{parse("app.log"), false, runTUIMode, "files"},
+		{parse("--no-follow", "app.log"), false, runDumpMode, "--no-follow"},
+		{parse(), true, runPipeMode, "pipe"},
+		{parse("--interactive"), true, runInteractiveMode, "pipe with --interactive"},
+		{parse("--interactive"), false, runTUIMode, "--interactive without a pipe"},
+	}
+	for _, tt := range tests {
+		if got := chooseMode(tt.opts, tt.pipe); name(got) != name(tt.want) {
+			t.Errorf("%s: chose the wrong mode", tt.label)
+		}
+	}
+}
+
+// pipeStdin replaces os.Stdin with a pipe holding input for the rest of the
+// test.
+func pipeStdin(t *testing.T, input string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString(input)
+	w.Close()
+	old := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = old })
+}
+
+func TestRunInteractiveMode(t *testing.T) {
+	pipeStdin(t, "level=info msg=piped\n")
+	tty, err := os.Create(filepath.Join(t.TempDir(), "tty"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldOpen, oldRun := openTTY, runProgram
+	t.Cleanup(func() { openTTY, runProgram = oldOpen, oldRun })
+	openTTY = func() (*os.File, error) { return tty, nil }
+	ran := false
+	runProgram = func(p *tea.Program) error {
+		ran = true
+		return nil
+	}
+
+	opts, err := parseFlags([]string{"--interactive"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runInteractiveMode(opts, parser.NewAutoParser()); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("the TUI program was not run")
+	}
+}
+
+func TestRunInteractiveMode_NoTerminal(t *testing.T) {
+	pipeStdin(t, "level=info msg=piped\n")
+	oldOpen, oldRun, oldOut, oldErr := openTTY, runProgram, os.Stdout, os.Stderr
+	t.Cleanup(func() { openTTY, runProgram, os.Stdout, os.Stderr = oldOpen, oldRun, oldOut, oldErr })
+	openTTY = func() (*os.File, error) { return nil, errors.New("no tty") }
+	runProgram = func(*tea.Program) error {
+		t.Error("the TUI should not run without a terminal")
+		return nil
+	}
+	out, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, os.Stderr = out, out
+
+	opts, err := parseFlags([]string{"--interactive"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := runInteractiveMode(opts, parser.NewAutoParser()); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(out.Name())
+	if !strings.Contains(string(got), "Warning: --interactive") || !strings.Contains(string(got), "piped") {
+		t.Errorf("want a warning and the printed input, got %q", got)
+	}
+}
+
+func TestParseFlags_ConfigDefaults(t *testing.T) {
+	cfg := config.Config{Theme: "light", TimestampFormat: "iso", Level: "error", FollowMode: "descriptor"}
+
+	opts, err := parseFlags([]string{"a.log"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.Theme != tui.ThemeLight || opts.render.TimestampFormat != tui.TimestampISO {
+		t.Errorf("config values not applied: %+v", opts.render)
+	}
+	if opts.followMode != source.FollowDescriptor {
+		t.Error("follow mode from config not applied")
+	}
+	if opts.keep(parser.LogEntry{Level: "warn"}) || !opts.keep(parser.LogEntry{Level: "error"}) {
+		t.Error("level filter from config not applied")
+	}
+
+	// Flags win over file values.
+	opts, err = parseFlags([]string{"--theme", "dark", "--timestamp", "relative", "--level", "debug", "--follow-name", "a.log"}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.Theme != tui.ThemeDark || opts.render.TimestampFormat != tui.TimestampRelative {
+		t.Errorf("flags should override config: %+v", opts.render)
+	}
+	if opts.followMode != source.FollowName {
+		t.Error("--follow-name should override config")
+	}
+	if !opts.keep(parser.LogEntry{Level: "debug"}) {
+		t.Error("--level should override config")
+	}
+
+	if _, err := parseFlags([]string{"--theme", "neon"}, config.Config{}); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+func TestLoadConfig_Flag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lp.yaml")
+	if err := os.WriteFile(path, []byte("theme: light\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"--config", path, "a.log"}, {"--config=" + path}} {
+		cfg, err := loadConfig(args)
+		if err != nil || cfg.Theme != "light" {
+			t.Errorf("loadConfig(%v) = %+v, %v", args, cfg, err)
+		}
+	}
+	if _, err := loadConfig([]string{"--config", path + ".missing"}); err == nil {
+		t.Error("expected error for missing --config file")
+	}
+}
+
+func TestPipeMode_Grep(t *testing.T) {
+	input := `{"level":"info","msg":"GET /api/users","status":"200"}
+level=error msg="GET /api/orders" status=503
+plain text about users
+{"level":"warn","msg":"POST /api/users","status":"500"}
+`
+	run := func(args ...string) string {
+		cmd := exec.Command("go", append([]string{"run", "."}, args...)...)
+		cmd.Stdin = strings.NewReader(input)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &bytes.Buffer{}
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("command failed: %v", err)
+		}
+		return out.String()
+	}
+	check := func(out string, want, unwanted []string) {
+		t.Helper()
+		for _, w := range want {
+			if !strings.Contains(out, w) {
+				t.Errorf("expected %q in output: %q", w, out)
+			}
+		}
+		for _, u := range unwanted {
+			if strings.Contains(out, u) {
+				t.Errorf("did not expect %q in output: %q", u, out)
+			}
+		}
+	}
+
+	check(run("--grep", "users"),
+		[]string{"GET /api/users", "plain text about users", "POST /api/users"},
+		[]string{"/api/orders"})
+	check(run("--grep", "users", "--grep", "^GET"),
+		[]string{"GET /api/users"},
+		[]string{"plain text", "POST", "orders"})
+	check(run("--grep-v", "users"),
+		[]string{"GET /api/orders"},
+		[]string{"/api/users", "plain text"})
+	// Entries without the field do not match it.
+	check(run("--grep", "^5", "--grep-field", "status"),
+		[]string{"GET /api/orders", "POST /api/users"},
+		[]string{"GET /api/users", "plain text"})
+}
+
+func TestParseFlags_Grep(t *testing.T) {
+	opts, err := parseFlags([]string{"--grep", "timeout", "--grep-v", "retry", "--grep-field", "msg,error"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.keep(parser.LogEntry{Message: "timeout", Fields: map[string]string{}}) {
+		t.Error("message matching --grep should be kept")
+	}
+	if !opts.keep(parser.LogEntry{Fields: map[string]string{"error": "dial timeout"}}) {
+		t.Error("second --grep-field should be searched too")
+	}
+	if opts.keep(parser.LogEntry{Message: "timeout, retry"}) {
+		t.Error("--grep-v match should be dropped")
+	}
+	if _, err := parseFlags([]string{"--grep", "("}, config.Config{}); err == nil {
+		t.Error("expected error for invalid --grep pattern")
+	}
+}
+
+func TestParseFlags_MaxFieldLength(t *testing.T) {
+	opts, err := parseFlags([]string{"--max-field-length", "40"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.MaxFieldValueLen != 40 {
+		t.Errorf("MaxFieldValueLen = %d, want 40", opts.render.MaxFieldValueLen)
+	}
+	if _, err := parseFlags([]string{"--max-field-length", "-1"}, config.Config{}); err == nil {
+		t.Error("expected error for negative --max-field-length")
+	}
+}
+
+func TestParseFlags_AlertLevel(t *testing.T) {
+	opts, err := parseFlags(nil, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.alertLevel != tui.DefaultAlertLevel || opts.bell {
+		t.Errorf("defaults: alertLevel = %q, bell = %v", opts.alertLevel, opts.bell)
+	}
+	opts, err = parseFlags([]string{"--alert-level", "warn", "--bell"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.alertLevel != "warn" || !opts.bell {
+		t.Errorf("alertLevel = %q, bell = %v; want warn, true", opts.alertLevel, opts.bell)
+	}
+	if opts, _ = parseFlags([]string{"--alert-level", "none"}, config.Config{}); opts.alertLevel != "" {
+		t.Errorf("--alert-level none: alertLevel = %q, want empty", opts.alertLevel)
+	}
+	if _, err := parseFlags([]string{"--alert-level", "loud"}, config.Config{}); err == nil {
+		t.Error("expected error for unknown --alert-level")
+	}
+}
+
+func TestParseFlags_Framing(t *testing.T) {
+	opts, err := parseFlags([]string{"--framing", "null"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.framing != source.FramingNullDelimited {
+		t.Errorf("framing = %v, want null", opts.framing)
+	}
+	if _, err := parseFlags([]string{"--framing", "protobuf"}, config.Config{}); err == nil {
+		t.Error("expected error for unknown --framing")
+	}
+}
+
+func TestParseFlags_Encoding(t *testing.T) {
+	opts, err := parseFlags([]string{"--encoding", "utf-16le", "app.log"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.encoding != source.EncodingUTF16LE {
+		t.Errorf("encoding = %v, want utf-16le", opts.encoding)
+	}
+	if _, err := parseFlags([]string{"--encoding", "latin1"}, config.Config{}); err == nil {
+		t.Error("expected error for unknown --encoding")
+	}
+}
+
+func TestParseFlags_SeparatorCompact(t *testing.T) {
+	opts, err := parseFlags([]string{"--separator", " | ", "--compact", "--color-keys"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.Separator != " | " || !opts.render.CompactMode || !opts.render.ColorKeysByName {
+		t.Errorf("Separator = %q, CompactMode = %v, ColorKeysByName = %v", opts.render.Separator, opts.render.CompactMode, opts.render.ColorKeysByName)
+	}
+	opts, err = parseFlags(nil, config.Config{Separator: " / ", Compact: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.render.Separator != " / " || !opts.render.CompactMode {
+		t.Errorf("from config: Separator = %q, CompactMode = %v", opts.render.Separator, opts.render.CompactMode)
+	}
+}
+
+func TestParseFlags_Fields(t *testing.T) {
+	opts, err := parseFlags([]string{"--fields", "method, status,", "--exclude-fields", "pid,caller"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(opts.render.IncludeFields, ","); got != "method,status" {
+		t.Errorf("IncludeFields = %q, want method,status", got)
+	}
+	if got := strings.Join(opts.render.ExcludeFields, ","); got != "pid,caller" {
+		t.Errorf("ExcludeFields = %q, want pid,caller", got)
+	}
+	if !opts.render.ShowAllFields {
+		t.Error("--fields should show fields inline")
+	}
+	if opts, _ = parseFlags([]string{"--exclude-fields", "pid"}, config.Config{}); opts.render.ShowAllFields {
+		t.Error("--exclude-fields alone should not show fields inline")
+	}
+}
+
+func TestParseFlags_K8sSelector(t *testing.T) {
+	opts, err := parseFlags([]string{"--k8s-selector", "app=api", "--k8s-namespace", "prod"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.k8sSel != "app=api" || opts.k8sNS != "prod" {
+		t.Errorf("k8sSel = %q, k8sNS = %q", opts.k8sSel, opts.k8sNS)
+	}
+	if _, err := parseFlags([]string{"--k8s-selector", "app=api", "app.log"}, config.Config{}); err == nil {
+		t.Error("expected error combining --k8s-selector with files")
+	}
+	if _, err := parseFlags([]string{"--k8s-namespace", "prod"}, config.Config{}); err == nil {
+		t.Error("expected error for --k8s-namespace without --k8s-selector")
+	}
+}
+
+func TestPipeMode_HeadTail(t *testing.T) {
+	bin := filepath.Join(t.TempDir(), "logpilot")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %v\n%s", err, out)
+	}
+	var input strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&input, "level=info msg=\"line %d\"\n", i)
+	}
+	messages := func(out string) []string {
+		var msgs []string
+		for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+			if i := strings.Index(l, "line "); i >= 0 {
+				msgs = append(msgs, l[i:])
+			}
+		}
+		return msgs
+	}
+
+	// --head stops reading: stdin stays open, yet the process exits.
+	cmd := exec.Command(bin, "--head", "5")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(stdin, input.String())
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("--head: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("--head did not exit while stdin was still open")
+	}
+	if got := strings.Join(messages(out.String()), ","); got != "line 1,line 2,line 3,line 4,line 5" {
+		t.Errorf("--head 5 printed %q", got)
+	}
+
+	cmd = exec.Command(bin, "--tail", "5")
+	cmd.Stdin = strings.NewReader(input.String())
+	outb, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("--tail: %v", err)
+	}
+	if got := strings.Join(messages(string(outb)), ","); got != "line 96,line 97,line 98,line 99,line 100" {
+		t.Errorf("--tail 5 printed %q", got)
+	}
+}
+
+func TestPipeMode_ExportHTML(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.html")
+	cmd := exec.Command("go", "run", ".", "--tail", "2", "--export-html", report)
+	cmd.Stdin = strings.NewReader("level=info msg=first\nlevel=warn msg=second\nlevel=error msg=\"third <b>\"\n")
+	var stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = io.Discard, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v\n%s", err, stderr.String())
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	for _, want := range []string{"second", "third &lt;b&gt;", "level-warn", "level-error", "</html>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "first") {
+		t.Errorf("report holds an entry --tail did not print:\n%s", page)
+	}
+}
+
+func TestParseFlags_Glob(t *testing.T) {
+	opts, err := parseFlags([]string{"--glob-depth", "2", "--rescan-interval", "0", "logs/**/*.log"}, config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.globDepth != 2 || opts.rescanInterval() >= 0 {
+		t.Errorf("globDepth = %d, rescanInterval = %v; want 2 and disabled", opts.globDepth, opts.rescanInterval())
+	}
+	if opts, _ := parseFlags(nil, config.Config{}); opts.rescanInterval() != source.DefaultRescanInterval {
+		t.Errorf("default rescanInterval = %v", opts.rescanInterval())
+	}
+	if _, err := parseFlags([]string{"--glob-depth", "-1"}, config.Config{}); err == nil {
+		t.Error("expected error for negative --glob-depth")
+	}
+}
+
+func TestParseFlags_From(t *testing.T) {
+	tests := []struct {
+		args      []string
+		from      source.ReadFrom
+		tailLines int
+	}{
+		{nil, source.ReadLastN, DefaultTailLines},
+		{[]string{"--from", "start"}, source.ReadStart, 0},
+		{[]string{"--from", "end"}, source.ReadEnd, 0},
+		{[]string{"--from", "50"}, source.ReadLastN, 50},
+	}
+	for _, tt := range tests {
+		opts, err := parseFlags(append(tt.args, "app.log"), config.Config{})
+		if err != nil {
+			t.Fatalf("%v: %v", tt.args, err)
+		}
+		if from, n := opts.readFrom(source.ReadLastN, DefaultTailLines); from != tt.from || n != tt.tailLines {
+			t.Errorf("%v: readFrom = %v, %d; want %v, %d", tt.args, from, n, tt.from, tt.tailLines)
+		}
+	}
+	for _, args := range [][]string{{"--from", "middle"}, {"--from", "-3"}, {"--from", "end", "--no-follow"}} {
+		if _, err := parseFlags(append(args, "app.log"), config.Config{}); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestParseFlags_HeadTail(t *testing.T) {
+	if _, err := parseFlags([]string{"--head", "5", "--tail", "5"}, config.Config{}); err == nil {
+		t.Error("expected error combining --head and --tail")
+	}
+	if _, err := parseFlags([]string{"--tail", "-1"}, config.Config{}); err == nil {
+		t.Error("expected error for negative --tail")
+	}
+}
+
+func TestNoFollow_URL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "{\"level\":\"info\",\"msg\":\"from afar\"}\nlevel=warn msg=\"also remote\"\n")
+	}))
+	defer srv.Close()
+
+	cmd := exec.Command("go", "run", ".", "--no-follow", "--auth-header", "Bearer t0ken", srv.URL+"/app.log")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &bytes.Buffer{}
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("command failed: %v", err)
+	}
+	for _, want := range []string{"from afar", "also remote"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got: %q", want, out.String())
+		}
+	}
+
+	if _, err := parseFlags([]string{srv.URL, "app.log"}, config.Config{}); err == nil {
+		t.Error("expected error combining a URL with files")
+	}
+}
+
+func TestParseFlags_Preset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.SavePreset("errors", config.Preset{HiddenLevels: []string{"info"}, Theme: "light"}); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := parseFlags([]string{"--preset", "errors"}, config.Config{})
+	if err != nil {
+		t.Fatalf("parseFlags: %v", err)
+	}
+	if opts.preset == nil || opts.preset.Theme != "light" {
+		t.Errorf("preset = %+v", opts.preset)
+	}
+	if _, err := parseFlags([]string{"--preset", "nope"}, config.Config{}); err == nil || !strings.Contains(err.Error(), "--preset") {
+		t.Errorf("error = %v, want an unknown --preset error", err)
+	}
+}
@@ -1,2 +1,222 @@
 // Package config provides configuration handling for LogPilot.
 package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+	"github.com/clarabennettdev/logpilot/internal/tui"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the contents of a LogPilot configuration file. Every setting is
+// optional; command-line flags override the values set here.
+type Config struct {
+	Theme           string              `yaml:"theme"`            // see tui.ParseTheme
+	TimestampFormat string              `yaml:"timestamp_format"` // relative, iso or local
+	Level           string              `yaml:"level"`            // hide entries below this level
+	FieldOrder      []string            `yaml:"field_order"`
+	ShowAllFields   bool                `yaml:"show_all_fields"`
+	Separator       string              `yaml:"separator"` // between level, time, message and fields
+	Compact         bool                `yaml:"compact"`
+	ColorKeys       bool                `yaml:"color_keys"`
+	MarkStderr      bool                `yaml:"mark_stderr"`
+	Layout          string              `yaml:"layout"`             // inline or table
+	FollowMode      string              `yaml:"follow"`             // name or descriptor
+	GeoIPDB         string              `yaml:"geoip_db"`           // comma-separated CSV files
+	TabWidth        int                 `yaml:"tab_width"`          // columns between tab stops
+	TimePrecision   int                 `yaml:"relative_precision"` // units in relative times
+	JustNow         time.Duration       `yaml:"just_now"`           // how near now reads "just now"
+	Redact          Redact              `yaml:"redact"`
+	Schema          Schema              `yaml:"schema"`
+	Keys            map[string]string   `yaml:"keys"`         // key -> key it acts as, added to keymap
+	KeyMap          map[string][]string `yaml:"keymap"`       // action -> keys bound to it
+	ConfirmQuit     bool                `yaml:"confirm_quit"` // ask before q or ctrl+c quits the TUI
+}
+
+// Redact holds the redaction rules of a configuration file.
+type Redact struct {
+	Defaults bool     `yaml:"defaults"` // start from tui.DefaultRedactConfig
+	Fields   []string `yaml:"fields"`
+	Patterns []string `yaml:"patterns"` // regular expressions
+	Mode     string   `yaml:"mode"`     // mask or hash
+}
+
+// Schema holds the entry schema of a configuration file. Entries that do
+// not match it are flagged in the view.
+type Schema struct {
+	Required []string          `yaml:"required"`
+	Types    map[string]string `yaml:"types"` // field -> string, number, integer, bool, object or array
+}
+
+// DefaultPath returns the configuration file LogPilot reads when no
+// --config flag is given: $XDG_CONFIG_HOME/logpilot/config.yaml, falling
+// back to ~/.config/logpilot/config.yaml.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "logpilot", "config.yaml")
+}
+
+// Load reads and validates the configuration file at path.
+func Load(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("reading config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadDefault loads the file at DefaultPath. A missing file is not an
+// error and yields an empty Config.
+func LoadDefault() (Config, error) {
+	path := DefaultPath()
+	if path == "" {
+		return Config{}, nil
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	return cfg, err
+}
+
+// validate checks the enum-like and pattern fields.
+func (c Config) validate() error {
+	if c.Theme != "" {
+		if _, err := tui.ParseTheme(c.Theme); err != nil {
+			return fmt.Errorf("theme: %w", err)
+		}
+	}
+	if c.TimestampFormat != "" {
+		if _, err := tui.ParseTimestampFormat(c.TimestampFormat); err != nil {
+			return fmt.Errorf("timestamp_format: %w", err)
+		}
+	}
+	if c.Layout != "" {
+		if _, err := tui.ParseLayoutMode(c.Layout); err != nil {
+			return fmt.Errorf("layout: %w", err)
+		}
+	}
+	if c.Level != "" && !tui.ValidLevel(c.Level) {
+		return fmt.Errorf("level: unknown level %q", c.Level)
+	}
+	if c.FollowMode != "" {
+		if _, err := source.ParseFollowMode(c.FollowMode); err != nil {
+			return fmt.Errorf("follow: %w", err)
+		}
+	}
+	if c.Redact.Mode != "" {
+		if _, err := tui.ParseRedactMode(c.Redact.Mode); err != nil {
+			return fmt.Errorf("redact.mode: %w", err)
+		}
+	}
+	for _, p := range c.Redact.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("redact.patterns: %w", err)
+		}
+	}
+	if _, err := c.Schema.Validator(); err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	if _, err := c.KeyBindings(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// KeyBindings returns tui.DefaultKeyMap with the actions of the file's
+// keymap bound to the keys it lists instead, and then each key of keys
+// added to the action of the key it maps to. It reports an error for an
+// unknown action, a mapping to an unbound key or a key bound to two
+// actions.
+func (c Config) KeyBindings() (tui.KeyMap, error) {
+	km := tui.DefaultKeyMap()
+	for name, keys := range c.KeyMap {
+		if err := km.Bind(name, keys); err != nil {
+			return tui.KeyMap{}, fmt.Errorf("keymap: %w", err)
+		}
+	}
+	if err := km.Alias(c.Keys); err != nil {
+		return tui.KeyMap{}, fmt.Errorf("keys: %w", err)
+	}
+	if err := km.Validate(); err != nil {
+		return tui.KeyMap{}, fmt.Errorf("keymap: %w", err)
+	}
+	return km, nil
+}
+
+// RenderConfig returns tui.DefaultConfig with the file's rendering settings
+// applied. The configuration must have been validated by Load.
+func (c Config) RenderConfig() tui.RenderConfig {
+	rc := tui.DefaultConfig()
+	if c.Theme != "" {
+		rc.Theme, _ = tui.ParseTheme(c.Theme)
+	}
+	if c.TimestampFormat != "" {
+		rc.TimestampFormat, _ = tui.ParseTimestampFormat(c.TimestampFormat)
+	}
+	rc.FieldOrder = c.FieldOrder
+	rc.ShowAllFields = c.ShowAllFields
+	rc.Separator = c.Separator
+	rc.CompactMode = c.Compact
+	rc.RelativeTimePrecision = c.TimePrecision
+	rc.JustNowThreshold = c.JustNow
+	rc.ColorKeysByName = c.ColorKeys
+	rc.MarkStderr = c.MarkStderr
+	if c.Layout != "" {
+		rc.Layout, _ = tui.ParseLayoutMode(c.Layout)
+	}
+	rc.Redact = c.Redact.config()
+	rc.MarkIssues = !c.Schema.empty()
+	return rc
+}
+
+// config converts the file's redaction rules to a tui.RedactConfig.
+func (r Redact) config() tui.RedactConfig {
+	var rc tui.RedactConfig
+	if r.Defaults {
+		rc = tui.DefaultRedactConfig()
+	}
+	rc.Fields = append(rc.Fields, r.Fields...)
+	for _, p := range r.Patterns {
+		rc.Patterns = append(rc.Patterns, regexp.MustCompile(p))
+	}
+	if r.Mode != "" {
+		rc.Mode, _ = tui.ParseRedactMode(r.Mode)
+	}
+	return rc
+}
+
+// empty reports whether the schema sets no rules.
+func (s Schema) empty() bool {
+	return len(s.Required) == 0 && len(s.Types) == 0
+}
+
+// Validator returns a validator for the schema, or nil if it is empty.
+func (s Schema) Validator() (*parser.Validator, error) {
+	if s.empty() {
+		return nil, nil
+	}
+	return parser.NewValidator(parser.Schema{Required: s.Required, Types: s.Types})
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/tui"
+)
+
+const sampleConfig = `
+theme: light
+timestamp_format: iso
+level: warn
+field_order: [service, request_id]
+show_all_fields: true
+separator: " | "
+compact: true
+color_keys: true
+layout: table
+follow: descriptor
+redact:
+  fields: [session]
+  patterns: ['\bsk_[a-z0-9]+\b']
+  mode: hash
+keys:
+  x: q
+`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Sample(t *testing.T) {
+	cfg, err := Load(writeConfig(t, sampleConfig))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Level != "warn" || cfg.FollowMode != "descriptor" || cfg.Keys["x"] != "q" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	rc := cfg.RenderConfig()
+	if rc.Theme != tui.ThemeLight {
+		t.Errorf("Theme = %v, want light", rc.Theme)
+	}
+	if rc.TimestampFormat != tui.TimestampISO {
+		t.Errorf("TimestampFormat = %v, want ISO", rc.TimestampFormat)
+	}
+	if len(rc.FieldOrder) != 2 || rc.FieldOrder[0] != "service" {
+		t.Errorf("FieldOrder = %v", rc.FieldOrder)
+	}
+	if !rc.ShowAllFields {
+		t.Error("ShowAllFields should be set")
+	}
+	if rc.Layout != tui.LayoutTable {
+		t.Errorf("Layout = %v, want table", rc.Layout)
+	}
+	if rc.Separator != " | " || !rc.CompactMode || !rc.ColorKeysByName {
+		t.Errorf("Separator = %q, CompactMode = %v, ColorKeysByName = %v", rc.Separator, rc.CompactMode, rc.ColorKeysByName)
+	}
+	if len(rc.Redact.Fields) != 1 || len(rc.Redact.Patterns) != 1 || rc.Redact.Mode != tui.RedactHash {
+		t.Errorf("Redact = %+v", rc.Redact)
+	}
+	// Settings not in the file keep their defaults.
+	if def := tui.DefaultConfig(); rc.ANSIMode != def.ANSIMode || rc.TerminalWidth != def.TerminalWidth {
+		t.Error("unset settings should keep defaults")
+	}
+}
+
+func TestLoad_RedactDefaults(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "redact:\n  defaults: true\n  fields: [ssn]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := cfg.RenderConfig().Redact
+	if len(rc.Fields) != len(tui.DefaultRedactConfig().Fields)+1 {
+		t.Errorf("Fields = %v, want defaults plus ssn", rc.Fields)
+	}
+}
+
+func TestLoad_Schema(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "schema:\n  required: [request_id]\n  types:\n    status: integer\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.RenderConfig().MarkIssues {
+		t.Error("MarkIssues should be set with a schema")
+	}
+	v, err := cfg.Schema.Validator()
+	if err != nil || v == nil {
+		t.Fatalf("Validator = %v, %v", v, err)
+	}
+	if issues := v.Validate(parser.LogEntry{Fields: map[string]string{"status": "OK"}}); len(issues) != 2 {
+		t.Errorf("issues = %q, want a missing request_id and a bad status", issues)
+	}
+
+	if v, err := (Schema{}).Validator(); v != nil || err != nil {
+		t.Errorf("empty schema: Validator = %v, %v, want nil", v, err)
+	}
+	if (Config{}).RenderConfig().MarkIssues {
+		t.Error("MarkIssues should be off without a schema")
+	}
+}
+
+func TestLoad_KeyMap(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "keymap:\n  down: [n, ctrl+n]\n  stats: []\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	km, err := cfg.KeyBindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(km.Down, " ") != "n ctrl+n" || len(km.Stats) != 0 || strings.Join(km.Up, " ") != "k up" {
+		t.Errorf("Down = %q, Stats = %q, Up = %q", km.Down, km.Stats, km.Up)
+	}
+}
+
+func TestLoad_InvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"theme":     "theme: neon\n",
+		"timestamp": "timestamp_format: sometimes\n",
+		"level":     "level: loud\n",
+		"layout":    "layout: grid\n",
+		"follow":    "follow: maybe\n",
+		"mode":      "redact:\n  mode: shred\n",
+		"pattern":   "redact:\n  patterns: ['(']\n",
+		"schema":    "schema:\n  types:\n    status: int\n",
+		"conflict":  "keymap:\n  down: [n, k]\n",
+		"action":    "keymap:\n  scroll: [n]\n",
+		"remap":     "keys:\n  n: j\nkeymap:\n  top: [n]\n",
+		"unbound":   "keys:\n  x: Z\n",
+		"yaml":      "theme: [dark\n",
+	}
+	for name, content := range tests {
+		_, err := Load(writeConfig(t, content))
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+			continue
+		}
+		if !strings.Contains(err.Error(), "config") {
+			t.Errorf("%s: error should name the config file: %v", name, err)
+		}
+	}
+}
+
+func TestLoadDefault_XDG(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if got, want := DefaultPath(), filepath.Join(dir, "logpilot", "config.yaml"); got != want {
+		t.Errorf("DefaultPath = %q, want %q", got, want)
+	}
+
+	// A missing default file is fine.
+	cfg, err := LoadDefault()
+	if err != nil || cfg.Theme != "" {
+		t.Errorf("LoadDefault without file = %+v, %v", cfg, err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "logpilot"), 0o755)
+	os.WriteFile(DefaultPath(), []byte("theme: light\n"), 0o644)
+	if cfg, err := LoadDefault(); err != nil || cfg.Theme != "light" {
+		t.Errorf("LoadDefault = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoad_MissingExplicitFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "nope.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/clarabennettdev/logpilot/internal/tui"
+	"gopkg.in/yaml.v3"
+)
+
+// Preset is a named set of view settings, saved from the TUI (key P) and
+// loaded with the preset picker (key p) or --preset.
+type Preset struct {
+	Name         string   `yaml:"name"`
+	Filter       string   `yaml:"filter,omitempty"`        // regular expression matched against messages
+	HiddenLevels []string `yaml:"hidden_levels,omitempty"` // levels toggled off
+	FieldOrder   []string `yaml:"field_order,omitempty"`
+	HiddenFields []string `yaml:"hidden_fields,omitempty"` // fields not displayed
+	Theme        string   `yaml:"theme,omitempty"`         // see tui.ParseTheme
+}
+
+// presetsFile is the layout of the presets file.
+type presetsFile struct {
+	Presets []Preset `yaml:"presets"`
+}
+
+// PresetsPath returns the file presets are kept in: presets.yaml next to
+// the default configuration file.
+func PresetsPath() string {
+	path := DefaultPath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "presets.yaml")
+}
+
+// LoadPresets reads the saved presets in the order they were first saved.
+// A missing presets file is not an error and yields no presets.
+func LoadPresets() ([]Preset, error) {
+	path := PresetsPath()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading presets: %w", err)
+	}
+	var f presetsFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing presets %s: %w", path, err)
+	}
+	for _, p := range f.Presets {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("presets %s: %w", path, err)
+		}
+	}
+	return f.Presets, nil
+}
+
+// FindPreset returns the saved preset called name.
+func FindPreset(name string) (Preset, error) {
+	presets, err := LoadPresets()
+	if err != nil {
+		return Preset{}, err
+	}
+	for _, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("no preset named %q", name)
+}
+
+// SavePreset saves p under name, replacing any preset of that name.
+func SavePreset(name string, p Preset) error {
+	p.Name = name
+	if err := p.validate(); err != nil {
+		return err
+	}
+	path := PresetsPath()
+	if path == "" {
+		return fmt.Errorf("saving preset: no config directory")
+	}
+	presets, err := LoadPresets()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range presets {
+		if presets[i].Name == name {
+			presets[i], replaced = p, true
+		}
+	}
+	if !replaced {
+		presets = append(presets, p)
+	}
+
+	data, err := yaml.Marshal(presetsFile{Presets: presets})
+	if err != nil {
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	// Replace the file atomically so a crash never loses the other presets.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("saving preset: %w", err)
+	}
+	return nil
+}
+
+// validate checks the name, filter, levels and theme.
+func (p Preset) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("preset without a name")
+	}
+	if _, err := regexp.Compile(p.Filter); err != nil {
+		return fmt.Errorf("preset %q: filter: %w", p.Name, err)
+	}
+	for _, l := range p.HiddenLevels {
+		if !tui.ValidLevel(l) {
+			return fmt.Errorf("preset %q: hidden_levels: unknown level %q", p.Name, l)
+		}
+	}
+	if p.Theme != "" {
+		if _, err := tui.ParseTheme(p.Theme); err != nil {
+			return fmt.Errorf("preset %q: theme: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// TUI converts p to a tui.Preset. Without a theme the preset keeps
+// defaultTheme. p must have been validated, as by LoadPresets.
+func (p Preset) TUI(defaultTheme tui.Theme) tui.Preset {
+	tp := tui.Preset{
+		Name:         p.Name,
+		Filter:       p.Filter,
+		HiddenLevels: p.HiddenLevels,
+		FieldOrder:   p.FieldOrder,
+		HiddenFields: p.HiddenFields,
+		Theme:        defaultTheme,
+	}
+	if p.Theme != "" {
+		tp.Theme, _ = tui.ParseTheme(p.Theme)
+	}
+	return tp
+}
+
+// PresetStore keeps the TUI's presets in the presets file.
+type PresetStore struct {
+	// DefaultTheme is used for presets saved without a theme.
+	DefaultTheme tui.Theme
+}
+
+// Presets implements tui.PresetStore.
+func (s PresetStore) Presets() ([]tui.Preset, error) {
+	presets, err := LoadPresets()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]tui.Preset, len(presets))
+	for i, p := range presets {
+		out[i] = p.TUI(s.DefaultTheme)
+	}
+	return out, nil
+}
+
+// SavePreset implements tui.PresetStore.
+func (s PresetStore) SavePreset(p tui.Preset) error {
+	return SavePreset(p.Name, Preset{
+		Filter:       p.Filter,
+		HiddenLevels: p.HiddenLevels,
+		FieldOrder:   p.FieldOrder,
+		HiddenFields: p.HiddenFields,
+		Theme:        p.Theme.String(),
+	})
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/tui"
+)
+
+func TestPresets_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if got, want := PresetsPath(), filepath.Join(dir, "logpilot", "presets.yaml"); got != want {
+		t.Errorf("PresetsPath = %q, want %q", got, want)
+	}
+
+	// No file yet: no presets, no error.
+	if presets, err := LoadPresets(); err != nil || len(presets) != 0 {
+		t.Fatalf("LoadPresets without file = %v, %v", presets, err)
+	}
+
+	errPreset := Preset{Filter: "timeout|refused", HiddenLevels: []string{"debug", "trace"}, FieldOrder: []string{"service"}, HiddenFields: []string{"pid"}, Theme: "light"}
+	if err := SavePreset("errors", errPreset); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	if err := SavePreset("all", Preset{Theme: "dark"}); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	// Saving under an existing name replaces that preset in place.
+	errPreset.Filter = "refused"
+	if err := SavePreset("errors", errPreset); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+
+	presets, err := LoadPresets()
+	if err != nil {
+		t.Fatalf("LoadPresets: %v", err)
+	}
+	if len(presets) != 2 || presets[0].Name != "errors" || presets[1].Name != "all" {
+		t.Fatalf("presets = %+v, want errors then all", presets)
+	}
+	p := presets[0]
+	if p.Filter != "refused" || len(p.HiddenLevels) != 2 || p.HiddenLevels[1] != "trace" || p.FieldOrder[0] != "service" || p.HiddenFields[0] != "pid" || p.Theme != "light" {
+		t.Errorf("errors preset = %+v", p)
+	}
+
+	if p, err := FindPreset("all"); err != nil || p.Theme != "dark" {
+		t.Errorf("FindPreset(all) = %+v, %v", p, err)
+	}
+	if _, err := FindPreset("missing"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
+
+func TestPresets_Invalid(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	tests := map[string]Preset{
+		"filter": {Filter: "("},
+		"level":  {HiddenLevels: []string{"loud"}},
+		"theme":  {Theme: "neon"},
+	}
+	for name, p := range tests {
+		if err := SavePreset(name, p); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := SavePreset("", Preset{}); err == nil {
+		t.Error("expected error for a preset without a name")
+	}
+	if _, err := os.Stat(PresetsPath()); err == nil {
+		t.Error("invalid presets should not be written")
+	}
+
+	os.MkdirAll(filepath.Dir(PresetsPath()), 0o755)
+	os.WriteFile(PresetsPath(), []byte("presets:\n  - name: x\n    theme: neon\n"), 0o644)
+	if _, err := LoadPresets(); err == nil {
+		t.Error("expected error loading an invalid presets file")
+	}
+}
+
+func TestPresetStore(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	store := PresetStore{DefaultTheme: tui.ThemeSolarizedDark}
+	want := tui.Preset{Name: "api", Filter: "GET", HiddenLevels: []string{"info"}, FieldOrder: []string{"path"}, Theme: tui.ThemeHighContrast}
+	if err := store.SavePreset(want); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	if err := SavePreset("plain", Preset{}); err != nil {
+		t.Fatal(err)
+	}
+
+	presets, err := store.Presets()
+	if err != nil || len(presets) != 2 {
+		t.Fatalf("Presets = %+v, %v", presets, err)
+	}
+	got := presets[0]
+	if got.Name != want.Name || got.Filter != want.Filter || got.HiddenLevels[0] != "info" || got.FieldOrder[0] != "path" || got.Theme != want.Theme {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+	if presets[1].Theme != tui.ThemeSolarizedDark {
+		t.Errorf("preset without a theme got %v, want the default theme", presets[1].Theme)
+	}
+}
@@ -0,0 +1,375 @@
+// Package enrich adds derived fields to parsed log entries.
+package enrich
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// GeoInfo is what a GeoProvider knows about an address.
+type GeoInfo struct {
+	Country string // ISO 3166-1 alpha-2 code, e.g. "US"
+	ASN     uint32 // autonomous system number; 0 if unknown
+	Org     string // autonomous system organization
+}
+
+// GeoProvider looks up geo/ASN information for an IP address. Lookups must
+// be local; enrichment never touches the network.
+type GeoProvider interface {
+	Lookup(ip netip.Addr) (GeoInfo, bool)
+}
+
+// GeoEnricher annotates IP-valued fields with "<field>_country" and
+// "<field>_asn" fields. It implements parser.Enricher.
+type GeoEnricher struct {
+	provider GeoProvider
+}
+
+// NewGeoEnricher creates an enricher backed by provider.
+func NewGeoEnricher(provider GeoProvider) *GeoEnricher {
+	return &GeoEnricher{provider: provider}
+}
+
+// Enrich adds geo fields for every field whose value is an IP address (with
+// or without a port). Existing fields are never overwritten.
+func (g *GeoEnricher) Enrich(entry *parser.LogEntry) {
+	if len(entry.Fields) == 0 {
+		return
+	}
+	added := make(map[string]string)
+	for k, v := range entry.Fields {
+		ip, ok := parseIP(v)
+		if !ok {
+			continue
+		}
+		info, ok := g.provider.Lookup(ip)
+		if !ok {
+			continue
+		}
+		if info.Country != "" {
+			added[k+"_country"] = info.Country
+		}
+		if info.ASN != 0 {
+			asn := "AS" + strconv.FormatUint(uint64(info.ASN), 10)
+			if info.Org != "" {
+				asn += " " + info.Org
+			}
+			added[k+"_asn"] = asn
+		}
+	}
+	for k, v := range added {
+		if _, exists := entry.Fields[k]; !exists {
+			entry.Fields[k] = v
+		}
+	}
+}
+
+// parseIP parses an address such as "10.0.0.1", "::1" or "10.0.0.1:443".
+func parseIP(v string) (netip.Addr, bool) {
+	v = strings.TrimSpace(v)
+	if ip, err := netip.ParseAddr(v); err == nil {
+		return ip.Unmap(), true
+	}
+	if host, _, err := net.SplitHostPort(v); err == nil {
+		if ip, err := netip.ParseAddr(host); err == nil {
+			return ip.Unmap(), true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// GeoDatabase is an in-memory GeoProvider loaded from local CSV files.
+type GeoDatabase struct {
+	tables []*geoTable
+}
+
+// geoTable holds the networks of one CSV file.
+type geoTable struct {
+	networks map[netip.Prefix]GeoInfo
+	bits     []int // distinct prefix lengths present, longest first
+}
+
+// geoColumns are the columns a geo database file may have. Columns mapped
+// to "" are known but unused.
+var geoColumns = map[string]string{
+	"network":                        "network",
+	"country_iso_code":               "country",
+	"autonomous_system_number":       "asn",
+	"autonomous_system_organization": "org",
+	"geoname_id":                     "geoname",
+	"registered_country_geoname_id":  "registered",
+	"represented_country_geoname_id": "",
+	"is_anonymous_proxy":             "",
+	"is_satellite_provider":          "",
+	"is_anycast":                     "",
+	"postal_code":                    "",
+	"latitude":                       "",
+	"longitude":                      "",
+	"accuracy_radius":                "",
+}
+
+// OpenGeoDatabase loads a geo/ASN database from one or more CSV files. A
+// file starts with a header row naming its columns, in any order:
+//
+//	network,country_iso_code,autonomous_system_number,autonomous_system_organization
+//
+// network is required and the others are optional; lines starting with
+// '#' are skipped. The block files of MaxMind's GeoLite2 CSV downloads have
+// such headers: the ASN blocks (network, autonomous_system_number,
+// autonomous_system_organization) are read as they are, and the Country
+// and City blocks, which name countries by geoname_id, are read together
+// with the locations file next to them (GeoLite2-Country-Locations-en.csv
+// for GeoLite2-Country-Blocks-IPv4.csv). A lookup takes each detail from
+// the first file that has it.
+func OpenGeoDatabase(paths ...string) (*GeoDatabase, error) {
+	db := &GeoDatabase{}
+	for _, path := range paths {
+		t, err := openGeoTable(path)
+		if err != nil {
+			return nil, err
+		}
+		db.tables = append(db.tables, t)
+	}
+	return db, nil
+}
+
+// openGeoTable reads the CSV file at path, and the locations file next to
+// it if its countries are geoname IDs.
+func openGeoTable(path string) (*geoTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening geo database: %w", err)
+	}
+	defer f.Close()
+	t, err := readGeoTable(f, func() (map[string]string, error) {
+		return readGeoLocations(locationsPath(path))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// locationsPath returns the GeoLite2 locations file belonging to the
+// blocks file at path.
+func locationsPath(path string) string {
+	dir, name := filepath.Split(path)
+	for _, blocks := range []string{"-Blocks-IPv4", "-Blocks-IPv6"} {
+		if i := strings.Index(name, blocks); i >= 0 {
+			return dir + name[:i] + "-Locations-en.csv"
+		}
+	}
+	return dir + "Locations-en.csv"
+}
+
+// ReadGeoDatabase parses a geo/ASN CSV database; see OpenGeoDatabase.
+// Countries given as geoname IDs cannot be resolved and are an error.
+func ReadGeoDatabase(r io.Reader) (*GeoDatabase, error) {
+	t, err := readGeoTable(r, func() (map[string]string, error) {
+		return nil, errors.New("countries are geoname IDs; open the file with its locations file next to it")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GeoDatabase{tables: []*geoTable{t}}, nil
+}
+
+// newGeoReader returns a CSV reader for a geo database file.
+func newGeoReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return cr
+}
+
+// geoLocationColumns are the columns of a locations file that are used;
+// the others are ignored.
+var geoLocationColumns = map[string]string{
+	"geoname_id":       "geoname",
+	"country_iso_code": "country",
+}
+
+// readGeoHeader reads the header row of a geo database file and returns
+// the index of each of the columns in want, or -1 where it is missing.
+// Columns not in known are an error if strict, and ignored otherwise.
+func readGeoHeader(cr *csv.Reader, known map[string]string, strict bool, want ...string) ([]int, error) {
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading geo database: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+		col, ok := known[name]
+		if !ok && strict {
+			return nil, fmt.Errorf("unknown column %q in the header row", name)
+		}
+		if col != "" {
+			cols[col] = i
+		}
+	}
+	idx := make([]int, len(want))
+	for i, col := range want {
+		idx[i] = -1
+		if n, ok := cols[col]; ok {
+			idx[i] = n
+		}
+	}
+	return idx, nil
+}
+
+// readGeoTable reads a geo database file. locations is called for the
+// geoname ID -> country code table if the file names countries that way.
+func readGeoTable(r io.Reader, locations func() (map[string]string, error)) (*geoTable, error) {
+	cr := newGeoReader(r)
+	idx, err := readGeoHeader(cr, geoColumns, true, "network", "country", "asn", "org", "geoname", "registered")
+	if err != nil {
+		return nil, err
+	}
+	network, country, asnCol, org, geoname, registered := idx[0], idx[1], idx[2], idx[3], idx[4], idx[5]
+	if network < 0 {
+		return nil, errors.New("the header row lacks the network column")
+	}
+	var countries map[string]string
+	if country < 0 && (geoname >= 0 || registered >= 0) {
+		if countries, err = locations(); err != nil {
+			return nil, err
+		}
+	}
+	field := func(row []string, i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return row[i]
+	}
+
+	t := &geoTable{networks: make(map[netip.Prefix]GeoInfo)}
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading geo database: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		prefix, err := netip.ParsePrefix(field(row, network))
+		if err != nil {
+			return nil, fmt.Errorf("geo database line %d: %w", line, err)
+		}
+		info := GeoInfo{Country: field(row, country), Org: field(row, org)}
+		if countries != nil {
+			// The country the network is in, or else the one it is
+			// registered in.
+			info.Country = countries[field(row, geoname)]
+			if info.Country == "" {
+				info.Country = countries[field(row, registered)]
+			}
+		}
+		if v := field(row, asnCol); v != "" {
+			asn, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("geo database line %d: invalid ASN %q", line, v)
+			}
+			info.ASN = uint32(asn)
+		}
+		t.add(prefix.Masked(), info)
+	}
+	return t, nil
+}
+
+// readGeoLocations reads a GeoLite2 locations file into a geoname ID ->
+// country code table.
+func readGeoLocations(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("countries are geoname IDs: opening the locations file: %w", err)
+	}
+	defer f.Close()
+	cr := newGeoReader(f)
+	idx, err := readGeoHeader(cr, geoLocationColumns, false, "geoname", "country")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if idx[0] < 0 || idx[1] < 0 {
+		return nil, fmt.Errorf("%s: the header row lacks geoname_id or country_iso_code", path)
+	}
+	countries := make(map[string]string)
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return countries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if max(idx[0], idx[1]) < len(row) {
+			countries[row[idx[0]]] = row[idx[1]]
+		}
+	}
+}
+
+// add records a network, keeping t.bits sorted longest first.
+func (t *geoTable) add(prefix netip.Prefix, info GeoInfo) {
+	t.networks[prefix] = info
+	bits := prefix.Bits()
+	for i, b := range t.bits {
+		if b == bits {
+			return
+		}
+		if b < bits {
+			t.bits = append(t.bits[:i], append([]int{bits}, t.bits[i:]...)...)
+			return
+		}
+	}
+	t.bits = append(t.bits, bits)
+}
+
+// lookup returns the most specific network of t containing ip.
+func (t *geoTable) lookup(ip netip.Addr) (GeoInfo, bool) {
+	for _, bits := range t.bits {
+		prefix, err := ip.Prefix(bits)
+		if err != nil {
+			continue // prefix length belongs to the other address family
+		}
+		if info, ok := t.networks[prefix]; ok {
+			return info, true
+		}
+	}
+	return GeoInfo{}, false
+}
+
+// Lookup returns what the files know about ip, each detail from the most
+// specific network containing ip in the first file that has it.
+func (db *GeoDatabase) Lookup(ip netip.Addr) (GeoInfo, bool) {
+	var info GeoInfo
+	found := false
+	for _, t := range db.tables {
+		ti, ok := t.lookup(ip)
+		if !ok {
+			continue
+		}
+		found = true
+		if info.Country == "" {
+			info.Country = ti.Country
+		}
+		if info.ASN == 0 {
+			info.ASN, info.Org = ti.ASN, ti.Org
+		}
+	}
+	return info, found
+}
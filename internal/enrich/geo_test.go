@@ -0,0 +1,186 @@
+package enrich
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// stubGeo answers lookups from a fixed table and records what was asked.
+type stubGeo struct {
+	table   map[string]GeoInfo
+	queried []string
+}
+
+func (s *stubGeo) Lookup(ip netip.Addr) (GeoInfo, bool) {
+	s.queried = append(s.queried, ip.String())
+	info, ok := s.table[ip.String()]
+	return info, ok
+}
+
+func TestGeoEnricher_IPFields(t *testing.T) {
+	stub := &stubGeo{table: map[string]GeoInfo{
+		"203.0.113.7": {Country: "NL", ASN: 64500, Org: "Example Net"},
+		"2001:db8::1": {Country: "DE"},
+	}}
+	entry := parser.LogEntry{Fields: map[string]string{
+		"client_ip": "203.0.113.7",
+		"upstream":  "[2001:db8::1]:8080",
+		"user":      "alice",
+		"status":    "200",
+	}}
+
+	NewGeoEnricher(stub).Enrich(&entry)
+
+	want := map[string]string{
+		"client_ip_country": "NL",
+		"client_ip_asn":     "AS64500 Example Net",
+		"upstream_country":  "DE",
+	}
+	for k, v := range want {
+		if entry.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, entry.Fields[k], v)
+		}
+	}
+	for _, k := range []string{"upstream_asn", "user_country", "status_country"} {
+		if _, ok := entry.Fields[k]; ok {
+			t.Errorf("unexpected field %s", k)
+		}
+	}
+	if len(stub.queried) != 2 {
+		t.Errorf("non-IP values should not be looked up, queried %v", stub.queried)
+	}
+}
+
+func TestGeoEnricher_DoesNotOverwrite(t *testing.T) {
+	stub := &stubGeo{table: map[string]GeoInfo{"198.51.100.1": {Country: "US"}}}
+	entry := parser.LogEntry{Fields: map[string]string{
+		"ip":         "198.51.100.1",
+		"ip_country": "already set",
+	}}
+	NewGeoEnricher(stub).Enrich(&entry)
+	if entry.Fields["ip_country"] != "already set" {
+		t.Errorf("existing field overwritten: %q", entry.Fields["ip_country"])
+	}
+}
+
+func TestGeoEnricher_ViaAutoParser(t *testing.T) {
+	stub := &stubGeo{table: map[string]GeoInfo{"203.0.113.7": {Country: "NL"}}}
+	p := parser.NewAutoParser()
+	p.AddEnricher(NewGeoEnricher(stub))
+
+	entry := p.Parse(`level=warn msg="failed login" src=203.0.113.7`)
+	if entry.Fields["src_country"] != "NL" {
+		t.Errorf("src_country = %q, want NL", entry.Fields["src_country"])
+	}
+}
+
+const sampleDB = `network,country_iso_code,autonomous_system_number,autonomous_system_organization
+# documentation ranges
+203.0.113.0/24,NL,64500,Example Net
+203.0.113.128/25,BE,64501,Example Sub
+2001:db8::/32,DE,,
+198.51.100.0/24,US
+`
+
+func TestGeoDatabase_Lookup(t *testing.T) {
+	db, err := ReadGeoDatabase(strings.NewReader(sampleDB))
+	if err != nil {
+		t.Fatalf("ReadGeoDatabase: %v", err)
+	}
+	tests := []struct {
+		ip      string
+		country string
+		asn     uint32
+		found   bool
+	}{
+		{"203.0.113.7", "NL", 64500, true},
+		{"203.0.113.200", "BE", 64501, true}, // most specific prefix wins
+		{"2001:db8::1", "DE", 0, true},
+		{"198.51.100.9", "US", 0, true},
+		{"192.0.2.1", "", 0, false},
+	}
+	for _, tt := range tests {
+		info, ok := db.Lookup(netip.MustParseAddr(tt.ip))
+		if ok != tt.found || info.Country != tt.country || info.ASN != tt.asn {
+			t.Errorf("Lookup(%s) = %+v, %v", tt.ip, info, ok)
+		}
+	}
+}
+
+func TestGeoDatabase_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"network":        "network,country_iso_code\nnot-a-network,US\n",
+		"ASN":            "network,country_iso_code,autonomous_system_number\n10.0.0.0/8,US,notanumber\n",
+		"no header":      "10.0.0.0/8,US\n",
+		"unknown column": "network,country,asn\n10.0.0.0/8,US,64500\n",
+		"no network":     "country_iso_code\nUS\n",
+		"empty":          "",
+		// Geoname IDs need the locations file, which a reader lacks.
+		"geoname IDs": "network,geoname_id,registered_country_geoname_id\n10.0.0.0/8,2750405,2750405\n",
+	}
+	for name, content := range tests {
+		if _, err := ReadGeoDatabase(strings.NewReader(content)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := OpenGeoDatabase("/nonexistent/geo.csv"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// writeGeoFile writes a geo database file named name into dir.
+func writeGeoFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenGeoDatabase_GeoLite2(t *testing.T) {
+	dir := t.TempDir()
+	asn := writeGeoFile(t, dir, "GeoLite2-ASN-Blocks-IPv4.csv", `network,autonomous_system_number,autonomous_system_organization
+203.0.113.0/24,64500,"Example Net, Inc."
+`)
+	country := writeGeoFile(t, dir, "GeoLite2-Country-Blocks-IPv4.csv", `network,geoname_id,registered_country_geoname_id,represented_country_geoname_id,is_anonymous_proxy,is_satellite_provider,is_anycast
+203.0.113.0/25,2750405,2750405,,0,0,
+203.0.113.128/25,,2802361,,0,0,
+198.51.100.0/24,6252001,6252001,,0,0,
+`)
+	writeGeoFile(t, dir, "GeoLite2-Country-Locations-en.csv", `geoname_id,locale_code,continent_code,continent_name,country_iso_code,country_name,is_in_european_union
+2750405,en,EU,Europe,NL,Netherlands,1
+2802361,en,EU,Europe,BE,Belgium,1
+6252001,en,NA,"North America",US,"United States",0
+`)
+
+	db, err := OpenGeoDatabase(asn, country)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ip   string
+		want GeoInfo
+	}{
+		{"203.0.113.7", GeoInfo{Country: "NL", ASN: 64500, Org: "Example Net, Inc."}},
+		{"203.0.113.200", GeoInfo{Country: "BE", ASN: 64500, Org: "Example Net, Inc."}}, // registered country
+		{"198.51.100.9", GeoInfo{Country: "US"}},
+	}
+	for _, tt := range tests {
+		if info, ok := db.Lookup(netip.MustParseAddr(tt.ip)); !ok || info != tt.want {
+			t.Errorf("Lookup(%s) = %+v, %v, want %+v", tt.ip, info, ok, tt.want)
+		}
+	}
+
+	// Without its locations file, a Country blocks file cannot be read.
+	other := t.TempDir()
+	lone := writeGeoFile(t, other, "GeoLite2-Country-Blocks-IPv6.csv", "network,geoname_id\n2001:db8::/32,2921044\n")
+	if _, err := OpenGeoDatabase(lone); err == nil || !strings.Contains(err.Error(), "GeoLite2-Country-Locations-en.csv") {
+		t.Errorf("OpenGeoDatabase without locations: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cefHeaderFields names the seven pipe-delimited CEF header fields as they
+// are stored in Fields. The severity is mapped into Level instead.
+var cefHeaderFields = [...]string{"cef_version", "device_vendor", "device_product", "device_version", "signature_id", "name", "severity"}
+
+// cefTimeLayouts are the "rt" (receipt time) formats CEF allows besides
+// epoch milliseconds.
+var cefTimeLayouts = []string{
+	"Jan 02 2006 15:04:05.000 MST",
+	"Jan 02 2006 15:04:05 MST",
+	"Jan 02 2006 15:04:05.000",
+	"Jan 02 2006 15:04:05",
+}
+
+// isCEF reports whether a line is an ArcSight Common Event Format record.
+func isCEF(line string) bool {
+	return strings.HasPrefix(line, "CEF:")
+}
+
+// CEFParser parses Common Event Format lines such as
+// "CEF:0|Vendor|Product|1.0|100|Event|5|src=1.2.3.4 dst=5.6.7.8".
+type CEFParser struct {
+	timeCfg TimeConfig
+}
+
+// SetTimeConfig sets how zoneless timestamps are interpreted.
+func (p *CEFParser) SetTimeConfig(cfg TimeConfig) { p.timeCfg = cfg }
+
+// Parse parses a CEF line. The header fields go into Fields, the event name
+// becomes the message and the 0–10 severity is mapped to a level. The
+// extension's key=value pairs are added to Fields; its "rt" key, if present,
+// sets the timestamp.
+func (p *CEFParser) Parse(line string) LogEntry {
+	entry := LogEntry{
+		Raw:       line,
+		Format:    FormatCEF,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+	}
+
+	header, extension, ok := splitCEFHeader(strings.TrimPrefix(strings.TrimSpace(line), "CEF:"))
+	if !ok {
+		entry.Message = line
+		return entry
+	}
+	for i, name := range cefHeaderFields {
+		entry.Fields[name] = header[i]
+	}
+	entry.Message = header[5]
+	entry.Level = cefLevel(header[6])
+
+	for k, v := range parseCEFExtension(extension) {
+		entry.Fields[k] = v
+	}
+	if rt, ok := entry.Fields["rt"]; ok {
+		entry.Timestamp = p.parseTime(rt)
+	}
+	return entry
+}
+
+// splitCEFHeader splits the seven header fields off s (the record without
+// its "CEF:" prefix) and unescapes "\|" and "\\" in them. The extension may
+// be empty or missing altogether.
+func splitCEFHeader(s string) (header [7]string, extension string, ok bool) {
+	var b strings.Builder
+	n := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\'):
+			i++
+			b.WriteByte(s[i])
+		case c == '|':
+			header[n] = b.String()
+			b.Reset()
+			n++
+			if n == len(header) {
+				return header, s[i+1:], true
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if n == len(header)-1 {
+		// No pipe after the severity: the extension is missing.
+		header[n] = b.String()
+		return header, "", true
+	}
+	return header, "", false
+}
+
+// parseCEFExtension parses space-separated key=value pairs. Values run up to
+// the next key, so they may contain spaces; "\=", "\\", "\n" and "\r" are
+// unescaped.
+func parseCEFExtension(s string) map[string]string {
+	fields := make(map[string]string)
+	key, start := "", -1
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++ // skip the escaped character
+		case '=':
+			// The key is the word before '='; the previous value ends before it.
+			ks := strings.LastIndexByte(s[:i], ' ') + 1
+			if !isCEFKey(s[ks:i]) {
+				continue
+			}
+			if start >= 0 {
+				fields[key] = unescapeCEFValue(strings.TrimSpace(s[start:ks]))
+			}
+			key, start = s[ks:i], i+1
+		}
+	}
+	if start >= 0 {
+		fields[key] = unescapeCEFValue(strings.TrimSpace(s[start:]))
+	}
+	return fields
+}
+
+// isCEFKey reports whether k can be an extension key, so that an unescaped
+// '=' inside a value such as a URL is not mistaken for a new pair.
+func isCEFKey(k string) bool {
+	if k == "" {
+		return false
+	}
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_.-[]", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// unescapeCEFValue resolves the escapes allowed in extension values.
+func unescapeCEFValue(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i+1 == len(v) {
+			b.WriteByte(v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(v[i])
+		}
+	}
+	return b.String()
+}
+
+// cefLevel maps a CEF severity to a canonical level: 0–3 (Low) is INFO,
+// 4–6 (Medium) WARN, 7–8 (High) ERROR and 9–10 (Very-High) FATAL. The
+// named severities of CEF 0.1 are accepted as well.
+func cefLevel(severity string) string {
+	n, err := strconv.Atoi(severity)
+	if err != nil {
+		switch strings.ToLower(severity) {
+		case "low":
+			n = 0
+		case "medium":
+			n = 4
+		case "high":
+			n = 7
+		case "very-high":
+			n = 9
+		default:
+			return ""
+		}
+	}
+	switch {
+	case n < 0 || n > 10:
+		return ""
+	case n <= 3:
+		return "INFO"
+	case n <= 6:
+		return "WARN"
+	case n <= 8:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+// parseTime parses an "rt" value: epoch milliseconds or one of the CEF
+// date layouts.
+func (p *CEFParser) parseTime(v string) time.Time {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms)
+	}
+	for _, layout := range cefTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, p.timeCfg.location()); err == nil {
+			return t
+		}
+	}
+	return p.timeCfg.parse(v)
+}
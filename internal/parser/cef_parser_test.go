@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCEFParser_Basic(t *testing.T) {
+	p := &CEFParser{}
+	e := p.Parse(`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232 msg=Detected a threat. No action needed. rt=1739822400123`)
+
+	if e.Format != FormatCEF {
+		t.Errorf("Format = %v, want cef", e.Format)
+	}
+	if e.Message != "worm successfully stopped" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Level != "FATAL" {
+		t.Errorf("Level = %q, want FATAL", e.Level)
+	}
+	if want := time.UnixMilli(1739822400123); !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+
+	fields := map[string]string{
+		"cef_version":    "0",
+		"device_vendor":  "Security",
+		"device_product": "threatmanager",
+		"device_version": "1.0",
+		"signature_id":   "100",
+		"severity":       "10",
+		"src":            "10.0.0.1",
+		"dst":            "2.1.2.2",
+		"spt":            "1232",
+		"msg":            "Detected a threat. No action needed.",
+	}
+	for k, v := range fields {
+		if e.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, e.Fields[k], v)
+		}
+	}
+}
+
+func TestCEFParser_Escapes(t *testing.T) {
+	p := &CEFParser{}
+	e := p.Parse(`CEF:0|Acme\|Corp|Fire\\wall|2.0|42|Blocked|3|act=blocked\=yes path=C:\\Temp note=line1\nline2 url=http://x/?a=b`)
+
+	fields := map[string]string{
+		"device_vendor":  "Acme|Corp",
+		"device_product": `Fire\wall`,
+		"act":            "blocked=yes",
+		"path":           `C:\Temp`,
+		"note":           "line1\nline2",
+		"url":            "http://x/?a=b",
+	}
+	for k, v := range fields {
+		if e.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, e.Fields[k], v)
+		}
+	}
+	if e.Message != "Blocked" || e.Level != "INFO" {
+		t.Errorf("Message = %q, Level = %q", e.Message, e.Level)
+	}
+}
+
+func TestCEFParser_MissingExtension(t *testing.T) {
+	p := &CEFParser{}
+	for _, line := range []string{
+		"CEF:0|Vendor|Product|1.0|100|Event|5|",
+		"CEF:0|Vendor|Product|1.0|100|Event|5",
+	} {
+		e := p.Parse(line)
+		if e.Message != "Event" || e.Level != "WARN" {
+			t.Errorf("%q: Message = %q, Level = %q", line, e.Message, e.Level)
+		}
+		if len(e.Fields) != len(cefHeaderFields) {
+			t.Errorf("%q: expected only header fields, got %v", line, e.Fields)
+		}
+	}
+
+	e := p.Parse("CEF:0|Vendor|Product|1.0")
+	if e.Message != "CEF:0|Vendor|Product|1.0" {
+		t.Errorf("truncated header should fall back to raw message, got %q", e.Message)
+	}
+}
+
+func TestCEFLevel(t *testing.T) {
+	tests := map[string]string{
+		"0": "INFO", "3": "INFO",
+		"4": "WARN", "6": "WARN",
+		"7": "ERROR", "8": "ERROR",
+		"9": "FATAL", "10": "FATAL",
+		"11": "", "-1": "", "Unknown": "",
+		"Low": "INFO", "Medium": "WARN", "High": "ERROR", "Very-High": "FATAL",
+	}
+	for severity, want := range tests {
+		if got := cefLevel(severity); got != want {
+			t.Errorf("cefLevel(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+func TestDetectFormat_CEF(t *testing.T) {
+	lines := []string{
+		"CEF:0|Vendor|Product|1.0|100|Event|5|src=1.2.3.4 dst=5.6.7.8",
+		"CEF:0|Vendor|Product|1.0|101|Other|2|src=1.2.3.4",
+	}
+	if got := DetectFormat(lines); got != FormatCEF {
+		t.Errorf("DetectFormat = %v, want cef", got)
+	}
+
+	e := NewAutoParser().Parse(lines[0])
+	if e.Format != FormatCEF || e.Fields["dst"] != "5.6.7.8" {
+		t.Errorf("AutoParser: Format = %v, Fields = %v", e.Format, e.Fields)
+	}
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// criPattern matches a CRI log frame as written by containerd and CRI-O:
+// "2024-01-15T10:30:00.000000000Z stdout F message". The tag is P for a
+// partial frame, continued by the next frame of the same stream, or F for
+// the full (final) one.
+var criPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\S+) (stdout|stderr) ([PF])(?: (.*))?$`)
+
+// dockerStreamPattern recognizes the stream key of Docker's json-file driver.
+var dockerStreamPattern = regexp.MustCompile(`"stream"\s*:\s*"(?:stdout|stderr)"`)
+
+// isCRI reports whether the (first) line is a CRI frame.
+func isCRI(line string) bool {
+	first, _, _ := strings.Cut(line, "\n")
+	return len(first) > 0 && first[0] >= '0' && first[0] <= '9' && criPattern.MatchString(strings.TrimRight(first, "\r"))
+}
+
+// isDockerJSON reports whether a JSON object line is a Docker json-file
+// record such as {"log":"msg\n","stream":"stdout","time":"..."}.
+func isDockerJSON(line string) bool {
+	first, _, _ := strings.Cut(line, "\n")
+	return strings.Contains(first, `"log"`) && dockerStreamPattern.MatchString(first)
+}
+
+// criFrame is one parsed CRI or Docker json-file record.
+type criFrame struct {
+	time    time.Time
+	stream  string
+	partial bool
+	content string
+	attrs   map[string]string // Docker "attrs"
+}
+
+// CRIParser parses container runtime log files: the CRI text format found
+// under /var/log/pods and Docker's json-file format. The runtime metadata
+// goes into Fields ("stream" and "logtag"), the container's own output into
+// Message. The stream is also kept in Stream.
+//
+// Runtimes split long lines into partial frames. Parse accepts several
+// frames joined with newlines and reassembles them: partial frames are
+// concatenated with the frame that completes them, while complete frames
+// are separated by newlines.
+type CRIParser struct{}
+
+// Parse parses one or more newline-joined frames. The timestamp and stream
+// are those of the first frame; "logtag" is F unless the last frame is
+// still partial.
+func (p *CRIParser) Parse(line string) LogEntry {
+	entry := LogEntry{
+		Raw:       line,
+		Format:    FormatCRI,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+	}
+
+	var msg strings.Builder
+	first, partial := true, false
+	for _, raw := range strings.Split(strings.TrimRight(line, "\n"), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		f, ok := parseCRIFrame(raw)
+		if first {
+			if !ok {
+				entry.Message = line
+				return entry
+			}
+			for k, v := range f.attrs {
+				entry.Fields[k] = v
+			}
+			entry.Timestamp = f.time
+			entry.Fields["stream"] = f.stream
+			entry.Stream = f.stream
+			first = false
+		} else if !partial {
+			msg.WriteByte('\n')
+		}
+		if !ok {
+			// Not a frame: keep the text as a line of its own.
+			msg.WriteString(raw)
+			partial = false
+			continue
+		}
+		msg.WriteString(f.content)
+		partial = f.partial
+	}
+
+	entry.Message = msg.String()
+	entry.Fields["logtag"] = "F"
+	if partial {
+		entry.Fields["logtag"] = "P"
+	}
+	// Partial frames make up a single line of output, so count lines of
+	// the reassembled message rather than frames.
+	entry.LineCount = CountLines(entry.Message)
+	entry.Level = detectLevel(entry.Message, false)
+	return entry
+}
+
+// parseCRIFrame parses a single CRI text or Docker json-file record.
+func parseCRIFrame(s string) (criFrame, bool) {
+	if strings.HasPrefix(s, "{") {
+		return parseDockerFrame(s)
+	}
+	m := criPattern.FindStringSubmatch(s)
+	if m == nil {
+		return criFrame{}, false
+	}
+	t, _ := time.Parse(time.RFC3339Nano, m[1])
+	return criFrame{time: t, stream: m[2], partial: m[3] == "P", content: m[4]}, true
+}
+
+// parseDockerFrame parses a json-file record. Docker keeps the trailing
+// newline in "log"; a record without one is a partial line.
+func parseDockerFrame(s string) (criFrame, bool) {
+	var rec struct {
+		Log    *string           `json:"log"`
+		Stream string            `json:"stream"`
+		Time   string            `json:"time"`
+		Attrs  map[string]string `json:"attrs"`
+	}
+	if err := json.Unmarshal([]byte(s), &rec); err != nil || rec.Log == nil {
+		return criFrame{}, false
+	}
+	t, _ := time.Parse(time.RFC3339Nano, rec.Time)
+	content, full := strings.CutSuffix(*rec.Log, "\n")
+	return criFrame{
+		time:    t,
+		stream:  rec.Stream,
+		partial: !full,
+		content: strings.TrimSuffix(content, "\r"),
+		attrs:   rec.Attrs,
+	}, true
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCRIParser_Text(t *testing.T) {
+	p := &CRIParser{}
+	e := p.Parse("2024-01-15T10:30:00.123456789Z stderr F ERROR connection refused")
+
+	if e.Format != FormatCRI {
+		t.Errorf("Format = %v, want cri", e.Format)
+	}
+	if e.Message != "ERROR connection refused" || e.Level != "ERROR" {
+		t.Errorf("Message = %q, Level = %q", e.Message, e.Level)
+	}
+	if want := time.Date(2024, 1, 15, 10, 30, 0, 123456789, time.UTC); !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+	if e.Fields["stream"] != "stderr" || e.Stream != "stderr" || e.Fields["logtag"] != "F" {
+		t.Errorf("Fields = %v, Stream = %q", e.Fields, e.Stream)
+	}
+
+	if e := p.Parse("2024-01-15T10:30:00Z stdout F"); e.Message != "" || e.Fields["stream"] != "stdout" {
+		t.Errorf("empty frame: Message = %q, Fields = %v", e.Message, e.Fields)
+	}
+}
+
+func TestCRIParser_PartialFrames(t *testing.T) {
+	p := &CRIParser{}
+	e := p.Parse("2024-01-15T10:30:00.000000001Z stderr P first part, \n" +
+		"2024-01-15T10:30:00.000000002Z stderr P second part, \n" +
+		"2024-01-15T10:30:00.000000003Z stderr F end\n" +
+		"2024-01-15T10:30:00.000000004Z stderr F next line")
+
+	if want := "first part, second part, end\nnext line"; e.Message != want {
+		t.Errorf("Message = %q, want %q", e.Message, want)
+	}
+	if e.Fields["stream"] != "stderr" || e.Fields["logtag"] != "F" {
+		t.Errorf("Fields = %v", e.Fields)
+	}
+	if e.LineCount != 2 {
+		t.Errorf("LineCount = %d, want 2", e.LineCount)
+	}
+	if want := time.Date(2024, 1, 15, 10, 30, 0, 1, time.UTC); !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want the first frame's %v", e.Timestamp, want)
+	}
+
+	e = p.Parse("2024-01-15T10:30:00Z stdout P still going")
+	if e.Message != "still going" || e.Fields["logtag"] != "P" {
+		t.Errorf("lone partial: Message = %q, Fields = %v", e.Message, e.Fields)
+	}
+}
+
+func TestCRIParser_DockerJSON(t *testing.T) {
+	p := &CRIParser{}
+	e := p.Parse(`{"log":"WARN disk almost full\n","stream":"stdout","time":"2024-01-15T10:30:00.5Z","attrs":{"tag":"web"}}`)
+
+	if e.Message != "WARN disk almost full" || e.Level != "WARN" {
+		t.Errorf("Message = %q, Level = %q", e.Message, e.Level)
+	}
+	if want := time.Date(2024, 1, 15, 10, 30, 0, 5e8, time.UTC); !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+	if e.Fields["stream"] != "stdout" || e.Stream != "stdout" || e.Fields["logtag"] != "F" || e.Fields["tag"] != "web" {
+		t.Errorf("Fields = %v, Stream = %q", e.Fields, e.Stream)
+	}
+
+	// Docker splits lines over 16KB into records without the newline.
+	e = p.Parse(`{"log":"part one ","stream":"stderr","time":"2024-01-15T10:30:00Z"}` + "\n" +
+		`{"log":"part two\n","stream":"stderr","time":"2024-01-15T10:30:00Z"}`)
+	if e.Message != "part one part two" || e.Fields["stream"] != "stderr" || e.Fields["logtag"] != "F" {
+		t.Errorf("joined: Message = %q, Fields = %v", e.Message, e.Fields)
+	}
+}
+
+func TestCRIParser_NotAFrame(t *testing.T) {
+	e := (&CRIParser{}).Parse("just some text")
+	if e.Message != "just some text" || len(e.Fields) != 0 {
+		t.Errorf("Message = %q, Fields = %v", e.Message, e.Fields)
+	}
+}
+
+func TestDetectFormat_CRI(t *testing.T) {
+	tests := map[string][]string{
+		"cri text": {
+			"2024-01-15T10:30:00.000000000Z stdout F starting",
+			`2024-01-15T10:30:01.000000000Z stdout F {"level":"info","msg":"json inside"}`,
+		},
+		"docker json-file": {
+			`{"log":"starting\n","stream":"stdout","time":"2024-01-15T10:30:00Z"}`,
+			`{"log":"ready\n","stream":"stderr","time":"2024-01-15T10:30:01Z"}`,
+		},
+	}
+	for name, lines := range tests {
+		if got := DetectFormat(lines); got != FormatCRI {
+			t.Errorf("%s: DetectFormat = %v, want cri", name, got)
+		}
+		if e := NewAutoParser().Parse(lines[1]); e.Format != FormatCRI || e.Fields["stream"] == "" {
+			t.Errorf("%s: AutoParser: Format = %v, Fields = %v", name, e.Format, e.Fields)
+		}
+	}
+
+	// Ordinary JSON with a "log" key is not mistaken for Docker output.
+	if got := detectLine(`{"log":"x","level":"info"}`); got != FormatJSON {
+		t.Errorf("detectLine = %v, want json", got)
+	}
+}
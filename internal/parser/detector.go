@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -14,6 +15,11 @@ const (
 	FormatJSON
 	FormatLogfmt
 	FormatPlain
+	FormatGELF
+	FormatCEF
+	FormatKlog
+	FormatCRI
+	FormatOTLP
 )
 
 func (f Format) String() string {
@@ -24,19 +30,43 @@ func (f Format) String() string {
 		return "logfmt"
 	case FormatPlain:
 		return "plain"
+	case FormatGELF:
+		return "gelf"
+	case FormatCEF:
+		return "cef"
+	case FormatKlog:
+		return "klog"
+	case FormatCRI:
+		return "cri"
+	case FormatOTLP:
+		return "otlp"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseFormat returns the format named name, as printed by Format.String.
+func ParseFormat(name string) (Format, error) {
+	for f := FormatJSON; f <= FormatOTLP; f++ {
+		if strings.EqualFold(name, f.String()) {
+			return f, nil
+		}
+	}
+	return FormatUnknown, fmt.Errorf("unknown format %q", name)
+}
+
 // LogEntry is the unified parsed log entry.
 type LogEntry struct {
 	Timestamp time.Time
 	Level     string
 	Message   string
 	Fields    map[string]string
+	LineCount int // source lines joined into this entry; 1 for single-line entries
 	Raw       string
 	Format    Format
+	Source    string   // originating source (file path, "stdin", ...); set by the caller
+	Stream    string   // "stdout" or "stderr" where the source or format tells them apart
+	Issues    []string // schema violations found by a Validator
 }
 
 // Parser can parse a single log line into a LogEntry.
@@ -44,94 +74,163 @@ type Parser interface {
 	Parse(line string) LogEntry
 }
 
-// DetectFormat analyzes the first N lines and returns the most likely format.
-func DetectFormat(lines []string) Format {
-	if len(lines) == 0 {
-		return FormatUnknown
-	}
+// MultiParser is a Parser for lines that may hold several entries, such as
+// a JSON array of log objects written as one batch.
+type MultiParser interface {
+	Parser
+	// ParseMulti parses line into one or more entries.
+	ParseMulti(line string) []LogEntry
+}
+
+// DefaultDetectSample is how many non-blank lines DetectFormat inspects.
+const DefaultDetectSample = 200
 
-	jsonCount, logfmtCount, plainCount := 0, 0, 0
+// DefaultMinConfidence is the share of sampled lines the dominant format
+// must reach for ParserFor to commit to it.
+const DefaultMinConfidence = 0.8
+
+// DetectFormat returns the most likely format of lines, inspecting up to
+// DefaultDetectSample non-blank lines.
+func DetectFormat(lines []string) Format {
+	f, _ := DetectFormatN(lines, DefaultDetectSample)
+	return f
+}
 
+// DetectFormatN inspects up to sample non-blank lines (all of them if sample
+// is zero or negative) and returns the most likely format together with the
+// confidence: the share of inspected lines in that format, from 0 to 1.
+func DetectFormatN(lines []string, sample int) (Format, float64) {
+	counts := make(map[Format]int)
+	total := 0
 	for _, line := range lines {
+		if sample > 0 && total == sample {
+			break
+		}
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
-		switch detectLine(line) {
-		case FormatJSON:
-			jsonCount++
-		case FormatLogfmt:
-			logfmtCount++
-		default:
-			plainCount++
-		}
+		counts[detectLine(line)]++
+		total++
 	}
-
-	if jsonCount >= logfmtCount && jsonCount >= plainCount && jsonCount > 0 {
-		return FormatJSON
+	if total == 0 {
+		return FormatUnknown, 0
 	}
-	if logfmtCount >= jsonCount && logfmtCount >= plainCount && logfmtCount > 0 {
-		return FormatLogfmt
+
+	// The most common format wins; ties go to the more structured format.
+	best, bestCount := FormatUnknown, 0
+	for _, f := range detectPriority {
+		if counts[f] > bestCount {
+			best, bestCount = f, counts[f]
+		}
 	}
-	if plainCount > 0 {
-		return FormatPlain
+	return best, float64(bestCount) / float64(total)
+}
+
+// ParserFor returns a parser for lines like them: the parser of the detected
+// format if at least minConfidence of the sampled lines are in it, or else an
+// AutoParser that detects the format of every line.
+func ParserFor(lines []string, sample int, minConfidence float64) Parser {
+	f, confidence := DetectFormatN(lines, sample)
+	if f == FormatUnknown || confidence < minConfidence {
+		return NewAutoParser()
 	}
-	return FormatUnknown
+	return NewParser(f)
 }
 
+// CountLines returns how many source lines make up a (possibly joined)
+// entry, ignoring a trailing newline.
+func CountLines(s string) int {
+	return strings.Count(strings.TrimRight(s, "\n"), "\n") + 1
+}
+
+// detectPriority lists formats from most to least specific; it breaks ties
+// in DetectFormat.
+var detectPriority = []Format{FormatCRI, FormatCEF, FormatKlog, FormatOTLP, FormatGELF, FormatJSON, FormatLogfmt, FormatPlain}
+
 // detectLine determines the format of a single line.
 func detectLine(line string) Format {
 	trimmed := strings.TrimSpace(line)
 	if len(trimmed) == 0 {
 		return FormatUnknown
 	}
+	if isCEF(trimmed) {
+		return FormatCEF
+	}
+	if isCRI(trimmed) {
+		return FormatCRI
+	}
+	if _, ok := jsonArrayElements(trimmed); ok {
+		return FormatJSON
+	}
 	if trimmed[0] == '{' && trimmed[len(trimmed)-1] == '}' {
+		if isDockerJSON(trimmed) {
+			return FormatCRI
+		}
+		if isGELF(trimmed) {
+			return FormatGELF
+		}
+		if isOTLP(trimmed) {
+			return FormatOTLP
+		}
 		return FormatJSON
 	}
+	if isKlog(trimmed) {
+		return FormatKlog
+	}
 	if isLogfmt(trimmed) {
 		return FormatLogfmt
 	}
 	return FormatPlain
 }
 
-// isLogfmt checks if a line looks like key=value pairs.
+// parsePinned parses line in the pinned format, reporting false if there is
+// none or the line does not fit it.
+func (a *AutoParser) parsePinned(line string) (LogEntry, bool) {
+	trimmed := strings.TrimSpace(line)
+	switch a.pinned {
+	case FormatJSON:
+		return a.jsonParser.parse(line)
+	case FormatGELF:
+		if isGELF(trimmed) {
+			return a.gelfParser.Parse(line), true
+		}
+	case FormatOTLP:
+		if isOTLP(trimmed) {
+			return a.otlpParser.Parse(line), true
+		}
+	case FormatCEF:
+		if isCEF(trimmed) {
+			return a.cefParser.Parse(line), true
+		}
+	case FormatCRI:
+		if isCRI(trimmed) || isDockerJSON(trimmed) {
+			return a.criParser.Parse(line), true
+		}
+	case FormatKlog:
+		if isKlog(trimmed) {
+			return a.klogParser.Parse(line), true
+		}
+	case FormatLogfmt:
+		if isLogfmt(trimmed) {
+			return a.logfmtParser.Parse(line), true
+		}
+	}
+	return LogEntry{}, false
+}
+
+// isLogfmt checks if a line looks like key=value pairs: it must start with
+// at least two of them. Bare keys after the first pair are allowed but not
+// counted.
 func isLogfmt(line string) bool {
-	// Must have at least 2 key=value pairs to be considered logfmt
 	count := 0
-	i := 0
-	for i < len(line) {
-		// skip whitespace
-		for i < len(line) && line[i] == ' ' {
-			i++
-		}
-		// find '='
-		start := i
-		for i < len(line) && line[i] != '=' && line[i] != ' ' {
-			i++
-		}
-		if i >= len(line) || line[i] != '=' || i == start {
-			break
-		}
-		i++ // skip '='
-		if i < len(line) && line[i] == '"' {
-			// quoted value
-			i++
-			for i < len(line) && line[i] != '"' {
-				if line[i] == '\\' {
-					i++
-				}
-				i++
-			}
-			if i < len(line) {
-				i++ // skip closing quote
-			}
-		} else {
-			for i < len(line) && line[i] != ' ' {
-				i++
-			}
+	scanLogfmt(line, func(key, value string, bare bool) bool {
+		if bare {
+			return count > 0
 		}
 		count++
-	}
+		return count < 2
+	})
 	return count >= 2
 }
 
@@ -142,6 +241,16 @@ func NewParser(f Format) Parser {
 		return &JSONParser{}
 	case FormatLogfmt:
 		return &LogfmtParser{}
+	case FormatGELF:
+		return &GELFParser{}
+	case FormatCEF:
+		return &CEFParser{}
+	case FormatKlog:
+		return &KlogParser{}
+	case FormatCRI:
+		return &CRIParser{}
+	case FormatOTLP:
+		return &OTLPParser{}
 	default:
 		return &PlainParser{}
 	}
@@ -149,24 +258,186 @@ func NewParser(f Format) Parser {
 
 // AutoParser detects the format per-line for mixed format streams.
 type AutoParser struct {
+	cefParser    CEFParser
+	criParser    CRIParser
+	gelfParser   GELFParser
+	hybridParser HybridParser
 	jsonParser   JSONParser
+	klogParser   KlogParser
 	logfmtParser LogfmtParser
+	otlpParser   OTLPParser
 	plainParser  PlainParser
+	enrichers    []Enricher
+	pinned       Format          // see Pin
+	first        decliningParser // see SetExecParser
+	panics       *panicStats     // see Panics
+}
+
+// decliningParser is a parser that may decline a line, leaving it to format
+// detection.
+type decliningParser interface {
+	parse(line string) (LogEntry, bool)
 }
 
 // NewAutoParser creates a parser that handles mixed formats.
 func NewAutoParser() *AutoParser {
-	return &AutoParser{}
+	return &AutoParser{panics: &panicStats{}}
+}
+
+// Pin returns a copy of a that parses every line as format f instead of
+// detecting the format of each line, which keeps a homogeneous input
+// consistent. Lines that fail to parse as f (invalid JSON, say) still get
+// their format detected. Pinning FormatPlain or FormatUnknown has no effect,
+// as any line would pass as plain text.
+func (a *AutoParser) Pin(f Format) *AutoParser {
+	b := *a
+	b.pinned = f
+	return &b
+}
+
+// PinDetected is like ParserFor for an AutoParser: it returns a pinned to
+// the format of lines if at least minConfidence of up to sample of them are
+// in it, and a itself otherwise.
+func (a *AutoParser) PinDetected(lines []string, sample int, minConfidence float64) *AutoParser {
+	f, confidence := DetectFormatN(lines, sample)
+	if f == FormatUnknown || f == FormatPlain || confidence < minConfidence {
+		return a
+	}
+	return a.Pin(f)
+}
+
+// Pinned returns the format set by Pin, or FormatUnknown.
+func (a *AutoParser) Pinned() Format {
+	return a.pinned
+}
+
+// SetTimeConfig sets how zoneless and yearless timestamps are interpreted
+// by every underlying parser.
+func (a *AutoParser) SetTimeConfig(cfg TimeConfig) {
+	a.cefParser.SetTimeConfig(cfg)
+	a.hybridParser.SetTimeConfig(cfg)
+	a.jsonParser.SetTimeConfig(cfg)
+	a.klogParser.SetTimeConfig(cfg)
+	a.logfmtParser.SetTimeConfig(cfg)
+	a.plainParser.SetTimeConfig(cfg)
+}
+
+// SetKeySet sets the fields JSON and logfmt lines take their timestamp,
+// level and message from. By default DefaultKeySet is used.
+func (a *AutoParser) SetKeySet(keys KeySet) {
+	keys = keys.resolve()
+	a.jsonParser.keys = keys
+	a.logfmtParser.keys = keys
+	a.hybridParser.logfmt.keys = keys
+}
+
+// SetJSONConfig sets how JSON lines without a message key get their
+// message; see JSONParserConfig.
+func (a *AutoParser) SetJSONConfig(cfg JSONParserConfig) {
+	a.jsonParser.SetConfig(cfg)
+}
+
+// SetExtractPairs controls whether plain-text lines have embedded key=value
+// fragments copied into Fields. It is on by default.
+func (a *AutoParser) SetExtractPairs(on bool) {
+	a.plainParser.SetExtractPairs(on)
+}
+
+// SetStrictLevels controls whether plain-text lines only take their level
+// from a delimited level word near the start, such as "[ERROR]" or "INFO:".
+// It is off by default; see PlainParser.SetStrictLevels.
+func (a *AutoParser) SetStrictLevels(on bool) {
+	a.plainParser.SetStrictLevels(on)
+	a.hybridParser.plain.SetStrictLevels(on)
+}
+
+// SetExecParser hands every line to p first. Lines p gives no usable answer
+// for are detected and parsed as usual; enrichers apply either way.
+func (a *AutoParser) SetExecParser(p *ExecParser) {
+	a.first = p
 }
 
-// Parse detects and parses a single line.
+// Parse detects and parses a single line, then applies any enrichers.
+//
+// A panic in a parser or enricher does not escape: a line whose parsing
+// panics is returned unparsed, as a plain entry with the line as its
+// message, and an enricher that panics leaves the entry as far as it got.
+// Panics records every such failure.
 func (a *AutoParser) Parse(line string) LogEntry {
+	var entry LogEntry
+	if err := safely(line, func() { entry = a.parse(line) }); err != nil {
+		a.recordPanic(err)
+		entry = unparsed(line)
+	}
+	for _, e := range a.enrichers {
+		if err := safely(line, func() { e.Enrich(&entry) }); err != nil {
+			a.recordPanic(err)
+		}
+	}
+	return entry
+}
+
+// ParseMulti is Parse for lines that may hold several entries: a JSON array
+// of objects becomes one entry per element, each parsed by Parse as a line
+// of its own. Any other line becomes a single entry.
+func (a *AutoParser) ParseMulti(line string) []LogEntry {
+	elems, ok := jsonArrayElements(line)
+	if !ok {
+		return []LogEntry{a.Parse(line)}
+	}
+	entries := make([]LogEntry, len(elems))
+	for i, elem := range elems {
+		entries[i] = a.Parse(elem)
+	}
+	return entries
+}
+
+// Panics returns how many lines made a parser or enricher panic, and the
+// latest such panic as a *ParsePanicError. Copies made by Pin share the
+// count with a.
+func (a *AutoParser) Panics() (int, error) {
+	if a.panics == nil {
+		return 0, nil
+	}
+	return a.panics.get()
+}
+
+// recordPanic records a recovered panic.
+func (a *AutoParser) recordPanic(err error) {
+	if a.panics != nil {
+		a.panics.record(err)
+	}
+}
+
+func (a *AutoParser) parse(line string) LogEntry {
+	if a.first != nil {
+		if entry, ok := a.first.parse(line); ok {
+			return entry
+		}
+	}
+	if entry, ok := a.parsePinned(line); ok {
+		return entry
+	}
 	switch detectLine(line) {
+	case FormatCEF:
+		return a.cefParser.Parse(line)
+	case FormatCRI:
+		return a.criParser.Parse(line)
+	case FormatGELF:
+		return a.gelfParser.Parse(line)
 	case FormatJSON:
 		return a.jsonParser.Parse(line)
+	case FormatKlog:
+		return a.klogParser.Parse(line)
 	case FormatLogfmt:
 		return a.logfmtParser.Parse(line)
+	case FormatOTLP:
+		return a.otlpParser.Parse(line)
 	default:
+		// Plain text may be a prefix followed by logfmt pairs.
+		if entry, ok := a.hybridParser.parse(line); ok {
+			return entry
+		}
 		return a.plainParser.Parse(line)
 	}
 }
@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 // --- Real-world JSON log samples ---
@@ -67,7 +69,18 @@ var plainSamples = []string{
 	`2024/01/15 10:30:14 http: TLS handshake error from 10.0.0.5:54321`,
 	`2024-01-15 10:30:15.000 TRACE Entering function ProcessBatch`,
 	`--- FAIL: TestUserCreate (0.01s)`,
-	`E0115 10:30:17.000000   12345 server.go:123] Unable to attach to pod`,
+}
+
+func TestParseFormat(t *testing.T) {
+	for f := FormatJSON; f <= FormatCRI; f++ {
+		got, err := ParseFormat(strings.ToUpper(f.String()))
+		if err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %v, %v; want %v", f.String(), got, err, f)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
 }
 
 func TestDetectFormat(t *testing.T) {
@@ -93,6 +106,97 @@ func TestDetectFormat(t *testing.T) {
 	}
 }
 
+func TestDetectFormatN(t *testing.T) {
+	f, confidence := DetectFormatN(jsonSamples, 0)
+	if f != FormatJSON || confidence != 1 {
+		t.Errorf("clean JSON: got %v, %.2f; want json, 1", f, confidence)
+	}
+
+	// A comment line and blank lines in front of three JSON lines.
+	mixed := append([]string{"# exported from prod", "", "  "}, jsonSamples[:3]...)
+	f, confidence = DetectFormatN(mixed, 0)
+	if f != FormatJSON || confidence != 0.75 {
+		t.Errorf("mixed: got %v, %.2f; want json, 0.75", f, confidence)
+	}
+
+	if f, confidence := DetectFormatN(nil, 10); f != FormatUnknown || confidence != 0 {
+		t.Errorf("empty: got %v, %.2f", f, confidence)
+	}
+}
+
+func TestDetectFormatN_EarlyExit(t *testing.T) {
+	// Only the first sample non-blank lines count; blank lines don't use up
+	// the sample.
+	lines := append([]string{"", jsonSamples[0], "", jsonSamples[1]}, logfmtSamples...)
+	f, confidence := DetectFormatN(lines, 2)
+	if f != FormatJSON || confidence != 1 {
+		t.Errorf("sample 2: got %v, %.2f; want json, 1", f, confidence)
+	}
+	if f, _ := DetectFormatN(lines, 0); f != FormatLogfmt {
+		t.Errorf("whole input: got %v, want logfmt", f)
+	}
+
+	// The default sample keeps DetectFormat from reading the whole input.
+	long := make([]string, 0, DefaultDetectSample+1000)
+	for len(long) < DefaultDetectSample {
+		long = append(long, jsonSamples[len(long)%len(jsonSamples)])
+	}
+	for len(long) < cap(long) {
+		long = append(long, plainSamples[0])
+	}
+	if f := DetectFormat(long); f != FormatJSON {
+		t.Errorf("DetectFormat = %v, want json from the first %d lines", f, DefaultDetectSample)
+	}
+}
+
+func TestParserFor(t *testing.T) {
+	if _, ok := ParserFor(logfmtSamples, 0, DefaultMinConfidence).(*LogfmtParser); !ok {
+		t.Error("clean logfmt should get a LogfmtParser")
+	}
+	mixed := append(append([]string{}, jsonSamples[:3]...), logfmtSamples[:2]...)
+	if _, ok := ParserFor(mixed, 0, DefaultMinConfidence).(*AutoParser); !ok {
+		t.Error("low-confidence input should fall back to AutoParser")
+	}
+	if _, ok := ParserFor(mixed, 0, 0.5).(*JSONParser); !ok {
+		t.Error("a lower threshold should accept the dominant format")
+	}
+	if _, ok := ParserFor(nil, 0, 0).(*AutoParser); !ok {
+		t.Error("empty input should fall back to AutoParser")
+	}
+}
+
+func TestAutoParser_Pin(t *testing.T) {
+	a := NewAutoParser()
+	p := a.PinDetected(jsonSamples, 0, DefaultMinConfidence)
+	if p.Pinned() != FormatJSON || a.Pinned() != FormatUnknown {
+		t.Fatalf("Pinned() = %v (original %v), want json (unknown)", p.Pinned(), a.Pinned())
+	}
+
+	// A GELF-looking object is parsed as plain JSON once pinned.
+	gelf := `{"version":"1.1","host":"h","short_message":"hi","level":3}`
+	if f := a.Parse(gelf).Format; f != FormatGELF {
+		t.Errorf("unpinned: format = %v, want gelf", f)
+	}
+	if f := p.Parse(gelf).Format; f != FormatJSON {
+		t.Errorf("pinned: format = %v, want json", f)
+	}
+	// Lines that are not JSON still get their own format.
+	if e := p.Parse("panic: boom"); e.Format != FormatPlain || e.Message != "panic: boom" {
+		t.Errorf("pinned fallback = %v %q, want plain", e.Format, e.Message)
+	}
+	if e := p.Parse(`{"msg":"cut`); e.Format == FormatJSON {
+		t.Error("invalid JSON should not go through the pinned parser")
+	}
+
+	mixed := append(append([]string{}, jsonSamples[:3]...), logfmtSamples[:2]...)
+	if p := a.PinDetected(mixed, 0, DefaultMinConfidence); p != a {
+		t.Error("mixed input should not be pinned")
+	}
+	if p := a.PinDetected(plainSamples, 0, DefaultMinConfidence); p != a {
+		t.Error("plain text should not be pinned")
+	}
+}
+
 func TestDetectLineCounts(t *testing.T) {
 	// Verify each sample is detected as its expected format
 	for i, line := range jsonSamples {
@@ -142,6 +246,93 @@ func TestJSONParser(t *testing.T) {
 	}
 }
 
+func TestJSONParser_NumericLevels(t *testing.T) {
+	p := &JSONParser{}
+	tests := []struct {
+		line, level string
+	}{
+		// pino
+		{`{"level":10,"time":1705312200000,"msg":"tracing"}`, "TRACE"},
+		{`{"level":20,"time":1705312200000,"msg":"debugging"}`, "DEBUG"},
+		{`{"level":30,"time":1705312200000,"pid":42,"msg":"listening"}`, "INFO"},
+		{`{"level":40,"time":1705312200000,"msg":"slow"}`, "WARN"},
+		{`{"level":50,"time":1705312200000,"msg":"failed"}`, "ERROR"},
+		{`{"level":60,"time":1705312200000,"msg":"dying"}`, "FATAL"},
+		// syslog severity under priority
+		{`{"priority":3,"message":"disk error"}`, "ERROR"},
+		{`{"priority":6,"message":"started"}`, "INFO"},
+		{`{"PRIORITY":"warning","message":"textual"}`, "WARNING"},
+		// a level key holding neither falls through to the next
+		{`{"level":null,"severity":"error","message":"x"}`, "ERROR"},
+		{`{"level":true,"message":"x"}`, ""},
+	}
+	for _, tt := range tests {
+		entry := p.Parse(tt.line)
+		if entry.Level != tt.level {
+			t.Errorf("%s: Level = %q, want %q", tt.line, entry.Level, tt.level)
+		}
+		if _, ok := entry.Fields["level"]; ok {
+			t.Errorf("%s: level should not be kept as a field", tt.line)
+		}
+	}
+
+	entry := p.Parse(`{"level":30,"time":1705312200000,"pid":42,"msg":"listening"}`)
+	if entry.Message != "listening" || entry.Fields["pid"] != "42" || entry.Timestamp.IsZero() {
+		t.Errorf("pino entry = %+v", entry)
+	}
+	if entry := p.Parse(`{"level":"info","priority":"high","msg":"queued"}`); entry.Level != "INFO" {
+		t.Errorf("level should win over priority: %+v", entry)
+	}
+}
+
+func TestJSONParser_MessageFallback(t *testing.T) {
+	p := &JSONParser{}
+	tests := []struct {
+		line, message string
+	}{
+		{`{"ts":"2024-01-15T10:30:00Z","level":"info","event":"user.created","user_id":42}`, "user.created"},
+		{`{"level":"warn","Action":"login","user":"ann"}`, "login"},
+		// event wins over action, whatever the order in the line
+		{`{"action":"write","event":"cache.flush"}`, "cache.flush"},
+		// an empty or non-scalar fallback key is skipped
+		{`{"event":"","action":"retry"}`, "retry"},
+		{`{"event":{"name":"x"},"status":200}`, "status=200"},
+		// otherwise the first scalar fields, in key order
+		{`{"time":1705312200,"level":"error","status":503,"path":"/api/users","method":"GET","latency_ms":12.5,"tags":["a"]}`,
+			"latency_ms=12.5 method=GET path=/api/users"},
+		{`{"note":"disk almost full","ok":false}`, `note="disk almost full" ok=false`},
+		// nothing usable: the raw line is shown
+		{`{"level":"info","data":{"a":1}}`, ""},
+		// an explicit message, even empty, is kept
+		{`{"msg":"","event":"ignored"}`, ""},
+	}
+	for _, tt := range tests {
+		entry := p.Parse(tt.line)
+		if entry.Message != tt.message {
+			t.Errorf("%s: Message = %q, want %q", tt.line, entry.Message, tt.message)
+		}
+	}
+
+	// The fields are kept as they are.
+	entry := p.Parse(`{"level":"info","event":"user.created","user_id":42}`)
+	if entry.Fields["event"] != "user.created" || entry.Fields["user_id"] != "42" || len(entry.Fields) != 2 {
+		t.Errorf("Fields = %v", entry.Fields)
+	}
+
+	p.SetConfig(JSONParserConfig{FallbackKeys: []string{"Op"}, FallbackFields: 1})
+	if entry := p.Parse(`{"op":"vacuum","event":"db"}`); entry.Message != "vacuum" {
+		t.Errorf("custom fallback key: Message = %q", entry.Message)
+	}
+	if entry := p.Parse(`{"event":"db","b":2}`); entry.Message != "b=2" {
+		t.Errorf("custom field count: Message = %q", entry.Message)
+	}
+
+	p.SetConfig(JSONParserConfig{MessageFallback: FallbackNone})
+	if entry := p.Parse(`{"event":"user.created"}`); entry.Message != "" {
+		t.Errorf("FallbackNone: Message = %q, want empty", entry.Message)
+	}
+}
+
 func TestLogfmtParser(t *testing.T) {
 	p := &LogfmtParser{}
 
@@ -184,6 +375,123 @@ func TestPlainParser(t *testing.T) {
 	}
 }
 
+func TestPlainParser_WindowsBracketed(t *testing.T) {
+	p := &PlainParser{}
+	tests := []struct {
+		line  string
+		level string
+		ts    time.Time
+		msg   string
+	}{
+		{"[2024-01-15 10:30:00.123] [Information] Service started", "INFO",
+			time.Date(2024, 1, 15, 10, 30, 0, 123e6, time.UTC), "Service started"},
+		{"[2024-01-15T10:30:01Z] [Warning] Disk C: is 91% full", "WARN",
+			time.Date(2024, 1, 15, 10, 30, 1, 0, time.UTC), "Disk C: is 91% full"},
+		{"[1/15/2024 2:05:09 PM] [Error] Application crashed [code 0xC0000005]", "ERROR",
+			time.Date(2024, 1, 15, 14, 5, 9, 0, time.UTC), "Application crashed [code 0xC0000005]"},
+		{"[2024-01-15 10:30:03] [Critical] Database unreachable", "FATAL",
+			time.Date(2024, 1, 15, 10, 30, 3, 0, time.UTC), "Database unreachable"},
+		{"[2024-01-15 10:30:04] [VERBOSE] Polling queue", "DEBUG",
+			time.Date(2024, 1, 15, 10, 30, 4, 0, time.UTC), "Polling queue"},
+		{"[Warning] no timestamp here", "WARN", time.Time{}, "no timestamp here"},
+	}
+	for _, tt := range tests {
+		e := p.Parse(tt.line)
+		if e.Level != tt.level {
+			t.Errorf("%q: Level = %q, want %q", tt.line, e.Level, tt.level)
+		}
+		if !e.Timestamp.Equal(tt.ts) {
+			t.Errorf("%q: Timestamp = %v, want %v", tt.line, e.Timestamp, tt.ts)
+		}
+		if e.Message != tt.msg {
+			t.Errorf("%q: Message = %q, want %q", tt.line, e.Message, tt.msg)
+		}
+	}
+
+	// A bracketed level later in the line is left to the unbracketed match.
+	if e := p.Parse("Worker [Error] handler failed"); e.Level != "ERROR" || e.Message != "Worker [Error] handler failed" {
+		t.Errorf("mid-line: Level = %q, Message = %q", e.Level, e.Message)
+	}
+}
+
+func TestPlainParser_LevelScoring(t *testing.T) {
+	tests := []struct {
+		line   string
+		level  string
+		strict string // level in strict mode
+	}{
+		{"no error occurred", "", ""},
+		{"error handling succeeded", "", ""},
+		{"retrying the request after the previous attempt hit an ERROR", "", ""},
+		{"[WARN] disk almost full", "WARN", "WARN"},
+		{"INFO: server started", "INFO", "INFO"},
+		{"error: connection refused", "ERROR", "ERROR"},
+		{"ERROR connection refused", "ERROR", ""},
+		{"api-7f9c <debug> cache warmed", "DEBUG", "DEBUG"},
+		{"myhost app[42]: WARN queue is filling up", "WARN", ""},
+		{"info: recovered from error in worker", "INFO", "INFO"},
+		{"worker 3 reported no error, INFO: idle", "INFO", ""},
+	}
+	lenient, strict := &PlainParser{}, &PlainParser{}
+	strict.SetStrictLevels(true)
+	for _, tt := range tests {
+		if got := lenient.Parse(tt.line).Level; got != tt.level {
+			t.Errorf("%q: Level = %q, want %q", tt.line, got, tt.level)
+		}
+		if got := strict.Parse(tt.line).Level; got != tt.strict {
+			t.Errorf("%q: strict Level = %q, want %q", tt.line, got, tt.strict)
+		}
+	}
+}
+
+func TestPlainParser_EmbeddedPairs(t *testing.T) {
+	p := &PlainParser{}
+	nginx := `2026-02-17 20:00:00 INFO 10.0.0.1 "GET /api/users?page=2 HTTP/1.1" 200 rt=0.042 upstream=10.0.0.5:8080 ua="curl/8.5 (linux)"`
+	e := p.Parse(nginx)
+	want := map[string]string{"rt": "0.042", "upstream": "10.0.0.5:8080", "ua": "curl/8.5 (linux)"}
+	for k, v := range want {
+		if e.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, e.Fields[k], v)
+		}
+	}
+	if _, ok := e.Fields["page"]; ok {
+		t.Error("query string parameter should not be extracted")
+	}
+	if !strings.Contains(e.Message, "GET /api/users") {
+		t.Errorf("Message should keep the prose, got %q", e.Message)
+	}
+
+	human := p.Parse("2026-02-17 20:00:00 WARN login failed for user=alice, retrying in 5s")
+	if human.Fields["user"] != "alice" || len(human.Fields) != 1 {
+		t.Errorf("Fields = %v, want only user=alice", human.Fields)
+	}
+	if human.Message != "WARN login failed for user=alice, retrying in 5s" {
+		t.Errorf("Message = %q", human.Message)
+	}
+}
+
+func TestPlainParser_EmbeddedPairsFalsePositives(t *testing.T) {
+	p := &PlainParser{}
+	for _, line := range []string{
+		"INFO we know that x = y holds here",
+		"INFO comparing a == b failed",
+		"INFO see https://example.com/?q=1&r=2 for details",
+		"INFO score =5 and total= 7",
+	} {
+		if e := p.Parse(line); len(e.Fields) != 0 {
+			t.Errorf("%q: unexpected fields %v", line, e.Fields)
+		}
+	}
+}
+
+func TestPlainParser_EmbeddedPairsDisabled(t *testing.T) {
+	a := NewAutoParser()
+	a.SetExtractPairs(false)
+	if e := a.Parse("Feb 17 20:00:00 host app: user=alice logged in"); len(e.Fields) != 0 {
+		t.Errorf("extraction disabled, got fields %v", e.Fields)
+	}
+}
+
 func TestAutoParser(t *testing.T) {
 	ap := NewAutoParser()
 
@@ -202,6 +510,60 @@ func TestAutoParser(t *testing.T) {
 	}
 }
 
+func TestParseMulti_JSONArray(t *testing.T) {
+	line := `[{"level":"info","msg":"one","n":1},{"level":"warn","msg":"two","host":"a"}, {"level":"error","msg":"three","ts":"2026-02-17T20:00:00Z"}]`
+	if f := detectLine(line); f != FormatJSON {
+		t.Errorf("detectLine = %v, want json", f)
+	}
+	check := func(name string, entries []LogEntry) {
+		t.Helper()
+		if len(entries) != 3 {
+			t.Fatalf("%s: got %d entries, want 3", name, len(entries))
+		}
+		want := []struct{ level, msg, key, val string }{
+			{"INFO", "one", "n", "1"},
+			{"WARN", "two", "host", "a"},
+			{"ERROR", "three", "", ""},
+		}
+		for i, w := range want {
+			e := entries[i]
+			if e.Level != w.level || e.Message != w.msg || e.Format != FormatJSON {
+				t.Errorf("%s: entry %d = %s %q (%v)", name, i, e.Level, e.Message, e.Format)
+			}
+			if w.key != "" && (e.Fields[w.key] != w.val || len(e.Fields) != 1) {
+				t.Errorf("%s: entry %d fields = %v, want only %s=%s", name, i, e.Fields, w.key, w.val)
+			}
+			if !strings.HasPrefix(e.Raw, "{") || !strings.HasSuffix(e.Raw, "}") {
+				t.Errorf("%s: entry %d Raw = %q, want its element", name, i, e.Raw)
+			}
+		}
+		if !entries[2].Timestamp.Equal(time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)) {
+			t.Errorf("%s: entry 2 Timestamp = %v", name, entries[2].Timestamp)
+		}
+	}
+	check("AutoParser", NewAutoParser().ParseMulti(line))
+	check("JSONParser", (&JSONParser{}).ParseMulti(line))
+}
+
+func TestParseMulti_NotAnArrayOfObjects(t *testing.T) {
+	a := NewAutoParser()
+	for _, line := range []string{
+		`[2024-01-15 10:30:00] [Warning] Disk low`,
+		`[1, 2, 3]`,
+		`[]`,
+		`[{"msg":"a"}, 3]`,
+		`[{"msg":"broken"`,
+		`{"level":"info","msg":"single"}`,
+	} {
+		if entries := a.ParseMulti(line); len(entries) != 1 {
+			t.Errorf("%q: got %d entries, want 1", line, len(entries))
+		}
+	}
+	if f := detectLine(`[2024-01-15 10:30:00] [Warning] Disk low`); f == FormatJSON {
+		t.Error("a bracketed plain line was detected as JSON")
+	}
+}
+
 func TestFormatString(t *testing.T) {
 	if FormatJSON.String() != "json" {
 		t.Error("JSON string")
@@ -263,3 +625,26 @@ func BenchmarkDetectFormat100Mixed(b *testing.B) {
 		DetectFormat(lines)
 	}
 }
+
+func TestLineCount(t *testing.T) {
+	trace := "2026-02-17 20:00:00 ERROR boom\n\tat com.example.Foo.bar(Foo.java:10)\n\tat com.example.Main.main(Main.java:3)\n"
+	tests := []struct {
+		name   string
+		parser Parser
+		line   string
+		want   int
+	}{
+		{"plain single", &PlainParser{}, "2026-02-17 20:00:00 INFO ok", 1},
+		{"plain joined", &PlainParser{}, trace, 3},
+		{"logfmt single", &LogfmtParser{}, "level=info msg=ok", 1},
+		{"json single", &JSONParser{}, `{"msg":"ok"}`, 1},
+		{"json pretty", &JSONParser{}, "{\n  \"msg\": \"ok\"\n}", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parser.Parse(tt.line).LineCount; got != tt.want {
+				t.Errorf("LineCount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
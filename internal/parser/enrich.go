@@ -0,0 +1,12 @@
+package parser
+
+// Enricher adds derived information to an entry after it has been parsed.
+type Enricher interface {
+	Enrich(entry *LogEntry)
+}
+
+// AddEnricher registers an enricher that runs on every entry Parse returns.
+// Enrichers run in the order they were added.
+func (a *AutoParser) AddEnricher(e Enricher) {
+	a.enrichers = append(a.enrichers, e)
+}
@@ -0,0 +1,71 @@
+package parser
+
+import "strings"
+
+// errorKeys and stackKeys name the fields Go loggers (zap, logrus, slog)
+// use for an error and its stack trace.
+var (
+	errorKeys = []string{"error", "err"}
+	stackKeys = []string{"stacktrace", "stack"}
+)
+
+// ErrorDetails is the error information found in a structured entry.
+type ErrorDetails struct {
+	ErrorKey string   // field holding the error, "" if none
+	Chain    []string // the error split into its wrapped links, outermost first
+	StackKey string   // field holding the stack trace, "" if none
+	Stack    string   // the stack trace with real newlines
+}
+
+// ExtractErrorDetails looks for an error and a stack trace in the fields of
+// a JSON or logfmt entry; other formats yield nothing. The fields themselves
+// are not modified, so exports keep the raw values.
+func ExtractErrorDetails(e LogEntry) ErrorDetails {
+	var d ErrorDetails
+	if e.Format != FormatJSON && e.Format != FormatLogfmt {
+		return d
+	}
+	for _, k := range errorKeys {
+		if v, ok := e.Fields[k]; ok && v != "" {
+			d.ErrorKey, d.Chain = k, ErrorChain(v)
+			break
+		}
+	}
+	for _, k := range stackKeys {
+		if v, ok := e.Fields[k]; ok && v != "" {
+			d.StackKey, d.Stack = k, v
+			if e.Format == FormatLogfmt {
+				// logfmt values keep their escapes.
+				d.Stack = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(v)
+			}
+			break
+		}
+	}
+	return d
+}
+
+// ErrorChain splits a wrapped Go error such as
+// "loading config: open app.yaml: permission denied" at each ": " into its
+// links, outermost first. Text inside double quotes is not split, and a
+// message without ": " is a single link.
+func ErrorChain(msg string) []string {
+	var chain []string
+	quoted, start := false, 0
+	for i := 0; i < len(msg); i++ {
+		switch {
+		case msg[i] == '\\' && quoted:
+			i++
+		case msg[i] == '"':
+			quoted = !quoted
+		case !quoted && msg[i] == ':' && i+1 < len(msg) && msg[i+1] == ' ':
+			if link := strings.TrimSpace(msg[start:i]); link != "" {
+				chain = append(chain, link)
+			}
+			start = i + 2
+		}
+	}
+	if link := strings.TrimSpace(msg[start:]); link != "" || len(chain) == 0 {
+		chain = append(chain, link)
+	}
+	return chain
+}
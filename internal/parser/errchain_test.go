@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrorChain(t *testing.T) {
+	tests := map[string][]string{
+		"handling request: loading user 42: sql: no rows in result set": {"handling request", "loading user 42", "sql", "no rows in result set"},
+		"save: open /tmp/x: permission denied":                          {"save", "open /tmp/x", "permission denied"},
+		"connection refused":                                            {"connection refused"},
+		"dial tcp 10.0.0.1:5432: i/o timeout":                           {"dial tcp 10.0.0.1:5432", "i/o timeout"},
+		`parse "a: b": invalid syntax`:                                  {`parse "a: b"`, "invalid syntax"},
+		"":                                                              {""},
+	}
+	for msg, want := range tests {
+		if got := ErrorChain(msg); !reflect.DeepEqual(got, want) {
+			t.Errorf("ErrorChain(%q) = %q, want %q", msg, got, want)
+		}
+	}
+}
+
+func TestExtractErrorDetails(t *testing.T) {
+	e := NewAutoParser().Parse(`{"level":"error","msg":"request failed","error":"handler: query users: context deadline exceeded","stacktrace":"main.handler\n\t/app/main.go:42\nmain.main\n\t/app/main.go:10"}`)
+	d := ExtractErrorDetails(e)
+
+	if want := []string{"handler", "query users", "context deadline exceeded"}; d.ErrorKey != "error" || !reflect.DeepEqual(d.Chain, want) {
+		t.Errorf("ErrorKey = %q, Chain = %q, want %q", d.ErrorKey, d.Chain, want)
+	}
+	if want := "main.handler\n\t/app/main.go:42\nmain.main\n\t/app/main.go:10"; d.StackKey != "stacktrace" || d.Stack != want {
+		t.Errorf("StackKey = %q, Stack = %q", d.StackKey, d.Stack)
+	}
+	if e.Fields["error"] != "handler: query users: context deadline exceeded" {
+		t.Errorf("raw error field changed: %q", e.Fields["error"])
+	}
+
+	e = NewAutoParser().Parse(`level=error msg=failed err="boom" stack="main.f\n\tmain.go:3"`)
+	d = ExtractErrorDetails(e)
+	if d.ErrorKey != "err" || !reflect.DeepEqual(d.Chain, []string{"boom"}) {
+		t.Errorf("logfmt: ErrorKey = %q, Chain = %q", d.ErrorKey, d.Chain)
+	}
+	if d.Stack != "main.f\n\tmain.go:3" {
+		t.Errorf("logfmt: Stack = %q", d.Stack)
+	}
+
+	if d := ExtractErrorDetails(NewAutoParser().Parse("ERROR something error=x: y")); d.ErrorKey != "" {
+		t.Errorf("plain entries should be ignored, got %+v", d)
+	}
+}
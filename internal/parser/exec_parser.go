@@ -0,0 +1,300 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultExecTimeout is how long an ExecParser waits for the command's
+	// answer to one line.
+	DefaultExecTimeout = 2 * time.Second
+	// DefaultExecRestarts is how many times in a row an ExecParser restarts
+	// a failing command before leaving every line to its fallback.
+	DefaultExecRestarts = 5
+)
+
+// ExecConfig configures an ExecParser.
+type ExecConfig struct {
+	// Command is the program to run and its arguments.
+	Command []string
+	// Timeout bounds the wait for each answer (default DefaultExecTimeout).
+	// A command that misses it is killed and restarted.
+	Timeout time.Duration
+	// MaxRestarts is how many consecutive failures, crashes or timeouts,
+	// are answered with a restart (default DefaultExecRestarts). After that
+	// the command is given up on.
+	MaxRestarts int
+	// Fallback parses the lines the command gives no usable answer for
+	// (default a new AutoParser). It is not used when the ExecParser is
+	// installed in an AutoParser with SetExecParser, which falls back to
+	// its own detection.
+	Fallback Parser
+	// Stderr receives the command's standard error; nil discards it.
+	Stderr io.Writer
+	// Time sets how zoneless and yearless timestamps in answers are
+	// interpreted.
+	Time TimeConfig
+}
+
+// ExecParser hands parsing to an external command. The command runs for as
+// long as the parser does: each raw line is written to its standard input,
+// and it answers with one line of JSON on standard output:
+//
+//	{"timestamp": "2026-02-17T20:00:00Z", "level": "info", "message": "...", "fields": {"k": "v"}}
+//
+// Every key is optional; timestamps are understood like those of JSON logs
+// and field values may be any JSON value. A command that crashes or times out
+// is restarted. Lines it cannot answer for, answers that are not a JSON
+// object, and lines containing a newline (which would break the protocol) go
+// to the fallback parser. ExecParser is safe for concurrent use; lines are
+// sent to the command one at a time.
+type ExecParser struct {
+	config ExecConfig
+
+	mu       sync.Mutex
+	proc     *execProc
+	failures int   // consecutive failures since the last answer
+	err      error // latest failure
+}
+
+// NewExecParser creates an ExecParser. The command is started by Start or
+// the first Parse.
+func NewExecParser(cfg ExecConfig) *ExecParser {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultExecTimeout
+	}
+	if cfg.MaxRestarts <= 0 {
+		cfg.MaxRestarts = DefaultExecRestarts
+	}
+	if cfg.Fallback == nil {
+		cfg.Fallback = NewAutoParser()
+	}
+	return &ExecParser{config: cfg}
+}
+
+// Start starts the command if it is not running, reporting whether it could
+// be started at all.
+func (p *ExecParser) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.proc != nil {
+		return nil
+	}
+	proc, err := p.start()
+	if err != nil {
+		return err
+	}
+	p.proc = proc
+	return nil
+}
+
+// Parse sends line to the command and returns the entry it answers with, or
+// the fallback parser's entry. A panic in either is recovered from: the
+// line is returned unparsed and the panic reported by Err.
+func (p *ExecParser) Parse(line string) LogEntry {
+	var entry LogEntry
+	err := safely(line, func() {
+		var ok bool
+		if entry, ok = p.parse(line); !ok {
+			entry = p.config.Fallback.Parse(line)
+		}
+	})
+	if err != nil {
+		p.mu.Lock()
+		p.err = err
+		p.mu.Unlock()
+		return unparsed(line)
+	}
+	return entry
+}
+
+// parse sends line to the command and reports whether it gave a usable
+// answer.
+func (p *ExecParser) parse(line string) (LogEntry, bool) {
+	if strings.ContainsRune(line, '\n') {
+		return LogEntry{}, false
+	}
+	answer, ok := p.exchange(line)
+	if !ok {
+		return LogEntry{}, false
+	}
+	entry, err := decodeExecEntry(answer, line, p.config.Time)
+	return entry, err == nil
+}
+
+// exchange is roundTrip under p.mu.
+func (p *ExecParser) exchange(line string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.roundTrip(line)
+}
+
+// Err returns the latest failure of the command or recovered panic, or nil.
+func (p *ExecParser) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close stops the command. Lines parsed afterwards restart it.
+func (p *ExecParser) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.proc != nil {
+		p.proc.stop()
+		p.proc = nil
+	}
+	return nil
+}
+
+// roundTrip writes line to the command, starting it if needed, and waits for
+// the answer. It reports false if there is none; the command is then stopped
+// so that the next line restarts it. p.mu must be held.
+func (p *ExecParser) roundTrip(line string) (string, bool) {
+	if p.proc == nil {
+		if p.failures > p.config.MaxRestarts {
+			return "", false
+		}
+		proc, err := p.start()
+		if err != nil {
+			p.fail(err)
+			return "", false
+		}
+		p.proc = proc
+	}
+	if _, err := io.WriteString(p.proc.stdin, line+"\n"); err != nil {
+		p.fail(fmt.Errorf("writing to parser command: %w", err))
+		return "", false
+	}
+
+	timer := time.NewTimer(p.config.Timeout)
+	defer timer.Stop()
+	select {
+	case answer := <-p.proc.answers:
+		p.failures = 0
+		return answer, true
+	case <-p.proc.exited:
+		// An answer written just before exiting still counts.
+		select {
+		case answer := <-p.proc.answers:
+			p.failures = 0
+			return answer, true
+		default:
+		}
+		p.fail(errors.New("parser command exited"))
+	case <-timer.C:
+		p.fail(fmt.Errorf("parser command did not answer within %s", p.config.Timeout))
+	}
+	return "", false
+}
+
+// fail records err and stops the command. p.mu must be held.
+func (p *ExecParser) fail(err error) {
+	p.err = err
+	p.failures++
+	if p.proc != nil {
+		p.proc.stop()
+		p.proc = nil
+	}
+}
+
+// start launches the command.
+func (p *ExecParser) start() (*execProc, error) {
+	if len(p.config.Command) == 0 {
+		return nil, errors.New("no parser command given")
+	}
+	cmd := exec.Command(p.config.Command[0], p.config.Command[1:]...)
+	cmd.Stderr = p.config.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("starting parser command: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("starting parser command: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting parser command: %w", err)
+	}
+	proc := &execProc{
+		cmd:     cmd,
+		stdin:   stdin,
+		answers: make(chan string, 1),
+		quit:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	go proc.read(stdout)
+	return proc, nil
+}
+
+// execProc is one run of the command.
+type execProc struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	answers chan string   // lines read from stdout
+	quit    chan struct{} // closed by stop
+	exited  chan struct{} // closed once the command has exited
+}
+
+// read passes the lines of stdout on to answers until the command exits or
+// is stopped.
+func (e *execProc) read(stdout io.Reader) {
+	defer close(e.exited)
+	defer e.cmd.Wait()
+	br := bufio.NewReader(stdout)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		select {
+		case e.answers <- strings.TrimRight(line, "\r\n"):
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// stop kills the command and waits for it to exit.
+func (e *execProc) stop() {
+	close(e.quit)
+	e.stdin.Close()
+	e.cmd.Process.Kill()
+	<-e.exited
+}
+
+// execAnswer is the JSON the command answers with.
+type execAnswer struct {
+	Timestamp interface{}            `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// decodeExecEntry converts the command's answer for line to an entry.
+func decodeExecEntry(answer, line string, tc TimeConfig) (LogEntry, error) {
+	var a execAnswer
+	if err := json.Unmarshal([]byte(answer), &a); err != nil {
+		return LogEntry{}, fmt.Errorf("malformed parser command answer: %w", err)
+	}
+	entry := LogEntry{
+		Timestamp: tc.parse(a.Timestamp),
+		Level:     a.Level,
+		Message:   a.Message,
+		Fields:    make(map[string]string, len(a.Fields)),
+		LineCount: 1,
+		Raw:       line,
+	}
+	for k, v := range a.Fields {
+		entry.Fields[k] = jsonValueString(v)
+	}
+	return entry, nil
+}
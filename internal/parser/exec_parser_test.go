@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// execHelperSource is a parser command for the tests. It answers each line
+// with an entry echoing it, except for a few lines that misbehave on purpose.
+const execHelperSource = `package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		line := in.Text()
+		switch line {
+		case "bad":
+			fmt.Println("not json")
+			continue
+		case "crash":
+			os.Exit(3)
+		case "slow":
+			time.Sleep(time.Minute)
+		}
+		out, _ := json.Marshal(map[string]interface{}{
+			"timestamp": "2026-02-17T20:00:00Z",
+			"level":     "info",
+			"message":   "echo: " + line,
+			"fields":    map[string]interface{}{"pid": os.Getpid(), "len": len(line)},
+		})
+		fmt.Println(string(out))
+	}
+}
+`
+
+var (
+	execHelperOnce sync.Once
+	execHelperPath string
+	execHelperErr  error
+)
+
+// execHelper builds execHelperSource once and returns the binary's path.
+func execHelper(t *testing.T) string {
+	t.Helper()
+	execHelperOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "logpilot-exec-helper")
+		if err != nil {
+			execHelperErr = err
+			return
+		}
+		src := filepath.Join(dir, "main.go")
+		if err := os.WriteFile(src, []byte(execHelperSource), 0o644); err != nil {
+			execHelperErr = err
+			return
+		}
+		execHelperPath = filepath.Join(dir, "helper")
+		if out, err := exec.Command("go", "build", "-o", execHelperPath, src).CombinedOutput(); err != nil {
+			t.Logf("%s", out)
+			execHelperErr = err
+		}
+	})
+	if execHelperErr != nil {
+		t.Fatalf("building helper: %v", execHelperErr)
+	}
+	return execHelperPath
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if execHelperPath != "" {
+		os.RemoveAll(filepath.Dir(execHelperPath))
+	}
+	os.Exit(code)
+}
+
+func newTestExecParser(t *testing.T, timeout time.Duration) *ExecParser {
+	t.Helper()
+	p := NewExecParser(ExecConfig{Command: []string{execHelper(t)}, Timeout: timeout})
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestExecParser_RoundTrip(t *testing.T) {
+	p := newTestExecParser(t, 5*time.Second)
+
+	for _, line := range []string{"first line", "second line"} {
+		entry := p.Parse(line)
+		if entry.Message != "echo: "+line {
+			t.Errorf("Message = %q, want %q", entry.Message, "echo: "+line)
+		}
+		if entry.Level != "info" {
+			t.Errorf("Level = %q, want info", entry.Level)
+		}
+		if want := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC); !entry.Timestamp.Equal(want) {
+			t.Errorf("Timestamp = %v, want %v", entry.Timestamp, want)
+		}
+		if entry.Fields["len"] == "" || entry.Fields["pid"] == "" {
+			t.Errorf("Fields = %v, want len and pid", entry.Fields)
+		}
+		if entry.Raw != line || entry.LineCount != 1 {
+			t.Errorf("Raw = %q, LineCount = %d", entry.Raw, entry.LineCount)
+		}
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("Err = %v, want nil", err)
+	}
+}
+
+func TestExecParser_MalformedAnswerFallsBack(t *testing.T) {
+	p := newTestExecParser(t, 5*time.Second)
+
+	entry := p.Parse("bad")
+	if entry.Message != "bad" || entry.Format != FormatPlain {
+		t.Errorf("got %q (%v), want the fallback's plain entry", entry.Message, entry.Format)
+	}
+	// The command keeps running.
+	pid := p.Parse("next").Fields["pid"]
+	if p.Parse("again").Fields["pid"] != pid || pid == "" {
+		t.Errorf("command restarted after a malformed answer")
+	}
+}
+
+func TestExecParser_CrashRestarts(t *testing.T) {
+	p := newTestExecParser(t, 5*time.Second)
+
+	before := p.Parse("hello").Fields["pid"]
+	entry := p.Parse("crash")
+	if entry.Message != "crash" {
+		t.Errorf("crashed line: Message = %q, want the fallback's", entry.Message)
+	}
+	if p.Err() == nil {
+		t.Error("Err = nil after a crash")
+	}
+	after := p.Parse("hello again")
+	if after.Message != "echo: hello again" {
+		t.Fatalf("after crash: Message = %q, want it parsed by a new command", after.Message)
+	}
+	if after.Fields["pid"] == before {
+		t.Errorf("pid %s unchanged, want a restarted command", before)
+	}
+}
+
+func TestExecParser_Timeout(t *testing.T) {
+	p := newTestExecParser(t, 200*time.Millisecond)
+
+	start := time.Now()
+	entry := p.Parse("slow")
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Parse took %v, want the timeout to cut it short", d)
+	}
+	if entry.Message != "slow" {
+		t.Errorf("Message = %q, want the fallback's", entry.Message)
+	}
+	if entry := p.Parse("quick"); entry.Message != "echo: quick" {
+		t.Errorf("after timeout: Message = %q, want it parsed by a new command", entry.Message)
+	}
+}
+
+func TestExecParser_GivesUp(t *testing.T) {
+	p := NewExecParser(ExecConfig{Command: []string{execHelper(t)}, MaxRestarts: 2})
+	t.Cleanup(func() { p.Close() })
+
+	for i := 0; i < 3; i++ {
+		p.Parse("crash")
+	}
+	if entry := p.Parse("hello"); entry.Message != "hello" {
+		t.Errorf("Message = %q, want the fallback's once restarts are used up", entry.Message)
+	}
+}
+
+func TestExecParser_StartError(t *testing.T) {
+	p := NewExecParser(ExecConfig{Command: []string{filepath.Join(t.TempDir(), "missing")}})
+	if err := p.Start(); err == nil {
+		t.Fatal("Start: want an error for a missing command")
+	}
+	if entry := p.Parse(`level=warn msg=hi`); entry.Level != "WARN" || entry.Message != "hi" {
+		t.Errorf("got %+v, want the fallback's logfmt entry", entry)
+	}
+}
+
+func TestAutoParser_SetExecParser(t *testing.T) {
+	a := NewAutoParser()
+	a.SetExecParser(newTestExecParser(t, 5*time.Second))
+
+	if entry := a.Parse("hello"); entry.Message != "echo: hello" {
+		t.Errorf("Message = %q, want the command's answer", entry.Message)
+	}
+	entry := a.Parse(`{"level":"error","msg":"bad"}`)
+	if entry.Message != "echo: "+`{"level":"error","msg":"bad"}` {
+		t.Errorf("Message = %q, want the command's answer", entry.Message)
+	}
+	// Unanswered lines are detected as usual.
+	if entry := a.Parse("bad"); entry.Message != "bad" || entry.Format != FormatPlain {
+		t.Errorf("got %q (%v), want a plain entry", entry.Message, entry.Format)
+	}
+	if entry := a.Pin(FormatJSON).Parse("pinned"); entry.Message != "echo: pinned" {
+		t.Errorf("pinned: Message = %q, want the command's answer", entry.Message)
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gelfVersionPattern recognizes the version key every GELF 1.1 payload carries.
+var gelfVersionPattern = regexp.MustCompile(`"version"\s*:\s*"1\.1"`)
+
+// isGELF reports whether a JSON object line looks like a GELF message.
+func isGELF(line string) bool {
+	return gelfVersionPattern.MatchString(line)
+}
+
+// GELFParser parses Graylog Extended Log Format (GELF 1.1) JSON lines.
+type GELFParser struct{}
+
+// Parse parses a GELF JSON line. short_message becomes the message, the
+// numeric syslog level is mapped to a canonical level, and "_"-prefixed
+// additional fields are stored without the underscore.
+func (p *GELFParser) Parse(line string) LogEntry {
+	entry := LogEntry{
+		Raw:       line,
+		Format:    FormatGELF,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &raw); err != nil {
+		entry.Message = line
+		return entry
+	}
+
+	for k, v := range raw {
+		switch k {
+		case "version":
+			// Protocol bookkeeping, not interesting to display.
+		case "short_message":
+			entry.Message = jsonValueString(v)
+		case "timestamp":
+			if f, ok := v.(float64); ok {
+				sec, frac := math.Modf(f)
+				entry.Timestamp = time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3)
+			}
+		case "level":
+			if f, ok := v.(float64); ok {
+				entry.Level = syslogLevel(int(f))
+			}
+		default:
+			entry.Fields[strings.TrimPrefix(k, "_")] = jsonValueString(v)
+		}
+	}
+	return entry
+}
+
+// jsonValueString renders a decoded JSON value as a field string.
+func jsonValueString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// syslogLevel maps a syslog severity (0 emergency … 7 debug) to a canonical
+// level name.
+func syslogLevel(severity int) string {
+	switch {
+	case severity <= 2:
+		return "FATAL"
+	case severity == 3:
+		return "ERROR"
+	case severity == 4:
+		return "WARN"
+	case severity <= 6:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
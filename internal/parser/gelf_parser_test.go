@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+const gelfSample = `{"version":"1.1","host":"web-01.example.org","short_message":"Connection refused","full_message":"Connection refused\nat db.Connect (db.go:42)","timestamp":1739822400.123,"level":3,"_request_id":"abc-123","_user_id":42,"_tags":["db","retry"]}`
+
+func TestGELFParser_Payload(t *testing.T) {
+	p := &GELFParser{}
+	e := p.Parse(gelfSample)
+
+	if e.Format != FormatGELF {
+		t.Errorf("Format = %v, want gelf", e.Format)
+	}
+	if e.Message != "Connection refused" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Level != "ERROR" {
+		t.Errorf("Level = %q, want ERROR", e.Level)
+	}
+	want := time.Unix(1739822400, 123000000)
+	if !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+
+	fields := map[string]string{
+		"host":         "web-01.example.org",
+		"full_message": "Connection refused\nat db.Connect (db.go:42)",
+		"request_id":   "abc-123",
+		"user_id":      "42",
+		"tags":         `["db","retry"]`,
+	}
+	for k, v := range fields {
+		if e.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, e.Fields[k], v)
+		}
+	}
+	for _, k := range []string{"_request_id", "version", "short_message", "level", "timestamp"} {
+		if _, ok := e.Fields[k]; ok {
+			t.Errorf("unexpected field %q", k)
+		}
+	}
+}
+
+func TestGELFParser_Levels(t *testing.T) {
+	tests := map[int]string{0: "FATAL", 2: "FATAL", 3: "ERROR", 4: "WARN", 5: "INFO", 6: "INFO", 7: "DEBUG"}
+	for severity, want := range tests {
+		if got := syslogLevel(severity); got != want {
+			t.Errorf("syslogLevel(%d) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+func TestGELFParser_Invalid(t *testing.T) {
+	p := &GELFParser{}
+	e := p.Parse(`{"version":"1.1",`)
+	if e.Message != `{"version":"1.1",` {
+		t.Errorf("invalid payload should fall back to raw message, got %q", e.Message)
+	}
+}
+
+func TestDetectGELF(t *testing.T) {
+	lines := []string{
+		gelfSample,
+		`{"version": "1.1", "host": "h", "short_message": "ok", "level": 6}`,
+	}
+	if got := DetectFormat(lines); got != FormatGELF {
+		t.Errorf("DetectFormat = %v, want gelf", got)
+	}
+	if got := detectLine(`{"level":"info","version":"2.0"}`); got != FormatJSON {
+		t.Errorf("non-GELF JSON detected as %v", got)
+	}
+}
+
+func TestAutoParser_RoutesGELF(t *testing.T) {
+	a := &AutoParser{}
+	e := a.Parse(gelfSample)
+	if e.Format != FormatGELF || e.Fields["request_id"] != "abc-123" {
+		t.Errorf("AutoParser did not route to GELF: %+v", e)
+	}
+	if e := a.Parse(`{"level":"info","msg":"plain json"}`); e.Format != FormatJSON {
+		t.Errorf("generic JSON routed to %v", e.Format)
+	}
+}
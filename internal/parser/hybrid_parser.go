@@ -0,0 +1,92 @@
+package parser
+
+import "strings"
+
+// HybridParser parses lines made of a plain-text prefix followed by logfmt
+// pairs, as syslog writes the logfmt output of a program:
+//
+//	2024-01-15T10:30:00Z app[123]: level=info msg="listening" port=8080
+//
+// The prefix's timestamp and level are found as PlainParser finds them and
+// the pairs are parsed as LogfmtParser parses a line; where both have a
+// timestamp or a level, the pairs' wins. What remains of the prefix, such
+// as "app[123]", becomes the "prefix" field, or the message if the pairs
+// hold none. Entries have FormatLogfmt.
+type HybridParser struct {
+	plain  PlainParser
+	logfmt LogfmtParser
+}
+
+// NewHybridParser returns a hybrid parser that reads the timestamp, level
+// and message of the pairs from the keys named in keys. The zero
+// HybridParser uses DefaultKeySet.
+func NewHybridParser(keys KeySet) *HybridParser {
+	return &HybridParser{logfmt: LogfmtParser{keys: keys.resolve()}}
+}
+
+// SetTimeConfig sets how zoneless and yearless timestamps are interpreted.
+func (p *HybridParser) SetTimeConfig(cfg TimeConfig) {
+	p.plain.SetTimeConfig(cfg)
+	p.logfmt.SetTimeConfig(cfg)
+}
+
+// Parse parses a line. A line that is not a prefix followed by logfmt
+// pairs is parsed as plain text.
+func (p *HybridParser) Parse(line string) LogEntry {
+	if entry, ok := p.parse(line); ok {
+		return entry
+	}
+	return p.plain.Parse(line)
+}
+
+// parse parses line, reporting false if it is not a prefix followed by
+// logfmt pairs.
+func (p *HybridParser) parse(line string) (LogEntry, bool) {
+	prefix, tail, ok := splitHybrid(strings.TrimSpace(line), p.logfmt.keys.orDefault())
+	if !ok {
+		return LogEntry{}, false
+	}
+	head := p.plain.Parse(prefix)
+	entry := p.logfmt.Parse(tail)
+	entry.Raw = line
+	entry.LineCount = CountLines(line)
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = head.Timestamp
+	}
+	if entry.Level == "" {
+		entry.Level = head.Level
+	}
+	if rest := strings.TrimSpace(strings.TrimSuffix(head.Message, ":")); rest != "" {
+		if entry.Message == "" {
+			entry.Message = rest
+		} else if _, ok := entry.Fields["prefix"]; !ok {
+			entry.Fields["prefix"] = rest
+		}
+	}
+	return entry, true
+}
+
+// splitHybrid splits line into a plain-text prefix and a logfmt tail. The
+// tail starts with the word holding the line's first '=', must start with
+// at least two pairs and must name a timestamp, level or message key of
+// keys, so that prose ending in a few key=value pairs stays plain text.
+func splitHybrid(line string, keys KeySet) (prefix, tail string, ok bool) {
+	eq := strings.IndexByte(line, '=')
+	if eq < 0 {
+		return "", "", false
+	}
+	start := strings.LastIndexAny(line[:eq], " \t") + 1
+	if start == 0 {
+		return "", "", false
+	}
+	prefix, tail = strings.TrimSpace(line[:start]), line[start:]
+	if prefix == "" || !isLogfmt(tail) {
+		return "", "", false
+	}
+	named := false
+	scanLogfmt(tail, func(key, value string, bare bool) bool {
+		named = keys.has(strings.ToLower(key))
+		return !named
+	})
+	return prefix, tail, named
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHybridParser_SyslogPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		ts     time.Time
+		level  string
+		msg    string
+		fields map[string]string
+	}{
+		{
+			name:   "prefix timestamp",
+			line:   `2024-01-15T10:30:00Z app[123]: level=info msg="listening" port=8080`,
+			ts:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			level:  "INFO",
+			msg:    "listening",
+			fields: map[string]string{"port": "8080", "prefix": "app[123]"},
+		},
+		{
+			name:   "pairs timestamp wins",
+			line:   `2024-01-15T10:30:00Z app[123]: ts=2024-01-15T10:29:59.5Z level=warn msg="slow query" ms=812`,
+			ts:     time.Date(2024, 1, 15, 10, 29, 59, 5e8, time.UTC),
+			level:  "WARN",
+			msg:    "slow query",
+			fields: map[string]string{"ms": "812", "prefix": "app[123]"},
+		},
+		{
+			name:   "prefix level",
+			line:   `2024-01-15 10:30:00 ERROR worker: msg="job failed" job=7`,
+			ts:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			level:  "ERROR",
+			msg:    "job failed",
+			fields: map[string]string{"job": "7", "prefix": "ERROR worker"},
+		},
+		{
+			name:   "no message key",
+			line:   `2024-01-15T10:30:00Z cron[9]: level=debug job=cleanup`,
+			ts:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+			level:  "DEBUG",
+			msg:    "cron[9]",
+			fields: map[string]string{"job": "cleanup"},
+		},
+	}
+	p := NewAutoParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := p.Parse(tt.line)
+			if e.Format != FormatLogfmt || !e.Timestamp.Equal(tt.ts) || e.Level != tt.level || e.Message != tt.msg || e.Raw != tt.line {
+				t.Errorf("got %v %v %q %q (raw %q), want logfmt %v %q %q", e.Format, e.Timestamp, e.Level, e.Message, e.Raw, tt.ts, tt.level, tt.msg)
+			}
+			if len(e.Fields) != len(tt.fields) {
+				t.Errorf("fields = %q, want %q", e.Fields, tt.fields)
+			}
+			for k, v := range tt.fields {
+				if e.Fields[k] != v {
+					t.Errorf("fields[%q] = %q, want %q", k, e.Fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestHybridParser_LeavesOtherLines(t *testing.T) {
+	p := NewAutoParser()
+	for line, want := range map[string]Format{
+		`level=info msg=hello user=bob`:                   FormatLogfmt,
+		`user login failed user=bob ip=10.0.0.1`:          FormatPlain, // no timestamp, level or message key
+		`2024-01-15T10:30:00Z starting up port=8080`:      FormatPlain, // a single pair
+		`2024-01-15T10:30:00Z app: a=b level=info x=y`:    FormatLogfmt,
+		`2024-01-15T10:30:00Z retry=3 after "a b" level=`: FormatPlain, // one pair, then prose
+	} {
+		if got := p.Parse(line).Format; got != want {
+			t.Errorf("Parse(%q).Format = %v, want %v", line, got, want)
+		}
+	}
+
+	// On its own, HybridParser parses other lines as plain text.
+	if e := (&HybridParser{}).Parse("just prose"); e.Format != FormatPlain || e.Message != "just prose" {
+		t.Errorf("got %v %q", e.Format, e.Message)
+	}
+}
@@ -2,42 +2,144 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 )
 
-var (
-	timestampKeys = []string{"timestamp", "time", "ts", "@timestamp", "created_at"}
-	levelKeys     = []string{"level", "severity", "log_level", "lvl"}
-	messageKeys   = []string{"message", "msg", "log", "text"}
+// MessageFallback selects what a JSON entry without a message key gets as
+// its message.
+type MessageFallback int
+
+const (
+	// FallbackSynthesize builds the message from the entry's fields: the
+	// first of JSONParserConfig.FallbackKeys present, or else a few of the
+	// other fields as key=value pairs.
+	FallbackSynthesize MessageFallback = iota
+	// FallbackNone leaves the message empty, so the raw line is shown.
+	FallbackNone
 )
 
+// DefaultFallbackKeys are the fields, in order of preference, a message is
+// taken from when a JSON entry has no message key.
+var DefaultFallbackKeys = []string{"event", "action", "operation", "error", "err", "description"}
+
+// DefaultFallbackFields is how many fields a synthesized message lists when
+// none of the fallback keys is present.
+const DefaultFallbackFields = 3
+
+// JSONParserConfig controls how JSONParser fills in the message of entries
+// that have none of the message keys. The zero value synthesizes one from
+// DefaultFallbackKeys or DefaultFallbackFields fields. Either way the
+// fields are all kept.
+type JSONParserConfig struct {
+	MessageFallback MessageFallback
+	// FallbackKeys are the fields, in order of preference and compared
+	// case-insensitively, whose value becomes the message (default
+	// DefaultFallbackKeys).
+	FallbackKeys []string
+	// FallbackFields is how many fields, in key order, make up the message
+	// when none of FallbackKeys is present (default DefaultFallbackFields).
+	// Only fields with a string, number or boolean value are used.
+	FallbackFields int
+}
+
 // JSONParser parses JSON log lines.
-type JSONParser struct{}
+type JSONParser struct {
+	timeCfg TimeConfig
+	keys    KeySet
+	cfg     JSONParserConfig
+}
+
+// NewJSONParser returns a JSON parser that reads the timestamp, level and
+// message from the fields named in keys. The zero JSONParser uses
+// DefaultKeySet.
+func NewJSONParser(keys KeySet) *JSONParser {
+	return &JSONParser{keys: keys.resolve()}
+}
+
+// SetTimeConfig sets how zoneless and yearless timestamps are interpreted.
+func (p *JSONParser) SetTimeConfig(cfg TimeConfig) { p.timeCfg = cfg }
+
+// SetConfig sets how entries without a message key get their message.
+func (p *JSONParser) SetConfig(cfg JSONParserConfig) {
+	if cfg.FallbackKeys != nil {
+		keys := make([]string, len(cfg.FallbackKeys))
+		for i, k := range cfg.FallbackKeys {
+			keys[i] = strings.ToLower(k)
+		}
+		cfg.FallbackKeys = keys
+	}
+	p.cfg = cfg
+}
 
-// Parse parses a JSON log line.
+// Parse parses a JSON log line. A line that is not valid JSON becomes the
+// message as is.
 func (p *JSONParser) Parse(line string) LogEntry {
+	entry, _ := p.parse(line)
+	return entry
+}
+
+// ParseMulti parses a JSON array of objects into one entry per element, and
+// any other line like Parse.
+func (p *JSONParser) ParseMulti(line string) []LogEntry {
+	elems, ok := jsonArrayElements(line)
+	if !ok {
+		return []LogEntry{p.Parse(line)}
+	}
+	entries := make([]LogEntry, len(elems))
+	for i, elem := range elems {
+		entries[i] = p.Parse(elem)
+	}
+	return entries
+}
+
+// jsonArrayElements returns the elements of line if it is a non-empty JSON
+// array of objects, as exporters write a batch of entries.
+func jsonArrayElements(line string) ([]string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) < 2 || trimmed[0] != '[' || trimmed[len(trimmed)-1] != ']' ||
+		!strings.HasPrefix(strings.TrimLeft(trimmed[1:], " \t\r\n"), "{") {
+		return nil, false
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &raw); err != nil || len(raw) == 0 {
+		return nil, false
+	}
+	elems := make([]string, len(raw))
+	for i, r := range raw {
+		if len(r) == 0 || r[0] != '{' {
+			return nil, false
+		}
+		elems[i] = string(r)
+	}
+	return elems, true
+}
+
+// parse is Parse, also reporting whether the line was valid JSON.
+func (p *JSONParser) parse(line string) (LogEntry, bool) {
 	entry := LogEntry{
-		Raw:    line,
-		Format: FormatJSON,
-		Fields: make(map[string]string),
+		Raw:       line,
+		Format:    FormatJSON,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
 	}
 
 	var raw map[string]interface{}
 	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &raw); err != nil {
 		entry.Message = line
-		return entry
+		return entry, false
 	}
 
 	// Extract known fields
-	entry.Timestamp = extractTimestamp(raw, timestampKeys)
-	entry.Level = extractString(raw, levelKeys)
-	entry.Message = extractString(raw, messageKeys)
+	keys := p.keys.orDefault()
+	entry.Timestamp = extractTimestamp(raw, keys.Timestamp, p.timeCfg)
+	entry.Level = extractLevel(raw, keys.Level)
+	entry.Message = extractString(raw, keys.Message)
 
 	// Remaining fields
 	for k, v := range raw {
-		kl := strings.ToLower(k)
-		if isKnownKey(kl, timestampKeys) || isKnownKey(kl, levelKeys) || isKnownKey(kl, messageKeys) {
+		if keys.has(strings.ToLower(k)) {
 			continue
 		}
 		switch val := v.(type) {
@@ -52,71 +154,163 @@ func (p *JSONParser) Parse(line string) LogEntry {
 	// Normalize level
 	entry.Level = strings.ToUpper(entry.Level)
 
-	return entry
+	if entry.Message == "" && !hasKey(raw, keys.Message) && p.cfg.MessageFallback == FallbackSynthesize {
+		entry.Message = p.synthesizeMessage(raw, keys)
+	}
+	return entry, true
 }
 
-func extractString(m map[string]interface{}, keys []string) string {
-	for k, v := range m {
-		kl := strings.ToLower(k)
-		for _, key := range keys {
-			if kl == key {
-				if s, ok := v.(string); ok {
-					return s
-				}
+// hasKey reports whether any of keys is present in m, compared
+// case-insensitively.
+func hasKey(m map[string]interface{}, keys []string) bool {
+	_, ok := lookupKey(m, keys)
+	return ok
+}
+
+// synthesizeMessage builds a message for an entry without a message key:
+// the value of the first fallback key holding a non-empty scalar, or else
+// the first few scalar fields other than the timestamp and level, in key
+// order, as key=value pairs. It returns "" if there are none.
+func (p *JSONParser) synthesizeMessage(raw map[string]interface{}, keys KeySet) string {
+	fallback := p.cfg.FallbackKeys
+	if fallback == nil {
+		fallback = DefaultFallbackKeys
+	}
+	for _, key := range fallback {
+		for k, v := range raw {
+			if strings.ToLower(k) != key {
+				continue
+			}
+			if s, ok := scalarString(v); ok && s != "" {
+				return s
 			}
 		}
 	}
-	return ""
+
+	n := p.cfg.FallbackFields
+	if n <= 0 {
+		n = DefaultFallbackFields
+	}
+	var names []string
+	for k, v := range raw {
+		if _, ok := scalarString(v); ok && !keys.has(strings.ToLower(k)) {
+			names = append(names, k)
+		}
+	}
+	sortStrings(names)
+	parts := make([]string, 0, n)
+	for _, k := range names[:min(n, len(names))] {
+		s, _ := scalarString(raw[k])
+		if strings.ContainsAny(s, " \t\"=") || s == "" {
+			s = fmt.Sprintf("%q", s)
+		}
+		parts = append(parts, k+"="+s)
+	}
+	return strings.Join(parts, " ")
 }
 
-func extractTimestamp(m map[string]interface{}, keys []string) time.Time {
-	for k, v := range m {
-		kl := strings.ToLower(k)
-		for _, key := range keys {
-			if kl == key {
-				return parseTimestamp(v)
-			}
+// sortStrings sorts s in place.
+func sortStrings(s []string) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j] < s[j-1]; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
 		}
 	}
-	return time.Time{}
 }
 
-func isKnownKey(k string, keys []string) bool {
+// scalarString returns a JSON string, number or boolean as a string.
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64, bool:
+		b, _ := json.Marshal(v)
+		return string(b), true
+	default:
+		return "", false
+	}
+}
+
+// lookupKey returns the value of the first of keys present in m, compared
+// case-insensitively.
+func lookupKey(m map[string]interface{}, keys []string) (interface{}, bool) {
 	for _, key := range keys {
-		if k == key {
-			return true
+		for k, v := range m {
+			if strings.ToLower(k) == key {
+				return v, true
+			}
 		}
 	}
-	return false
+	return nil, false
 }
 
-var timeFormats = []string{
-	time.RFC3339,
-	time.RFC3339Nano,
-	"2006-01-02T15:04:05",
-	"2006-01-02 15:04:05",
-	"2006-01-02 15:04:05.000",
-	"2006-01-02T15:04:05.000Z",
-	"02/Jan/2006:15:04:05 -0700",
-	"Jan  2 15:04:05",
-	"Jan 2 15:04:05",
-	"2006/01/02 15:04:05",
+// extractString returns the first of keys in m that holds a string.
+func extractString(m map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		for k, v := range m {
+			if s, ok := v.(string); ok && strings.ToLower(k) == key {
+				return s
+			}
+		}
+	}
+	return ""
 }
 
-func parseTimestamp(v interface{}) time.Time {
-	switch val := v.(type) {
-	case string:
-		for _, layout := range timeFormats {
-			if t, err := time.Parse(layout, val); err == nil {
-				return t
+// extractLevel returns the level held by the first of keys in m that holds
+// a string or a number. Numbers are mapped by numericLevel.
+func extractLevel(m map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		for k, v := range m {
+			if strings.ToLower(k) != key {
+				continue
+			}
+			switch v := v.(type) {
+			case string:
+				return v
+			case float64:
+				return numericLevel(v)
 			}
 		}
-	case float64:
-		// Unix timestamp (seconds or milliseconds)
-		if val > 1e12 {
-			return time.UnixMilli(int64(val))
-		}
-		return time.Unix(int64(val), 0)
+	}
+	return ""
+}
+
+// numericLevel maps a numeric level to a canonical level name: 10 and up
+// on the pino and bunyan scale (10 trace, 20 debug, 30 info, 40 warn, 50
+// error, 60 fatal), below that as a syslog severity (see syslogLevel).
+func numericLevel(n float64) string {
+	switch {
+	case n < 0:
+		return ""
+	case n < 10:
+		return syslogLevel(int(n))
+	case n < 20:
+		return "TRACE"
+	case n < 30:
+		return "DEBUG"
+	case n < 40:
+		return "INFO"
+	case n < 50:
+		return "WARN"
+	case n < 60:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+func extractTimestamp(m map[string]interface{}, keys []string, cfg TimeConfig) time.Time {
+	if v, ok := lookupKey(m, keys); ok {
+		return cfg.parse(v)
 	}
 	return time.Time{}
 }
+
+func isKnownKey(k string, keys []string) bool {
+	for _, key := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
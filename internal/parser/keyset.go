@@ -0,0 +1,71 @@
+package parser
+
+import "strings"
+
+// KeySet names the fields the JSON and logfmt parsers read the timestamp,
+// level and message from. Keys match case-insensitively; when several are
+// present, the one listed first wins. A nil slice means the default keys for
+// that role.
+type KeySet struct {
+	Timestamp []string
+	Level     []string
+	Message   []string
+}
+
+// DefaultKeySet returns the keys recognized when no KeySet is given.
+func DefaultKeySet() KeySet {
+	return KeySet{
+		Timestamp: []string{"timestamp", "time", "ts", "@timestamp", "created_at"},
+		Level:     []string{"level", "severity", "log_level", "lvl", "priority"},
+		Message:   []string{"message", "msg", "log", "text"},
+	}
+}
+
+// Extend returns the key set with the keys of other added after its own, so
+// that e.g. DefaultKeySet().Extend(KeySet{Message: []string{"body"}}) also
+// recognizes "body" as the message.
+func (ks KeySet) Extend(other KeySet) KeySet {
+	ks = ks.resolve()
+	return KeySet{
+		Timestamp: append(append([]string(nil), ks.Timestamp...), other.Timestamp...),
+		Level:     append(append([]string(nil), ks.Level...), other.Level...),
+		Message:   append(append([]string(nil), ks.Message...), other.Message...),
+	}
+}
+
+// resolve fills unset roles with the defaults and lowercases every key.
+func (ks KeySet) resolve() KeySet {
+	def := DefaultKeySet()
+	return KeySet{
+		Timestamp: lowerKeys(ks.Timestamp, def.Timestamp),
+		Level:     lowerKeys(ks.Level, def.Level),
+		Message:   lowerKeys(ks.Message, def.Message),
+	}
+}
+
+func lowerKeys(keys, def []string) []string {
+	if keys == nil {
+		return def
+	}
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = strings.ToLower(k)
+	}
+	return out
+}
+
+// has reports whether the lowercased key k belongs to any role.
+func (ks KeySet) has(k string) bool {
+	return isKnownKey(k, ks.Timestamp) || isKnownKey(k, ks.Level) || isKnownKey(k, ks.Message)
+}
+
+// defaultKeys is the key set of zero-value parsers.
+var defaultKeys = DefaultKeySet()
+
+// orDefault returns ks, or the default keys for a zero KeySet.
+func (ks KeySet) orDefault() KeySet {
+	if ks.Timestamp == nil && ks.Level == nil && ks.Message == nil {
+		return defaultKeys
+	}
+	return ks
+}
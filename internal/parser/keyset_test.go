@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeySet_Custom(t *testing.T) {
+	keys := KeySet{
+		Timestamp: []string{"@t"},
+		Level:     []string{"LVL2"},
+		Message:   []string{"body"},
+	}
+	want := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+
+	for name, p := range map[string]Parser{
+		"json":   NewJSONParser(keys),
+		"logfmt": NewLogfmtParser(keys),
+	} {
+		line := `{"@t":"2026-02-17T20:00:00Z","lvl2":"warn","body":"custom keys","msg":"not the message"}`
+		if name == "logfmt" {
+			line = `@t=2026-02-17T20:00:00Z lvl2=warn body="custom keys" msg="not the message"`
+		}
+		e := p.Parse(line)
+		if e.Message != "custom keys" || e.Level != "WARN" || !e.Timestamp.Equal(want) {
+			t.Errorf("%s: Message = %q, Level = %q, Timestamp = %v", name, e.Message, e.Level, e.Timestamp)
+		}
+		if e.Fields["msg"] != "not the message" {
+			t.Errorf("%s: replaced default keys should stay ordinary fields, got %v", name, e.Fields)
+		}
+		if _, ok := e.Fields["body"]; ok {
+			t.Errorf("%s: body should not be left in Fields", name)
+		}
+	}
+}
+
+func TestKeySet_Defaults(t *testing.T) {
+	line := `{"time":"2026-02-17T20:00:00Z","level":"error","msg":"default keys"}`
+	for name, p := range map[string]Parser{
+		"zero value":  &JSONParser{},
+		"empty set":   NewJSONParser(KeySet{}),
+		"default set": NewJSONParser(DefaultKeySet()),
+	} {
+		if e := p.Parse(line); e.Message != "default keys" || e.Level != "ERROR" || e.Timestamp.IsZero() {
+			t.Errorf("%s: Message = %q, Level = %q, Timestamp = %v", name, e.Message, e.Level, e.Timestamp)
+		}
+	}
+
+	// Only the message role is overridden; level and timestamp keep the defaults.
+	e := NewLogfmtParser(KeySet{Message: []string{"body"}}).Parse("level=info body=hi ts=2026-02-17T20:00:00Z")
+	if e.Message != "hi" || e.Level != "INFO" || e.Timestamp.IsZero() {
+		t.Errorf("partial set: Message = %q, Level = %q, Timestamp = %v", e.Message, e.Level, e.Timestamp)
+	}
+}
+
+func TestKeySet_Priority(t *testing.T) {
+	// The earlier key in the list wins regardless of order in the line.
+	for i := 0; i < 20; i++ {
+		if e := (&LogfmtParser{}).Parse("text=second message=first"); e.Message != "first" {
+			t.Fatalf("logfmt: Message = %q, want first", e.Message)
+		}
+		if e := (&JSONParser{}).Parse(`{"text":"second","message":"first"}`); e.Message != "first" {
+			t.Fatalf("json: Message = %q, want first", e.Message)
+		}
+	}
+}
+
+func TestAutoParser_SetKeySet(t *testing.T) {
+	p := NewAutoParser()
+	p.SetKeySet(DefaultKeySet().Extend(KeySet{Message: []string{"body"}, Level: []string{"sev"}}))
+
+	if e := p.Parse(`{"sev":"debug","body":"extended"}`); e.Message != "extended" || e.Level != "DEBUG" {
+		t.Errorf("json: Message = %q, Level = %q", e.Message, e.Level)
+	}
+	if e := p.Parse("sev=warn body=extended other=1"); e.Message != "extended" || e.Level != "WARN" {
+		t.Errorf("logfmt: Message = %q, Level = %q", e.Message, e.Level)
+	}
+	if e := p.Parse(`{"level":"info","msg":"still works"}`); e.Message != "still works" || e.Level != "INFO" {
+		t.Errorf("defaults: Message = %q, Level = %q", e.Message, e.Level)
+	}
+}
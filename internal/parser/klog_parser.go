@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// klogPattern matches the klog header "Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg".
+var klogPattern = regexp.MustCompile(`^([IWEF])(\d{4} \d{2}:\d{2}:\d{2}\.\d{6})\s+(\d+) ([^\s:\]]+):(\d+)\] ?(.*)$`)
+
+// klogTimeLayout is the yearless timestamp layout of the klog header.
+const klogTimeLayout = "0102 15:04:05.000000"
+
+// klogLevels maps the klog severity letter to a canonical level.
+var klogLevels = map[string]string{"I": "INFO", "W": "WARN", "E": "ERROR", "F": "FATAL"}
+
+// isKlog reports whether a line starts with a klog header.
+func isKlog(line string) bool {
+	// Check the severity letter first to skip the regex for most lines.
+	return line != "" && strings.IndexByte("IWEF", line[0]) >= 0 && klogPattern.MatchString(line)
+}
+
+// KlogParser parses the glog/klog format used by Kubernetes components, e.g.
+// "E0115 10:30:17.000000   12345 server.go:123] message".
+type KlogParser struct {
+	timeCfg TimeConfig
+}
+
+// SetTimeConfig sets the zone and year assumed for klog's yearless,
+// zoneless timestamps.
+func (p *KlogParser) SetTimeConfig(cfg TimeConfig) { p.timeCfg = cfg }
+
+// Parse parses a klog line. The thread ID and the file:line source go into
+// Fields. Structured klog messages (`"msg" key="value" ...`) have the quotes
+// removed from the message and their pairs added to Fields.
+func (p *KlogParser) Parse(line string) LogEntry {
+	entry := LogEntry{
+		Raw:       line,
+		Format:    FormatKlog,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+	}
+
+	m := klogPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		entry.Message = line
+		return entry
+	}
+	entry.Level = klogLevels[m[1]]
+	if t, err := time.ParseInLocation(klogTimeLayout, m[2], p.timeCfg.location()); err == nil {
+		entry.Timestamp = p.timeCfg.withYear(t)
+	}
+	entry.Fields["thread_id"] = m[3]
+	entry.Fields["source"] = m[4] + ":" + m[5]
+
+	entry.Message = m[6]
+	if msg, rest, ok := splitQuotedMessage(m[6]); ok {
+		entry.Message = msg
+		extractPairs(rest, entry.Fields)
+	}
+	return entry
+}
+
+// splitQuotedMessage splits a structured klog message into its unquoted
+// leading message and the key/value pairs that follow it.
+func splitQuotedMessage(s string) (msg, rest string, ok bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+	msg, err = strconv.Unquote(quoted)
+	if err != nil {
+		return "", "", false
+	}
+	return msg, s[len(quoted):], true
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKlogParser_Severities(t *testing.T) {
+	tests := map[string]string{"I": "INFO", "W": "WARN", "E": "ERROR", "F": "FATAL"}
+	p := &KlogParser{}
+	for letter, want := range tests {
+		e := p.Parse(letter + "0115 10:30:17.000000   12345 server.go:123] something happened")
+		if e.Level != want {
+			t.Errorf("%s: Level = %q, want %q", letter, e.Level, want)
+		}
+		if e.Format != FormatKlog {
+			t.Errorf("%s: Format = %v, want klog", letter, e.Format)
+		}
+	}
+}
+
+func TestKlogParser_Fields(t *testing.T) {
+	p := &KlogParser{}
+	p.SetTimeConfig(TimeConfig{DefaultYear: 2026})
+	e := p.Parse("E0115 10:30:17.123456   12345 server.go:123] failed to sync pod: timeout")
+
+	want := time.Date(2026, 1, 15, 10, 30, 17, 123456000, time.UTC)
+	if !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+	if e.Message != "failed to sync pod: timeout" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Fields["thread_id"] != "12345" || e.Fields["source"] != "server.go:123" {
+		t.Errorf("Fields = %v", e.Fields)
+	}
+}
+
+func TestKlogParser_CurrentYear(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	p := &KlogParser{}
+	p.SetTimeConfig(TimeConfig{Now: func() time.Time { return now }})
+	e := p.Parse("I0115 10:30:17.000000 1 main.go:1] started")
+	if e.Timestamp.Year() != 2026 {
+		t.Errorf("Timestamp = %v, want the current year", e.Timestamp)
+	}
+}
+
+func TestKlogParser_Structured(t *testing.T) {
+	p := &KlogParser{}
+	e := p.Parse(`I0115 10:30:17.000000    7 status_manager.go:667] "Pod status updated" pod="kube-system/coredns" status="Running"`)
+	if e.Message != "Pod status updated" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Fields["pod"] != "kube-system/coredns" || e.Fields["status"] != "Running" {
+		t.Errorf("Fields = %v", e.Fields)
+	}
+}
+
+func TestDetectFormat_Klog(t *testing.T) {
+	lines := []string{
+		"I0115 10:30:17.000000   12345 server.go:123] starting",
+		"W0115 10:30:18.000000   12345 server.go:130] slow",
+	}
+	if got := DetectFormat(lines); got != FormatKlog {
+		t.Errorf("DetectFormat = %v, want klog", got)
+	}
+	if got := detectLine("Info 0115 is not klog"); got != FormatPlain {
+		t.Errorf("detectLine = %v, want plain", got)
+	}
+	e := NewAutoParser().Parse(lines[1])
+	if e.Format != FormatKlog || e.Level != "WARN" {
+		t.Errorf("AutoParser: Format = %v, Level = %q", e.Format, e.Level)
+	}
+}
@@ -5,28 +5,50 @@ import (
 )
 
 // LogfmtParser parses logfmt (key=value) log lines.
-type LogfmtParser struct{}
+type LogfmtParser struct {
+	timeCfg TimeConfig
+	keys    KeySet
+}
+
+// NewLogfmtParser returns a logfmt parser that reads the timestamp, level
+// and message from the keys named in keys. The zero LogfmtParser uses
+// DefaultKeySet.
+func NewLogfmtParser(keys KeySet) *LogfmtParser {
+	return &LogfmtParser{keys: keys.resolve()}
+}
+
+// SetTimeConfig sets how zoneless and yearless timestamps are interpreted.
+func (p *LogfmtParser) SetTimeConfig(cfg TimeConfig) { p.timeCfg = cfg }
 
 // Parse parses a logfmt line.
 func (p *LogfmtParser) Parse(line string) LogEntry {
 	entry := LogEntry{
-		Raw:    line,
-		Format: FormatLogfmt,
-		Fields: make(map[string]string),
+		Raw:       line,
+		Format:    FormatLogfmt,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
 	}
 
 	pairs := parseLogfmtPairs(strings.TrimSpace(line))
 
+	// When several keys of a role are present, the one listed first wins.
+	keys := p.keys.orDefault()
+	tsRank, levelRank, msgRank := len(keys.Timestamp), len(keys.Level), len(keys.Message)
 	for k, v := range pairs {
 		kl := strings.ToLower(k)
-		switch {
-		case isKnownKey(kl, timestampKeys):
-			entry.Timestamp = parseTimestamp(v)
-		case isKnownKey(kl, levelKeys):
-			entry.Level = strings.ToUpper(v)
-		case isKnownKey(kl, messageKeys):
-			entry.Message = v
-		default:
+		if r := keyRank(kl, keys.Timestamp); r >= 0 {
+			if r < tsRank {
+				entry.Timestamp, tsRank = p.timeCfg.parse(v), r
+			}
+		} else if r := keyRank(kl, keys.Level); r >= 0 {
+			if r < levelRank {
+				entry.Level, levelRank = strings.ToUpper(v), r
+			}
+		} else if r := keyRank(kl, keys.Message); r >= 0 {
+			if r < msgRank {
+				entry.Message, msgRank = v, r
+			}
+		} else {
 			entry.Fields[k] = v
 		}
 	}
@@ -34,48 +56,119 @@ func (p *LogfmtParser) Parse(line string) LogEntry {
 	return entry
 }
 
+// keyRank returns the position of k in keys, or -1.
+func keyRank(k string, keys []string) int {
+	for i, key := range keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseLogfmtPairs returns the key/value pairs of a logfmt line. Bare keys
+// map to an empty value.
 func parseLogfmtPairs(line string) map[string]string {
 	pairs := make(map[string]string)
+	scanLogfmt(line, func(key, value string, bare bool) bool {
+		pairs[key] = value
+		return true
+	})
+	return pairs
+}
+
+// scanLogfmt calls fn for each pair of a logfmt line in order, until fn
+// returns false. A quoted value runs to the matching unescaped quote and may
+// hold spaces and '='; its backslash escapes are resolved. An unquoted value
+// runs to the next space and may hold '=' too. A key without '=' is reported
+// as bare, with an empty value. Scanning stops at a token that cannot be a
+// key, such as one starting with '=' or '"'.
+func scanLogfmt(line string, fn func(key, value string, bare bool) bool) {
 	i := 0
 	for i < len(line) {
-		// skip whitespace
-		for i < len(line) && line[i] == ' ' {
+		for i < len(line) && isLogfmtSpace(line[i]) {
 			i++
 		}
-		// read key
+		if i == len(line) {
+			return
+		}
 		start := i
-		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+		for i < len(line) && line[i] != '=' && line[i] != '"' && !isLogfmtSpace(line[i]) {
 			i++
 		}
-		if i >= len(line) || line[i] != '=' {
-			break
-		}
 		key := line[start:i]
+		if key == "" || (i < len(line) && line[i] == '"') {
+			return
+		}
+		if i == len(line) || line[i] != '=' {
+			if !fn(key, "", true) {
+				return
+			}
+			continue
+		}
 		i++ // skip '='
 
 		var value string
 		if i < len(line) && line[i] == '"' {
-			// quoted value
-			i++
-			vstart := i
-			for i < len(line) && line[i] != '"' {
-				if line[i] == '\\' {
-					i++
-				}
-				i++
-			}
-			value = line[vstart:i]
-			if i < len(line) {
-				i++ // skip closing quote
-			}
+			value, i = scanQuoted(line, i+1)
 		} else {
 			vstart := i
-			for i < len(line) && line[i] != ' ' {
+			for i < len(line) && !isLogfmtSpace(line[i]) {
 				i++
 			}
 			value = line[vstart:i]
 		}
-		pairs[key] = value
+		if !fn(key, value, false) {
+			return
+		}
 	}
-	return pairs
 }
+
+// scanQuoted reads a quoted value starting after its opening quote at i. It
+// returns the unescaped value and the index after the closing quote. An
+// unterminated value runs to the end of the line.
+func scanQuoted(line string, i int) (string, int) {
+	start := i
+	for i < len(line) && line[i] != '"' && line[i] != '\\' {
+		i++
+	}
+	if i == len(line) || line[i] == '"' {
+		// No escapes: the common case needs no copy.
+		value := line[start:i]
+		if i < len(line) {
+			i++
+		}
+		return value, i
+	}
+
+	var b strings.Builder
+	b.WriteString(line[start:i])
+	for i < len(line) && line[i] != '"' {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) {
+			i++
+			switch line[i] {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			case '"', '\\':
+				c = line[i]
+			default:
+				// Unknown escapes are kept as written.
+				b.WriteByte('\\')
+				c = line[i]
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	if i < len(line) {
+		i++ // skip closing quote
+	}
+	return b.String(), i
+}
+
+func isLogfmtSpace(c byte) bool { return c == ' ' || c == '\t' }
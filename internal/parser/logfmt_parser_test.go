@@ -0,0 +1,64 @@
+package parser
+
+import "testing"
+
+func TestParseLogfmtPairs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want map[string]string
+	}{
+		{"escaped quotes", `msg="say \"hi\"" n=1`, map[string]string{"msg": `say "hi"`, "n": "1"}},
+		{"escaped backslash", `path="C:\\logs\\" next=x`, map[string]string{"path": `C:\logs\`, "next": "x"}},
+		{"escape sequences", `msg="a\tb\nc" odd="\q"`, map[string]string{"msg": "a\tb\nc", "odd": `\q`}},
+		{"equals in quoted value", `query="a=1&b=2" ok=true`, map[string]string{"query": "a=1&b=2", "ok": "true"}},
+		{"equals in bare value", `expr=x=y+1 ok=true`, map[string]string{"expr": "x=y+1", "ok": "true"}},
+		{"spaces in value", `msg="connection reset by peer" code=104`, map[string]string{"msg": "connection reset by peer", "code": "104"}},
+		{"empty values", `flag= other="" last=`, map[string]string{"flag": "", "other": "", "last": ""}},
+		{"bare key", `level=info cached user=bob`, map[string]string{"level": "info", "cached": "", "user": "bob"}},
+		{"unterminated quote", `a=1 msg="never closed`, map[string]string{"a": "1", "msg": "never closed"}},
+		{"tabs", "a=1\tb=2", map[string]string{"a": "1", "b": "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLogfmtPairs(tt.line)
+			if len(got) != len(tt.want) {
+				t.Fatalf("pairs = %q, want %q", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if g, ok := got[k]; !ok || g != v {
+					t.Errorf("pairs[%q] = %q, want %q", k, g, v)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLogfmt_Quoting(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`msg="say \"hi\" a=b" level=info`, true},
+		{`flag= level=info`, true},
+		{`level=info cached user=bob`, true},
+		{`msg="a \" b=c"`, false}, // one pair: the b=c is inside the quotes
+		{`starting server port=8080 host=x`, false},
+		{`="x" a=b`, false},
+	}
+	for _, tt := range tests {
+		if got := isLogfmt(tt.line); got != tt.want {
+			t.Errorf("isLogfmt(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLogfmtParser_EscapedMessage(t *testing.T) {
+	e := (&LogfmtParser{}).Parse(`level=warn msg="user said \"stop\" (x=1)" retries=3`)
+	if e.Message != `user said "stop" (x=1)` {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Fields["retries"] != "3" || len(e.Fields) != 1 {
+		t.Errorf("Fields = %v, want only retries=3", e.Fields)
+	}
+}
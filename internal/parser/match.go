@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FieldMatcher matches a regular expression against chosen parts of an
+// entry, like grep on structured logs.
+type FieldMatcher struct {
+	// Fields names what the pattern is applied to: "message" (the default
+	// when empty), "level", "raw", or any key in LogEntry.Fields. The
+	// matcher matches if any of them does.
+	Fields []string
+	// Pattern is the regular expression to look for.
+	Pattern *regexp.Regexp
+	// Negate inverts the result, like grep -v.
+	Negate bool
+}
+
+// NewFieldMatcher compiles pattern into a FieldMatcher for fields.
+func NewFieldMatcher(pattern string, fields []string, negate bool) (FieldMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return FieldMatcher{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return FieldMatcher{Fields: fields, Pattern: re, Negate: negate}, nil
+}
+
+// Match reports whether e matches. An entry lacking a targeted field does
+// not match that field.
+func (fm FieldMatcher) Match(e LogEntry) bool {
+	return fm.matchAny(e) != fm.Negate
+}
+
+func (fm FieldMatcher) matchAny(e LogEntry) bool {
+	if len(fm.Fields) == 0 {
+		return fm.Pattern.MatchString(e.Message)
+	}
+	for _, f := range fm.Fields {
+		if v, ok := fieldValue(e, f); ok && fm.Pattern.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue returns the value of the named part of e.
+func fieldValue(e LogEntry, name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "", "message", "msg":
+		return e.Message, true
+	case "level":
+		return e.Level, e.Level != ""
+	case "raw":
+		return e.Raw, true
+	}
+	v, ok := e.Fields[name]
+	return v, ok
+}
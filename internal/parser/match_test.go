@@ -0,0 +1,45 @@
+package parser
+
+import "testing"
+
+func TestFieldMatcher(t *testing.T) {
+	entry := LogEntry{
+		Message: "GET /api/users",
+		Level:   "INFO",
+		Raw:     `level=info msg="GET /api/users" status=503`,
+		Fields:  map[string]string{"status": "503", "path": "/api/users"},
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		fields  []string
+		negate  bool
+		want    bool
+	}{
+		{"message by default", "users$", nil, false, true},
+		{"message no match", "orders", nil, false, false},
+		{"negated", "orders", nil, true, true},
+		{"field", "^5..$", []string{"status"}, false, true},
+		{"field no match", "^2..$", []string{"status"}, false, false},
+		{"missing field", ".", []string{"user"}, false, false},
+		{"missing field negated", ".", []string{"user"}, true, true},
+		{"any of several fields", "^5", []string{"path", "status"}, false, true},
+		{"level", "(?i)^info$", []string{"level"}, false, true},
+		{"raw", `status=503`, []string{"raw"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, err := NewFieldMatcher(tt.pattern, tt.fields, tt.negate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fm.Match(entry); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFieldMatcher("(", nil, false); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otlpKeyPattern recognizes keys only OpenTelemetry log records carry.
+var otlpKeyPattern = regexp.MustCompile(`"(timeUnixNano|severityNumber)"\s*:`)
+
+// isOTLP reports whether a JSON object line looks like an OTLP log record.
+func isOTLP(line string) bool {
+	return otlpKeyPattern.MatchString(line)
+}
+
+// otlpValue is an OTLP AnyValue in its JSON encoding. Exactly one member is
+// set. 64-bit integers are encoded as strings, but numbers are accepted too.
+type otlpValue struct {
+	StringValue *string          `json:"stringValue"`
+	BoolValue   *bool            `json:"boolValue"`
+	IntValue    json.RawMessage  `json:"intValue"`
+	DoubleValue *float64         `json:"doubleValue"`
+	BytesValue  *string          `json:"bytesValue"`
+	ArrayValue  *otlpArrayValue  `json:"arrayValue"`
+	KvlistValue *otlpKvlistValue `json:"kvlistValue"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+type otlpKvlistValue struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpLogRecord is an OTLP LogRecord in its JSON encoding.
+type otlpLogRecord struct {
+	TimeUnixNano         json.RawMessage `json:"timeUnixNano"`
+	ObservedTimeUnixNano json.RawMessage `json:"observedTimeUnixNano"`
+	SeverityNumber       json.RawMessage `json:"severityNumber"`
+	SeverityText         string          `json:"severityText"`
+	Body                 otlpValue       `json:"body"`
+	Attributes           []otlpKeyValue  `json:"attributes"`
+	TraceID              string          `json:"traceId"`
+	SpanID               string          `json:"spanId"`
+	EventName            string          `json:"eventName"`
+}
+
+// OTLPParser parses OpenTelemetry log records in the OTLP/JSON encoding, one
+// record per line.
+type OTLPParser struct{}
+
+// Parse parses an OTLP/JSON log record. The body becomes the message,
+// severityText (or, without it, severityNumber) the level, and
+// timeUnixNano (or observedTimeUnixNano) the timestamp. Attributes are
+// flattened into fields, nested key/value lists with dotted keys; the trace
+// and span IDs and the event name are kept as fields as well.
+func (p *OTLPParser) Parse(line string) LogEntry {
+	entry := LogEntry{
+		Raw:       line,
+		Format:    FormatOTLP,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+	}
+
+	var rec otlpLogRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &rec); err != nil {
+		entry.Message = line
+		return entry
+	}
+
+	entry.Timestamp = otlpTime(rec.TimeUnixNano)
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = otlpTime(rec.ObservedTimeUnixNano)
+	}
+	if rec.SeverityText != "" {
+		entry.Level = strings.ToUpper(rec.SeverityText)
+	} else if n, ok := otlpInt(rec.SeverityNumber); ok {
+		entry.Level = otlpSeverityLevel(int(n))
+	}
+	if s := rec.Body.StringValue; s != nil {
+		entry.Message = *s
+	} else if v := rec.Body.plain(); v != nil {
+		entry.Message = jsonValueString(v)
+	}
+
+	for _, kv := range rec.Attributes {
+		flattenOTLP(kv.Key, kv.Value, entry.Fields)
+	}
+	for k, v := range map[string]string{"trace_id": rec.TraceID, "span_id": rec.SpanID, "event_name": rec.EventName} {
+		if v != "" {
+			entry.Fields[k] = v
+		}
+	}
+	return entry
+}
+
+// flattenOTLP stores v under key in fields, descending into key/value
+// lists with dotted keys.
+func flattenOTLP(key string, v otlpValue, fields map[string]string) {
+	if v.KvlistValue != nil {
+		for _, kv := range v.KvlistValue.Values {
+			flattenOTLP(key+"."+kv.Key, kv.Value, fields)
+		}
+		return
+	}
+	if p := v.plain(); p != nil {
+		fields[key] = jsonValueString(p)
+	} else {
+		fields[key] = ""
+	}
+}
+
+// plain converts v to the value encoding/json would decode it to, or nil
+// for an empty value.
+func (v otlpValue) plain() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.IntValue != nil:
+		if n, ok := otlpInt(v.IntValue); ok {
+			return n
+		}
+	case v.DoubleValue != nil:
+		return *v.DoubleValue
+	case v.BytesValue != nil:
+		return *v.BytesValue
+	case v.ArrayValue != nil:
+		values := make([]interface{}, len(v.ArrayValue.Values))
+		for i, e := range v.ArrayValue.Values {
+			values[i] = e.plain()
+		}
+		return values
+	case v.KvlistValue != nil:
+		values := make(map[string]interface{}, len(v.KvlistValue.Values))
+		for _, kv := range v.KvlistValue.Values {
+			values[kv.Key] = kv.Value.plain()
+		}
+		return values
+	}
+	return nil
+}
+
+// otlpInt decodes a 64-bit integer encoded as a JSON string or number.
+func otlpInt(raw json.RawMessage) (int64, bool) {
+	s := strings.Trim(string(raw), `"`)
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// otlpTime decodes a nanosecond Unix timestamp; zero or absent is the zero
+// time.
+func otlpTime(raw json.RawMessage) time.Time {
+	n, ok := otlpInt(raw)
+	if !ok || n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// otlpSeverityLevel maps an OTLP severity number (1–24, in ranges of four
+// per level) to a canonical level name.
+func otlpSeverityLevel(n int) string {
+	switch {
+	case n <= 0:
+		return ""
+	case n <= 4:
+		return "TRACE"
+	case n <= 8:
+		return "DEBUG"
+	case n <= 12:
+		return "INFO"
+	case n <= 16:
+		return "WARN"
+	case n <= 20:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+const otlpSample = `{"timeUnixNano":"1739822400123456789","observedTimeUnixNano":"1739822400200000000","severityNumber":17,"severityText":"Error","body":{"stringValue":"payment declined"},"attributes":[{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"retry","value":{"intValue":"3"}},{"key":"cached","value":{"boolValue":false}},{"key":"latency","value":{"doubleValue":0.25}},{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"card"},{"intValue":"7"}]}}},{"key":"http","value":{"kvlistValue":{"values":[{"key":"method","value":{"stringValue":"POST"}},{"key":"request","value":{"kvlistValue":{"values":[{"key":"size","value":{"intValue":512}}]}}}]}}}],"traceId":"5b8efff798038103d269b633813fc60c","spanId":"eee19b7ec3c1b174"}`
+
+func TestOTLPParser_Record(t *testing.T) {
+	p := &OTLPParser{}
+	e := p.Parse(otlpSample)
+
+	if e.Format != FormatOTLP {
+		t.Errorf("Format = %v, want otlp", e.Format)
+	}
+	if e.Message != "payment declined" {
+		t.Errorf("Message = %q", e.Message)
+	}
+	if e.Level != "ERROR" {
+		t.Errorf("Level = %q, want ERROR", e.Level)
+	}
+	want := time.Unix(1739822400, 123456789)
+	if !e.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", e.Timestamp, want)
+	}
+
+	fields := map[string]string{
+		"service.name":      "checkout",
+		"retry":             "3",
+		"cached":            "false",
+		"latency":           "0.25",
+		"tags":              `["card",7]`,
+		"http.method":       "POST",
+		"http.request.size": "512",
+		"trace_id":          "5b8efff798038103d269b633813fc60c",
+		"span_id":           "eee19b7ec3c1b174",
+	}
+	for k, v := range fields {
+		if e.Fields[k] != v {
+			t.Errorf("Fields[%s] = %q, want %q", k, e.Fields[k], v)
+		}
+	}
+	if len(e.Fields) != len(fields) {
+		t.Errorf("Fields = %v, want exactly %v", e.Fields, fields)
+	}
+}
+
+func TestOTLPParser_Fallbacks(t *testing.T) {
+	p := &OTLPParser{}
+	e := p.Parse(`{"observedTimeUnixNano":1739822400000000000,"severityNumber":10,"body":{"kvlistValue":{"values":[{"key":"op","value":{"stringValue":"sync"}}]}}}`)
+	if e.Level != "INFO" {
+		t.Errorf("Level = %q, want INFO from severityNumber", e.Level)
+	}
+	if !e.Timestamp.Equal(time.Unix(1739822400, 0)) {
+		t.Errorf("Timestamp = %v, want the observed time", e.Timestamp)
+	}
+	if e.Message != `{"op":"sync"}` {
+		t.Errorf("Message = %q, want the structured body as JSON", e.Message)
+	}
+
+	if e := p.Parse(`{"timeUnixNano":`); e.Message != `{"timeUnixNano":` {
+		t.Errorf("invalid payload should fall back to raw message, got %q", e.Message)
+	}
+}
+
+func TestOTLPParser_SeverityNumbers(t *testing.T) {
+	tests := map[int]string{0: "", 1: "TRACE", 5: "DEBUG", 9: "INFO", 12: "INFO", 13: "WARN", 17: "ERROR", 21: "FATAL", 24: "FATAL"}
+	for n, want := range tests {
+		if got := otlpSeverityLevel(n); got != want {
+			t.Errorf("otlpSeverityLevel(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestDetectOTLP(t *testing.T) {
+	lines := []string{
+		otlpSample,
+		`{"severityNumber":9,"body":{"stringValue":"ok"}}`,
+	}
+	if got := DetectFormat(lines); got != FormatOTLP {
+		t.Errorf("DetectFormat = %v, want otlp", got)
+	}
+	if got := detectLine(`{"level":"info","time":"2025-02-17T20:00:00Z"}`); got != FormatJSON {
+		t.Errorf("non-OTLP JSON detected as %v", got)
+	}
+	if f, err := ParseFormat("otlp"); err != nil || f != FormatOTLP {
+		t.Errorf("ParseFormat(otlp) = %v, %v", f, err)
+	}
+}
+
+func TestAutoParser_RoutesOTLP(t *testing.T) {
+	a := &AutoParser{}
+	e := a.Parse(otlpSample)
+	if e.Format != FormatOTLP || e.Fields["http.method"] != "POST" {
+		t.Errorf("AutoParser did not route to OTLP: %+v", e)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ParsePanicError describes a panic recovered while parsing a line.
+type ParsePanicError struct {
+	Line  string      // the line being parsed
+	Value interface{} // the value passed to panic
+}
+
+func (e *ParsePanicError) Error() string {
+	return fmt.Sprintf("parser panic on line %q: %v", truncateLine(e.Line, 80), e.Value)
+}
+
+// truncateLine shortens s to at most n bytes for error messages.
+func truncateLine(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// panicStats counts recovered parser panics. Copies of an AutoParser share
+// one.
+type panicStats struct {
+	mu    sync.Mutex
+	count int
+	last  error
+}
+
+// record counts err as the latest panic.
+func (s *panicStats) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.last = err
+}
+
+// get returns the count and the latest panic.
+func (s *panicStats) get() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count, s.last
+}
+
+// unparsed is the entry a line gets when parsing it panicked: the line as
+// the message and nothing else.
+func unparsed(line string) LogEntry {
+	return LogEntry{
+		Message:   line,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
+		Raw:       line,
+		Format:    FormatPlain,
+	}
+}
+
+// safely runs fn, returning the panic it raised, if any, as an error.
+func safely(line string, fn func()) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &ParsePanicError{Line: line, Value: v}
+		}
+	}()
+	fn()
+	return nil
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// panicParser panics on lines containing "boom" and declines the others.
+type panicParser struct{}
+
+func (panicParser) parse(line string) (LogEntry, bool) {
+	if strings.Contains(line, "boom") {
+		var fields map[string]string
+		fields["crash"] = line // assignment to a nil map
+	}
+	return LogEntry{}, false
+}
+
+func (p panicParser) Parse(line string) LogEntry {
+	entry, _ := p.parse(line)
+	return entry
+}
+
+// panicEnricher panics on entries at level error.
+type panicEnricher struct{}
+
+func (panicEnricher) Enrich(e *LogEntry) {
+	if e.Level == "ERROR" {
+		panic("enricher failed")
+	}
+	e.Fields["enriched"] = "yes"
+}
+
+func TestAutoParser_RecoversFromParserPanic(t *testing.T) {
+	a := NewAutoParser()
+	a.first = panicParser{}
+
+	lines := []string{
+		`{"level":"info","msg":"before"}`,
+		`{"level":"error","msg":"boom"}`,
+		`level=warn msg=after`,
+	}
+	var entries []LogEntry
+	for _, line := range lines {
+		entries = append(entries, a.Parse(line))
+	}
+
+	if entries[0].Message != "before" || entries[2].Message != "after" {
+		t.Errorf("lines around the panic: %q, %q", entries[0].Message, entries[2].Message)
+	}
+	bad := entries[1]
+	if bad.Raw != lines[1] || bad.Message != lines[1] || bad.Level != "" || bad.Format != FormatPlain {
+		t.Errorf("panicking line: %+v, want the raw line as a plain message", bad)
+	}
+
+	n, err := a.Panics()
+	if n != 1 {
+		t.Errorf("Panics = %d, want 1", n)
+	}
+	var pe *ParsePanicError
+	if !errors.As(err, &pe) || pe.Line != lines[1] {
+		t.Errorf("last panic = %v, want a ParsePanicError for the line", err)
+	}
+	if pinned, _ := a.Pin(FormatJSON).Panics(); pinned != 1 {
+		t.Errorf("pinned copy sees %d panics, want the shared count", pinned)
+	}
+}
+
+func TestAutoParser_RecoversFromEnricherPanic(t *testing.T) {
+	a := NewAutoParser()
+	a.AddEnricher(panicEnricher{})
+
+	if e := a.Parse(`level=error msg=oops`); e.Message != "oops" || e.Fields["enriched"] != "" {
+		t.Errorf("entry after a panicking enricher: %+v", e)
+	}
+	if e := a.Parse(`level=info msg=fine`); e.Fields["enriched"] != "yes" {
+		t.Errorf("enricher skipped after a panic: %+v", e)
+	}
+	if n, _ := a.Panics(); n != 1 {
+		t.Errorf("Panics = %d, want 1", n)
+	}
+}
+
+func TestAutoParser_PanicsZeroValue(t *testing.T) {
+	a := &AutoParser{first: panicParser{}}
+	if e := a.Parse("boom"); e.Message != "boom" {
+		t.Errorf("Message = %q, want the raw line", e.Message)
+	}
+	if n, err := a.Panics(); n != 0 || err != nil {
+		t.Errorf("Panics = %d, %v; a zero AutoParser records nothing", n, err)
+	}
+}
+
+func TestExecParser_RecoversFromFallbackPanic(t *testing.T) {
+	p := NewExecParser(ExecConfig{
+		Command:  []string{filepath.Join(t.TempDir(), "missing")},
+		Fallback: panicParser{},
+	})
+	if e := p.Parse("boom"); e.Raw != "boom" || e.Message != "boom" {
+		t.Errorf("got %+v, want the raw line", e)
+	}
+	var pe *ParsePanicError
+	if !errors.As(p.Err(), &pe) {
+		t.Errorf("Err = %v, want a ParsePanicError", p.Err())
+	}
+	if e := p.Parse("calm"); e.Raw != "" {
+		t.Errorf("fallback should still be used after a panic, got %+v", e)
+	}
+}
@@ -2,31 +2,75 @@ package parser
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // PlainParser parses plain text log lines with regex-based timestamp extraction.
-type PlainParser struct{}
+type PlainParser struct {
+	timeCfg      TimeConfig
+	skipPairs    bool
+	strictLevels bool
+}
+
+// SetTimeConfig sets how zoneless and yearless timestamps are interpreted.
+func (p *PlainParser) SetTimeConfig(cfg TimeConfig) { p.timeCfg = cfg }
+
+// SetExtractPairs controls whether key=value fragments embedded in the
+// message are copied into Fields. It is on by default.
+func (p *PlainParser) SetExtractPairs(on bool) { p.skipPairs = !on }
+
+// SetStrictLevels controls how sure the parser must be that a level word
+// is the line's level. When on, only a delimited level among the first few
+// words counts, such as "[ERROR]", "<warn>" or "INFO:". It is off by
+// default; see detectLevel.
+func (p *PlainParser) SetStrictLevels(on bool) { p.strictLevels = on }
 
 var plainTimestampPatterns = []*regexp.Regexp{
 	// ISO 8601 variants
 	regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+`),
-	// Syslog: Jan  2 15:04:05
-	regexp.MustCompile(`^([A-Z][a-z]{2}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+`),
+	// Syslog: Jan  2 15:04:05 (month names may be localized, see TimeConfig.Locale)
+	regexp.MustCompile(`^(\p{L}{3,5}\.?\s+\d{1,2}\s+\d{2}:\d{2}:\d{2})\s+`),
 	// Apache/Nginx: 02/Jan/2006:15:04:05 -0700
 	regexp.MustCompile(`\[(\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}\s+[+-]\d{4})\]`),
 	// Slash date: 2006/01/02 15:04:05
 	regexp.MustCompile(`^(\d{4}/\d{2}/\d{2}\s+\d{2}:\d{2}:\d{2})\s+`),
+	// Bracketed ISO, as written by PowerShell and .NET services:
+	// [2006-01-02 15:04:05.000]
+	regexp.MustCompile(`^\[(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\]\s*`),
+	// Bracketed US date from Windows event log exports: [1/2/2006 3:04:05 PM]
+	regexp.MustCompile(`^\[(\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}:\d{2} [AP]M)\]\s*`),
+}
+
+// bracketedLevelPattern matches a leading bracketed level such as the
+// "[Information]" or "[Warning]" of Windows event logs and ETW text output.
+var bracketedLevelPattern = regexp.MustCompile(`(?i)^\[(information|informational|info|warning|warn|error|critical|fatal|verbose|debug|trace)\]\s*`)
+
+// bracketedLevels maps the words bracketedLevelPattern accepts to canonical
+// levels.
+var bracketedLevels = map[string]string{
+	"information": "INFO", "informational": "INFO", "info": "INFO",
+	"warning": "WARN", "warn": "WARN",
+	"error":    "ERROR",
+	"critical": "FATAL", "fatal": "FATAL",
+	"verbose": "DEBUG", "debug": "DEBUG",
+	"trace": "TRACE",
 }
 
 var levelPattern = regexp.MustCompile(`(?i)\b(TRACE|DEBUG|INFO|WARN(?:ING)?|ERROR|FATAL|CRITICAL|PANIC)\b`)
 
+// embeddedPairPattern matches key=value fragments in prose. The key must start
+// a whitespace-separated word and there must be no spaces around '=', so
+// "x = y" or "a/b?c=d" are not taken as pairs. Values may be double-quoted.
+var embeddedPairPattern = regexp.MustCompile(`(?:^|\s)([A-Za-z_][\w.-]*)=("(?:[^"\\]|\\.)*"|[^\s"=]\S*)`)
+
 // Parse parses a plain text log line.
 func (p *PlainParser) Parse(line string) LogEntry {
 	entry := LogEntry{
-		Raw:    line,
-		Format: FormatPlain,
-		Fields: make(map[string]string),
+		Raw:       line,
+		Format:    FormatPlain,
+		Fields:    make(map[string]string),
+		LineCount: CountLines(line),
 	}
 
 	remaining := line
@@ -34,7 +78,7 @@ func (p *PlainParser) Parse(line string) LogEntry {
 	// Try to extract timestamp
 	for _, pat := range plainTimestampPatterns {
 		if m := pat.FindStringSubmatch(line); m != nil {
-			entry.Timestamp = parseTimestamp(m[1])
+			entry.Timestamp = p.timeCfg.parse(m[1])
 			if !entry.Timestamp.IsZero() {
 				// Remove timestamp from remaining
 				remaining = strings.TrimSpace(strings.Replace(line, m[0], "", 1))
@@ -43,14 +87,100 @@ func (p *PlainParser) Parse(line string) LogEntry {
 		}
 	}
 
-	// Try to extract level
-	if m := levelPattern.FindString(remaining); m != "" {
-		entry.Level = strings.ToUpper(m)
-		if entry.Level == "WARNING" {
-			entry.Level = "WARN"
-		}
+	if m := bracketedLevelPattern.FindStringSubmatch(remaining); m != nil {
+		entry.Level = bracketedLevels[strings.ToLower(m[1])]
+		remaining = remaining[len(m[0]):]
+	} else {
+		entry.Level = detectLevel(remaining, p.strictLevels)
 	}
 
 	entry.Message = remaining
+	if !p.skipPairs {
+		extractPairs(remaining, entry.Fields)
+	}
 	return entry
 }
+
+const (
+	// levelWordWindow is how many words from the start of a message a
+	// level word may appear at and still be taken for the line's level.
+	levelWordWindow = 3
+	// minLevelScore is the score a level word needs; see levelScore.
+	minLevelScore = 3
+)
+
+// detectLevel returns the level of free text, in canonical form, or "" if
+// there is none. Of the level words in s, the one with the highest
+// levelScore wins, the earliest on a tie; words scoring below
+// minLevelScore are ignored, so "no error occurred" has no level. In
+// strict mode only delimited words within levelWordWindow count.
+func detectLevel(s string, strict bool) string {
+	best, bestScore := "", minLevelScore-1
+	for _, loc := range levelPattern.FindAllStringIndex(s, -1) {
+		delimited, pos := levelContext(s, loc[0], loc[1])
+		if strict && (!delimited || pos >= levelWordWindow) {
+			continue
+		}
+		if score := levelScore(s[loc[0]:loc[1]], delimited, pos); score > bestScore {
+			best, bestScore = s[loc[0]:loc[1]], score
+		}
+	}
+	best = strings.ToUpper(best)
+	if best == "WARNING" {
+		return "WARN"
+	}
+	return best
+}
+
+// levelContext describes the level word s[start:end]: whether it is set
+// apart from prose, in brackets or quotes, followed by a colon or preceded
+// by "=", and how many words come before it.
+func levelContext(s string, start, end int) (delimited bool, pos int) {
+	var before, after byte
+	if start > 0 {
+		before = s[start-1]
+	}
+	if end < len(s) {
+		after = s[end]
+	}
+	delimited = after == ':' || before == '=' ||
+		strings.IndexByte(`[(<"'`, before) >= 0 && strings.IndexByte(`])>"'`, after) >= 0
+	return delimited, len(strings.Fields(s[:start]))
+}
+
+// levelScore rates how likely a level word is the line's level: delimited
+// words score highest, words in capitals next, and words near the start
+// beat words deep into the message, which are most likely prose.
+func levelScore(word string, delimited bool, pos int) int {
+	score := 0
+	if delimited {
+		score += 4
+	}
+	if word == strings.ToUpper(word) {
+		score += 2
+	}
+	if pos < levelWordWindow {
+		score++
+	} else {
+		score -= 2
+	}
+	return score
+}
+
+// extractPairs adds the key=value fragments found in msg to fields. The
+// message itself is left as is, so the prose stays readable.
+func extractPairs(msg string, fields map[string]string) {
+	for _, m := range embeddedPairPattern.FindAllStringSubmatch(msg, -1) {
+		key, value := m[1], m[2]
+		if strings.HasPrefix(value, `"`) {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else {
+				value = strings.Trim(value, `"`)
+			}
+		} else {
+			value = strings.TrimRight(value, ",;")
+		}
+		fields[key] = value
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import "strings"
+
+// DefaultStableVotes is how many consecutive lines must agree with the
+// leading format before a StreamingDetector locks it in.
+const DefaultStableVotes = 20
+
+// StreamingDetector detects the format of an unbounded stream as its lines
+// arrive, where DetectFormat needs them all up front. Every non-blank line
+// is a vote; the format with the most votes leads, ties going to the more
+// structured format as in DetectFormat. Once stableVotes consecutive lines
+// agree with the leader, the detector is stable: the format is locked in and
+// later lines no longer change it, so a caller can switch to a fixed parser.
+// A noisy first line is outvoted before the format locks.
+//
+// A StreamingDetector is not safe for concurrent use.
+type StreamingDetector struct {
+	stableVotes int
+	counts      map[Format]int
+	total       int
+	current     Format
+	streak      int // consecutive votes for current
+	stable      bool
+}
+
+// NewStreamingDetector returns a detector that locks in a format after
+// stableVotes consistent votes (DefaultStableVotes if stableVotes is zero
+// or negative).
+func NewStreamingDetector(stableVotes int) *StreamingDetector {
+	if stableVotes <= 0 {
+		stableVotes = DefaultStableVotes
+	}
+	return &StreamingDetector{stableVotes: stableVotes, counts: make(map[Format]int)}
+}
+
+// Observe counts line's vote. Blank lines and lines observed once the
+// detector is stable are ignored.
+func (d *StreamingDetector) Observe(line string) {
+	line = strings.TrimSpace(line)
+	if d.stable || line == "" {
+		return
+	}
+	f := detectLine(line)
+	d.counts[f]++
+	d.total++
+
+	leader, leaderCount := FormatUnknown, 0
+	for _, c := range detectPriority {
+		if d.counts[c] > leaderCount {
+			leader, leaderCount = c, d.counts[c]
+		}
+	}
+	if leader != d.current {
+		d.current, d.streak = leader, 0
+	}
+	if f == d.current {
+		d.streak++
+	} else {
+		d.streak = 0
+	}
+	d.stable = d.streak >= d.stableVotes
+}
+
+// Current returns the leading format, or FormatUnknown before the first
+// non-blank line.
+func (d *StreamingDetector) Current() Format {
+	return d.current
+}
+
+// Stable reports whether the format is locked in.
+func (d *StreamingDetector) Stable() bool {
+	return d.stable
+}
+
+// Confidence returns the share of votes for the current format, from 0 to 1.
+func (d *StreamingDetector) Confidence() float64 {
+	if d.total == 0 {
+		return 0
+	}
+	return float64(d.counts[d.current]) / float64(d.total)
+}
+
+// Lines returns the number of votes counted.
+func (d *StreamingDetector) Lines() int {
+	return d.total
+}
@@ -0,0 +1,75 @@
+package parser
+
+import "testing"
+
+func TestStreamingDetector_Converges(t *testing.T) {
+	d := NewStreamingDetector(5)
+	if d.Current() != FormatUnknown || d.Stable() {
+		t.Fatal("a new detector should have no format")
+	}
+
+	// A noisy first line leads at first, then is outvoted.
+	d.Observe("starting up...")
+	if d.Current() != FormatPlain {
+		t.Errorf("after one plain line: Current = %v, want plain", d.Current())
+	}
+	d.Observe("")
+	for i, line := range jsonSamples[:4] {
+		d.Observe(line)
+		if d.Current() != FormatJSON {
+			t.Errorf("after %d JSON lines: Current = %v, want json", i+1, d.Current())
+		}
+		if d.Stable() {
+			t.Fatalf("stable after only %d JSON lines", i+1)
+		}
+	}
+	d.Observe(jsonSamples[4])
+	if !d.Stable() || d.Current() != FormatJSON {
+		t.Fatalf("Current = %v, Stable = %v after 5 JSON lines in a row", d.Current(), d.Stable())
+	}
+	if d.Lines() != 6 || d.Confidence() != 5.0/6 {
+		t.Errorf("Lines = %d, Confidence = %v", d.Lines(), d.Confidence())
+	}
+
+	// Once stable, the format no longer changes.
+	for i := 0; i < 20; i++ {
+		d.Observe(`level=info msg="logfmt now"`)
+		d.Observe("plain again")
+	}
+	if d.Current() != FormatJSON || d.Lines() != 6 {
+		t.Errorf("stable detector changed: Current = %v, Lines = %d", d.Current(), d.Lines())
+	}
+}
+
+func TestStreamingDetector_MixedNeverLocks(t *testing.T) {
+	d := NewStreamingDetector(3)
+	var seen []Format
+	for i := 0; i < 30; i++ {
+		d.Observe(jsonSamples[i%len(jsonSamples)])
+		d.Observe(`level=warn msg="disk low"`)
+		seen = append(seen, d.Current())
+	}
+	if d.Stable() {
+		t.Error("alternating formats should not lock in")
+	}
+	// Ties go to the more structured format, so the leader holds steady.
+	for i, f := range seen {
+		if f != FormatJSON {
+			t.Fatalf("after pair %d: Current = %v, want json", i+1, f)
+		}
+	}
+}
+
+func TestStreamingDetector_DefaultVotes(t *testing.T) {
+	d := NewStreamingDetector(0)
+	for i := 0; i < DefaultStableVotes-1; i++ {
+		d.Observe(`level=info msg=ok`)
+	}
+	if d.Stable() {
+		t.Fatal("stable before DefaultStableVotes lines")
+	}
+	d.Observe(`level=info msg=ok`)
+	if !d.Stable() || d.Current() != FormatLogfmt {
+		t.Errorf("Current = %v, Stable = %v, want a stable logfmt", d.Current(), d.Stable())
+	}
+}
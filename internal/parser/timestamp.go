@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TimeConfig controls how timestamps lacking a zone or a year are
+// interpreted. The zero value assumes UTC and the current year.
+type TimeConfig struct {
+	// Location is applied to timestamps that carry no zone offset.
+	// Nil means UTC.
+	Location *time.Location
+	// DefaultYear is used for timestamps without a year, such as syslog's
+	// "Jan  2 15:04:05". Zero means the current year, stepping back a year
+	// when that would put the timestamp in the future (e.g. reading
+	// December logs in January). February 29 falls in the latest leap
+	// year up to the year chosen.
+	DefaultYear int
+	// Locale enables non-English month abbreviations ("de", "es", "fr",
+	// "it", "nl", "pt"). English is always recognized.
+	Locale string
+	// Now returns the current time; defaults to time.Now. For testing.
+	Now func() time.Time
+}
+
+var timeFormats = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02T15:04:05.000Z",
+	"02/Jan/2006:15:04:05 -0700",
+	"Jan  2 15:04:05",
+	"Jan 2 15:04:05",
+	"2006/01/02 15:04:05",
+	"1/2/2006 3:04:05 PM",
+}
+
+// localeMonths maps a locale to its month abbreviations, January first.
+var localeMonths = map[string][12]string{
+	"de": {"jan", "feb", "mär", "apr", "mai", "jun", "jul", "aug", "sep", "okt", "nov", "dez"},
+	"es": {"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+	"fr": {"janv", "févr", "mars", "avr", "mai", "juin", "juil", "août", "sept", "oct", "nov", "déc"},
+	"it": {"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+	"nl": {"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+	"pt": {"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+}
+
+// ParseLocation parses a time zone given as "local" for the system's zone,
+// an IANA name such as "UTC" or "Asia/Tokyo", or a fixed offset such as
+// "+09:00" or "-0500".
+func ParseLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	if strings.HasPrefix(name, "+") || strings.HasPrefix(name, "-") {
+		for _, layout := range []string{"-07:00", "-0700", "-07"} {
+			if t, err := time.Parse(layout, name); err == nil {
+				_, offset := t.Zone()
+				return time.FixedZone(name, offset), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid zone offset %q: want +hh:mm", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+func (c TimeConfig) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
+
+func (c TimeConfig) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// parse converts a raw timestamp value (string or numeric epoch) to a time.
+// It returns the zero time when the value isn't recognized.
+func (c TimeConfig) parse(v interface{}) time.Time {
+	switch val := v.(type) {
+	case string:
+		if t, ok := c.parseString(val); ok {
+			return t
+		}
+		if c.Locale != "" {
+			if translated := c.translateMonth(val); translated != val {
+				if t, ok := c.parseString(translated); ok {
+					return t
+				}
+			}
+		}
+	case float64:
+		// Unix timestamp (seconds or milliseconds)
+		if val > 1e12 {
+			return time.UnixMilli(int64(val))
+		}
+		return time.Unix(int64(val), 0)
+	}
+	return time.Time{}
+}
+
+func (c TimeConfig) parseString(val string) (time.Time, bool) {
+	loc := c.location()
+	for _, layout := range timeFormats {
+		t, err := time.ParseInLocation(layout, val, loc)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "2006") {
+			t = c.withYear(t)
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// withYear fills in the year for a timestamp parsed from a yearless layout.
+func (c TimeConfig) withYear(t time.Time) time.Time {
+	if c.DefaultYear != 0 {
+		return inYear(t, c.DefaultYear)
+	}
+	now := c.now().In(t.Location())
+	d := inYear(t, now.Year())
+	// Allow a little clock skew before deciding the log is from last year.
+	if d.After(now.Add(24 * time.Hour)) {
+		d = inYear(t, now.Year()-1)
+	}
+	return d
+}
+
+// inYear returns t's date and time of day in year. February 29 falls in the
+// latest leap year up to year rather than becoming March 1.
+func inYear(t time.Time, year int) time.Time {
+	if t.Month() == time.February && t.Day() == 29 {
+		for !isLeapYear(year) {
+			year--
+		}
+	}
+	return time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// isLeapYear reports whether year has a February 29.
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// translateMonth replaces a leading localized month abbreviation with its
+// English equivalent, so "Okt 5 10:00:00" becomes "Oct 5 10:00:00".
+func (c TimeConfig) translateMonth(val string) string {
+	months, ok := localeMonths[strings.ToLower(c.Locale)]
+	if !ok {
+		return val
+	}
+	end := strings.IndexFunc(val, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end <= 0 {
+		return val
+	}
+	word := strings.ToLower(val[:end])
+	rest := strings.TrimPrefix(val[end:], ".")
+	for i, name := range months {
+		if word == name {
+			return time.Month(i + 1).String()[:3] + rest
+		}
+	}
+	return val
+}
@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeConfig_SyslogCurrentYearAndZone(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	cfg := TimeConfig{Location: loc}
+
+	p := &PlainParser{}
+	p.SetTimeConfig(cfg)
+	// Use yesterday's date so the "not in the future" rule never applies.
+	y := time.Now().In(loc).AddDate(0, 0, -1)
+	line := y.Format("Jan _2") + " 10:30:02 myhost sshd[1234]: Accepted publickey"
+	entry := p.Parse(line)
+
+	if entry.Timestamp.IsZero() {
+		t.Fatalf("timestamp not parsed from %q", line)
+	}
+	if entry.Timestamp.Year() != y.Year() {
+		t.Errorf("year = %d, want %d", entry.Timestamp.Year(), y.Year())
+	}
+	if _, off := entry.Timestamp.Zone(); off != 3600 {
+		t.Errorf("zone offset = %d, want 3600", off)
+	}
+	if entry.Timestamp.Hour() != 10 || entry.Timestamp.Day() != y.Day() {
+		t.Errorf("timestamp = %v, want %s 10:30:02", entry.Timestamp, y.Format("Jan 2"))
+	}
+}
+
+func TestTimeConfig_DefaultYear(t *testing.T) {
+	cfg := TimeConfig{DefaultYear: 2019}
+	ts := cfg.parse("Mar  4 05:06:07")
+	want := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Errorf("parse = %v, want %v", ts, want)
+	}
+}
+
+func TestTimeConfig_YearRollover(t *testing.T) {
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	cfg := TimeConfig{Now: func() time.Time { return now }}
+	ts := cfg.parse("Dec 31 23:59:00")
+	if ts.Year() != 2025 {
+		t.Errorf("year = %d, want 2025 for a December log read in January", ts.Year())
+	}
+}
+
+func TestTimeConfig_LeapDay(t *testing.T) {
+	tests := []struct {
+		cfg  TimeConfig
+		want time.Time
+	}{
+		{TimeConfig{DefaultYear: 2024}, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		// Not a leap year: the latest one before it.
+		{TimeConfig{DefaultYear: 2025}, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		{TimeConfig{Now: func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }}, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		{TimeConfig{Now: func() time.Time { return time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC) }}, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		// Still to come this leap year: the one before.
+		{TimeConfig{Now: func() time.Time { return time.Date(2028, 1, 10, 0, 0, 0, 0, time.UTC) }}, time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		{TimeConfig{Now: func() time.Time { return time.Date(2100, 3, 1, 0, 0, 0, 0, time.UTC) }}, time.Date(2096, 2, 29, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if ts := tt.cfg.parse("Feb 29 08:00:00"); !ts.Equal(tt.want) {
+			t.Errorf("parse with %+v = %v, want %v", tt.cfg, ts, tt.want)
+		}
+	}
+	// Other days keep their date.
+	cfg := TimeConfig{DefaultYear: 2025}
+	if ts := cfg.parse("Feb 28 08:00:00"); !ts.Equal(time.Date(2025, 2, 28, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("parse = %v", ts)
+	}
+}
+
+func TestTimeConfig_ExplicitZoneWins(t *testing.T) {
+	cfg := TimeConfig{Location: time.FixedZone("X", -5*3600)}
+	ts := cfg.parse("2024-01-15T10:30:00Z")
+	if _, off := ts.Zone(); off != 0 {
+		t.Errorf("explicit Z should stay UTC, got offset %d", off)
+	}
+	ts = cfg.parse("2024-01-15 10:30:00")
+	if _, off := ts.Zone(); off != -5*3600 {
+		t.Errorf("zoneless timestamp should use configured location, got offset %d", off)
+	}
+}
+
+func TestTimeConfig_LocaleMonths(t *testing.T) {
+	cfg := TimeConfig{Locale: "de", DefaultYear: 2024}
+	tests := map[string]time.Month{
+		"Okt  5 10:00:00": time.October,
+		"Mär 12 08:15:00": time.March,
+		"Dez 24 18:00:00": time.December,
+		"Jan  1 00:00:00": time.January,
+	}
+	for in, month := range tests {
+		ts := cfg.parse(in)
+		if ts.IsZero() || ts.Month() != month {
+			t.Errorf("parse(%q) = %v, want month %v", in, ts, month)
+		}
+	}
+
+	fr := TimeConfig{Locale: "fr", DefaultYear: 2024}
+	if ts := fr.parse("févr. 3 12:00:00"); ts.Month() != time.February {
+		t.Errorf("french month: got %v", ts)
+	}
+
+	// Without a locale, non-English months aren't recognized.
+	if ts := (TimeConfig{}).parse("Okt  5 10:00:00"); !ts.IsZero() {
+		t.Errorf("expected zero time without locale, got %v", ts)
+	}
+}
+
+func TestAutoParser_SetTimeConfig(t *testing.T) {
+	ap := NewAutoParser()
+	ap.SetTimeConfig(TimeConfig{DefaultYear: 2020, Locale: "es"})
+
+	entry := ap.Parse("ene 15 10:30:02 host app: started")
+	if entry.Timestamp.Year() != 2020 || entry.Timestamp.Month() != time.January {
+		t.Errorf("plain timestamp = %v, want January 2020", entry.Timestamp)
+	}
+	entry = ap.Parse(`ts="Jan 15 10:30:02" level=info msg=hi`)
+	if entry.Timestamp.Year() != 2020 {
+		t.Errorf("logfmt timestamp = %v, want year 2020", entry.Timestamp)
+	}
+	entry = ap.Parse(`{"time":"Jan 15 10:30:02","msg":"hi"}`)
+	if entry.Timestamp.Year() != 2020 {
+		t.Errorf("json timestamp = %v, want year 2020", entry.Timestamp)
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int // seconds east of UTC on 2026-02-17
+	}{
+		{"UTC", 0},
+		{"Asia/Tokyo", 9 * 3600},
+		{"+09:00", 9 * 3600},
+		{"-0500", -5 * 3600},
+		{"+02", 2 * 3600},
+	}
+	for _, tt := range tests {
+		loc, err := ParseLocation(tt.name)
+		if err != nil {
+			t.Errorf("ParseLocation(%q): %v", tt.name, err)
+			continue
+		}
+		if _, off := time.Date(2026, 2, 17, 0, 0, 0, 0, loc).Zone(); off != tt.offset {
+			t.Errorf("ParseLocation(%q) offset = %d, want %d", tt.name, off, tt.offset)
+		}
+	}
+	if loc, err := ParseLocation("Local"); err != nil || loc != time.Local {
+		t.Errorf("ParseLocation(Local) = %v, %v", loc, err)
+	}
+	for _, bad := range []string{"Mars/Olympus", "+9x"} {
+		if _, err := ParseLocation(bad); err == nil {
+			t.Errorf("ParseLocation(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeWindow selects entries whose timestamp falls within [Since, Until].
+// A zero bound is open-ended.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+	// ExcludeUntimed drops entries without a timestamp; by default they are
+	// kept, since continuation lines and banners often carry none.
+	ExcludeUntimed bool
+}
+
+// Active reports whether the window restricts anything.
+func (w TimeWindow) Active() bool {
+	return !w.Since.IsZero() || !w.Until.IsZero()
+}
+
+// Contains reports whether t lies within the window. Both bounds are
+// inclusive. A zero t is contained unless ExcludeUntimed is set.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Active() {
+		return true
+	}
+	if t.IsZero() {
+		return !w.ExcludeUntimed
+	}
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && t.After(w.Until) {
+		return false
+	}
+	return true
+}
+
+// ParseTimeBound parses a window bound given either as an absolute RFC 3339
+// time ("2026-02-17T20:00:00Z") or as a duration before now ("15m", "2h30m",
+// "7d"). An empty string yields the zero time.
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := parseRelative(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: want RFC 3339 or a duration like 15m", s)
+	}
+	return now.Add(-d), nil
+}
+
+// parseRelative parses a Go duration, additionally accepting a whole number
+// of days with a "d" suffix.
+func parseRelative(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid day count %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative duration %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+var windowNow = time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+
+func TestParseTimeBound_Relative(t *testing.T) {
+	tests := map[string]time.Time{
+		"15m":   windowNow.Add(-15 * time.Minute),
+		"2h30m": windowNow.Add(-150 * time.Minute),
+		"90s":   windowNow.Add(-90 * time.Second),
+		"7d":    windowNow.Add(-7 * 24 * time.Hour),
+		"":      {},
+	}
+	for in, want := range tests {
+		got, err := ParseTimeBound(in, windowNow)
+		if err != nil {
+			t.Errorf("ParseTimeBound(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseTimeBound(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestParseTimeBound_Absolute(t *testing.T) {
+	got, err := ParseTimeBound("2026-02-17T19:30:00+01:00", windowNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 2, 17, 18, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, err := ParseTimeBound("2026-02-17T19:30:00.250Z", windowNow); err != nil {
+		t.Errorf("fractional seconds: %v", err)
+	}
+}
+
+func TestParseTimeBound_Invalid(t *testing.T) {
+	for _, in := range []string{"yesterday", "-5m", "xd", "2026-02-17"} {
+		if _, err := ParseTimeBound(in, windowNow); err == nil {
+			t.Errorf("ParseTimeBound(%q) should fail", in)
+		}
+	}
+}
+
+func TestTimeWindow_Contains(t *testing.T) {
+	since := windowNow.Add(-time.Hour)
+	until := windowNow
+	w := TimeWindow{Since: since, Until: until}
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before", since.Add(-time.Nanosecond), false},
+		{"at since", since, true},
+		{"inside", since.Add(30 * time.Minute), true},
+		{"at until", until, true},
+		{"after", until.Add(time.Nanosecond), false},
+		{"untimed", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		if got := w.Contains(tt.t); got != tt.want {
+			t.Errorf("%s: Contains = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	w.ExcludeUntimed = true
+	if w.Contains(time.Time{}) {
+		t.Error("untimed entry should be excluded")
+	}
+}
+
+func TestTimeWindow_OpenEnded(t *testing.T) {
+	w := TimeWindow{Since: windowNow}
+	if !w.Contains(windowNow.Add(1000 * time.Hour)) {
+		t.Error("no until bound should accept any later time")
+	}
+	if (TimeWindow{}).Active() || !(TimeWindow{}).Contains(time.Time{}) {
+		t.Error("zero window should be inactive and accept everything")
+	}
+}
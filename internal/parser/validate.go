@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Schema describes the entries a team's logs are expected to hold.
+type Schema struct {
+	// Required names the fields every entry must have. "timestamp", "level"
+	// and "message" are also satisfied by the entry's parsed timestamp,
+	// level and message.
+	Required []string
+	// Types maps field names to the type their values must have: string,
+	// number, integer, bool, object or array. Fields an entry lacks are not
+	// checked; list them in Required as well to demand them.
+	Types map[string]string
+}
+
+// validTypes are the type names a Schema may use.
+var validTypes = map[string]bool{"string": true, "number": true, "integer": true, "bool": true, "object": true, "array": true}
+
+// Validator checks entries against a Schema. As an Enricher it records what
+// it finds in LogEntry.Issues.
+type Validator struct {
+	schema Schema
+	types  []string // keys of schema.Types, sorted, for a stable issue order
+}
+
+// NewValidator returns a Validator for schema, or an error if it names an
+// unknown type.
+func NewValidator(schema Schema) (*Validator, error) {
+	v := &Validator{schema: schema}
+	for k, t := range schema.Types {
+		if !validTypes[t] {
+			return nil, fmt.Errorf("field %q: unknown type %q (want string, number, integer, bool, object or array)", k, t)
+		}
+		v.types = append(v.types, k)
+	}
+	sortStrings(v.types)
+	return v, nil
+}
+
+// Validate returns entry's violations of the schema: one issue per missing
+// required field, then one per field of the wrong type. It returns nil for
+// a valid entry.
+func (v *Validator) Validate(entry LogEntry) []string {
+	var issues []string
+	for _, k := range v.schema.Required {
+		if !hasField(entry, k) {
+			issues = append(issues, fmt.Sprintf("missing required field %q", k))
+		}
+	}
+	for _, k := range v.types {
+		val, ok := entry.Fields[k]
+		if !ok {
+			continue
+		}
+		if want := v.schema.Types[k]; !hasType(val, want) {
+			issues = append(issues, fmt.Sprintf("field %q: want %s, got %q", k, want, val))
+		}
+	}
+	return issues
+}
+
+// Enrich sets entry.Issues to its violations of the schema.
+func (v *Validator) Enrich(entry *LogEntry) {
+	entry.Issues = v.Validate(*entry)
+}
+
+// hasField reports whether entry has field k, counting the parsed
+// timestamp, level and message under their own names.
+func hasField(entry LogEntry, k string) bool {
+	if _, ok := entry.Fields[k]; ok {
+		return true
+	}
+	switch k {
+	case "timestamp":
+		return !entry.Timestamp.IsZero()
+	case "level":
+		return entry.Level != ""
+	case "message":
+		return entry.Message != ""
+	}
+	return false
+}
+
+// hasType reports whether a field value has type t. Parsers keep JSON
+// values other than strings in their JSON encoding, so a number or bool
+// passes as a string too.
+func hasType(val, t string) bool {
+	switch t {
+	case "number":
+		_, err := strconv.ParseFloat(val, 64)
+		return err == nil
+	case "integer":
+		_, err := strconv.ParseInt(val, 10, 64)
+		return err == nil
+	case "bool":
+		return val == "true" || val == "false"
+	case "object":
+		return strings.HasPrefix(val, "{")
+	case "array":
+		return strings.HasPrefix(val, "[")
+	default:
+		return true
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidator_MissingRequired(t *testing.T) {
+	v, err := NewValidator(Schema{Required: []string{"message", "level", "request_id", "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewAutoParser()
+
+	entry := p.Parse(`{"level":"info","msg":"ok","request_id":"r1","user":"ann"}`)
+	if issues := v.Validate(entry); issues != nil {
+		t.Errorf("valid entry: issues = %q, want none", issues)
+	}
+
+	entry = p.Parse(`{"msg":"no level","user":"ann"}`)
+	want := []string{`missing required field "level"`, `missing required field "request_id"`}
+	if issues := v.Validate(entry); !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q, want %q", issues, want)
+	}
+}
+
+func TestValidator_TypeMismatch(t *testing.T) {
+	v, err := NewValidator(Schema{Types: map[string]string{
+		"status":  "integer",
+		"latency": "number",
+		"cached":  "bool",
+		"user":    "object",
+		"tags":    "array",
+		"host":    "string",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewAutoParser()
+
+	entry := p.Parse(`{"msg":"ok","status":200,"latency":1.5,"cached":false,"user":{"id":1},"tags":["a"],"host":"web1"}`)
+	if issues := v.Validate(entry); issues != nil {
+		t.Errorf("valid entry: issues = %q, want none", issues)
+	}
+	// Fields the entry lacks are not type-checked.
+	if issues := v.Validate(p.Parse(`{"msg":"sparse"}`)); issues != nil {
+		t.Errorf("sparse entry: issues = %q, want none", issues)
+	}
+
+	entry = p.Parse(`{"msg":"bad","status":"OK","latency":"fast","cached":"yes","user":"ann","tags":"a,b","host":7}`)
+	want := []string{
+		`field "cached": want bool, got "yes"`,
+		`field "latency": want number, got "fast"`,
+		`field "status": want integer, got "OK"`,
+		`field "tags": want array, got "a,b"`,
+		`field "user": want object, got "ann"`,
+	}
+	if issues := v.Validate(entry); !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q, want %q", issues, want)
+	}
+}
+
+func TestValidator_Enrich(t *testing.T) {
+	v, err := NewValidator(Schema{Required: []string{"timestamp"}, Types: map[string]string{"pid": "integer"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewAutoParser()
+	p.AddEnricher(v)
+
+	if entry := p.Parse(`{"time":"2026-02-17T20:00:00Z","msg":"ok","pid":42}`); entry.Issues != nil {
+		t.Errorf("Issues = %q, want none", entry.Issues)
+	}
+	entry := p.Parse(`level=info msg=hi pid=abc`)
+	if len(entry.Issues) != 2 {
+		t.Errorf("Issues = %q, want a missing timestamp and a bad pid", entry.Issues)
+	}
+}
+
+func TestNewValidator_UnknownType(t *testing.T) {
+	if _, err := NewValidator(Schema{Types: map[string]string{"status": "int"}}); err == nil {
+		t.Error("want an error for an unknown type")
+	}
+}
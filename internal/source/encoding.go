@@ -0,0 +1,105 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding is the character encoding of a log file. Lines are always
+// emitted as UTF-8.
+type Encoding int
+
+const (
+	// EncodingAuto tells the encoding by the byte order mark at the start
+	// of the file: UTF-16 with a UTF-16 BOM, UTF-8 otherwise.
+	EncodingAuto Encoding = iota
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+)
+
+// String returns the encoding's name as accepted by ParseEncoding.
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF8:
+		return "utf-8"
+	case EncodingUTF16LE:
+		return "utf-16le"
+	case EncodingUTF16BE:
+		return "utf-16be"
+	default:
+		return "auto"
+	}
+}
+
+// ParseEncoding parses "auto", "utf-8", "utf-16le" or "utf-16be".
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(s, "_", "-")) {
+	case "auto", "":
+		return EncodingAuto, nil
+	case "utf-8", "utf8":
+		return EncodingUTF8, nil
+	case "utf-16le", "utf16le":
+		return EncodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return EncodingUTF16BE, nil
+	}
+	return EncodingAuto, fmt.Errorf("unknown encoding %q (want auto, utf-8, utf-16le or utf-16be)", s)
+}
+
+// detectEncoding resolves EncodingAuto for the file read by r by looking
+// at its first bytes. Other encodings are returned as they are.
+func detectEncoding(r io.ReaderAt, e Encoding) Encoding {
+	if e != EncodingAuto {
+		return e
+	}
+	var bom [2]byte
+	if n, _ := r.ReadAt(bom[:], 0); n == len(bom) {
+		switch {
+		case bom[0] == 0xFF && bom[1] == 0xFE:
+			return EncodingUTF16LE
+		case bom[0] == 0xFE && bom[1] == 0xFF:
+			return EncodingUTF16BE
+		}
+	}
+	return EncodingUTF8
+}
+
+// unitSize is the size in bytes of the encoding's code units. Offsets into
+// a file are kept at multiples of it.
+func (e Encoding) unitSize() int {
+	if e == EncodingUTF16LE || e == EncodingUTF16BE {
+		return 2
+	}
+	return 1
+}
+
+// isNewline reports whether the code unit b is a line feed.
+func (e Encoding) isNewline(b []byte) bool {
+	switch e {
+	case EncodingUTF16LE:
+		return b[0] == '\n' && b[1] == 0
+	case EncodingUTF16BE:
+		return b[0] == 0 && b[1] == '\n'
+	default:
+		return b[0] == '\n'
+	}
+}
+
+// decode wraps r, which must start at a code unit boundary, so that it
+// yields UTF-8. A byte order mark is decoded like any other character, to
+// be stripped with the first line.
+func (e Encoding) decode(r io.Reader) io.Reader {
+	switch e {
+	case EncodingUTF16LE:
+		return transform.NewReader(r, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder())
+	case EncodingUTF16BE:
+		return transform.NewReader(r, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder())
+	default:
+		return r
+	}
+}
@@ -0,0 +1,121 @@
+package source
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16, with a byte order mark if bom is set.
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	if bom {
+		s = "\ufeff" + s
+	}
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = order.AppendUint16(b, u)
+	}
+	return b
+}
+
+func startFileSource(t *testing.T, cfg FileConfig) *FileSource {
+	t.Helper()
+	src := NewFileSource(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { src.Stop() })
+	return src
+}
+
+func wantLines(t *testing.T, entries []LogEntry, want ...string) {
+	t.Helper()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Line != want[i] {
+			t.Errorf("line %d = %q, want %q", i, e.Line, want[i])
+		}
+	}
+}
+
+func TestFileSource_UTF16LE(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "windows.log")
+	os.WriteFile(path, encodeUTF16("Dienst gestartet: café\r\n上海 ready\r\n", binary.LittleEndian, true), 0644)
+
+	src := startFileSource(t, FileConfig{Patterns: []string{path}})
+	wantLines(t, collectLines(t, src, 2*time.Second, 2), "Dienst gestartet: café", "上海 ready")
+
+	// A code unit caught half-written is left for the next read rather
+	// than decoded in halves.
+	appended := encodeUTF16("naïve\r\n", binary.LittleEndian, false)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.Write(appended[:5])
+	first := collectLines(t, src, 3*time.Second, 1)
+	f.Write(appended[5:])
+	f.Close()
+	wantLines(t, append(first, collectLines(t, src, 3*time.Second, 1)...), "na", "ïve")
+}
+
+func TestFileSource_UTF16TailLastN(t *testing.T) {
+	// 上 is U+4E0A, whose low byte is a line feed.
+	path := filepath.Join(t.TempDir(), "tail.log")
+	os.WriteFile(path, encodeUTF16("one\ntwo 上\nthree 上上\n", binary.LittleEndian, true), 0644)
+
+	src := startFileSource(t, FileConfig{Patterns: []string{path}, ReadFrom: ReadLastN, TailLines: 2, NoFollow: true})
+	wantLines(t, collectLines(t, src, 2*time.Second, 3), "two 上", "three 上上")
+}
+
+func TestFileSource_UTF16ReopenRedetects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, encodeUTF16("first\n", binary.LittleEndian, true), 0644)
+
+	src := startFileSource(t, FileConfig{Patterns: []string{path}})
+	wantLines(t, collectLines(t, src, 2*time.Second, 1), "first")
+
+	// Truncated and rewritten in big-endian.
+	os.WriteFile(path, encodeUTF16("big\n", binary.BigEndian, true), 0644)
+	wantLines(t, collectLines(t, src, 3*time.Second, 1), "big")
+
+	// Rotated, with plain UTF-8 taking its place.
+	os.Rename(path, path+".1")
+	time.Sleep(200 * time.Millisecond)
+	os.WriteFile(path, []byte("utf-8 again\n"), 0644)
+	wantLines(t, collectLines(t, src, 5*time.Second, 1), "utf-8 again")
+}
+
+func TestFileSource_EncodingOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nobom.log")
+	os.WriteFile(path, encodeUTF16("no mark\nstill ü\n", binary.BigEndian, false), 0644)
+
+	src := startFileSource(t, FileConfig{Patterns: []string{path}, Encoding: EncodingUTF16BE, NoFollow: true})
+	wantLines(t, collectLines(t, src, 2*time.Second, 3), "no mark", "still ü")
+}
+
+func TestParseEncoding(t *testing.T) {
+	for in, want := range map[string]Encoding{
+		"":         EncodingAuto,
+		"auto":     EncodingAuto,
+		"UTF-8":    EncodingUTF8,
+		"utf16le":  EncodingUTF16LE,
+		"utf_16be": EncodingUTF16BE,
+	} {
+		got, err := ParseEncoding(in)
+		if err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("latin1"); err == nil {
+		t.Error("expected an error for an unsupported encoding")
+	}
+	if EncodingUTF16LE.String() != "utf-16le" {
+		t.Errorf("String = %q", EncodingUTF16LE.String())
+	}
+}
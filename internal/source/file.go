@@ -1,25 +1,120 @@
 package source
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// FollowMode controls what happens when a tailed file is rotated.
+type FollowMode int
+
+const (
+	// FollowName follows the path: on rotation the new file at the same
+	// name is opened (like tail -F).
+	FollowName FollowMode = iota
+	// FollowDescriptor keeps reading the original file after it has been
+	// renamed or removed (like tail -f).
+	FollowDescriptor
+)
+
+// ParseFollowMode parses a rotation policy name: "name" or "descriptor".
+func ParseFollowMode(s string) (FollowMode, error) {
+	switch s {
+	case "name":
+		return FollowName, nil
+	case "descriptor":
+		return FollowDescriptor, nil
+	default:
+		return 0, fmt.Errorf("unknown follow mode %q (want name or descriptor)", s)
+	}
+}
+
+// ReadFrom selects where in its existing content a file is first read.
+type ReadFrom int
+
+const (
+	// ReadStart reads each file from its first line.
+	ReadStart ReadFrom = iota
+	// ReadEnd skips the existing content: only lines appended after Start
+	// are read, like tail -f -n 0.
+	ReadEnd
+	// ReadLastN reads the last FileConfig.TailLines lines, then follows.
+	ReadLastN
+)
+
+// ParseReadFrom parses where to read files from: "start", "end", or a
+// number of lines to read from the end.
+func ParseReadFrom(s string) (ReadFrom, int, error) {
+	switch s {
+	case "start":
+		return ReadStart, 0, nil
+	case "end":
+		return ReadEnd, 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, 0, fmt.Errorf("invalid read position %q (want start, end or a number of lines)", s)
+	}
+	return ReadLastN, n, nil
+}
+
+// DefaultRescanInterval is how often a following FileSource expands its
+// patterns again to find new files.
+const DefaultRescanInterval = 2 * time.Second
+
 // FileConfig holds configuration for a file source.
 type FileConfig struct {
-	// Patterns is a list of file paths or glob patterns.
+	// Patterns is a list of file paths or glob patterns. A "**" segment
+	// matches any number of directories, as in /var/log/**/*.log.
 	Patterns []string
-	// TailLines is the number of lines to read from the end on startup.
-	// If 0, read from the beginning. If negative, read from the beginning.
+	// MaxGlobDepth limits how many directories below its leading literal
+	// segments a "**" pattern descends. Zero means no limit.
+	MaxGlobDepth int
+	// RescanInterval is how often the patterns are expanded again while
+	// following, so that matching files created after Start are tailed
+	// too, from their first line. A file created in a watched directory
+	// triggers a rescan at once. Zero means DefaultRescanInterval; a
+	// negative interval disables rescanning.
+	RescanInterval time.Duration
+	// ReadFrom is where existing content is first read from (default
+	// ReadStart). Files found by a rescan are always read from the start.
+	ReadFrom ReadFrom
+	// TailLines is the number of lines ReadLastN reads from the end. It is
+	// ignored by the other ReadFrom modes.
 	TailLines int
+	// NoFollow reads the existing content once and stops instead of tailing.
+	// Lines() closes once every file has been drained. Following is the
+	// default.
+	NoFollow bool
+	// FollowMode selects the rotation policy. Defaults to FollowName.
+	FollowMode FollowMode
+	// StateFile, if set, is a JSON file where the read offset of every file
+	// is kept, so that a restart resumes where the previous run stopped.
+	// A stored offset takes precedence over ReadFrom. Offsets are tracked
+	// per inode, so a rotated or recreated file is read as a new file.
+	StateFile string
+	// MaxLineLength truncates longer lines to this many bytes, marked with
+	// TruncatedMarker. Zero means lines of any length are passed through.
+	MaxLineLength int
+	// LineFilter, if set, drops lines for which it returns false before they
+	// are sent to Lines. It is called concurrently from one goroutine per
+	// file, so it must be safe for concurrent use. Like the rest of the
+	// config it is fixed once the source is created.
+	LineFilter LineFilter
+	// Encoding is the files' character encoding. The default, EncodingAuto,
+	// reads files starting with a UTF-16 byte order mark as UTF-16 and all
+	// others as UTF-8.
+	Encoding Encoding
 }
 
 // FileSource reads log lines from one or more files with live tailing
@@ -31,6 +126,30 @@ type FileSource struct {
 	cancel  context.CancelFunc
 	wg      sync.WaitGroup
 	stopped chan struct{}
+	state   *offsetState // nil unless FileConfig.StateFile is set
+	ctxDone <-chan struct{}
+
+	emitted atomic.Int64 // lines sent to the lines channel
+	loading atomic.Int64 // tailers still reading their initial content
+	scanned atomic.Int64 // bytes the initial reads went through
+
+	// One per tailer; see Reload.
+	reloadMu sync.Mutex
+	tailers  []tailerControl
+}
+
+// tailerControl lets Reload reach a tailer goroutine.
+type tailerControl struct {
+	reload chan reloadRequest
+	events chan fsnotify.Event // the watcher's events for the tailer's file
+	done   chan struct{}       // closed when the tailer exits
+}
+
+// reloadRequest asks a tailer to pause (acknowledged via paused) and, once
+// resume is closed, to re-read its file from the start.
+type reloadRequest struct {
+	paused *sync.WaitGroup
+	resume <-chan struct{}
 }
 
 // NewFileSource creates a new file source from the given config.
@@ -46,21 +165,40 @@ func NewFileSource(cfg FileConfig) *FileSource {
 func (fs *FileSource) Lines() <-chan LogEntry { return fs.lines }
 func (fs *FileSource) Errors() <-chan error   { return fs.errs }
 
+// Stats reports emitted lines and the lines channel's fill. A FileSource
+// never drops lines: when the channel is full, reading waits.
+func (fs *FileSource) Stats() SourceStats {
+	return SourceStats{
+		Emitted:   fs.emitted.Load(),
+		BufferLen: int64(len(fs.lines)),
+		BufferCap: int64(cap(fs.lines)),
+		Loading:   fs.loading.Load() > 0,
+		Scanned:   fs.scanned.Load(),
+	}
+}
+
 // Start resolves glob patterns and begins tailing all matched files.
 func (fs *FileSource) Start(ctx context.Context) error {
 	ctx, fs.cancel = context.WithCancel(ctx)
+	fs.ctxDone = ctx.Done()
 
 	paths, err := fs.resolvePatterns()
 	if err != nil {
-		return fmt.Errorf("resolving file patterns: %w", err)
+		return fs.abort(fmt.Errorf("resolving file patterns: %w", err))
 	}
 	if len(paths) == 0 {
-		return fmt.Errorf("no files matched patterns: %v", fs.config.Patterns)
+		return fs.abort(fmt.Errorf("no files matched patterns: %v", fs.config.Patterns))
+	}
+
+	if fs.config.StateFile != "" {
+		if fs.state, err = loadOffsetState(fs.config.StateFile); err != nil {
+			return fs.abort(err)
+		}
 	}
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return fmt.Errorf("creating watcher: %w", err)
+		return fs.abort(fmt.Errorf("creating watcher: %w", err))
 	}
 
 	// Watch directories containing the files (for rotation detection).
@@ -75,16 +213,45 @@ func (fs *FileSource) Start(ctx context.Context) error {
 		}
 	}
 
-	// Start a tailer goroutine per file.
+	// Start a tailer goroutine per file, each fed its own file's events.
+	subs := make(map[string]tailerControl, len(paths))
 	for _, p := range paths {
+		subs[p] = fs.startTailer(ctx, p, false)
+	}
+	newSubs := make(chan subscription)
+	var created chan struct{} // nil unless rescanning
+	if fs.rescanInterval() > 0 {
+		created = make(chan struct{}, 1)
+	}
+	dispatchDone := make(chan struct{})
+	go func() {
+		defer close(dispatchDone)
+		fs.dispatchEvents(ctx, watcher.Events, watcher.Errors, subs, newSubs, created)
+	}()
+	if interval := fs.rescanInterval(); interval > 0 {
+		// The rescanner counts as a tailer, so the tailers it starts are
+		// added while the wait group cannot be done.
 		fs.wg.Add(1)
-		go fs.tailFile(ctx, watcher, p)
+		go fs.rescan(ctx, interval, watcher, paths, newSubs, created)
 	}
 
-	// Wait for all tailers then clean up.
+	stopSaver := make(chan struct{})
+	saverDone := make(chan struct{})
+	go fs.saveStatePeriodically(stopSaver, saverDone)
+
+	// Wait for all tailers then clean up. Closing the watcher ends the
+	// dispatcher if cancellation has not already.
 	go func() {
 		fs.wg.Wait()
 		watcher.Close()
+		<-dispatchDone
+		close(stopSaver)
+		<-saverDone
+		if fs.state != nil {
+			if err := fs.state.save(); err != nil {
+				fs.sendError(err)
+			}
+		}
 		close(fs.lines)
 		close(fs.errs)
 		close(fs.stopped)
@@ -93,22 +260,194 @@ func (fs *FileSource) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop cancels tailing and waits for goroutines to finish.
+// startTailer starts a goroutine tailing path, reading it from the start
+// if fromStart is set, and registers it for Reload.
+func (fs *FileSource) startTailer(ctx context.Context, path string, fromStart bool) tailerControl {
+	tc := tailerControl{
+		reload: make(chan reloadRequest),
+		events: make(chan fsnotify.Event, 64),
+		done:   make(chan struct{}),
+	}
+	fs.reloadMu.Lock()
+	fs.tailers = append(fs.tailers, tc)
+	fs.reloadMu.Unlock()
+	fs.wg.Add(1)
+	fs.loading.Add(1)
+	go func() {
+		defer close(tc.done)
+		fs.tailFile(ctx, tc.events, path, tc.reload, fromStart)
+	}()
+	return tc
+}
+
+// rescanInterval returns the configured rescan interval, or 0 if the
+// source does not rescan.
+func (fs *FileSource) rescanInterval() time.Duration {
+	switch {
+	case fs.config.NoFollow || fs.config.RescanInterval < 0:
+		return 0
+	case fs.config.RescanInterval == 0:
+		return DefaultRescanInterval
+	default:
+		return fs.config.RescanInterval
+	}
+}
+
+// subscription hands the dispatcher the tailer of a newly found file.
+type subscription struct {
+	path string
+	tc   tailerControl
+}
+
+// rescan expands the patterns every interval, and whenever a value arrives
+// on created, until ctx is cancelled, and starts tailing the files that
+// were not matched before. Files that are already tailed under another
+// name, such as a log rotated to app.log.1, are not tailed again. tailed
+// are the paths tailed from the start.
+func (fs *FileSource) rescan(ctx context.Context, interval time.Duration, watcher *fsnotify.Watcher, tailed []string, newSubs chan<- subscription, created <-chan struct{}) {
+	defer fs.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	known := make(map[string]struct{}, len(tailed)) // tailed paths
+	dirs := map[string]struct{}{}                   // watched directories
+	ids := map[string]struct{}{}                    // IDs of tailed files
+	for _, p := range tailed {
+		known[p] = struct{}{}
+		dirs[filepath.Dir(p)] = struct{}{}
+	}
+	// remember notes what the tailed paths hold now, so that once rotated
+	// away they are recognized.
+	remember := func() {
+		for p := range known {
+			if info, err := os.Stat(p); err == nil {
+				if id, ok := fileID(info); ok {
+					ids[id] = struct{}{}
+				}
+			}
+		}
+	}
+	remember()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-created:
+		}
+		remember()
+		paths, _ := fs.matchPatterns(false)
+		for _, p := range paths {
+			if _, ok := known[p]; ok {
+				continue
+			}
+			info, err := os.Stat(p)
+			if err != nil {
+				continue
+			}
+			if id, ok := fileID(info); ok {
+				if _, seen := ids[id]; seen {
+					continue
+				}
+			}
+			d := filepath.Dir(p)
+			if _, ok := dirs[d]; !ok {
+				if err := watcher.Add(d); err != nil {
+					fs.sendError(fmt.Errorf("watching directory %s: %w", d, err))
+				}
+				dirs[d] = struct{}{}
+			}
+			known[p] = struct{}{}
+			select {
+			case newSubs <- subscription{path: p, tc: fs.startTailer(ctx, p, true)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// abort ends a Start that failed before tailing began: Lines and Errors are
+// closed and Stop returns at once. It returns err.
+func (fs *FileSource) abort(err error) error {
+	fs.cancel()
+	close(fs.lines)
+	close(fs.errs)
+	close(fs.stopped)
+	return err
+}
+
+// Stop cancels tailing and waits for goroutines to finish. It may be called
+// more than once, and does nothing if the source was never started.
 func (fs *FileSource) Stop() error {
-	if fs.cancel != nil {
-		fs.cancel()
+	if fs.cancel == nil {
+		return nil
 	}
+	fs.cancel()
 	<-fs.stopped
 	return nil
 }
 
+// Reload re-reads every tailed file from the beginning. Lines already read
+// but not yet consumed are discarded, then a Reset entry is emitted, followed
+// by the files' full content, so a consumer that clears its buffer on Reset
+// sees every line exactly once. Files no longer being tailed (e.g. after a
+// NoFollow read) are not re-read.
+func (fs *FileSource) Reload() error {
+	fs.reloadMu.Lock()
+	defer fs.reloadMu.Unlock()
+
+	// Pause every tailer so none emits while the channel is drained. A
+	// paused tailer cannot exit, so the lines channel stays open until
+	// resume is closed.
+	var paused sync.WaitGroup
+	resume := make(chan struct{})
+	defer close(resume)
+	n := 0
+	for _, tc := range fs.tailers {
+		paused.Add(1)
+		select {
+		case tc.reload <- reloadRequest{paused: &paused, resume: resume}:
+			n++
+		case <-tc.done:
+			paused.Done()
+		}
+	}
+	paused.Wait()
+	if n == 0 {
+		return nil
+	}
+
+drain:
+	for {
+		select {
+		case <-fs.lines:
+		default:
+			break drain
+		}
+	}
+	select {
+	case fs.lines <- LogEntry{Reset: true}:
+		return nil
+	case <-fs.ctxDone:
+		return fmt.Errorf("reloading: %w", context.Canceled)
+	}
+}
+
 // resolvePatterns expands glob patterns into unique absolute file paths.
 func (fs *FileSource) resolvePatterns() ([]string, error) {
+	return fs.matchPatterns(true)
+}
+
+// matchPatterns expands glob patterns into unique absolute file paths. A
+// pattern matching nothing is taken as a literal path; if no such file
+// exists, strict makes it an error, otherwise it is skipped.
+func (fs *FileSource) matchPatterns(strict bool) ([]string, error) {
 	seen := map[string]struct{}{}
 	var result []string
 
 	for _, pattern := range fs.config.Patterns {
-		matches, err := filepath.Glob(pattern)
+		matches, err := expandGlob(pattern, fs.config.MaxGlobDepth)
 		if err != nil {
 			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
 		}
@@ -119,6 +458,9 @@ func (fs *FileSource) resolvePatterns() ([]string, error) {
 				return nil, err
 			}
 			if _, err := os.Stat(abs); err != nil {
+				if !strict {
+					continue
+				}
 				return nil, fmt.Errorf("file not found: %s", abs)
 			}
 			if _, ok := seen[abs]; !ok {
@@ -145,9 +487,63 @@ func (fs *FileSource) resolvePatterns() ([]string, error) {
 	return result, nil
 }
 
+// dispatchEvents routes watcher events to the channel of the file they
+// concern, and reports watcher errors, until ctx is cancelled or the watcher
+// is closed. It is the only receiver of the watcher's channels, so every
+// tailer sees its own events, and it closes the tailers' event channels when
+// it returns so none of them waits on a watcher that is gone. Events for a
+// tailer that has exited are dropped. Tailers started later are added
+// through newSubs. The creation of a file no tailer follows is signalled on
+// created, unless it is nil, without waiting for the signal to be taken.
+func (fs *FileSource) dispatchEvents(ctx context.Context, events <-chan fsnotify.Event, errs <-chan error, subs map[string]tailerControl, newSubs <-chan subscription, created chan<- struct{}) {
+	defer func() {
+		for _, tc := range subs {
+			close(tc.events)
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-newSubs:
+			subs[s.path] = s.tc
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			abs, _ := filepath.Abs(event.Name)
+			tc, ok := subs[abs]
+			if !ok {
+				if event.Has(fsnotify.Create) {
+					select {
+					case created <- struct{}{}:
+					default:
+					}
+				}
+				continue
+			}
+			select {
+			case tc.events <- event:
+			case <-tc.done:
+			case <-ctx.Done():
+				return
+			}
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			fs.sendError(fmt.Errorf("watching files: %w", err))
+		}
+	}
+}
+
 // tailFile reads initial lines then tails a single file, handling rotation.
-func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, path string) {
+// events carries the watcher's events for path; once it is closed, the file
+// is still followed by polling. A file read fromStart ignores ReadFrom.
+func (fs *FileSource) tailFile(ctx context.Context, events <-chan fsnotify.Event, path string, reload <-chan reloadRequest, fromStart bool) {
 	defer fs.wg.Done()
+	loaded := sync.OnceFunc(func() { fs.loading.Add(-1) })
+	defer loaded()
 
 	f, err := os.Open(path)
 	if err != nil {
@@ -156,18 +552,32 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 	}
 	defer f.Close()
 
-	// Read initial lines.
-	if fs.config.TailLines > 0 {
-		if err := fs.seekToLastN(f, fs.config.TailLines); err != nil {
-			fs.sendError(fmt.Errorf("seeking in %s: %w", path, err))
+	// Read initial lines, resuming from the state file if it knows the file.
+	if !fs.resume(f) && !fromStart {
+		switch fs.config.ReadFrom {
+		case ReadEnd:
+			if _, err := f.Seek(0, io.SeekEnd); err != nil {
+				fs.sendError(fmt.Errorf("seeking in %s: %w", path, err))
+			}
+		case ReadLastN:
+			if err := fs.seekToLastN(f, fs.config.TailLines); err != nil {
+				fs.sendError(fmt.Errorf("seeking in %s: %w", path, err))
+			}
 		}
 	}
 
-	offset, err := fs.readLines(f, path)
+	// The initial content streams out as it is read, and counts towards
+	// the bytes scanned while loading.
+	offset, err := fs.readLines(f, path, &fs.scanned)
+	loaded()
 	if err != nil {
 		fs.sendError(fmt.Errorf("initial read of %s: %w", path, err))
 		return
 	}
+	fs.recordOffset(f, path, offset)
+	if fs.config.NoFollow {
+		return
+	}
 
 	// Record inode for rotation detection.
 	lastStat, _ := f.Stat()
@@ -182,12 +592,24 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 		case <-ctx.Done():
 			return
 
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return
+		case req := <-reload:
+			req.paused.Done()
+			<-req.resume
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				fs.sendError(fmt.Errorf("reloading %s: %w", path, err))
+				continue
 			}
-			abs, _ := filepath.Abs(event.Name)
-			if abs != path {
+			if offset, err = fs.readLines(f, path, nil); err != nil {
+				fs.sendError(err)
+			}
+			lastSize = offset
+			fs.recordOffset(f, path, offset)
+
+		case event, ok := <-events:
+			if !ok {
+				// A nil channel is never ready, so the loop goes on
+				// polling instead of spinning on the closed channel.
+				events = nil
 				continue
 			}
 
@@ -196,9 +618,11 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 				if err != nil {
 					fs.sendError(err)
 				}
+				fs.recordOffset(f, path, offset)
 			}
 
-			if event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+			if fs.config.FollowMode == FollowName &&
+				(event.Has(fsnotify.Create) || event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove)) {
 				// File was rotated — reopen.
 				newF, newOffset, reopened := fs.tryReopen(path, lastStat)
 				if reopened {
@@ -207,15 +631,22 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 					offset = newOffset
 					lastStat, _ = f.Stat()
 					lastSize = newOffset
+					fs.recordOffset(f, path, offset)
 				}
 			}
 
-		case _, ok := <-watcher.Errors:
-			if !ok {
-				return
+		case <-ticker.C:
+			if fs.config.FollowMode == FollowDescriptor {
+				// Keep reading the descriptor we have, wherever the file
+				// now lives.
+				offset, lastSize, err = fs.handleWrite(f, path, offset, lastSize)
+				if err != nil {
+					fs.sendError(err)
+				}
+				fs.recordOffset(f, path, offset)
+				continue
 			}
 
-		case <-ticker.C:
 			// Check for truncation or new data.
 			stat, err := os.Stat(path)
 			if err != nil {
@@ -227,6 +658,7 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 					offset = newOffset
 					lastStat, _ = f.Stat()
 					lastSize = newOffset
+					fs.recordOffset(f, path, offset)
 				}
 				continue
 			}
@@ -244,7 +676,7 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 				lastStat, _ = f.Stat()
 			}
 
-			newOff, err := fs.readLines(f, path)
+			newOff, err := fs.readLines(f, path, nil)
 			if err != nil {
 				fs.sendError(err)
 				continue
@@ -253,13 +685,67 @@ func (fs *FileSource) tailFile(ctx context.Context, watcher *fsnotify.Watcher, p
 				offset = newOff
 			}
 			lastSize = offset
+			fs.recordOffset(f, path, offset)
+		}
+	}
+}
+
+// resume seeks f to the offset stored in the state file, if there is one for
+// this file, and reports whether it did. An offset beyond the end of the file
+// means it was truncated since, so reading starts over.
+func (fs *FileSource) resume(f *os.File) bool {
+	if fs.state == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	off, ok := fs.state.offset(info)
+	if !ok {
+		return false
+	}
+	if off > info.Size() {
+		off = 0
+	}
+	_, err = f.Seek(off, io.SeekStart)
+	return err == nil
+}
+
+// recordOffset notes in the state, if any, how far f has been read.
+func (fs *FileSource) recordOffset(f *os.File, path string, offset int64) {
+	if fs.state == nil {
+		return
+	}
+	if info, err := f.Stat(); err == nil {
+		fs.state.record(path, info, offset)
+	}
+}
+
+// saveStatePeriodically writes the state file every stateSaveInterval until
+// stop is closed, then closes done.
+func (fs *FileSource) saveStatePeriodically(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	if fs.state == nil {
+		return
+	}
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := fs.state.save(); err != nil {
+				fs.sendError(err)
+			}
 		}
 	}
 }
 
 // handleWrite reads new data after a write event, handling truncation.
 func (fs *FileSource) handleWrite(f *os.File, path string, offset, lastSize int64) (int64, int64, error) {
-	stat, err := os.Stat(path)
+	stat, err := fs.stat(f, path)
 	if err != nil {
 		return offset, lastSize, fmt.Errorf("stat %s: %w", path, err)
 	}
@@ -270,7 +756,7 @@ func (fs *FileSource) handleWrite(f *os.File, path string, offset, lastSize int6
 		}
 		offset = 0
 	}
-	newOff, err := fs.readLines(f, path)
+	newOff, err := fs.readLines(f, path, nil)
 	if err != nil {
 		return offset, lastSize, err
 	}
@@ -280,6 +766,15 @@ func (fs *FileSource) handleWrite(f *os.File, path string, offset, lastSize int6
 	return offset, stat.Size(), nil
 }
 
+// stat returns the file info used for truncation checks: the open
+// descriptor's in FollowDescriptor mode, otherwise whatever is at path.
+func (fs *FileSource) stat(f *os.File, path string) (os.FileInfo, error) {
+	if fs.config.FollowMode == FollowDescriptor {
+		return f.Stat()
+	}
+	return os.Stat(path)
+}
+
 // tryReopen attempts to reopen a file after rotation. Returns the new file,
 // offset after initial read, and whether reopening succeeded.
 func (fs *FileSource) tryReopen(path string, lastStat os.FileInfo) (*os.File, int64, bool) {
@@ -297,43 +792,88 @@ func (fs *FileSource) tryReopen(path string, lastStat os.FileInfo) (*os.File, in
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
-		off, _ := fs.readLines(f, path)
+		off, _ := fs.readLines(f, path, nil)
 		return f, off, true
 	}
 	return nil, 0, false
 }
 
 // readLines reads available lines from the current position, sends them,
-// and returns the new offset.
-func (fs *FileSource) readLines(f *os.File, path string) (int64, error) {
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-	for scanner.Scan() {
+// and returns the new offset. Line endings are dropped, CRLF included, and
+// so is a byte order mark at the start of the file. Files in UTF-16 are
+// decoded; the encoding is detected on every call, so a file replaced by
+// rotation or truncation gets its own. The bytes read are added to scanned
+// unless it is nil.
+func (fs *FileSource) readLines(f *os.File, path string, scanned *atomic.Int64) (int64, error) {
+	pos, _ := f.Seek(0, io.SeekCurrent)
+	atStart := pos == 0
+	enc := detectEncoding(f, fs.config.Encoding)
+	var r io.Reader = f
+	end := int64(-1)
+	if unit := int64(enc.unitSize()); unit > 1 {
+		// Read whole code units only, so that a unit still being written
+		// is read next time rather than decoded in halves.
+		info, err := f.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("reading %s: %w", path, err)
+		}
+		pos -= pos % unit
+		end = max(pos+(info.Size()-pos)/unit*unit, pos)
+		r = io.NewSectionReader(f, pos, end-pos)
+	}
+	var read, counted int64
+	if scanned != nil {
+		r = &countingReader{r: r, n: &read}
+	}
+	lr := newLineReader(enc.decode(r), fs.config.MaxLineLength)
+	for {
+		line, err := lr.next()
+		if scanned != nil {
+			scanned.Add(read - counted)
+			counted = read
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if atStart {
+			line, atStart = stripBOM(line), false
+		}
+		if fs.config.LineFilter != nil && !fs.config.LineFilter(line) {
+			continue
+		}
 		fs.lines <- LogEntry{
-			Line:   scanner.Text(),
+			Line:   line,
 			Source: path,
 		}
+		fs.emitted.Add(1)
 	}
-	if err := scanner.Err(); err != nil {
-		return 0, fmt.Errorf("reading %s: %w", path, err)
+	if end >= 0 {
+		return f.Seek(end, io.SeekStart)
 	}
 	off, _ := f.Seek(0, io.SeekCurrent)
 	return off, nil
 }
 
 // seekToLastN positions the file to read approximately the last n lines.
-// It works by scanning backwards from the end.
+// It works by scanning backwards from the end, stopping once it has seen n
+// line ends; the bytes it goes through count as scanned.
 func (fs *FileSource) seekToLastN(f *os.File, n int) error {
 	stat, err := f.Stat()
 	if err != nil {
 		return err
 	}
-	size := stat.Size()
+	enc := detectEncoding(f, fs.config.Encoding)
+	unit := enc.unitSize()
+	size := stat.Size() / int64(unit) * int64(unit)
 	if size == 0 {
 		return nil
 	}
 
-	// Read chunks from the end to find newlines.
+	// Read chunks from the end to find newlines. The chunk size is a
+	// multiple of every code unit size, so chunks hold whole units.
 	const chunkSize = 8192
 	newlines := 0
 	offset := size
@@ -349,11 +889,12 @@ func (fs *FileSource) seekToLastN(f *os.File, n int) error {
 		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
 			return err
 		}
-		for i := len(buf) - 1; i >= 0; i-- {
-			if buf[i] == '\n' {
+		fs.scanned.Add(readSize)
+		for i := len(buf) - unit; i >= 0; i -= unit {
+			if enc.isNewline(buf[i : i+unit]) {
 				newlines++
 				if newlines > n {
-					offset += int64(i) + 1
+					offset += int64(i + unit)
 					break
 				}
 			}
@@ -372,3 +913,15 @@ func (fs *FileSource) sendError(err error) {
 	default:
 	}
 }
+
+// countingReader adds the number of bytes read to *n.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
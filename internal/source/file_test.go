@@ -2,10 +2,17 @@ package source
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 func collectLines(t *testing.T, src *FileSource, timeout time.Duration, n int) []LogEntry {
@@ -27,6 +34,45 @@ func collectLines(t *testing.T, src *FileSource, timeout time.Duration, n int) [
 	return entries
 }
 
+func TestFileSource_TailLastNStreamsWhileLoading(t *testing.T) {
+	const total, tail = 20000, 5000
+	path := filepath.Join(t.TempDir(), "big.log")
+	var b strings.Builder
+	for i := 0; i < total; i++ {
+		fmt.Fprintf(&b, "line %05d %s\n", i, strings.Repeat("x", 190))
+	}
+	os.WriteFile(path, []byte(b.String()), 0644)
+	tailBytes := int64(tail * 202)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}, ReadFrom: ReadLastN, TailLines: tail, NoFollow: true})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	// The first lines arrive while the rest of the tail is still being
+	// read: the reader waits for room in the lines channel.
+	first := collectLines(t, src, 5*time.Second, 1)
+	st := src.Stats()
+	if !st.Loading || st.Scanned <= tailBytes || st.Scanned >= 2*tailBytes {
+		t.Errorf("after the first line: loading %v, scanned %d; want loading with %d < scanned < %d", st.Loading, st.Scanned, tailBytes, 2*tailBytes)
+	}
+
+	entries := append(first, collectLines(t, src, 10*time.Second, tail)...)
+	if len(entries) != tail {
+		t.Fatalf("got %d lines, want %d", len(entries), tail)
+	}
+	if !strings.HasPrefix(entries[0].Line, "line 15000 ") || !strings.HasPrefix(entries[tail-1].Line, "line 19999 ") {
+		t.Errorf("lines %q .. %q, want 15000 .. 19999", entries[0].Line[:10], entries[tail-1].Line[:10])
+	}
+	if _, ok := <-src.Lines(); ok {
+		t.Error("lines beyond the tail")
+	}
+	if st := src.Stats(); st.Loading || st.Scanned < 2*tailBytes {
+		t.Errorf("after loading: loading %v, scanned %d; want done with at least %d", st.Loading, st.Scanned, 2*tailBytes)
+	}
+}
+
 func TestFileSource_ReadFromStart(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.log")
@@ -54,7 +100,7 @@ func TestFileSource_TailLastN(t *testing.T) {
 	path := filepath.Join(dir, "test.log")
 	os.WriteFile(path, []byte("a\nb\nc\nd\ne\n"), 0644)
 
-	src := NewFileSource(FileConfig{Patterns: []string{path}, TailLines: 2})
+	src := NewFileSource(FileConfig{Patterns: []string{path}, ReadFrom: ReadLastN, TailLines: 2})
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -71,6 +117,69 @@ func TestFileSource_TailLastN(t *testing.T) {
 	src.Stop()
 }
 
+func TestFileSource_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      ReadFrom
+		tailLines int
+		want      string
+	}{
+		{"start", ReadStart, 2, "a,b,c,new"}, // TailLines is ignored
+		{"end", ReadEnd, 0, "new"},
+		{"last N", ReadLastN, 2, "b,c,new"},
+		{"last 0", ReadLastN, 0, "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.log")
+			os.WriteFile(path, []byte("a\nb\nc\n"), 0644)
+
+			src := NewFileSource(FileConfig{Patterns: []string{path}, ReadFrom: tt.from, TailLines: tt.tailLines})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := src.Start(ctx); err != nil {
+				t.Fatal(err)
+			}
+			defer src.Stop()
+
+			time.Sleep(200 * time.Millisecond) // let the existing content be read or skipped
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+			f.WriteString("new\n")
+			f.Close()
+
+			var got []string
+			for _, e := range collectLines(t, src, 3*time.Second, strings.Count(tt.want, ",")+1) {
+				got = append(got, e.Line)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("lines = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseReadFrom(t *testing.T) {
+	tests := []struct {
+		in    string
+		from  ReadFrom
+		lines int
+		ok    bool
+	}{
+		{"start", ReadStart, 0, true},
+		{"end", ReadEnd, 0, true},
+		{"100", ReadLastN, 100, true},
+		{"0", ReadLastN, 0, true},
+		{"-5", 0, 0, false},
+		{"middle", 0, 0, false},
+	}
+	for _, tt := range tests {
+		from, lines, err := ParseReadFrom(tt.in)
+		if (err == nil) != tt.ok || from != tt.from || lines != tt.lines {
+			t.Errorf("ParseReadFrom(%q) = %v, %d, %v", tt.in, from, lines, err)
+		}
+	}
+}
+
 func TestFileSource_LiveTailing(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.log")
@@ -185,6 +294,113 @@ func TestFileSource_GlobPattern(t *testing.T) {
 	src.Stop()
 }
 
+func TestFileSource_RecursiveGlobRescan(t *testing.T) {
+	dir := globTree(t, "api/api.log", "db/slow/db.log", "notes.txt")
+	os.WriteFile(filepath.Join(dir, "api", "api.log"), []byte("from-api\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "db", "slow", "db.log"), []byte("from-db\n"), 0644)
+
+	pattern := filepath.Join(dir, "**", "*.log")
+	src := NewFileSource(FileConfig{Patterns: []string{pattern}, ReadFrom: ReadLastN, TailLines: 1, RescanInterval: 50 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	lines := map[string]bool{}
+	for _, e := range collectLines(t, src, 2*time.Second, 2) {
+		lines[e.Line] = true
+	}
+	if !lines["from-api"] || !lines["from-db"] {
+		t.Fatalf("expected the nested logs, got %v", lines)
+	}
+
+	// A file created after Start, in a new directory, is read from its
+	// first line although only the last line of the others was read.
+	newDir := filepath.Join(dir, "worker", "1")
+	os.MkdirAll(newDir, 0755)
+	os.WriteFile(filepath.Join(newDir, "worker.log"), []byte("first\nsecond\n"), 0644)
+	entries := collectLines(t, src, 5*time.Second, 2)
+	if entries[0].Line != "first" || entries[1].Line != "second" {
+		t.Fatalf("new file lines = %v", entries)
+	}
+
+	// And it is followed like the others.
+	f, _ := os.OpenFile(filepath.Join(newDir, "worker.log"), os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("third\n")
+	f.Close()
+	if e := collectLines(t, src, 5*time.Second, 1)[0]; e.Line != "third" {
+		t.Errorf("appended line = %q, want third", e.Line)
+	}
+}
+
+func TestFileSource_AttachesCreatedFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "app-2024-01-15.log"), []byte("monday\n"), 0644)
+
+	// The periodic rescan is too slow to matter: the file's creation
+	// triggers one.
+	pattern := filepath.Join(dir, "app-*.log")
+	src := NewFileSource(FileConfig{Patterns: []string{pattern}, RescanInterval: time.Hour})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+	if e := collectLines(t, src, 2*time.Second, 1)[0]; e.Line != "monday" {
+		t.Fatalf("line = %q, want monday", e.Line)
+	}
+
+	os.WriteFile(filepath.Join(dir, "app-2024-01-16.txt"), []byte("ignored\n"), 0644)
+	next := filepath.Join(dir, "app-2024-01-16.log")
+	os.WriteFile(next, []byte("tuesday\n"), 0644)
+	e := collectLines(t, src, 3*time.Second, 1)[0]
+	if e.Line != "tuesday" || e.Source != next {
+		t.Fatalf("got %q from %s, want tuesday from the new file", e.Line, e.Source)
+	}
+
+	// The new file is followed like the first.
+	f, _ := os.OpenFile(next, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("still tuesday\n")
+	f.Close()
+	if e := collectLines(t, src, 5*time.Second, 1)[0]; e.Line != "still tuesday" {
+		t.Errorf("appended line = %q, want still tuesday", e.Line)
+	}
+}
+
+func TestFileSource_RescanSkipsRotatedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("files have no stable identity here")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	os.WriteFile(path, []byte("before\n"), 0644)
+
+	// The pattern also matches the rotated test.log.1.
+	src := NewFileSource(FileConfig{Patterns: []string{path + "*"}, RescanInterval: 50 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+	collectLines(t, src, 2*time.Second, 1)
+	time.Sleep(100 * time.Millisecond) // let a rescan see test.log
+
+	os.Rename(path, path+".1")
+	time.Sleep(200 * time.Millisecond)
+	os.WriteFile(path, []byte("after\n"), 0644)
+
+	if e := collectLines(t, src, 5*time.Second, 1)[0]; e.Line != "after" {
+		t.Fatalf("line = %q, want after", e.Line)
+	}
+	select {
+	case e := <-src.Lines():
+		t.Errorf("unexpected line %q from %s: the rotated file was read again", e.Line, e.Source)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
 func TestFileSource_MultiplePatterns(t *testing.T) {
 	dir := t.TempDir()
 	p1 := filepath.Join(dir, "a.log")
@@ -272,3 +488,367 @@ func TestFileSource_EmptyFile(t *testing.T) {
 	cancel()
 	src.Stop()
 }
+
+func TestFileSource_NoFollow(t *testing.T) {
+	dir := t.TempDir()
+	p1 := filepath.Join(dir, "a.log")
+	p2 := filepath.Join(dir, "b.log")
+	os.WriteFile(p1, []byte("a1\na2\n"), 0644)
+	os.WriteFile(p2, []byte("b1\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{p1, p2}, NoFollow: true})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case e, ok := <-src.Lines():
+			if !ok {
+				if len(got) != 3 {
+					t.Errorf("got %d lines before close, want 3: %v", len(got), got)
+				}
+				if st := src.Stats(); st.Emitted != 3 || st.Dropped != 0 || st.BufferLen != 0 {
+					t.Errorf("Stats() = %+v, want 3 emitted and nothing dropped or buffered", st)
+				}
+				// Stop after a natural close must not block or double-close.
+				src.Stop()
+				return
+			}
+			got = append(got, e.Line)
+		case <-timeout:
+			t.Fatalf("lines channel did not close; got %v", got)
+		}
+	}
+}
+
+func rotationSource(t *testing.T, mode FollowMode) (*FileSource, string, context.CancelFunc) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	os.WriteFile(path, []byte("before\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}, FollowMode: mode})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	collectLines(t, src, 2*time.Second, 1)
+	return src, path, cancel
+}
+
+func TestFileSource_FollowNameSwitchesFile(t *testing.T) {
+	src, path, cancel := rotationSource(t, FollowName)
+	defer cancel()
+
+	os.Rename(path, path+".1")
+	time.Sleep(200 * time.Millisecond)
+	os.WriteFile(path, []byte("new-file\n"), 0644)
+
+	entries := collectLines(t, src, 5*time.Second, 1)
+	if entries[0].Line != "new-file" {
+		t.Errorf("name mode should follow the new file, got %q", entries[0].Line)
+	}
+
+	cancel()
+	src.Stop()
+}
+
+func TestFileSource_FollowDescriptorKeepsOldFile(t *testing.T) {
+	src, path, cancel := rotationSource(t, FollowDescriptor)
+	defer cancel()
+
+	os.Rename(path, path+".1")
+	time.Sleep(200 * time.Millisecond)
+	os.WriteFile(path, []byte("new-file\n"), 0644)
+
+	f, err := os.OpenFile(path+".1", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("old-file\n")
+	f.Close()
+
+	entries := collectLines(t, src, 5*time.Second, 1)
+	if entries[0].Line != "old-file" {
+		t.Errorf("descriptor mode should keep reading the rotated file, got %q", entries[0].Line)
+	}
+
+	// Nothing from the new file should show up.
+	select {
+	case e := <-src.Lines():
+		t.Errorf("unexpected line after rotation: %q", e.Line)
+	case <-time.After(1500 * time.Millisecond):
+	}
+
+	cancel()
+	src.Stop()
+}
+
+func TestFileSource_HugeLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	huge := strings.Repeat("y", 5<<20)
+	os.WriteFile(path, []byte("first\n"+huge+"\nlast\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}, NoFollow: true, MaxLineLength: 100})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := collectLines(t, src, 5*time.Second, 3)
+	if len(entries) != 3 {
+		t.Fatalf("got %d lines, want 3", len(entries))
+	}
+	if want := strings.Repeat("y", 100) + TruncatedMarker; entries[1].Line != want {
+		t.Errorf("truncated line = %.20q..., want %d bytes", entries[1].Line, len(want))
+	}
+	if entries[0].Line != "first" || entries[2].Line != "last" {
+		t.Errorf("surrounding lines = %q, %q", entries[0].Line, entries[2].Line)
+	}
+	src.Stop()
+}
+
+func TestFileSource_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	os.WriteFile(path, []byte("line1\nline2\nline3\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	collectLines(t, src, 2*time.Second, 3)
+
+	if err := src.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	entries := collectLines(t, src, 2*time.Second, 4)
+	if !entries[0].Reset {
+		t.Fatalf("first entry after Reload should be a Reset marker, got %+v", entries[0])
+	}
+	for i, want := range []string{"line1", "line2", "line3"} {
+		if entries[i+1].Reset || entries[i+1].Line != want {
+			t.Errorf("entry %d = %+v, want %q", i+1, entries[i+1], want)
+		}
+	}
+	select {
+	case e := <-src.Lines():
+		t.Errorf("unexpected extra entry after reload: %+v", e)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	src.Stop()
+}
+
+func TestFileSource_ReloadDiscardsUnreadLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	os.WriteFile(path, []byte("a\nb\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Wait for the initial read to be buffered, then reload without
+	// consuming it.
+	time.Sleep(200 * time.Millisecond)
+	if err := src.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	var after []string
+	seenReset := false
+	for _, e := range collectLines(t, src, 2*time.Second, 3) {
+		switch {
+		case e.Reset:
+			seenReset = true
+		case seenReset:
+			after = append(after, e.Line)
+		default:
+			t.Errorf("buffered line %q should have been discarded", e.Line)
+		}
+	}
+	if len(after) != 2 || after[0] != "a" || after[1] != "b" {
+		t.Errorf("lines after reset = %v, want [a b]", after)
+	}
+
+	cancel()
+	src.Stop()
+}
+
+func TestFileSource_LineFilter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	os.WriteFile(path, []byte("level=debug a\nlevel=error b\nlevel=info c\n"), 0644)
+
+	src := NewFileSource(FileConfig{
+		Patterns:   []string{path},
+		LineFilter: RegexpFilter(regexp.MustCompile(`level=(error|info)`)),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	entries := collectLines(t, src, 2*time.Second, 2)
+	time.Sleep(100 * time.Millisecond)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("level=debug d\nlevel=error e\n")
+	f.Close()
+	entries = append(entries, collectLines(t, src, 3*time.Second, 1)...)
+
+	want := []string{"level=error b", "level=info c", "level=error e"}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Line != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, e.Line, want[i])
+		}
+	}
+}
+
+func TestFileSource_CancelStopsAllTailers(t *testing.T) {
+	dir := t.TempDir()
+	const files = 30
+	for i := 0; i < files; i++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("app%02d.log", i)), []byte("hello\n"), 0644)
+	}
+	before := runtime.NumGoroutine()
+
+	src := NewFileSource(FileConfig{Patterns: []string{filepath.Join(dir, "*.log")}})
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	collectLines(t, src, 2*time.Second, files)
+	if n := runtime.NumGoroutine(); n < before+files {
+		t.Fatalf("goroutines = %d, want at least one tailer per file above %d", n, before)
+	}
+
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return after cancel")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("goroutines leaked: %d, want at most %d\n%s", runtime.NumGoroutine(), before, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileSource_DispatchEvents(t *testing.T) {
+	fs := NewFileSource(FileConfig{})
+	events := make(chan fsnotify.Event)
+	errs := make(chan error)
+	live := tailerControl{events: make(chan fsnotify.Event, 1), done: make(chan struct{})}
+	gone := tailerControl{events: make(chan fsnotify.Event), done: make(chan struct{})}
+	close(gone.done)
+	livePath, _ := filepath.Abs("live.log")
+	gonePath, _ := filepath.Abs("gone.log")
+
+	done := make(chan struct{})
+	go func() {
+		fs.dispatchEvents(context.Background(), events, errs, map[string]tailerControl{livePath: live, gonePath: gone}, nil, nil)
+		close(done)
+	}()
+
+	// An exited tailer's events are dropped instead of blocking the others.
+	events <- fsnotify.Event{Name: "gone.log", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "other.log", Op: fsnotify.Write}
+	events <- fsnotify.Event{Name: "live.log", Op: fsnotify.Write}
+	if ev := <-live.events; ev.Name != "live.log" {
+		t.Errorf("live tailer got %v", ev)
+	}
+
+	errs <- errors.New("queue overflow")
+	if err := <-fs.Errors(); err == nil || !strings.Contains(err.Error(), "queue overflow") {
+		t.Errorf("watcher error = %v", err)
+	}
+
+	// A closed watcher closes every tailer's channel.
+	close(events)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("dispatcher did not return after the watcher closed")
+	}
+	if _, ok := <-live.events; ok {
+		t.Error("tailer channel still open")
+	}
+}
+
+func TestFileSource_BOMAndCRLF(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "windows.log")
+	os.WriteFile(path, []byte("\ufeff[2024-01-15 10:30:00] [Warning] Disk low\r\nsecond\r\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	entries := collectLines(t, src, 2*time.Second, 2)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("appended\r\n")
+	f.Close()
+	entries = append(entries, collectLines(t, src, 2*time.Second, 1)...)
+
+	want := []string{"[2024-01-15 10:30:00] [Warning] Disk low", "second", "appended"}
+	for i, e := range entries {
+		if e.Line != want[i] {
+			t.Errorf("line %d = %q, want %q", i, e.Line, want[i])
+		}
+		if strings.ContainsAny(e.Line, "\r\ufeff") {
+			t.Errorf("line %d still has a BOM or CR: %q", i, e.Line)
+		}
+	}
+}
+
+func TestFileSource_StopAfterFailedStart(t *testing.T) {
+	NewFileSource(FileConfig{}).Stop() // never started
+
+	src := NewFileSource(FileConfig{Patterns: []string{filepath.Join(t.TempDir(), "missing-*.log")}})
+	if err := src.Start(context.Background()); err == nil {
+		t.Fatal("expected an error when no file matches")
+	}
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop blocked after a failed Start")
+	}
+	if _, ok := <-src.Lines(); ok {
+		t.Error("Lines should be closed after a failed Start")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package source
+
+import "os"
+
+// fileID is not available on this platform, so offsets are not persisted.
+func fileID(info os.FileInfo) (string, bool) {
+	return "", false
+}
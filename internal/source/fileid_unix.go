@@ -0,0 +1,19 @@
+//go:build unix
+
+package source
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileID returns an identifier that stays the same for a file across renames
+// and changes when a new file is created at the same path.
+func fileID(info os.FileInfo) (string, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%d", st.Dev, st.Ino), true
+}
@@ -0,0 +1,112 @@
+package source
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FramingMode selects how a byte stream is split into log lines.
+type FramingMode int
+
+const (
+	// FramingLine splits on newlines, dropping a trailing "\r".
+	FramingLine FramingMode = iota
+	// FramingLengthPrefixed reads frames of a 4-byte big-endian length
+	// followed by that many payload bytes.
+	FramingLengthPrefixed
+	// FramingNullDelimited splits on NUL bytes.
+	FramingNullDelimited
+)
+
+// MaxFrameSize is the largest length-prefixed frame read without a maximum
+// line length. A larger length most likely means the stream is not framed
+// the way it was declared, so it is reported as an error rather than
+// allocated.
+const MaxFrameSize = 64 << 20
+
+// ErrFrameTooLarge is returned for a length prefix above MaxFrameSize.
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// String returns the mode's name as accepted by ParseFramingMode.
+func (f FramingMode) String() string {
+	switch f {
+	case FramingLengthPrefixed:
+		return "length"
+	case FramingNullDelimited:
+		return "null"
+	default:
+		return "line"
+	}
+}
+
+// ParseFramingMode parses "line", "length" or "null".
+func ParseFramingMode(s string) (FramingMode, error) {
+	switch strings.ToLower(s) {
+	case "line", "":
+		return FramingLine, nil
+	case "length", "length-prefixed":
+		return FramingLengthPrefixed, nil
+	case "null", "nul", "null-delimited":
+		return FramingNullDelimited, nil
+	}
+	return FramingLine, fmt.Errorf("unknown framing %q (want line, length or null)", s)
+}
+
+// frameReader returns one frame of input at a time, or io.EOF at the end.
+type frameReader interface {
+	next() (string, error)
+}
+
+// newFrameReader returns the reader for mode. max limits the frame length
+// as for lineReader.
+func newFrameReader(r io.Reader, mode FramingMode, max int) frameReader {
+	switch mode {
+	case FramingLengthPrefixed:
+		return &lengthReader{r: bufio.NewReaderSize(r, 64*1024), max: max}
+	case FramingNullDelimited:
+		return newDelimReader(r, max, 0)
+	default:
+		return newLineReader(r, max)
+	}
+}
+
+// lengthReader reads length-prefixed frames. Payloads are converted to text
+// with invalid UTF-8 replaced, and a trailing line ending is dropped.
+type lengthReader struct {
+	r   *bufio.Reader
+	max int // maximum frame length in bytes; 0 means up to MaxFrameSize
+}
+
+func (lr *lengthReader) next() (string, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(lr.r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", fmt.Errorf("truncated frame header: %w", err)
+		}
+		return "", err
+	}
+	n := int64(binary.BigEndian.Uint32(hdr[:]))
+
+	keep := n
+	if lr.max > 0 && keep > int64(lr.max) {
+		keep = int64(lr.max)
+	} else if lr.max <= 0 && n > MaxFrameSize {
+		return "", fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, n)
+	}
+	payload := make([]byte, keep)
+	if _, err := io.ReadFull(lr.r, payload); err != nil {
+		return "", fmt.Errorf("truncated frame: want %d bytes: %w", n, io.ErrUnexpectedEOF)
+	}
+	if rest := n - keep; rest > 0 {
+		if _, err := lr.r.Discard(int(rest)); err != nil {
+			return "", fmt.Errorf("truncated frame: want %d bytes: %w", n, io.ErrUnexpectedEOF)
+		}
+	}
+
+	text := finishLine(trimLineEnding(payload), keep < n)
+	return strings.ToValidUTF8(text, "\uFFFD"), nil
+}
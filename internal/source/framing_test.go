@@ -0,0 +1,106 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lengthFrames encodes each payload as a 4-byte big-endian length followed
+// by the payload.
+func lengthFrames(payloads ...string) []byte {
+	var b bytes.Buffer
+	for _, p := range payloads {
+		binary.Write(&b, binary.BigEndian, uint32(len(p)))
+		b.WriteString(p)
+	}
+	return b.Bytes()
+}
+
+// framedLines runs a StdinSource over input and returns its lines and the
+// error it reported, if any.
+func framedLines(t *testing.T, input []byte, opts ...StdinOption) ([]string, error) {
+	t.Helper()
+	src := NewStdinSource(append(opts, WithReader(bytes.NewReader(input)))...)
+	go src.Start(context.Background())
+	var lines []string
+	for _, e := range stdinCollectLines(t, src, 2*time.Second) {
+		lines = append(lines, e.Line)
+	}
+	return lines, <-src.Errors()
+}
+
+func TestFraming_LengthPrefixed(t *testing.T) {
+	input := lengthFrames(`{"msg":"one"}`, "two\nstill two", "", "three\n")
+	lines, err := framedLines(t, input, WithFraming(FramingLengthPrefixed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{`{"msg":"one"}`, "two\nstill two", "", "three"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestFraming_LengthPrefixedTruncated(t *testing.T) {
+	input := lengthFrames("complete", "cut short")
+	input = input[:len(input)-3]
+	lines, err := framedLines(t, input, WithFraming(FramingLengthPrefixed))
+	if len(lines) != 1 || lines[0] != "complete" {
+		t.Errorf("lines = %q, want only the complete frame", lines)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	// A partial length header is truncated too.
+	input = append(lengthFrames("ok"), 0, 0)
+	if _, err := framedLines(t, input, WithFraming(FramingLengthPrefixed)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("partial header: error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestFraming_LengthPrefixedLimits(t *testing.T) {
+	input := lengthFrames("0123456789", "next")
+	lines, err := framedLines(t, input, WithFraming(FramingLengthPrefixed), WithMaxLineLength(4))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "0123"+TruncatedMarker || lines[1] != "next" {
+		t.Errorf("lines = %q, want the first frame truncated and the second intact", lines)
+	}
+
+	huge := []byte{0xff, 0xff, 0xff, 0xff}
+	if _, err := framedLines(t, huge, WithFraming(FramingLengthPrefixed)); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("error = %v, want ErrFrameTooLarge", err)
+	}
+}
+
+func TestFraming_NullDelimited(t *testing.T) {
+	input := []byte("first\x00multi\nline\r\n\x00\x00last")
+	lines, err := framedLines(t, input, WithFraming(FramingNullDelimited))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"first", "multi\nline\r\n", "", "last"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestParseFramingMode(t *testing.T) {
+	for _, mode := range []FramingMode{FramingLine, FramingLengthPrefixed, FramingNullDelimited} {
+		got, err := ParseFramingMode(mode.String())
+		if err != nil || got != mode {
+			t.Errorf("ParseFramingMode(%q) = %v, %v", mode.String(), got, err)
+		}
+	}
+	if _, err := ParseFramingMode("protobuf"); err == nil {
+		t.Error("expected error for unknown framing")
+	}
+}
@@ -0,0 +1,103 @@
+package source
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// recursiveWildcard is the path segment matching any number of directories.
+const recursiveWildcard = "**"
+
+// expandGlob returns the paths matching pattern. Patterns without a "**"
+// segment are expanded by filepath.Glob. Otherwise "**" matches zero or
+// more directories: the directory tree below the pattern's leading
+// segments without wildcards is walked, at most maxDepth levels deep unless
+// maxDepth is 0, and every file whose path matches is returned in lexical
+// order. Symbolic links to directories are not followed.
+func expandGlob(pattern string, maxDepth int) ([]string, error) {
+	sep := string(filepath.Separator)
+	segs := strings.Split(filepath.Clean(pattern), sep)
+	recursive := false
+	for _, s := range segs {
+		if s == recursiveWildcard {
+			recursive = true
+		} else if _, err := filepath.Match(s, ""); err != nil {
+			return nil, err
+		}
+	}
+	if !recursive {
+		return filepath.Glob(pattern)
+	}
+
+	fixed := 0
+	for fixed < len(segs)-1 && !hasGlobMeta(segs[fixed]) {
+		fixed++
+	}
+	root := strings.Join(segs[:fixed], sep)
+	switch {
+	case root == "" && filepath.IsAbs(pattern):
+		root = sep
+	case root == "":
+		root = "."
+	}
+	rest := segs[fixed:]
+
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped, like filepath.Glob does.
+			if d != nil && d.IsDir() && path != root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return nil
+		}
+		name := strings.Split(rel, sep)
+		if d.IsDir() {
+			if maxDepth > 0 && len(name) >= maxDepth+1 {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if matchSegments(rest, name) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchSegments reports whether the path segments name match the pattern
+// segments pat, where "**" stands for any number of segments.
+func matchSegments(pat, name []string) bool {
+	if len(pat) == 0 {
+		return len(name) == 0
+	}
+	if pat[0] == recursiveWildcard {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pat[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pat[0], name[0])
+	return ok && matchSegments(pat[1:], name[1:])
+}
+
+// hasGlobMeta reports whether s holds any of the special characters of a
+// filepath.Match pattern.
+func hasGlobMeta(s string) bool {
+	magic := `*?[`
+	if filepath.Separator != '\\' {
+		magic = `*?[\`
+	}
+	return strings.ContainsAny(s, magic)
+}
@@ -0,0 +1,72 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// globTree creates files at the given slash-separated paths below a new
+// temporary directory and returns it.
+func globTree(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := globTree(t,
+		"app.log",
+		"notes.txt",
+		"api/api.log",
+		"api/old/api.log.1",
+		"api/v2/deep/api.log",
+		"db/db.log",
+		"db/logs/slow.log",
+	)
+	tests := []struct {
+		pattern  string
+		maxDepth int
+		want     string
+	}{
+		{"**/*.log", 0, "api/api.log,api/v2/deep/api.log,app.log,db/db.log,db/logs/slow.log"},
+		{"api/**/*.log", 0, "api/api.log,api/v2/deep/api.log"},
+		{"**/logs/*.log", 0, "db/logs/slow.log"},
+		{"**/api.log*", 0, "api/api.log,api/old/api.log.1,api/v2/deep/api.log"},
+		{"**/*.log", 1, "api/api.log,app.log,db/db.log"},
+		{"**", 0, "api/api.log,api/old/api.log.1,api/v2/deep/api.log,app.log,db/db.log,db/logs/slow.log,notes.txt"},
+		// Single-star patterns keep filepath.Glob's behavior.
+		{"*/*.log", 0, "api/api.log,db/db.log"},
+		{"*.log", 0, "app.log"},
+		{"nothing/**/*.log", 0, ""},
+	}
+	for _, tt := range tests {
+		matches, err := expandGlob(filepath.Join(dir, filepath.FromSlash(tt.pattern)), tt.maxDepth)
+		if err != nil {
+			t.Errorf("expandGlob(%q): %v", tt.pattern, err)
+			continue
+		}
+		var got []string
+		for _, m := range matches {
+			rel, _ := filepath.Rel(dir, m)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("expandGlob(%q, %d) = %s, want %s", tt.pattern, tt.maxDepth, strings.Join(got, ","), tt.want)
+		}
+	}
+
+	if _, err := expandGlob(filepath.Join(dir, "**", "[.log"), 0); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,352 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PodEventType says whether a pod appeared or went away.
+type PodEventType int
+
+const (
+	// PodAdded reports a running pod matching the selector. It may be
+	// reported more than once for the same pod.
+	PodAdded PodEventType = iota
+	// PodDeleted reports that a pod is gone.
+	PodDeleted
+)
+
+// PodEvent is a change to the set of pods a K8sSource follows.
+type PodEvent struct {
+	Type      PodEventType
+	Namespace string
+	Name      string
+	// UID tells a recreated pod apart from its predecessor of the same name.
+	UID string
+}
+
+// PodWatcher reports the pods matching a label selector as they come and go.
+type PodWatcher interface {
+	// Watch sends an event for every pod already present and then for each
+	// change, until ctx is cancelled or the watch ends. An empty namespace
+	// means the current one. Watch must not close events.
+	Watch(ctx context.Context, namespace, selector string, events chan<- PodEvent) error
+}
+
+// PodLogOpener opens the followed log stream of a pod.
+type PodLogOpener interface {
+	// OpenLogs returns the pod's log output from since on, or all of it if
+	// since is zero. Lines are written as "kubectl logs --prefix
+	// --timestamps" writes them, "[pod/NAME/CONTAINER] TIMESTAMP line";
+	// lines without the prefix or the timestamp are taken as they are. The
+	// stream ends when the pod's containers exit; closing it stops
+	// following.
+	OpenLogs(ctx context.Context, namespace, pod string, since time.Time) (io.ReadCloser, error)
+}
+
+// PodPositions remembers the timestamp of the last line read from each
+// container of each pod, so that a K8sSource replacing another, as a
+// RetryingSource creates one after the watch ends, resumes the pods' logs
+// where they stopped rather than reading them again. The zero PodPositions
+// is ready to use, and it is safe for concurrent use.
+type PodPositions struct {
+	mu   sync.Mutex
+	last map[string]map[string]time.Time // by pod UID and container
+}
+
+// since returns the time to reopen the pod with UID uid from: the earliest
+// of its containers' positions, or zero if none was read.
+func (p *PodPositions) since(uid string) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var since time.Time
+	for _, t := range p.last[uid] {
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// advance records a line of container of the pod with UID uid written at
+// t, reporting false if a line that late was read already.
+func (p *PodPositions) advance(uid, container string, t time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last == nil {
+		p.last = make(map[string]map[string]time.Time)
+	}
+	if p.last[uid] == nil {
+		p.last[uid] = make(map[string]time.Time)
+	}
+	if !t.After(p.last[uid][container]) {
+		return false
+	}
+	p.last[uid][container] = t
+	return true
+}
+
+// K8sConfig holds configuration for a Kubernetes source.
+type K8sConfig struct {
+	// Namespace to watch; empty means kubectl's current namespace.
+	Namespace string
+	// Selector is a label selector such as "app=api,tier!=canary".
+	Selector string
+	// Watcher and Logs default to running kubectl.
+	Watcher PodWatcher
+	Logs    PodLogOpener
+	// MaxLineLength truncates longer lines; 0 keeps lines of any length.
+	MaxLineLength int
+	// BufferSize is the capacity of the lines channel (default
+	// DefaultBufferSize).
+	BufferSize int
+	// Positions is where the pods' logs were read up to; sources sharing
+	// it resume one another's streams. Nil reads every pod's log from the
+	// start.
+	Positions *PodPositions
+}
+
+// K8sSource follows the logs of every pod matching a label selector. Pods
+// are watched, so a pod recreated by its deployment (under a new UID) is
+// picked up again and the stream survives restarts. Entries are tagged with
+// "namespace" and "pod", and their Source is "namespace/pod". When a pod is
+// deleted a "--- pod NAME terminated ---" entry marks the end of its logs.
+type K8sSource struct {
+	config  K8sConfig
+	lines   chan LogEntry
+	errs    chan error
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	emitted atomic.Int64
+}
+
+// podStream is one pod's running log stream.
+type podStream struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewK8sSource creates a K8sSource.
+func NewK8sSource(cfg K8sConfig) *K8sSource {
+	if cfg.Watcher == nil || cfg.Logs == nil {
+		kc := kubectl{}
+		if cfg.Watcher == nil {
+			cfg.Watcher = kc
+		}
+		if cfg.Logs == nil {
+			cfg.Logs = kc
+		}
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	if cfg.Positions == nil {
+		cfg.Positions = &PodPositions{}
+	}
+	return &K8sSource{
+		config:  cfg,
+		lines:   make(chan LogEntry, cfg.BufferSize),
+		errs:    make(chan error, 32),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (k *K8sSource) Lines() <-chan LogEntry { return k.lines }
+func (k *K8sSource) Errors() <-chan error   { return k.errs }
+
+// Start begins watching pods and returns immediately. The lines channel is
+// closed once the watch has ended and every pod's stream has finished, or
+// after ctx is cancelled.
+func (k *K8sSource) Start(ctx context.Context) error {
+	if k.config.Selector == "" {
+		close(k.lines)
+		close(k.errs)
+		close(k.stopped)
+		return errors.New("k8s source: empty label selector")
+	}
+	ctx, k.cancel = context.WithCancel(ctx)
+
+	events := make(chan PodEvent)
+	go func() {
+		defer close(events)
+		if err := k.config.Watcher.Watch(ctx, k.config.Namespace, k.config.Selector, events); err != nil && ctx.Err() == nil {
+			k.sendError(fmt.Errorf("watching pods %q: %w", k.config.Selector, err))
+		}
+	}()
+	go k.run(ctx, events)
+	return nil
+}
+
+// Stop cancels the watch and every pod stream and waits for them to finish.
+func (k *K8sSource) Stop() error {
+	if k.cancel != nil {
+		k.cancel()
+	}
+	<-k.stopped
+	return nil
+}
+
+// Reload is not supported: pod logs cannot be re-read from the beginning.
+func (k *K8sSource) Reload() error { return ErrReloadUnsupported }
+
+// Stats reports the lines emitted so far and the lines channel's fill.
+func (k *K8sSource) Stats() SourceStats {
+	return SourceStats{
+		Emitted:   k.emitted.Load(),
+		BufferLen: int64(len(k.lines)),
+		BufferCap: int64(cap(k.lines)),
+	}
+}
+
+// run starts and stops pod streams as events arrive. Streams are keyed by
+// UID so that a recreated pod gets a new stream even under the same name.
+// Once the watch ends, the streams still running are followed to their end.
+func (k *K8sSource) run(ctx context.Context, events <-chan PodEvent) {
+	var wg sync.WaitGroup
+	streams := map[string]podStream{}
+	defer func() {
+		wg.Wait()
+		for _, ps := range streams {
+			ps.cancel()
+		}
+		close(k.lines)
+		close(k.errs)
+		close(k.stopped)
+	}()
+
+	for ev := range events {
+		switch ev.Type {
+		case PodAdded:
+			if _, ok := streams[ev.UID]; ok {
+				continue
+			}
+			sctx, cancel := context.WithCancel(ctx)
+			ps := podStream{cancel: cancel, done: make(chan struct{})}
+			streams[ev.UID] = ps
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(ps.done)
+				k.follow(sctx, ev)
+			}()
+		case PodDeleted:
+			ps, ok := streams[ev.UID]
+			if !ok {
+				continue
+			}
+			delete(streams, ev.UID)
+			ps.cancel()
+			<-ps.done
+			k.emit(ctx, LogEntry{
+				Line:   fmt.Sprintf("--- pod %s terminated ---", ev.Name),
+				Source: podSource(ev),
+				Tags:   podTags(ev),
+			})
+		}
+	}
+}
+
+// follow emits one pod's log lines until its stream ends or ctx is
+// cancelled. The stream starts where the pod's positions say it was read
+// to; lines up to each container's position are skipped, since the time a
+// stream is reopened from is only precise to the second.
+func (k *K8sSource) follow(ctx context.Context, ev PodEvent) {
+	pos := k.config.Positions
+	rc, err := k.config.Logs.OpenLogs(ctx, ev.Namespace, ev.Name, pos.since(ev.UID))
+	if err != nil {
+		if ctx.Err() == nil {
+			k.sendError(fmt.Errorf("opening logs of pod %s: %w", podSource(ev), err))
+		}
+		return
+	}
+	// Closing the stream unblocks a pending read once ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { rc.Close() })
+	defer func() {
+		if stop() {
+			rc.Close()
+		}
+	}()
+
+	src, tags := podSource(ev), podTags(ev)
+	lr := newLineReader(rc, k.config.MaxLineLength)
+	for {
+		line, err := lr.next()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				k.sendError(fmt.Errorf("reading logs of pod %s: %w", src, err))
+			}
+			return
+		}
+		container, t, rest := splitPodLine(line)
+		if !t.IsZero() {
+			if !pos.advance(ev.UID, container, t) {
+				continue // read before the stream was reopened
+			}
+			line = rest
+		}
+		if !k.emit(ctx, LogEntry{Line: line, Source: src, Tags: tags}) {
+			return
+		}
+	}
+}
+
+// splitPodLine splits a line of OpenLogs into the container it came from,
+// its timestamp and the line as the container wrote it. A line lacking the
+// timestamp is returned as it is, with a zero time.
+func splitPodLine(line string) (container string, t time.Time, rest string) {
+	rest = line
+	if strings.HasPrefix(rest, "[pod/") {
+		if end := strings.Index(rest, "] "); end >= 0 {
+			prefix := rest[len("[pod/"):end]
+			if i := strings.LastIndexByte(prefix, '/'); i >= 0 {
+				container, rest = prefix[i+1:], rest[end+2:]
+			}
+		}
+	}
+	stamp, msg, ok := strings.Cut(rest, " ")
+	if !ok {
+		stamp, msg = rest, ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return "", time.Time{}, line
+	}
+	return container, t, msg
+}
+
+// emit sends entry, reporting false if ctx was cancelled first.
+func (k *K8sSource) emit(ctx context.Context, entry LogEntry) bool {
+	select {
+	case k.lines <- entry:
+		k.emitted.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (k *K8sSource) sendError(err error) {
+	select {
+	case k.errs <- err:
+	default:
+	}
+}
+
+// podSource returns the "namespace/pod" source name of ev's pod.
+func podSource(ev PodEvent) string {
+	if ev.Namespace == "" {
+		return ev.Name
+	}
+	return ev.Namespace + "/" + ev.Name
+}
+
+// podTags returns the tags attached to ev's pod's entries.
+func podTags(ev PodEvent) map[string]string {
+	return map[string]string{"namespace": ev.Namespace, "pod": ev.Name}
+}
@@ -0,0 +1,396 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeInformer replays the pod events the test sends on its channel.
+type fakeInformer struct {
+	events chan PodEvent
+}
+
+func (f *fakeInformer) Watch(ctx context.Context, namespace, selector string, out chan<- PodEvent) error {
+	for {
+		select {
+		case ev, ok := <-f.events:
+			if !ok {
+				return nil
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// fakePodLogs hands out a pipe per opened pod stream so the test can write
+// log lines and see when a stream was closed.
+type fakePodLogs struct {
+	mu      sync.Mutex
+	writers map[string]*io.PipeWriter
+	closed  map[string]bool
+	opened  chan string
+}
+
+func newFakePodLogs() *fakePodLogs {
+	return &fakePodLogs{
+		writers: map[string]*io.PipeWriter{},
+		closed:  map[string]bool{},
+		opened:  make(chan string, 10),
+	}
+}
+
+func (f *fakePodLogs) OpenLogs(ctx context.Context, namespace, pod string, since time.Time) (io.ReadCloser, error) {
+	r, w := io.Pipe()
+	f.mu.Lock()
+	f.writers[pod] = w
+	f.mu.Unlock()
+	f.opened <- pod
+	return &fakeStream{PipeReader: r, onClose: func() {
+		f.mu.Lock()
+		f.closed[pod] = true
+		f.mu.Unlock()
+	}}, nil
+}
+
+func (f *fakePodLogs) write(t *testing.T, pod, text string) {
+	t.Helper()
+	f.mu.Lock()
+	w := f.writers[pod]
+	f.mu.Unlock()
+	if _, err := io.WriteString(w, text); err != nil {
+		t.Fatalf("writing to %s: %v", pod, err)
+	}
+}
+
+func (f *fakePodLogs) isClosed(pod string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed[pod]
+}
+
+type fakeStream struct {
+	*io.PipeReader
+	onClose func()
+}
+
+func (s *fakeStream) Close() error {
+	s.onClose()
+	return s.PipeReader.Close()
+}
+
+func waitOpened(t *testing.T, logs *fakePodLogs, want string) {
+	t.Helper()
+	select {
+	case pod := <-logs.opened:
+		if pod != want {
+			t.Fatalf("opened stream of %q, want %q", pod, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stream of %s was not opened", want)
+	}
+}
+
+func nextEntry(t *testing.T, src Source) LogEntry {
+	t.Helper()
+	select {
+	case e, ok := <-src.Lines():
+		if !ok {
+			t.Fatal("lines channel closed")
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a line")
+	}
+	return LogEntry{}
+}
+
+func TestK8sSource_FollowsPodsAcrossRecreation(t *testing.T) {
+	informer := &fakeInformer{events: make(chan PodEvent)}
+	logs := newFakePodLogs()
+	src := NewK8sSource(K8sConfig{Selector: "app=api", Watcher: informer, Logs: logs})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "prod", Name: "api-1", UID: "u1"}
+	waitOpened(t, logs, "api-1")
+	logs.write(t, "api-1", "hello from one\n")
+	e := nextEntry(t, src)
+	if e.Line != "hello from one" || e.Source != "prod/api-1" {
+		t.Errorf("entry = %+v, want the line from prod/api-1", e)
+	}
+	if f := e.ApplyTags(nil); f["namespace"] != "prod" || f["pod"] != "api-1" {
+		t.Errorf("tags = %v, want namespace and pod", f)
+	}
+
+	// A repeated add of the same pod does not open a second stream.
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "prod", Name: "api-1", UID: "u1"}
+
+	informer.events <- PodEvent{Type: PodDeleted, Namespace: "prod", Name: "api-1", UID: "u1"}
+	if e := nextEntry(t, src); e.Line != "--- pod api-1 terminated ---" || e.Source != "prod/api-1" {
+		t.Errorf("entry = %+v, want the termination marker", e)
+	}
+	if !logs.isClosed("api-1") {
+		t.Error("stream of the deleted pod was not closed")
+	}
+
+	// The deployment recreates the pod under the same name.
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "prod", Name: "api-1", UID: "u2"}
+	waitOpened(t, logs, "api-1")
+	logs.write(t, "api-1", "back again\n")
+	if e := nextEntry(t, src); e.Line != "back again" {
+		t.Errorf("entry = %+v, want the recreated pod's line", e)
+	}
+	select {
+	case pod := <-logs.opened:
+		t.Errorf("unexpected extra stream for %s", pod)
+	default:
+	}
+}
+
+func TestK8sSource_MergesPods(t *testing.T) {
+	informer := &fakeInformer{events: make(chan PodEvent)}
+	logs := newFakePodLogs()
+	src := NewK8sSource(K8sConfig{Selector: "app=api", Watcher: informer, Logs: logs})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "ns", Name: "a", UID: "ua"}
+	waitOpened(t, logs, "a")
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "ns", Name: "b", UID: "ub"}
+	waitOpened(t, logs, "b")
+	logs.write(t, "a", "from a\n")
+	logs.write(t, "b", "from b\n")
+
+	got := map[string]string{}
+	for i := 0; i < 2; i++ {
+		e := nextEntry(t, src)
+		got[e.Source] = e.Line
+	}
+	if got["ns/a"] != "from a" || got["ns/b"] != "from b" {
+		t.Errorf("entries by source = %v", got)
+	}
+
+	if err := src.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if !logs.isClosed("a") || !logs.isClosed("b") {
+		t.Error("Stop did not close the pod streams")
+	}
+	if _, ok := <-src.Lines(); ok {
+		t.Error("lines channel still open after Stop")
+	}
+}
+
+func TestK8sSource_WatchEnd(t *testing.T) {
+	informer := &fakeInformer{events: make(chan PodEvent)}
+	logs := newFakePodLogs()
+	src := NewK8sSource(K8sConfig{Selector: "app=api", Watcher: informer, Logs: logs})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	informer.events <- PodEvent{Type: PodAdded, Namespace: "ns", Name: "a", UID: "ua"}
+	waitOpened(t, logs, "a")
+	close(informer.events)
+
+	// The running stream is still followed after the watch ended.
+	logs.write(t, "a", "last words\n")
+	if e := nextEntry(t, src); e.Line != "last words" {
+		t.Errorf("entry = %+v", e)
+	}
+	logs.writers["a"].Close()
+	select {
+	case _, ok := <-src.Lines():
+		if ok {
+			t.Error("unexpected entry after the last stream ended")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lines channel not closed after the watch and streams ended")
+	}
+}
+
+func TestK8sSource_EmptySelector(t *testing.T) {
+	src := NewK8sSource(K8sConfig{Watcher: &fakeInformer{}, Logs: newFakePodLogs()})
+	if err := src.Start(context.Background()); err == nil {
+		t.Error("expected an error for an empty selector")
+	}
+	if err := src.Stop(); err != nil {
+		t.Error(err)
+	}
+	if !errors.Is(src.Reload(), ErrReloadUnsupported) {
+		t.Error("Reload should be unsupported")
+	}
+}
+
+func TestDecodePodEvents(t *testing.T) {
+	input := `{"type":"ADDED","object":{"metadata":{"name":"p1","namespace":"ns","uid":"u1"},"status":{"phase":"Pending"}}}
+{"type":"MODIFIED","object":{"metadata":{"name":"p1","namespace":"ns","uid":"u1"},"status":{"phase":"Running"}}}
+{
+  "type": "DELETED",
+  "object": {"metadata": {"name": "p1", "namespace": "ns", "uid": "u1"}, "status": {"phase": "Running"}}
+}
+`
+	events := make(chan PodEvent, 10)
+	if err := decodePodEvents(context.Background(), strings.NewReader(input), events); err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+	var got []PodEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	want := []PodEvent{
+		{Type: PodAdded, Namespace: "ns", Name: "p1", UID: "u1"},
+		{Type: PodDeleted, Namespace: "ns", Name: "p1", UID: "u1"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	if err := decodePodEvents(context.Background(), strings.NewReader("{broken"), events); err == nil {
+		t.Error("expected an error for malformed output")
+	}
+}
+
+// replayingPodLogs serves each pod's whole log, as far as it has grown, on
+// every open and then ends the stream, as a "kubectl logs" connection does
+// when it drops and --since-time is only precise to the second.
+type replayingPodLogs struct {
+	mu    sync.Mutex
+	logs  map[string][]string // by pod, each line with its prefix
+	shown map[string]int      // lines of each pod served per open so far
+	since map[string][]time.Time
+}
+
+func (f *replayingPodLogs) OpenLogs(ctx context.Context, namespace, pod string, since time.Time) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.since[pod] = append(f.since[pod], since)
+	// Each connection sees two more lines of every pod.
+	f.shown[pod] = min(f.shown[pod]+2, len(f.logs[pod]))
+	return io.NopCloser(strings.NewReader(strings.Join(f.logs[pod][:f.shown[pod]], ""))), nil
+}
+
+// onceInformer reports its pods running and then ends the watch.
+type onceInformer struct{ pods []PodEvent }
+
+func (w onceInformer) Watch(ctx context.Context, namespace, selector string, out chan<- PodEvent) error {
+	for _, ev := range w.pods {
+		select {
+		case out <- ev:
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+func TestK8sSource_ResumesPodsAcrossReconnects(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	stamp := func(ms int) string { return base.Add(time.Duration(ms) * time.Millisecond).Format(time.RFC3339Nano) }
+	logs := &replayingPodLogs{
+		logs: map[string][]string{
+			// The sidecar's lines are older than the app's before them.
+			"a": {
+				"[pod/a/app] " + stamp(100) + " a1\n",
+				"[pod/a/sidecar] " + stamp(50) + " a-side1\n",
+				"[pod/a/app] " + stamp(300) + " a2\n",
+				"[pod/a/sidecar] " + stamp(200) + " a-side2\n",
+			},
+			"b": {
+				"[pod/b/app] " + stamp(150) + " b1\n",
+				"[pod/b/app] " + stamp(250) + " b2\n",
+				"[pod/b/app] " + stamp(350) + " b3\n",
+				"[pod/b/app] " + stamp(450) + " b4\n",
+			},
+		},
+		shown: map[string]int{},
+		since: map[string][]time.Time{},
+	}
+	pods := onceInformer{pods: []PodEvent{
+		{Type: PodAdded, Namespace: "ns", Name: "a", UID: "ua"},
+		{Type: PodAdded, Namespace: "ns", Name: "b", UID: "ub"},
+	}}
+	positions := &PodPositions{}
+	src := NewRetryingSource(RetryConfig{
+		Name:       "k8s",
+		MaxRetries: 2,
+		Backoff:    time.Millisecond,
+		MaxBackoff: time.Millisecond,
+		New: func() (Source, error) {
+			return NewK8sSource(K8sConfig{Selector: "app=api", Watcher: pods, Logs: logs, Positions: positions}), nil
+		},
+	})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	seen := map[string]int{}
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case e, ok := <-src.Lines():
+			if !ok {
+				done = true
+				continue
+			}
+			if !strings.HasPrefix(e.Line, "---") {
+				seen[e.Source+" "+e.Line]++
+			}
+		case <-timeout:
+			t.Fatal("the source did not give up reconnecting")
+		}
+	}
+	for _, want := range []string{"ns/a a1", "ns/a a-side1", "ns/a a2", "ns/a a-side2", "ns/b b1", "ns/b b2", "ns/b b3", "ns/b b4"} {
+		if seen[want] != 1 {
+			t.Errorf("%q emitted %d times, want once", want, seen[want])
+		}
+	}
+	if len(seen) != 8 {
+		t.Errorf("lines emitted = %v", seen)
+	}
+	// A reopened pod starts from its least advanced container.
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if got := logs.since["a"]; len(got) < 2 || !got[0].IsZero() || !got[1].Equal(base.Add(50*time.Millisecond)) {
+		t.Errorf("pod a reopened since %v", got)
+	}
+}
+
+func TestSplitPodLine(t *testing.T) {
+	tests := []struct {
+		line, container, rest string
+		ms                    int // -1: no timestamp
+	}{
+		{"[pod/api-1/app] 2024-01-15T10:30:00.25Z hello world", "app", "hello world", 250},
+		{"2024-01-15T10:30:00Z hello", "", "hello", 0},
+		{"2024-01-15T10:30:00Z", "", "", 0},
+		{"[pod/api-1/app] no timestamp", "", "[pod/api-1/app] no timestamp", -1},
+		{"plain line", "", "plain line", -1},
+	}
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	for _, tt := range tests {
+		container, ts, rest := splitPodLine(tt.line)
+		want := time.Time{}
+		if tt.ms >= 0 {
+			want = base.Add(time.Duration(tt.ms) * time.Millisecond)
+		}
+		if container != tt.container || rest != tt.rest || !ts.Equal(want) {
+			t.Errorf("splitPodLine(%q) = %q, %v, %q", tt.line, container, ts, rest)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// kubectl implements PodWatcher and PodLogOpener by running the kubectl
+// command, so the cluster credentials and context are whatever kubectl is
+// configured with.
+type kubectl struct{}
+
+// Watch runs "kubectl get pods --watch" and translates its events.
+func (kubectl) Watch(ctx context.Context, namespace, selector string, events chan<- PodEvent) error {
+	args := []string{"get", "pods", "--watch", "--output-watch-events", "--output", "json", "--selector", selector}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	decodeErr := decodePodEvents(ctx, out, events)
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return kubectlError(err, &stderr)
+	}
+	return decodeErr
+}
+
+// OpenLogs runs "kubectl logs --follow" for the pod's containers.
+func (kubectl) OpenLogs(ctx context.Context, namespace, pod string, since time.Time) (io.ReadCloser, error) {
+	args := []string{"logs", "--follow", "--all-containers", "--prefix", "--timestamps", pod}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	if !since.IsZero() {
+		args = append(args, "--since-time", since.UTC().Format(time.RFC3339Nano))
+	}
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &cmdReader{ReadCloser: out, cmd: cmd}, nil
+}
+
+// cmdReader is a command's output; closing it kills the command.
+type cmdReader struct {
+	io.ReadCloser
+	cmd  *exec.Cmd
+	once sync.Once
+}
+
+func (c *cmdReader) Close() error {
+	c.once.Do(func() {
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	})
+	return nil
+}
+
+// kubectlError adds kubectl's error output to err.
+func kubectlError(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("kubectl: %s: %w", msg, err)
+	}
+	return fmt.Errorf("kubectl: %w", err)
+}
+
+// watchEvent is one event of "kubectl get pods --watch --output-watch-events
+// --output json".
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+			UID       string `json:"uid"`
+		} `json:"metadata"`
+		Status struct {
+			Phase string `json:"phase"`
+		} `json:"status"`
+	} `json:"object"`
+}
+
+// decodePodEvents reads watch events from r and sends them to events. Only
+// running pods are reported as added, since a pending pod has no logs yet.
+func decodePodEvents(ctx context.Context, r io.Reader, events chan<- PodEvent) error {
+	dec := json.NewDecoder(r)
+	for {
+		var we watchEvent
+		if err := dec.Decode(&we); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decoding watch event: %w", err)
+		}
+		ev := PodEvent{
+			Namespace: we.Object.Metadata.Namespace,
+			Name:      we.Object.Metadata.Name,
+			UID:       we.Object.Metadata.UID,
+		}
+		switch {
+		case we.Type == "DELETED":
+			ev.Type = PodDeleted
+		case (we.Type == "ADDED" || we.Type == "MODIFIED") && we.Object.Status.Phase == "Running":
+			ev.Type = PodAdded
+		default:
+			continue
+		}
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncatedMarker is appended to lines cut short by a maximum line length.
+const TruncatedMarker = "…[truncated]"
+
+// lineReader reads newline-terminated lines of any length. Unlike
+// bufio.Scanner it never fails on long lines: without a limit the whole line
+// is returned, with one the line is cut at max bytes, marked with
+// TruncatedMarker, and the rest of it is skipped without being buffered.
+type lineReader struct {
+	r     *bufio.Reader
+	max   int   // maximum line length in bytes; 0 means unlimited
+	delim byte  // line terminator; '\n' also strips a preceding '\r'
+	err   error // read error held back until the partial line before it was returned
+
+	// consumed counts the input bytes of the lines returned, terminators
+	// and skipped parts of truncated lines included.
+	consumed int64
+}
+
+func newLineReader(r io.Reader, max int) *lineReader {
+	return newDelimReader(r, max, '\n')
+}
+
+// newDelimReader returns a lineReader for records terminated by delim.
+func newDelimReader(r io.Reader, max int, delim byte) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, 64*1024), max: max, delim: delim}
+}
+
+// next returns the next line without its line ending. A final line without
+// a trailing newline is returned as well. At the end of input it returns
+// io.EOF.
+func (lr *lineReader) next() (string, error) {
+	if lr.err != nil {
+		err := lr.err
+		lr.err = nil
+		return "", err
+	}
+
+	var line []byte
+	truncated := false
+	for {
+		chunk, err := lr.r.ReadSlice(lr.delim)
+		lr.consumed += int64(len(chunk))
+		if err == nil {
+			chunk = lr.trimDelim(chunk)
+		}
+		switch {
+		case truncated:
+			// Skip the rest of an over-long line.
+		case lr.max > 0 && len(line)+len(chunk) > lr.max:
+			line = append(line, chunk[:lr.max-len(line)]...)
+			truncated = true
+		default:
+			line = append(line, chunk...)
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil && len(line) == 0 {
+			return "", err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			lr.err = err
+		}
+		if lr.delim == '\n' && !truncated {
+			// A "\r\n" may have been split across reads.
+			line = bytes.TrimSuffix(line, []byte("\r"))
+		}
+		return finishLine(line, truncated), nil
+	}
+}
+
+// broken reports whether the line last returned was cut short by a read
+// error other than the end of the input; the error is returned next.
+func (lr *lineReader) broken() bool {
+	return lr.err != nil
+}
+
+// trimDelim drops the terminator from a complete record.
+func (lr *lineReader) trimDelim(b []byte) []byte {
+	if lr.delim == '\n' {
+		return trimLineEnding(b)
+	}
+	return b[:len(b)-1]
+}
+
+// trimLineEnding drops a trailing "\n" or "\r\n", like bufio.ScanLines.
+func trimLineEnding(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
+
+// utf8BOM is the byte order mark Windows tools often put at the start of
+// UTF-8 text.
+const utf8BOM = "\ufeff"
+
+// stripBOM removes a leading UTF-8 byte order mark. It is applied to the
+// first line of an input only.
+func stripBOM(line string) string {
+	return strings.TrimPrefix(line, utf8BOM)
+}
+
+// finishLine converts a line to a string. A truncated line loses any partial
+// UTF-8 sequence at the cut and gets the marker.
+func finishLine(line []byte, truncated bool) string {
+	if !truncated {
+		return string(line)
+	}
+	for len(line) > 0 && !utf8.Valid(line[max(len(line)-utf8.UTFMax, 0):]) {
+		line = line[:len(line)-1]
+	}
+	return string(line) + TruncatedMarker
+}
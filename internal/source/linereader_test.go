@@ -0,0 +1,58 @@
+package source
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestLineReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		want  []string
+	}{
+		{"crlf", "a\r\nb\n", 0, []string{"a", "b"}},
+		{"no trailing newline", "a\nb", 0, []string{"a", "b"}},
+		{"empty lines", "\n\nx\n", 0, []string{"", "", "x"}},
+		{"truncated", "abcdef\nxy\n", 3, []string{"abc" + TruncatedMarker, "xy"}},
+		{"exact length", "abc\n", 3, []string{"abc"}},
+		{"utf8 boundary", "aé\nz\n", 2, []string{"a" + TruncatedMarker, "z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := newLineReader(strings.NewReader(tt.input), tt.max)
+			var got []string
+			for {
+				line, err := lr.next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, line)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineReader_BrokenLine(t *testing.T) {
+	boom := errors.New("connection reset")
+	lr := newLineReader(io.MultiReader(strings.NewReader("one\r\ntw"), iotest.ErrReader(boom)), 0)
+	if line, err := lr.next(); line != "one" || err != nil || lr.broken() || lr.consumed != 5 {
+		t.Fatalf("next() = %q, %v; broken %v, consumed %d", line, err, lr.broken(), lr.consumed)
+	}
+	if line, err := lr.next(); line != "tw" || err != nil || !lr.broken() {
+		t.Fatalf("next() = %q, %v; broken %v, want the partial line", line, err, lr.broken())
+	}
+	if _, err := lr.next(); !errors.Is(err, boom) {
+		t.Errorf("next() error = %v, want %v", err, boom)
+	}
+}
@@ -0,0 +1,396 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRetries is how many consecutive failed connections a
+	// RetryingSource makes before giving up.
+	DefaultRetries = 5
+	// DefaultRetryBackoff is the delay before the first reconnect; it
+	// doubles with each further failure, up to DefaultRetryMaxBackoff.
+	DefaultRetryBackoff    = 500 * time.Millisecond
+	DefaultRetryMaxBackoff = 30 * time.Second
+	// DefaultRetryOverlap is how many of the latest lines a RetryingSource
+	// remembers to recognize the ones a reconnected source sends again.
+	DefaultRetryOverlap = 1000
+)
+
+// errSourceEnded is the failure of a connection whose lines simply ran out.
+var errSourceEnded = errors.New("source ended")
+
+// RetryConfig holds configuration for a retrying source.
+type RetryConfig struct {
+	// New creates the source to read from. It is called by Start and again
+	// for every reconnect, and must return a source that has not been
+	// started.
+	New func() (Source, error)
+	// Name identifies the source in reconnect markers and errors.
+	Name string
+	// MaxRetries is the number of consecutive failed connections before the
+	// source gives up (default DefaultRetries). A connection that delivers
+	// any line resets the count.
+	MaxRetries int
+	// Backoff and MaxBackoff bound the delay between connections (defaults
+	// DefaultRetryBackoff and DefaultRetryMaxBackoff). Each delay is picked
+	// at random from its upper half, so clients do not reconnect in step.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Overlap is how many of the latest lines are remembered to recognize
+	// a reconnected source sending them again (default
+	// DefaultRetryOverlap).
+	Overlap int
+	// BufferSize is the capacity of the lines channel (default
+	// DefaultBufferSize).
+	BufferSize int
+}
+
+// RetryingSource wraps sources that can drop, such as network streams, and
+// reconnects when one ends before the context is cancelled: its lines
+// channel closes, or it fails to start. Each reconnect creates a new source
+// with RetryConfig.New after an exponential backoff with jitter, and is
+// marked by a "--- reconnected to NAME ---" entry. Lines the new source
+// sends again, such as the tail of the log that a fresh stream replays, are
+// skipped, so every line is emitted once. The wrapped sources' errors are
+// passed on.
+type RetryingSource struct {
+	config  RetryConfig
+	lines   chan LogEntry
+	errs    chan error
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	emitted atomic.Int64
+
+	mu  sync.Mutex
+	cur Source // the connected source, for Reload
+
+	// history holds the latest lines emitted, oldest first; it is only
+	// used by run.
+	history []LogEntry
+}
+
+// NewRetryingSource creates a RetryingSource.
+func NewRetryingSource(cfg RetryConfig) *RetryingSource {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultRetries
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if cfg.Overlap <= 0 {
+		cfg.Overlap = DefaultRetryOverlap
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	return &RetryingSource{
+		config:  cfg,
+		lines:   make(chan LogEntry, cfg.BufferSize),
+		errs:    make(chan error, 32),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (r *RetryingSource) Lines() <-chan LogEntry { return r.lines }
+func (r *RetryingSource) Errors() <-chan error   { return r.errs }
+
+// Start begins connecting in the background and returns immediately.
+func (r *RetryingSource) Start(ctx context.Context) error {
+	if r.config.New == nil {
+		close(r.lines)
+		close(r.errs)
+		close(r.stopped)
+		return errors.New("retrying source: no source factory")
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	go r.run(ctx)
+	return nil
+}
+
+// Stop stops the connected source and waits for the source to finish.
+func (r *RetryingSource) Stop() error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	<-r.stopped
+	return nil
+}
+
+// Reload reloads the connected source.
+func (r *RetryingSource) Reload() error {
+	r.mu.Lock()
+	cur := r.cur
+	r.mu.Unlock()
+	if cur == nil {
+		return ErrReloadUnsupported
+	}
+	return cur.Reload()
+}
+
+// Stats reports the lines emitted so far and the lines channel's fill.
+func (r *RetryingSource) Stats() SourceStats {
+	return SourceStats{
+		Emitted:   r.emitted.Load(),
+		BufferLen: int64(len(r.lines)),
+		BufferCap: int64(cap(r.lines)),
+	}
+}
+
+// run connects, reconnecting after failures, until the retries are
+// exhausted or ctx is cancelled.
+func (r *RetryingSource) run(ctx context.Context) {
+	defer func() {
+		close(r.lines)
+		close(r.errs)
+		close(r.stopped)
+	}()
+
+	policy := retryPolicy{maxRetries: r.config.MaxRetries, backoff: r.config.Backoff, maxBackoff: r.config.MaxBackoff}
+	for attempt := 0; ; attempt++ {
+		delivered, err := r.connect(ctx, attempt > 0)
+		if ctx.Err() != nil {
+			return
+		}
+		if !policy.failed(delivered) {
+			r.sendError(fmt.Errorf("%s: giving up after %d attempts: %w", r.config.Name, policy.failures, err))
+			return
+		}
+		if !policy.wait(ctx) {
+			return
+		}
+	}
+}
+
+// retryPolicy counts the consecutive failures of a source's connections
+// and spaces the connections out: RetryingSource and URLSource retry
+// alike.
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	failures   int
+}
+
+// failed records a failed connection, reporting false once more than
+// maxRetries failed in a row. A connection that made progress before it
+// failed, such as delivering lines, starts the count over.
+func (p *retryPolicy) failed(progressed bool) bool {
+	if progressed {
+		p.failures = 0
+	}
+	p.failures++
+	return p.failures <= p.maxRetries
+}
+
+// wait waits for the delay before the next connection, reporting false if
+// ctx is cancelled first.
+func (p *retryPolicy) wait(ctx context.Context) bool {
+	select {
+	case <-time.After(p.delay()):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// delay returns the delay after the current run of failures: a random
+// duration in the upper half of the exponential backoff.
+func (p *retryPolicy) delay() time.Duration {
+	d := p.backoff
+	for i := 1; i < p.failures && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.maxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// connect creates a source and emits its lines until they run out or ctx
+// is cancelled. It reports whether any line came through, and why the
+// connection ended. On a reconnect the reconnect marker precedes the first
+// line, and lines already emitted are skipped.
+func (r *RetryingSource) connect(ctx context.Context, reconnect bool) (bool, error) {
+	src, err := r.config.New()
+	if err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	r.cur = src
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.cur = nil
+		r.mu.Unlock()
+	}()
+
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	started := make(chan error, 1)
+	go func() { started <- src.Start(sctx) }()
+
+	var replay *replayFilter
+	if reconnect {
+		replay = &replayFilter{history: append([]LogEntry(nil), r.history...)}
+	}
+	delivered := false
+	var lastErr error
+	lines, errs := src.Lines(), src.Errors()
+	for lines != nil {
+		select {
+		case entry, ok := <-lines:
+			if !ok {
+				lines = nil
+				continue
+			}
+			if !delivered && reconnect {
+				marker := LogEntry{Line: fmt.Sprintf("--- reconnected to %s ---", r.config.Name), Source: r.config.Name}
+				if !r.send(ctx, marker) {
+					src.Stop()
+					return true, ctx.Err()
+				}
+			}
+			delivered = true
+			for _, e := range replay.filter(entry) {
+				if !r.emit(ctx, e) {
+					src.Stop()
+					return true, ctx.Err()
+				}
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			lastErr = err
+			r.sendError(err)
+		case <-ctx.Done():
+			src.Stop()
+			return delivered, ctx.Err()
+		}
+	}
+
+	// Errors sent just before the lines ran out still count.
+	for errs != nil {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			lastErr = err
+			r.sendError(err)
+		default:
+			errs = nil
+		}
+	}
+	cancel()
+	src.Stop()
+	if err := <-started; err != nil {
+		return delivered, err
+	}
+	if lastErr != nil {
+		return delivered, lastErr
+	}
+	return delivered, errSourceEnded
+}
+
+// emit sends an entry of the wrapped source, reporting false if ctx was
+// cancelled first. Lines are remembered in the history; a Reset entry
+// clears it.
+func (r *RetryingSource) emit(ctx context.Context, entry LogEntry) bool {
+	if !r.send(ctx, entry) {
+		return false
+	}
+	if entry.Reset {
+		r.history = nil
+		return true
+	}
+	r.history = append(r.history, entry)
+	if len(r.history) > r.config.Overlap {
+		r.history = r.history[len(r.history)-r.config.Overlap:]
+	}
+	r.emitted.Add(1)
+	return true
+}
+
+// send sends entry, reporting false if ctx was cancelled first.
+func (r *RetryingSource) send(ctx context.Context, entry LogEntry) bool {
+	select {
+	case r.lines <- entry:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (r *RetryingSource) sendError(err error) {
+	select {
+	case r.errs <- err:
+	default:
+	}
+}
+
+// replayFilter recognizes the lines a reconnected source sends again. The
+// first lines of the new connection are held back while they repeat a run
+// of the history; once they have repeated it up to the latest line they are
+// dropped. As soon as one does not fit, the held back lines are let through,
+// but for those repeating the latest lines. A nil replayFilter lets every
+// line through.
+type replayFilter struct {
+	history []LogEntry // lines emitted before the reconnect, oldest first
+	pending []LogEntry // lines held back
+	start   int        // history index pending repeats from
+	done    bool
+}
+
+// filter returns the entries to emit now that entry has arrived.
+func (f *replayFilter) filter(entry LogEntry) []LogEntry {
+	if f == nil || f.done {
+		return []LogEntry{entry}
+	}
+	if entry.Reset {
+		// A new start: what came before is superseded anyway.
+		f.done = true
+		return append(f.pending, entry)
+	}
+	f.pending = append(f.pending, entry)
+	for ; f.start+len(f.pending) <= len(f.history); f.start++ {
+		if sameLines(f.history[f.start:f.start+len(f.pending)], f.pending) {
+			if f.start+len(f.pending) == len(f.history) {
+				// Caught up: everything held back was emitted before.
+				f.done = true
+				f.pending = nil
+			}
+			return nil
+		}
+	}
+	f.done = true
+	pending := f.pending
+	f.pending = nil
+	for k := min(len(pending)-1, len(f.history)); k > 0; k-- {
+		if sameLines(f.history[len(f.history)-k:], pending[:k]) {
+			return pending[k:]
+		}
+	}
+	return pending
+}
+
+// sameLines reports whether a and b hold the same lines from the same
+// sources.
+func sameLines(a, b []LogEntry) bool {
+	for i := range a {
+		if a[i].Line != b[i].Line || a[i].Source != b[i].Source {
+			return false
+		}
+	}
+	return true
+}
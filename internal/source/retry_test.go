@@ -0,0 +1,216 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedSource emits its lines and then either ends, dropping the
+// connection, or stays open until stopped. A startErr makes it fail to
+// start instead.
+type scriptedSource struct {
+	NoStats
+	lines    []string
+	stayOpen bool
+	startErr error
+
+	out     chan LogEntry
+	errs    chan error
+	once    sync.Once
+	stopped chan struct{}
+}
+
+func newScriptedSource(lines []string, stayOpen bool, startErr error) *scriptedSource {
+	return &scriptedSource{
+		lines:    lines,
+		stayOpen: stayOpen,
+		startErr: startErr,
+		out:      make(chan LogEntry, len(lines)),
+		errs:     make(chan error, 1),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (s *scriptedSource) Lines() <-chan LogEntry { return s.out }
+func (s *scriptedSource) Errors() <-chan error   { return s.errs }
+func (s *scriptedSource) Reload() error          { return ErrReloadUnsupported }
+
+func (s *scriptedSource) Start(ctx context.Context) error {
+	if s.startErr != nil {
+		close(s.out)
+		return s.startErr
+	}
+	for _, l := range s.lines {
+		s.out <- LogEntry{Line: l, Source: "net"}
+	}
+	if s.stayOpen {
+		select {
+		case <-ctx.Done():
+		case <-s.stopped:
+		}
+	}
+	close(s.out)
+	return nil
+}
+
+func (s *scriptedSource) Stop() error {
+	s.once.Do(func() { close(s.stopped) })
+	return nil
+}
+
+// scriptedFactory returns a RetryConfig.New handing out conns in turn; a
+// nil conn makes New fail. It counts the calls in *calls.
+func scriptedFactory(calls *int, conns ...*scriptedSource) func() (Source, error) {
+	return func() (Source, error) {
+		i := *calls
+		*calls++
+		if i >= len(conns) || conns[i] == nil {
+			return nil, errors.New("dial failed")
+		}
+		return conns[i], nil
+	}
+}
+
+func TestRetryingSource_Reconnects(t *testing.T) {
+	calls := 0
+	src := NewRetryingSource(RetryConfig{
+		Name: "net",
+		New: scriptedFactory(&calls,
+			newScriptedSource([]string{"a", "b", "c"}, false, nil), // drops
+			nil, // fails to connect
+			newScriptedSource(nil, false, errors.New("refused")), // fails to start
+			// Replays the tail before the new lines, and stays up.
+			newScriptedSource([]string{"b", "c", "d", "e"}, true, nil),
+		),
+		Backoff: time.Millisecond,
+	})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	var got []LogEntry
+	for len(got) < 6 {
+		got = append(got, nextEntry(t, src))
+	}
+	if want := "a,b,c,--- reconnected to net ---,d,e"; lineTexts(got) != want {
+		t.Errorf("lines = %s, want %s", lineTexts(got), want)
+	}
+	if calls != 4 {
+		t.Errorf("factory called %d times, want 4", calls)
+	}
+	select {
+	case e := <-src.Lines():
+		t.Errorf("unexpected line %q after reconnecting", e.Line)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if st := src.Stats(); st.Emitted != 5 {
+		t.Errorf("Emitted = %d, want 5 (the marker is not counted)", st.Emitted)
+	}
+}
+
+func TestRetryingSource_GivesUp(t *testing.T) {
+	calls := 0
+	src := NewRetryingSource(RetryConfig{Name: "net", New: scriptedFactory(&calls), MaxRetries: 2, Backoff: time.Millisecond})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	select {
+	case _, ok := <-src.Lines():
+		if ok {
+			t.Fatal("got a line, want the lines channel closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("source did not give up")
+	}
+	err := <-src.Errors()
+	if err == nil || !strings.Contains(err.Error(), "giving up after 3 attempts") || !strings.Contains(err.Error(), "dial failed") {
+		t.Errorf("error = %v, want it to give up with the last failure", err)
+	}
+	if calls != 3 {
+		t.Errorf("factory called %d times, want 3", calls)
+	}
+}
+
+func TestRetryingSource_StopDoesNotReconnect(t *testing.T) {
+	calls := 0
+	src := NewRetryingSource(RetryConfig{
+		Name:    "net",
+		New:     scriptedFactory(&calls, newScriptedSource([]string{"a"}, true, nil)),
+		Backoff: time.Millisecond,
+	})
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if e := nextEntry(t, src); e.Line != "a" {
+		t.Fatalf("line = %q, want a", e.Line)
+	}
+	src.Stop()
+	if _, ok := <-src.Lines(); ok {
+		t.Error("lines channel still open after Stop")
+	}
+	if calls != 1 {
+		t.Errorf("factory called %d times, want 1", calls)
+	}
+}
+
+func TestReplayFilter(t *testing.T) {
+	entries := func(lines ...string) []LogEntry {
+		var out []LogEntry
+		for _, l := range lines {
+			out = append(out, LogEntry{Line: l})
+		}
+		return out
+	}
+	tests := []struct {
+		name     string
+		history  []string
+		incoming []string
+		want     string
+	}{
+		{"full replay", []string{"a", "b", "c"}, []string{"a", "b", "c", "d"}, "d"},
+		{"tail replay", []string{"a", "b", "c"}, []string{"c", "d"}, "d"},
+		{"no replay", []string{"a", "b", "c"}, []string{"d", "e"}, "d,e"},
+		{"repeated lines", []string{"tick", "tick", "x", "tick"}, []string{"tick", "y"}, "y"},
+		{"partial match", []string{"a", "b", "c"}, []string{"a", "b", "z"}, "a,b,z"},
+		{"nothing before", nil, []string{"a"}, "a"},
+	}
+	for _, tt := range tests {
+		f := &replayFilter{history: entries(tt.history...)}
+		var got []LogEntry
+		for _, e := range entries(tt.incoming...) {
+			got = append(got, f.filter(e)...)
+		}
+		if lineTexts(got) != tt.want {
+			t.Errorf("%s: emitted %s, want %s", tt.name, lineTexts(got), tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	p := retryPolicy{maxRetries: 2, backoff: 100 * time.Millisecond, maxBackoff: 300 * time.Millisecond}
+	for i, want := range []struct {
+		progressed bool
+		retry      bool
+		max        time.Duration
+	}{
+		{false, true, 100 * time.Millisecond},
+		{false, true, 200 * time.Millisecond},
+		{true, true, 100 * time.Millisecond}, // progress starts the count over
+		{false, true, 200 * time.Millisecond},
+		{false, false, 300 * time.Millisecond},
+	} {
+		if got := p.failed(want.progressed); got != want.retry {
+			t.Errorf("failure %d: failed = %v, want %v", i, got, want.retry)
+		}
+		if d := p.delay(); d < want.max/2 || d > want.max {
+			t.Errorf("failure %d: delay = %v, want within [%v, %v]", i, d, want.max/2, want.max)
+		}
+	}
+}
@@ -1,7 +1,16 @@
 // Package source provides log source readers (file, stdin, k8s, docker, ssh).
 package source
 
-import "context"
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrReloadUnsupported is returned by Reload on sources that cannot re-read
+// their input, such as stdin.
+var ErrReloadUnsupported = errors.New("source cannot be reloaded")
 
 // LogEntry represents a single log line with metadata.
 type LogEntry struct {
@@ -9,6 +18,50 @@ type LogEntry struct {
 	Line string
 	// Source identifies which file/source produced this entry.
 	Source string
+	// Stream is "stdout" or "stderr" for sources that read both output
+	// streams of a process and tell them apart; empty otherwise.
+	Stream string
+	// Tags holds static metadata attached by a TaggingSource.
+	Tags map[string]string
+	// TagsOverwrite reports whether Tags take precedence over parsed fields
+	// with the same key.
+	TagsOverwrite bool
+	// Reset marks a reload: it carries no line, and the entries emitted
+	// before it are superseded by the ones that follow.
+	Reset bool
+}
+
+// LineFilter reports whether a raw line should be emitted. Sources apply it
+// before a line reaches the Lines channel, as a cheap pre-filter ahead of the
+// parsed filtering done by the TUI.
+type LineFilter func(line string) bool
+
+// ContainsFilter returns a LineFilter keeping lines that contain substr.
+func ContainsFilter(substr string) LineFilter {
+	return func(line string) bool { return strings.Contains(line, substr) }
+}
+
+// RegexpFilter returns a LineFilter keeping lines that match re.
+func RegexpFilter(re *regexp.Regexp) LineFilter {
+	return re.MatchString
+}
+
+// ApplyTags merges the entry's tags into fields and returns the result.
+// Existing keys are kept unless TagsOverwrite is set. fields may be nil.
+func (e LogEntry) ApplyTags(fields map[string]string) map[string]string {
+	if len(e.Tags) == 0 {
+		return fields
+	}
+	if fields == nil {
+		fields = make(map[string]string, len(e.Tags))
+	}
+	for k, v := range e.Tags {
+		if _, exists := fields[k]; exists && !e.TagsOverwrite {
+			continue
+		}
+		fields[k] = v
+	}
+	return fields
 }
 
 // Source defines the interface for all log sources.
@@ -21,4 +74,46 @@ type Source interface {
 	Start(ctx context.Context) error
 	// Stop gracefully shuts down the source.
 	Stop() error
+	// Reload re-reads the input from the beginning. The source emits a
+	// Reset entry followed by the whole content again. Sources that cannot
+	// do this return ErrReloadUnsupported.
+	Reload() error
+	// Stats reports how many lines were emitted and dropped so far and how
+	// full the lines channel is. It is safe to call concurrently with
+	// reading.
+	Stats() SourceStats
+}
+
+// SourceStats is a snapshot of a source's throughput and backpressure.
+type SourceStats struct {
+	// Emitted counts lines sent to the Lines channel.
+	Emitted int64
+	// Dropped counts lines discarded because the reader could not keep up
+	// (rate limit or DropOldest backpressure).
+	Dropped int64
+	// BufferLen and BufferCap are the lines waiting in the Lines channel
+	// and its capacity.
+	BufferLen int64
+	BufferCap int64
+	// Loading reports that the source is still reading the content it
+	// starts with, such as the last lines of a file, and Scanned how many
+	// bytes that read has gone through so far.
+	Loading bool
+	Scanned int64
 }
+
+// Saturation returns BufferLen as a fraction of BufferCap, or 0 for an
+// unbuffered channel.
+func (s SourceStats) Saturation() float64 {
+	if s.BufferCap <= 0 {
+		return 0
+	}
+	return float64(s.BufferLen) / float64(s.BufferCap)
+}
+
+// NoStats can be embedded in Source implementations that keep no
+// statistics; its Stats returns the zero SourceStats.
+type NoStats struct{}
+
+// Stats returns the zero SourceStats.
+func (NoStats) Stats() SourceStats { return SourceStats{} }
@@ -0,0 +1,124 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// stateSaveInterval is how often a FileSource writes its state file while
+// tailing. The state is also written when the source stops.
+const stateSaveInterval = 5 * time.Second
+
+// fileOffset is the persisted read position of one file.
+type fileOffset struct {
+	Path   string `json:"path"`
+	Offset int64  `json:"offset"`
+}
+
+// offsetState holds the byte offsets read so far, keyed by file identity
+// (device and inode) so that a rotated file is not mistaken for the one it
+// replaced.
+type offsetState struct {
+	path string
+
+	mu    sync.Mutex
+	files map[string]fileOffset
+	dirty bool
+}
+
+// loadOffsetState reads the state file at path. A missing file yields an
+// empty state.
+func loadOffsetState(path string) (*offsetState, error) {
+	s := &offsetState{path: path, files: make(map[string]fileOffset)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading state file: %w", err)
+	}
+	var doc struct {
+		Files map[string]fileOffset `json:"files"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing state file %s: %w", path, err)
+	}
+	for id, fo := range doc.Files {
+		s.files[id] = fo
+	}
+	return s, nil
+}
+
+// offset returns the stored offset for the file described by info.
+func (s *offsetState) offset(info os.FileInfo) (int64, bool) {
+	id, ok := fileID(info)
+	if !ok {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fo, ok := s.files[id]
+	return fo.Offset, ok
+}
+
+// record stores the offset read from the file at path. Entries for other
+// files previously seen at the same path (i.e. rotated away) are dropped.
+func (s *offsetState) record(path string, info os.FileInfo, offset int64) {
+	id, ok := fileID(info)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fo, ok := s.files[id]; ok && fo.Path == path && fo.Offset == offset {
+		return
+	}
+	for other, fo := range s.files {
+		if fo.Path == path && other != id {
+			delete(s.files, other)
+		}
+	}
+	s.files[id] = fileOffset{Path: path, Offset: offset}
+	s.dirty = true
+}
+
+// save writes the state file if anything changed since the last save. The
+// file is replaced atomically so a crash never leaves it half-written.
+func (s *offsetState) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(struct {
+		Files map[string]fileOffset `json:"files"`
+	}{s.files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing state file: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,138 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// drain starts a NoFollow source and returns every line it emits.
+func drain(t *testing.T, cfg FileConfig) []string {
+	t.Helper()
+	cfg.NoFollow = true
+	src := NewFileSource(cfg)
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for e := range src.Lines() {
+		got = append(got, e.Line)
+	}
+	src.Stop()
+	return got
+}
+
+func skipWithoutInodes(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("offset persistence needs inode numbers")
+	}
+}
+
+func appendTo(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileSource_StateFileResumes(t *testing.T) {
+	skipWithoutInodes(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	state := filepath.Join(dir, "state.json")
+	os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644)
+
+	cfg := FileConfig{Patterns: []string{path}, StateFile: state, ReadFrom: ReadLastN, TailLines: 1}
+	if got := drain(t, cfg); len(got) != 1 || got[0] != "three" {
+		t.Fatalf("first run = %v, want TailLines to apply without state", got)
+	}
+	if _, err := os.Stat(state); err != nil {
+		t.Fatalf("state file not written: %v", err)
+	}
+
+	appendTo(t, path, "four\nfive\n")
+	if got := drain(t, cfg); len(got) != 2 || got[0] != "four" || got[1] != "five" {
+		t.Errorf("second run = %v, want only the appended lines", got)
+	}
+	if got := drain(t, cfg); len(got) != 0 {
+		t.Errorf("third run = %v, want nothing new", got)
+	}
+}
+
+func TestFileSource_StateFileNewInode(t *testing.T) {
+	skipWithoutInodes(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	state := filepath.Join(dir, "state.json")
+	os.WriteFile(path, []byte("old1\nold2\n"), 0644)
+
+	cfg := FileConfig{Patterns: []string{path}, StateFile: state}
+	drain(t, cfg)
+
+	// Rotate: a new file (new inode) at the same path is read from the start.
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("new1\n"), 0644)
+	if got := drain(t, cfg); len(got) != 1 || got[0] != "new1" {
+		t.Errorf("after rotation = %v, want [new1]", got)
+	}
+
+	// Truncation in place: the stored offset is past the end, start over.
+	os.WriteFile(path, []byte("\n"), 0644)
+	if got := drain(t, cfg); len(got) != 1 || got[0] != "" {
+		t.Errorf("after truncation = %v, want one empty line", got)
+	}
+
+	s, err := loadOffsetState(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.files) != 1 {
+		t.Errorf("state should only keep the current file, got %v", s.files)
+	}
+}
+
+func TestFileSource_StateFileSavedOnStop(t *testing.T) {
+	skipWithoutInodes(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	state := filepath.Join(dir, "state.json")
+	os.WriteFile(path, []byte("a\nb\n"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}, StateFile: state})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	collectLines(t, src, 2*time.Second, 2)
+	appendTo(t, path, "c\n")
+	collectLines(t, src, 3*time.Second, 1)
+	src.Stop()
+
+	appendTo(t, path, "d\n")
+	if got := drain(t, FileConfig{Patterns: []string{path}, StateFile: state}); len(got) != 1 || got[0] != "d" {
+		t.Errorf("after restart = %v, want [d]", got)
+	}
+}
+
+func TestFileSource_StateFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	state := filepath.Join(dir, "state.json")
+	os.WriteFile(path, []byte("x\n"), 0644)
+	os.WriteFile(state, []byte("{not json"), 0644)
+
+	src := NewFileSource(FileConfig{Patterns: []string{path}, StateFile: state})
+	if err := src.Start(context.Background()); err == nil {
+		t.Error("expected an error for a corrupt state file")
+	}
+}
@@ -1,12 +1,14 @@
 package source
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -35,6 +37,43 @@ func WithBackpressure(bp BackpressureStrategy) StdinOption {
 	return func(s *StdinSource) { s.backpressure = bp }
 }
 
+// WithRateLimit caps emission at maxPerSec lines per second using a token
+// bucket (burst size maxPerSec). Lines over the limit are dropped and counted
+// in Dropped. Zero or negative disables the limit.
+func WithRateLimit(maxPerSec int) StdinOption {
+	return func(s *StdinSource) { s.rateLimit = maxPerSec }
+}
+
+// WithSampling keeps keep lines out of every every lines read (e.g.
+// WithSampling(1, 10) keeps one line in ten). Skipped lines are counted in
+// Sampled. Sampling is applied before the rate limit.
+func WithSampling(keep, every int) StdinOption {
+	return func(s *StdinSource) {
+		s.sampleKeep = keep
+		s.sampleEvery = every
+	}
+}
+
+// WithMaxLineLength truncates lines longer than n bytes, marking them with
+// TruncatedMarker. By default lines of any length are passed through.
+func WithMaxLineLength(n int) StdinOption {
+	return func(s *StdinSource) { s.maxLineLen = n }
+}
+
+// WithLineFilter drops lines for which f returns false before they are
+// sampled, rate limited or sent to Lines. f is called from the reading
+// goroutine only; it is set at construction and cannot be changed later.
+func WithLineFilter(f LineFilter) StdinOption {
+	return func(s *StdinSource) { s.lineFilter = f }
+}
+
+// WithFraming sets how the input is split into lines. The default is
+// FramingLine; the other modes suit log shippers that write binary or
+// multi-line records.
+func WithFraming(mode FramingMode) StdinOption {
+	return func(s *StdinSource) { s.framing = mode }
+}
+
 // WithReader overrides the default stdin reader (useful for testing).
 func WithReader(r io.Reader) StdinOption {
 	return func(s *StdinSource) { s.reader = r }
@@ -52,9 +91,25 @@ type StdinSource struct {
 	errs         chan error
 	bufSize      int
 	backpressure BackpressureStrategy
+	mu           sync.Mutex // guards cancel and stopped
 	cancel       context.CancelFunc
-	once         sync.Once
+	stopped      bool
 	done         chan struct{}
+
+	// Rate limiting and sampling.
+	rateLimit   int
+	tokens      float64
+	lastRefill  time.Time
+	sampleKeep  int
+	sampleEvery int
+	seen        int64
+	maxLineLen  int
+	lineFilter  LineFilter
+	framing     FramingMode
+
+	emitted atomic.Int64 // lines sent to the lines channel
+	dropped atomic.Int64 // lines dropped by the rate limit or DropOldest
+	sampled atomic.Int64 // lines skipped by sampling
 }
 
 // NewStdinSource creates a new StdinSource with the given options.
@@ -89,37 +144,113 @@ func (s *StdinSource) Lines() <-chan LogEntry { return s.lines }
 func (s *StdinSource) Errors() <-chan error { return s.errs }
 
 // Start reads lines from stdin until ctx is cancelled or EOF is reached.
+// Line endings, CRLF included, and a byte order mark at the start of the
+// input are dropped.
 func (s *StdinSource) Start(ctx context.Context) error {
+	s.mu.Lock()
 	ctx, s.cancel = context.WithCancel(ctx)
+	stopped := s.stopped
+	s.mu.Unlock()
 	defer close(s.lines)
 	defer close(s.errs)
 	defer close(s.done)
+	if stopped {
+		return nil
+	}
 
-	scanner := bufio.NewScanner(s.reader)
-	// Support very long log lines (up to 1 MB).
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	for scanner.Scan() {
+	lr := newFrameReader(s.reader, s.framing, s.maxLineLen)
+	first := true
+	for {
+		line, err := lr.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			select {
+			case s.errs <- fmt.Errorf("stdin read error: %w", err):
+			default:
+			}
+			return err
+		}
+		if first {
+			line, first = stripBOM(line), false
+		}
+		if s.lineFilter != nil && !s.lineFilter(line) {
+			continue
+		}
+		if !s.admit() {
+			continue
+		}
 		entry := LogEntry{
-			Line:   scanner.Text(),
+			Line:   line,
 			Source: "stdin",
 		}
 		if !s.emit(ctx, entry) {
 			return ctx.Err()
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		select {
-		case s.errs <- fmt.Errorf("stdin read error: %w", err):
-		default:
+}
+
+// Dropped returns the number of lines discarded by the rate limit or by the
+// DropOldest backpressure strategy.
+func (s *StdinSource) Dropped() int64 { return s.dropped.Load() }
+
+// Sampled returns the number of lines skipped by sampling.
+func (s *StdinSource) Sampled() int64 { return s.sampled.Load() }
+
+// Stats reports emitted and dropped lines and the lines channel's fill.
+// Lines skipped by sampling are not counted as dropped.
+func (s *StdinSource) Stats() SourceStats {
+	return SourceStats{
+		Emitted:   s.emitted.Load(),
+		Dropped:   s.dropped.Load(),
+		BufferLen: int64(len(s.lines)),
+		BufferCap: int64(cap(s.lines)),
+	}
+}
+
+// admit applies sampling and then the rate limit to the next line, reporting
+// whether it should be emitted. It is only called from the Start goroutine.
+func (s *StdinSource) admit() bool {
+	if s.sampleEvery > 1 && s.sampleKeep < s.sampleEvery {
+		n := s.seen
+		s.seen++
+		if n%int64(s.sampleEvery) >= int64(s.sampleKeep) {
+			s.sampled.Add(1)
+			return false
 		}
-		return err
 	}
-	return nil
+
+	if s.rateLimit > 0 {
+		now := time.Now()
+		if s.lastRefill.IsZero() {
+			s.tokens = float64(s.rateLimit)
+		} else {
+			s.tokens += now.Sub(s.lastRefill).Seconds() * float64(s.rateLimit)
+			if s.tokens > float64(s.rateLimit) {
+				s.tokens = float64(s.rateLimit)
+			}
+		}
+		s.lastRefill = now
+		if s.tokens < 1 {
+			s.dropped.Add(1)
+			return false
+		}
+		s.tokens--
+	}
+	return true
 }
 
 // emit sends an entry to the lines channel, respecting backpressure strategy.
 func (s *StdinSource) emit(ctx context.Context, entry LogEntry) bool {
+	if !s.send(ctx, entry) {
+		return false
+	}
+	s.emitted.Add(1)
+	return true
+}
+
+func (s *StdinSource) send(ctx context.Context, entry LogEntry) bool {
 	switch s.backpressure {
 	case DropOldest:
 		select {
@@ -128,6 +259,7 @@ func (s *StdinSource) emit(ctx context.Context, entry LogEntry) bool {
 			// Channel full — drop oldest.
 			select {
 			case <-s.lines:
+				s.dropped.Add(1)
 			default:
 			}
 			select {
@@ -146,13 +278,25 @@ func (s *StdinSource) emit(ctx context.Context, entry LogEntry) bool {
 	return true
 }
 
-// Stop cancels reading and waits for the reader goroutine to finish.
+// Stop cancels reading and waits for Start to return. The reader is closed
+// if it is an io.Closer, which interrupts a pending read on pipes and
+// network connections. Stop may be called more than once, and before Start,
+// which then returns at once.
 func (s *StdinSource) Stop() error {
-	s.once.Do(func() {
-		if s.cancel != nil {
-			s.cancel()
-		}
-	})
+	s.mu.Lock()
+	already, cancel := s.stopped, s.cancel
+	s.stopped = true
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	if c, ok := s.reader.(io.Closer); ok && !already {
+		c.Close()
+	}
 	<-s.done
 	return nil
 }
+
+// Reload is not supported: stdin cannot be read twice.
+func (s *StdinSource) Reload() error { return ErrReloadUnsupported }
@@ -2,6 +2,8 @@ package source
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -101,6 +103,36 @@ func TestStdinSource_Stop(t *testing.T) {
 	}
 }
 
+func TestStdinSource_StopInterruptsRead(t *testing.T) {
+	pr, _ := io.Pipe()
+	src := NewStdinSource(WithReader(pr))
+	go src.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop blocked on a pending read")
+	}
+}
+
+func TestStdinSource_StopBeforeStart(t *testing.T) {
+	src := NewStdinSource(WithReader(strings.NewReader("line\n")))
+	src.Stop()
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-src.Lines(); ok {
+		t.Error("a stopped source should not read")
+	}
+}
+
 func TestStdinSource_DropOldest(t *testing.T) {
 	input := "a\nb\nc\nd\n"
 	src := NewStdinSource(
@@ -120,6 +152,42 @@ func TestStdinSource_DropOldest(t *testing.T) {
 	}
 }
 
+func TestStdinSource_StatsDropOldest(t *testing.T) {
+	src := NewStdinSource(
+		WithReader(strings.NewReader(burst(10))),
+		WithBufferSize(2),
+		WithBackpressure(DropOldest),
+	)
+	if st := src.Stats(); st != (SourceStats{BufferCap: 2}) {
+		t.Errorf("initial Stats() = %+v", st)
+	}
+
+	// Nothing reads while Start runs, so DropOldest discards all but the
+	// last two lines.
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	st := src.Stats()
+	want := SourceStats{Emitted: 10, Dropped: 8, BufferLen: 2, BufferCap: 2}
+	if st != want {
+		t.Errorf("Stats() = %+v, want %+v", st, want)
+	}
+	if st.Dropped != src.Dropped() {
+		t.Errorf("Stats().Dropped = %d, Dropped() = %d", st.Dropped, src.Dropped())
+	}
+	if st.Saturation() != 1 {
+		t.Errorf("Saturation() = %v, want 1", st.Saturation())
+	}
+
+	entries := stdinCollectLines(t, src, 2*time.Second)
+	if len(entries) != 2 || entries[0].Line != "line 8" || entries[1].Line != "line 9" {
+		t.Errorf("remaining lines = %v, want the last two", entries)
+	}
+	if st := src.Stats(); st.BufferLen != 0 || st.Emitted != 10 {
+		t.Errorf("after draining: Stats() = %+v", st)
+	}
+}
+
 func TestStdinSource_LongLines(t *testing.T) {
 	long := strings.Repeat("x", 500_000)
 	src := NewStdinSource(WithReader(strings.NewReader(long + "\n")))
@@ -132,6 +200,63 @@ func TestStdinSource_LongLines(t *testing.T) {
 	}
 }
 
+func TestStdinSource_HugeLine(t *testing.T) {
+	huge := strings.Repeat("x", 5<<20)
+	input := "before\n" + huge + "\nafter one\nafter two\n"
+
+	src := NewStdinSource(WithReader(strings.NewReader(input)))
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 5*time.Second)
+
+	if len(entries) != 4 {
+		t.Fatalf("got %d lines, want 4", len(entries))
+	}
+	if len(entries[1].Line) != len(huge) {
+		t.Errorf("huge line length = %d, want %d", len(entries[1].Line), len(huge))
+	}
+	if entries[2].Line != "after one" || entries[3].Line != "after two" {
+		t.Errorf("lines after the huge one = %q, %q", entries[2].Line, entries[3].Line)
+	}
+}
+
+func TestStdinSource_MaxLineLength(t *testing.T) {
+	huge := strings.Repeat("x", 5<<20)
+	input := huge + "\nnext\n"
+
+	src := NewStdinSource(WithReader(strings.NewReader(input)), WithMaxLineLength(1024))
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 5*time.Second)
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d lines, want 2", len(entries))
+	}
+	if want := strings.Repeat("x", 1024) + TruncatedMarker; entries[0].Line != want {
+		t.Errorf("truncated line has length %d, want %d", len(entries[0].Line), len(want))
+	}
+	if entries[1].Line != "next" {
+		t.Errorf("line after truncated one = %q, want \"next\"", entries[1].Line)
+	}
+}
+
+func TestStdinSource_LineFilter(t *testing.T) {
+	input := "GET /health\nPOST /login\nGET /health\nPOST /logout\n"
+	src := NewStdinSource(
+		WithReader(strings.NewReader(input)),
+		WithLineFilter(ContainsFilter("POST")),
+		WithSampling(1, 2), // sampling must only see the lines that pass
+	)
+
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 2*time.Second)
+
+	if len(entries) != 1 || entries[0].Line != "POST /login" {
+		t.Fatalf("got %v, want only POST /login", entries)
+	}
+	if src.Sampled() != 1 {
+		t.Errorf("Sampled = %d, want 1", src.Sampled())
+	}
+}
+
 func TestStdinSource_Errors(t *testing.T) {
 	src := NewStdinSource(WithReader(strings.NewReader("")))
 
@@ -154,3 +279,106 @@ func TestStdinSource_ImplementsSource(t *testing.T) {
 func TestIsPipe(t *testing.T) {
 	_ = IsPipe()
 }
+
+func burst(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestStdinSource_RateLimit(t *testing.T) {
+	src := NewStdinSource(
+		WithReader(strings.NewReader(burst(1000))),
+		WithRateLimit(50),
+	)
+
+	start := time.Now()
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 2*time.Second)
+	elapsed := time.Since(start)
+
+	// The burst arrives far faster than a second, so at most the bucket size
+	// plus whatever refilled during the read should get through.
+	limit := 50 + int(elapsed.Seconds()*50) + 1
+	if len(entries) == 0 || len(entries) > limit {
+		t.Fatalf("emitted %d lines in %v, want 1..%d", len(entries), elapsed, limit)
+	}
+	if got := src.Dropped(); got != int64(1000-len(entries)) {
+		t.Errorf("Dropped() = %d, want %d", got, 1000-len(entries))
+	}
+	if entries[0].Line != "line 0" {
+		t.Errorf("first emitted line = %q, want the start of the burst", entries[0].Line)
+	}
+}
+
+func TestStdinSource_Sampling(t *testing.T) {
+	src := NewStdinSource(
+		WithReader(strings.NewReader(burst(100))),
+		WithSampling(1, 10),
+	)
+
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 2*time.Second)
+
+	if len(entries) != 10 {
+		t.Fatalf("emitted %d lines, want 10", len(entries))
+	}
+	for i, e := range entries {
+		if want := fmt.Sprintf("line %d", i*10); e.Line != want {
+			t.Errorf("entry %d = %q, want %q", i, e.Line, want)
+		}
+	}
+	if src.Sampled() != 90 {
+		t.Errorf("Sampled() = %d, want 90", src.Sampled())
+	}
+	if src.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", src.Dropped())
+	}
+}
+
+func TestStdinSource_SamplingWithRateLimit(t *testing.T) {
+	src := NewStdinSource(
+		WithReader(strings.NewReader(burst(1000))),
+		WithSampling(1, 2),
+		WithRateLimit(20),
+		WithBackpressure(DropOldest),
+	)
+
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 2*time.Second)
+
+	if src.Sampled() != 500 {
+		t.Errorf("Sampled() = %d, want 500", src.Sampled())
+	}
+	if total := int64(len(entries)) + src.Dropped() + src.Sampled(); total != 1000 {
+		t.Errorf("emitted+dropped+sampled = %d, want 1000", total)
+	}
+}
+
+func TestStdinSource_ReloadUnsupported(t *testing.T) {
+	src := NewStdinSource(WithReader(strings.NewReader("")))
+	if err := src.Reload(); !errors.Is(err, ErrReloadUnsupported) {
+		t.Errorf("Reload() = %v, want ErrReloadUnsupported", err)
+	}
+}
+
+func TestStdinSource_BOMAndCRLF(t *testing.T) {
+	input := "\ufeff{\"level\":\"info\"}\r\nsecond\r\n\ufeffthird\r\nlast\r"
+	src := NewStdinSource(WithReader(strings.NewReader(input)))
+
+	go src.Start(context.Background())
+	entries := stdinCollectLines(t, src, 2*time.Second)
+
+	// Only the BOM at the very start is an encoding marker.
+	want := []string{`{"level":"info"}`, "second", "\ufeffthird", "last"}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Line != want[i] {
+			t.Errorf("line %d = %q, want %q", i, e.Line, want[i])
+		}
+	}
+}
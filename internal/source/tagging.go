@@ -0,0 +1,79 @@
+package source
+
+import "context"
+
+// TagConfig holds configuration for a tagging source.
+type TagConfig struct {
+	// Tags are the static key/values attached to every entry.
+	Tags map[string]string
+	// Overwrite lets tags replace parsed fields with the same key. By default
+	// parsed fields win.
+	Overwrite bool
+}
+
+// TaggingSource wraps another Source and attaches static metadata (such as
+// env=staging or app=api) to every entry it emits. The tags end up as fields
+// once the entry is parsed; see LogEntry.ApplyTags.
+type TaggingSource struct {
+	src    Source
+	config TagConfig
+	lines  chan LogEntry
+}
+
+// NewTaggingSource creates a TaggingSource around src.
+func NewTaggingSource(src Source, cfg TagConfig) *TaggingSource {
+	tags := make(map[string]string, len(cfg.Tags))
+	for k, v := range cfg.Tags {
+		tags[k] = v
+	}
+	cfg.Tags = tags
+	return &TaggingSource{
+		src:    src,
+		config: cfg,
+		lines:  make(chan LogEntry, cap(src.Lines())),
+	}
+}
+
+func (t *TaggingSource) Lines() <-chan LogEntry { return t.lines }
+func (t *TaggingSource) Errors() <-chan error   { return t.src.Errors() }
+
+// Start begins forwarding tagged entries and then starts the wrapped source.
+// It returns whatever the wrapped source's Start returns.
+func (t *TaggingSource) Start(ctx context.Context) error {
+	go t.forward(ctx)
+	return t.src.Start(ctx)
+}
+
+// Stop stops the wrapped source.
+func (t *TaggingSource) Stop() error {
+	return t.src.Stop()
+}
+
+// Reload reloads the wrapped source.
+func (t *TaggingSource) Reload() error {
+	return t.src.Reload()
+}
+
+// Stats reports the wrapped source's statistics, counting the entries
+// waiting in the tagging stage as buffered too.
+func (t *TaggingSource) Stats() SourceStats {
+	st := t.src.Stats()
+	st.BufferLen += int64(len(t.lines))
+	st.BufferCap += int64(cap(t.lines))
+	return st
+}
+
+// forward copies entries from the wrapped source, attaching tags, until the
+// wrapped source's channel closes or ctx is cancelled.
+func (t *TaggingSource) forward(ctx context.Context) {
+	defer close(t.lines)
+	for entry := range t.src.Lines() {
+		entry.Tags = t.config.Tags
+		entry.TagsOverwrite = t.config.Overwrite
+		select {
+		case t.lines <- entry:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
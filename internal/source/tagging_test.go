@@ -0,0 +1,73 @@
+package source
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaggingSource_TagsEveryEntry(t *testing.T) {
+	inner := NewStdinSource(WithReader(strings.NewReader("one\ntwo\nthree\n")))
+	src := NewTaggingSource(inner, TagConfig{Tags: map[string]string{"env": "staging", "app": "api"}})
+
+	go src.Start(context.Background())
+
+	var got []LogEntry
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case e, ok := <-src.Lines():
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatal("timed out waiting for lines")
+		}
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3", len(got))
+	}
+	for i, e := range got {
+		fields := e.ApplyTags(nil)
+		if fields["env"] != "staging" || fields["app"] != "api" {
+			t.Errorf("entry %d: fields = %v, want env and app tags", i, fields)
+		}
+		if e.Source != "stdin" {
+			t.Errorf("entry %d: source = %q, want stdin", i, e.Source)
+		}
+	}
+}
+
+func TestApplyTags_OverwritePolicy(t *testing.T) {
+	tags := map[string]string{"env": "staging", "app": "api"}
+
+	keep := LogEntry{Tags: tags}
+	fields := keep.ApplyTags(map[string]string{"env": "prod", "user": "bob"})
+	if fields["env"] != "prod" {
+		t.Errorf("env = %q, parsed field should win without overwrite", fields["env"])
+	}
+	if fields["app"] != "api" || fields["user"] != "bob" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	over := LogEntry{Tags: tags, TagsOverwrite: true}
+	fields = over.ApplyTags(map[string]string{"env": "prod"})
+	if fields["env"] != "staging" {
+		t.Errorf("env = %q, tag should win with overwrite", fields["env"])
+	}
+}
+
+func TestApplyTags_NoTags(t *testing.T) {
+	var e LogEntry
+	if got := e.ApplyTags(nil); got != nil {
+		t.Errorf("ApplyTags with no tags = %v, want nil", got)
+	}
+}
+
+func TestTaggingSource_ImplementsSource(t *testing.T) {
+	var _ Source = (*TaggingSource)(nil)
+}
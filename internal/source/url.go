@@ -0,0 +1,260 @@
+package source
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// URLConfig holds configuration for a URL source.
+type URLConfig struct {
+	// URL is the http or https address of the log.
+	URL string
+	// Auth, if set, is sent as the Authorization header, such as
+	// "Bearer <token>".
+	Auth string
+	// Client makes the requests; nil means http.DefaultClient. It should
+	// have no overall timeout, which would cut long-lived streams.
+	Client *http.Client
+	// MaxRetries is the number of consecutive failed attempts before the
+	// source gives up (default DefaultRetries). Any data received resets
+	// the count.
+	MaxRetries int
+	// Backoff and MaxBackoff bound the delay between attempts, as for a
+	// RetryingSource (defaults DefaultRetryBackoff and
+	// DefaultRetryMaxBackoff).
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxLineLength truncates longer lines; 0 keeps lines of any length.
+	MaxLineLength int
+	// BufferSize is the capacity of the lines channel (default
+	// DefaultBufferSize).
+	BufferSize int
+}
+
+// URLSource streams a log served over HTTP, such as a chunked endpoint that
+// keeps sending lines. It follows the response until the body ends or the
+// context is cancelled. Connection failures, 5xx responses and broken
+// streams are retried as a RetryingSource retries. A broken stream resumes with
+// a Range request when the server supports it; otherwise the log is fetched
+// again from the start after a Reset entry. gzip-encoded bodies are
+// decompressed. Entries carry the URL as their Source.
+type URLSource struct {
+	config  URLConfig
+	lines   chan LogEntry
+	errs    chan error
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	emitted atomic.Int64
+}
+
+// NewURLSource creates a URLSource.
+func NewURLSource(cfg URLConfig) *URLSource {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultRetries
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	return &URLSource{
+		config:  cfg,
+		lines:   make(chan LogEntry, cfg.BufferSize),
+		errs:    make(chan error, 32),
+		stopped: make(chan struct{}),
+	}
+}
+
+// IsURL reports whether s is an http or https URL rather than a file path.
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func (u *URLSource) Lines() <-chan LogEntry { return u.lines }
+func (u *URLSource) Errors() <-chan error   { return u.errs }
+
+// Start validates the URL and begins fetching it in the background.
+func (u *URLSource) Start(ctx context.Context) error {
+	if parsed, err := url.Parse(u.config.URL); err != nil || !IsURL(u.config.URL) || parsed.Host == "" {
+		close(u.lines)
+		close(u.errs)
+		close(u.stopped)
+		return fmt.Errorf("invalid URL %q", u.config.URL)
+	}
+	ctx, u.cancel = context.WithCancel(ctx)
+	go u.run(ctx)
+	return nil
+}
+
+// Stop cancels the request and waits for the source to finish.
+func (u *URLSource) Stop() error {
+	if u.cancel != nil {
+		u.cancel()
+	}
+	<-u.stopped
+	return nil
+}
+
+// Reload is not supported: a stream cannot be replayed.
+func (u *URLSource) Reload() error { return ErrReloadUnsupported }
+
+// Stats reports the lines emitted so far and the lines channel's fill.
+func (u *URLSource) Stats() SourceStats {
+	return SourceStats{
+		Emitted:   u.emitted.Load(),
+		BufferLen: int64(len(u.lines)),
+		BufferCap: int64(cap(u.lines)),
+	}
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// urlStream is the state carried from one attempt to the next.
+type urlStream struct {
+	offset    int64 // body bytes received so far
+	resumable bool  // the server accepts byte ranges of the body as sent
+}
+
+// run fetches the URL, retrying failed attempts, until the body ends, the
+// retries are exhausted or ctx is cancelled.
+func (u *URLSource) run(ctx context.Context) {
+	defer func() {
+		close(u.lines)
+		close(u.errs)
+		close(u.stopped)
+	}()
+
+	var st urlStream
+	policy := retryPolicy{maxRetries: u.config.MaxRetries, backoff: u.config.Backoff, maxBackoff: u.config.MaxBackoff}
+	for {
+		before := st.offset
+		err := u.fetch(ctx, &st)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			u.sendError(fmt.Errorf("fetching %s: %w", u.config.URL, err))
+			return
+		}
+		if !policy.failed(st.offset > before) {
+			u.sendError(fmt.Errorf("fetching %s: giving up after %d attempts: %w", u.config.URL, policy.failures, err))
+			return
+		}
+		if !policy.wait(ctx) {
+			return
+		}
+	}
+}
+
+// fetch makes one request and emits the lines of its body. It returns nil
+// once the body has been read to the end.
+func (u *URLSource) fetch(ctx context.Context, st *urlStream) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.config.URL, nil)
+	if err != nil {
+		return permanentError{err}
+	}
+	if u.config.Auth != "" {
+		req.Header.Set("Authorization", u.config.Auth)
+	}
+	if st.offset > 0 && st.resumable {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", st.offset))
+	}
+	resp, err := u.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && st.offset > 0 && st.resumable:
+		// Resuming where the broken stream left off.
+	case resp.StatusCode == http.StatusOK:
+		if st.offset > 0 {
+			// The server sends the whole log again.
+			if !u.emit(ctx, LogEntry{Reset: true}) {
+				return ctx.Err()
+			}
+			st.offset = 0
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && st.offset > 0:
+		// Nothing was added since the stream broke.
+		return nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusRequestTimeout:
+		return fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return permanentError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+
+	body := io.Reader(resp.Body)
+	encoded := strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") && !resp.Uncompressed
+	if encoded {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return fmt.Errorf("reading gzip body: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+	// Byte offsets of a decompressed body mean nothing to the server.
+	st.resumable = resp.Header.Get("Accept-Ranges") == "bytes" && !encoded && !resp.Uncompressed
+
+	// The offset only moves past complete lines, so that a stream broken
+	// in the middle of one resumes at its start.
+	start := st.offset
+	lr := newLineReader(body, u.config.MaxLineLength)
+	for {
+		line, err := lr.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading body: %w", err)
+		}
+		if lr.broken() {
+			continue // read again by the next attempt
+		}
+		st.offset = start + lr.consumed
+		if !u.emit(ctx, LogEntry{Line: line, Source: u.config.URL}) {
+			return ctx.Err()
+		}
+	}
+}
+
+// emit sends entry, reporting false if ctx was cancelled first.
+func (u *URLSource) emit(ctx context.Context, entry LogEntry) bool {
+	select {
+	case u.lines <- entry:
+		if !entry.Reset {
+			u.emitted.Add(1)
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (u *URLSource) sendError(err error) {
+	select {
+	case u.errs <- err:
+	default:
+	}
+}
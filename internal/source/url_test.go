@@ -0,0 +1,233 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// urlLines runs a URLSource for cfg to completion and returns its entries
+// and the first error it reported.
+func urlLines(t *testing.T, cfg URLConfig) ([]LogEntry, error) {
+	t.Helper()
+	if cfg.Backoff == 0 {
+		cfg.Backoff = time.Millisecond
+	}
+	src := NewURLSource(cfg)
+	if err := src.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var entries []LogEntry
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-src.Lines():
+			if !ok {
+				var err error
+				select {
+				case err = <-src.Errors():
+				default:
+				}
+				return entries, err
+			}
+			entries = append(entries, e)
+		case <-timeout:
+			t.Fatal("timed out waiting for the URL source to finish")
+		}
+	}
+}
+
+func lineTexts(entries []LogEntry) string {
+	var s []string
+	for _, e := range entries {
+		if e.Reset {
+			s = append(s, "<reset>")
+			continue
+		}
+		s = append(s, e.Line)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestURLSource_StreamsChunkedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, "chunk %d\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	entries, err := urlLines(t, URLConfig{URL: srv.URL + "/app.log"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := lineTexts(entries); got != "chunk 1,chunk 2,chunk 3" {
+		t.Errorf("lines = %q", got)
+	}
+	if entries[0].Source != srv.URL+"/app.log" {
+		t.Errorf("source = %q, want the URL", entries[0].Source)
+	}
+}
+
+func TestURLSource_RetriesFlakyServer(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			http.Error(w, "warming up", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "finally\nup\n")
+	}))
+	defer srv.Close()
+
+	entries, err := urlLines(t, URLConfig{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := lineTexts(entries); got != "finally,up" {
+		t.Errorf("lines = %q", got)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestURLSource_GivesUp(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := urlLines(t, URLConfig{URL: srv.URL, MaxRetries: 2})
+	if err == nil || !strings.Contains(err.Error(), "giving up after 3 attempts") {
+		t.Errorf("error = %v, want giving up after 3 attempts", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestURLSource_AuthAndPermanentErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "who are you", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "welcome\n")
+	}))
+	defer srv.Close()
+
+	entries, err := urlLines(t, URLConfig{URL: srv.URL, Auth: "Bearer s3cret"})
+	if err != nil || lineTexts(entries) != "welcome" {
+		t.Errorf("with auth: lines = %q, err = %v", lineTexts(entries), err)
+	}
+
+	requests.Store(0)
+	_, err = urlLines(t, URLConfig{URL: srv.URL})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want 401", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("a 401 was retried: %d requests", n)
+	}
+}
+
+func TestURLSource_Gzip(t *testing.T) {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	gz.Write([]byte("zipped one\nzipped two\n"))
+	gz.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body.Bytes())
+	}))
+	defer srv.Close()
+
+	// Decompressed by the transport, and by the source when the transport
+	// leaves the body alone.
+	for _, client := range []*http.Client{http.DefaultClient, {Transport: &http.Transport{DisableCompression: true}}} {
+		entries, err := urlLines(t, URLConfig{URL: srv.URL, Client: client})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := lineTexts(entries); got != "zipped one,zipped two" {
+			t.Errorf("lines = %q", got)
+		}
+	}
+}
+
+func TestURLSource_ResumesBrokenStream(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		resumable bool
+		sent      int // bytes sent before the stream breaks
+		want      string
+	}{
+		{"range", true, 8, "one,two,three"},
+		{"restart", false, 8, "one,two,<reset>,one,two,three"},
+		// A line cut short is not emitted, and read again in full.
+		{"range mid-line", true, 6, "one,two,three"},
+		{"restart mid-line", false, 6, "one,<reset>,one,two,three"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.resumable {
+					w.Header().Set("Accept-Ranges", "bytes")
+				}
+				const body = "one\ntwo\nthree\n"
+				if requests.Add(1) == 1 {
+					fmt.Fprint(w, body[:tt.sent])
+					w.(http.Flusher).Flush()
+					panic(http.ErrAbortHandler) // break the stream
+				}
+				if rng := r.Header.Get("Range"); rng != "" {
+					var from int
+					fmt.Sscanf(rng, "bytes=%d-", &from)
+					if body[from-1] != '\n' {
+						t.Errorf("Range = %q resumes in the middle of a line", rng)
+					}
+					w.WriteHeader(http.StatusPartialContent)
+					fmt.Fprint(w, body[from:])
+					return
+				}
+				fmt.Fprint(w, body)
+			}))
+			defer srv.Close()
+
+			entries, err := urlLines(t, URLConfig{URL: srv.URL})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := lineTexts(entries); got != tt.want {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLSource_InvalidURL(t *testing.T) {
+	src := NewURLSource(URLConfig{URL: "ftp://example.com/log"})
+	if err := src.Start(context.Background()); err == nil {
+		t.Error("expected an error for a non-HTTP URL")
+	}
+	if err := src.Stop(); err != nil {
+		t.Error(err)
+	}
+	if !IsURL("https://example.com") || IsURL("/var/log/app.log") {
+		t.Error("IsURL misclassifies")
+	}
+}
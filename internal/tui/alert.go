@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultAlertLevel is the least severe level counted as an unseen error.
+const DefaultAlertLevel = "error"
+
+var alertStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FFFFFF")).
+	Background(lipgloss.Color("#C0392B")).
+	Bold(true)
+
+// bellOut is where the terminal bell is rung. Bubble Tea owns stdout, so the
+// bell goes to stderr, which is the same terminal.
+var bellOut io.Writer = os.Stderr
+
+// WithAlertLevel sets the least severe level that counts as an unseen error
+// when it arrives while the view is scrolled away from the bottom. An empty
+// level disables the alert.
+func WithAlertLevel(level string) ModelOption {
+	return func(m *Model) { m.alertLevel = level }
+}
+
+// WithAlertBell also rings the terminal bell for each batch of lines that
+// brings in unseen errors.
+func WithAlertBell(on bool) ModelOption {
+	return func(m *Model) { m.alertBell = on }
+}
+
+// noteUnseen counts the visible entries from buffer index start on that are
+// at or above the alert level, if the user is not watching the bottom of the
+// log. It returns a command ringing the bell when enabled and something was
+// counted.
+func (m *Model) noteUnseen(start int) tea.Cmd {
+	if m.alertLevel == "" || m.autoScroll || m.isAtBottom() {
+		return nil
+	}
+	min, ok := levelRanks[normalizeLevel(m.alertLevel)]
+	if !ok {
+		return nil
+	}
+	n := 0
+	for i := start; i < len(m.entries); i++ {
+		rank, ok := levelRanks[normalizeLevel(m.entries[i].Level)]
+		if ok && rank >= min && m.passes(i) {
+			n++
+		}
+	}
+	m.unseenErrors += n
+	if n == 0 || !m.alertBell {
+		return nil
+	}
+	return func() tea.Msg {
+		fmt.Fprint(bellOut, "\a")
+		return nil
+	}
+}
+
+// renderAlert returns the status bar badge for unseen errors, or "" if
+// there are none.
+func (m Model) renderAlert() string {
+	if m.unseenErrors == 0 {
+		return ""
+	}
+	return alertStyle.Render(fmt.Sprintf(" ⚠ %d ", m.unseenErrors))
+}
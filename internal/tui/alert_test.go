@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// scrolledUpModel returns a model with 50 info entries, scrolled to the top.
+func scrolledUpModel(opts ...ModelOption) Model {
+	var entries []parser.LogEntry
+	for i := 0; i < 50; i++ {
+		entries = append(entries, parser.LogEntry{Level: "INFO", Message: fmt.Sprintf("line %d", i)})
+	}
+	return press(applyMsgs(setupModel(80, 24, 0, opts...), logMsgs(entries...)), "g")
+}
+
+func TestAlert_CountsUnseenErrors(t *testing.T) {
+	m := scrolledUpModel()
+	m = applyMsgs(m, []tea.Msg{
+		LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR"}},
+		LogMsg{Rendered: "w1", Entry: parser.LogEntry{Level: "WARN"}},
+		LogBatchMsg{
+			Lines:   []string{"f1", "i1", "e2"},
+			Entries: []parser.LogEntry{{Level: "FATAL"}, {Level: "INFO"}, {Level: "error"}},
+		},
+	})
+	if m.unseenErrors != 3 {
+		t.Fatalf("unseenErrors = %d, want 3", m.unseenErrors)
+	}
+	if v := StripANSI(m.View()); !contains(v, "⚠ 3") {
+		t.Errorf("status bar should show the unseen count:\n%s", v)
+	}
+
+	m = press(m, "G")
+	if m.unseenErrors != 0 {
+		t.Errorf("jumping to the bottom should clear the count, got %d", m.unseenErrors)
+	}
+	if v := StripANSI(m.View()); contains(v, "⚠") {
+		t.Errorf("badge should be gone at the bottom:\n%s", v)
+	}
+}
+
+func TestAlert_NotCountedAtBottom(t *testing.T) {
+	m := press(scrolledUpModel(), "G")
+	m = applyMsgs(m, []tea.Msg{LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR"}}})
+	if m.unseenErrors != 0 {
+		t.Errorf("errors arriving while following are seen, got %d", m.unseenErrors)
+	}
+}
+
+func TestAlert_Level(t *testing.T) {
+	m := scrolledUpModel(WithAlertLevel("warn"))
+	m = applyMsgs(m, []tea.Msg{
+		LogMsg{Rendered: "w1", Entry: parser.LogEntry{Level: "WARN"}},
+		LogMsg{Rendered: "i1", Entry: parser.LogEntry{Level: "INFO"}},
+	})
+	if m.unseenErrors != 1 {
+		t.Errorf("unseenErrors = %d, want 1 with alert level warn", m.unseenErrors)
+	}
+
+	m = scrolledUpModel(WithAlertLevel(""))
+	m = applyMsgs(m, []tea.Msg{LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR"}}})
+	if m.unseenErrors != 0 {
+		t.Errorf("an empty alert level disables the count, got %d", m.unseenErrors)
+	}
+}
+
+func TestAlert_HiddenEntriesNotCounted(t *testing.T) {
+	m := scrolledUpModel(WithFilter(func(e parser.LogEntry) bool { return e.Message != "hidden" }))
+	m = applyMsgs(m, []tea.Msg{
+		LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR", Message: "hidden"}},
+		LogMsg{Rendered: "e2", Entry: parser.LogEntry{Level: "ERROR", Message: "shown"}},
+	})
+	if m.unseenErrors != 1 {
+		t.Errorf("unseenErrors = %d, want 1 (filtered entries are not counted)", m.unseenErrors)
+	}
+}
+
+func TestAlert_Bell(t *testing.T) {
+	var buf bytes.Buffer
+	old := bellOut
+	bellOut = &buf
+	defer func() { bellOut = old }()
+
+	m := scrolledUpModel(WithAlertBell(true))
+	_, cmd := m.Update(LogMsg{Rendered: "i1", Entry: parser.LogEntry{Level: "INFO"}})
+	if cmd != nil {
+		t.Error("no bell for entries below the alert level")
+	}
+	_, cmd = m.Update(LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR"}})
+	if cmd == nil {
+		t.Fatal("expected a bell command")
+	}
+	cmd()
+	if buf.String() != "\a" {
+		t.Errorf("bell wrote %q, want BEL", buf.String())
+	}
+
+	m = scrolledUpModel()
+	if _, cmd = m.Update(LogMsg{Rendered: "e1", Entry: parser.LogEntry{Level: "ERROR"}}); cmd != nil {
+		t.Error("the bell is off by default")
+	}
+}
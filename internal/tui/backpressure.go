@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+const (
+	// statsInterval is how often the source's statistics are polled.
+	statsInterval = time.Second
+	// loadingInterval is how often they are polled while the source is
+	// loading, to keep the loading spinner turning.
+	loadingInterval = 100 * time.Millisecond
+	// gaugeThreshold is the buffer saturation from which the backpressure
+	// gauge is shown in the status bar.
+	gaugeThreshold = 0.5
+	// gaugeCells is the width of the gauge bar.
+	gaugeCells = 5
+)
+
+// sourceStatsMsg carries a snapshot of the source's statistics.
+type sourceStatsMsg source.SourceStats
+
+// pollStats returns a command delivering the source's statistics after
+// statsInterval, or loadingInterval while it is loading, or nil without a
+// source.
+func (m Model) pollStats() tea.Cmd {
+	src := m.src
+	if src == nil {
+		return nil
+	}
+	interval := statsInterval
+	if m.srcStats.Loading {
+		interval = loadingInterval
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return sourceStatsMsg(src.Stats())
+	})
+}
+
+// renderGauge returns the backpressure status segment: how full the
+// source's buffer is and how many lines it dropped. It is empty while the
+// buffer is below gaugeThreshold and nothing was dropped.
+func (m Model) renderGauge() string {
+	st := m.srcStats
+	sat := st.Saturation()
+	if sat < gaugeThreshold && st.Dropped == 0 {
+		return ""
+	}
+	filled := int(sat*gaugeCells + 0.5)
+	val := strings.Repeat("▮", filled) + strings.Repeat("▯", gaugeCells-filled) + fmt.Sprintf(" %d%%", int(sat*100))
+	if st.Dropped > 0 {
+		val += fmt.Sprintf(", %d dropped", st.Dropped)
+	}
+	return statusItem("Buf:", val)
+}
+
+// spinnerFrames are the frames of the loading spinner, turning once a
+// second.
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// renderLoading returns the loading status segment: a spinner and the
+// bytes scanned while the source reads the content it starts with, such
+// as the last lines of a large file. It is empty once that is read.
+func (m Model) renderLoading() string {
+	st := m.srcStats
+	if !st.Loading {
+		return ""
+	}
+	frame := spinnerFrames[m.now().UnixMilli()/100%int64(len(spinnerFrames))]
+	return statusItem("Loading…", fmt.Sprintf("%c %s scanned", frame, formatBytes(st.Scanned)))
+}
+
+// formatBytes formats n bytes in the largest binary unit that keeps the
+// number at least 1, with one decimal from KiB up: "512 B", "1.5 MiB".
+func formatBytes(n int64) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, unit := float64(n)/1024, "KiB"
+	for _, u := range []string{"MiB", "GiB"} {
+		if v < 1024 {
+			break
+		}
+		v, unit = v/1024, u
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
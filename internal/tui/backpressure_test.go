@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+func TestBackpressureGauge(t *testing.T) {
+	if cmd := NewModel().Init(); cmd != nil {
+		t.Error("Init without a source should not poll")
+	}
+	m := NewModelWithSource(newChanSource(10), "test")
+	m.width, m.height, m.ready = 120, 24, true
+	if m.Init() == nil {
+		t.Fatal("Init with a source should start polling")
+	}
+
+	updated, cmd := m.Update(sourceStatsMsg(source.SourceStats{Emitted: 5, BufferLen: 2, BufferCap: 10}))
+	m = updated.(Model)
+	if cmd == nil {
+		t.Error("a stats message should schedule the next poll")
+	}
+	if v := StripANSI(m.View()); contains(v, "Buf:") {
+		t.Errorf("gauge shown below the threshold:\n%s", v)
+	}
+
+	m = applyMsgs(m, []tea.Msg{sourceStatsMsg(source.SourceStats{Emitted: 50, Dropped: 7, BufferLen: 8, BufferCap: 10})})
+	if v := StripANSI(m.View()); !contains(v, "▮▮▮▮▯ 80%, 7 dropped") {
+		t.Errorf("status bar should show the gauge:\n%s", v)
+	}
+}
+
+func TestLoadingIndicator(t *testing.T) {
+	m := NewModelWithSource(newChanSource(10), "big.log")
+	m.width, m.height, m.ready = 120, 24, true
+	m = applyMsgs(m, []tea.Msg{sourceStatsMsg(source.SourceStats{Loading: true, Scanned: 3 << 20})})
+	v := StripANSI(m.View())
+	if !contains(v, "Loading…") || !contains(v, "3.0 MiB scanned") {
+		t.Errorf("status bar should show the loading progress:\n%s", v)
+	}
+
+	m = applyMsgs(m, []tea.Msg{sourceStatsMsg(source.SourceStats{Emitted: 1000, Scanned: 5 << 20})})
+	if v := StripANSI(m.View()); contains(v, "Loading") {
+		t.Errorf("loading indicator shown after loading:\n%s", v)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1536:              "1.5 KiB",
+		5 << 20:           "5.0 MiB",
+		3 << 30:           "3.0 GiB",
+		5000 << 30:        "5000.0 GiB",
+		(1 << 20) - 1<<10: "1023.0 KiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
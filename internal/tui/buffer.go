@@ -0,0 +1,83 @@
+package tui
+
+import "github.com/clarabennettdev/logpilot/internal/parser"
+
+// WithMaxLines caps the number of lines the model keeps. When the cap is
+// exceeded the oldest lines are dropped. Zero (the default) keeps everything.
+func WithMaxLines(n int) ModelOption {
+	return func(m *Model) { m.maxLines = n }
+}
+
+// appendLines adds entries to the buffer, dropping the oldest ones if the
+// buffer is over its cap. lines are the texts shown for them by a model
+// without a renderer; an entry without one shows its input, and a line
+// without an entry gets an empty one.
+func (m *Model) appendLines(lines []string, entries []parser.LogEntry) {
+	start := len(m.entries)
+	m.entries = append(m.entries, entries...)
+	for len(m.entries) < start+len(lines) {
+		m.entries = append(m.entries, parser.LogEntry{})
+	}
+	if m.renderer == nil {
+		for len(m.lines) < start {
+			m.lines = append(m.lines, "")
+		}
+		m.lines = append(m.lines, lines...)
+		for len(m.lines) < len(m.entries) {
+			m.lines = append(m.lines, "")
+		}
+	}
+	m.extendView(start)
+	if m.maxLines > 0 && len(m.entries) > m.maxLines {
+		m.dropOldest(len(m.entries) - m.maxLines)
+	}
+}
+
+// dropOldest removes the first n lines and shifts every buffer index the
+// model holds (view, cursor, offset, compare selection, context view, marks)
+// to match.
+func (m *Model) dropOldest(n int) {
+	if n <= 0 {
+		return
+	}
+	n = min(n, len(m.entries))
+	m.dropped += n
+	m.entries = append(m.entries[:0:0], m.entries[n:]...)
+	if len(m.lines) >= n {
+		m.lines = append(m.lines[:0:0], m.lines[n:]...)
+	} else {
+		m.lines = nil
+	}
+
+	// Cursor and offset are view positions, so they move by the number of
+	// dropped lines that were visible.
+	shown := n
+	if m.filtered() {
+		shown, _ = m.viewPos(n)
+		view := make([]int, 0, len(m.view)-shown)
+		for _, i := range m.view[shown:] {
+			view = append(view, i-n)
+		}
+		m.view = view
+	}
+	m.cursor = max(m.cursor-shown, 0)
+	m.offset = max(m.offset-shown, 0)
+
+	if m.compareA >= 0 {
+		a, b := m.compareA-n, m.compareB
+		if b >= 0 {
+			b -= n
+		}
+		if a < 0 || (m.compareB >= 0 && b < 0) {
+			m.clearCompare()
+		} else {
+			m.compareA, m.compareB = a, b
+		}
+	}
+	if m.contextIndex >= 0 {
+		if m.contextIndex -= n; m.contextIndex < 0 {
+			m.closeContext()
+		}
+	}
+	m.shiftMarks(n)
+}
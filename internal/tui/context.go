@@ -0,0 +1,73 @@
+package tui
+
+import "fmt"
+
+// DefaultContextLines is how many lines the context view shows before and
+// after the selected entry.
+const DefaultContextLines = 5
+
+// WithContextLines sets how many lines before and after the selected entry
+// the context view (key X) shows.
+func WithContextLines(k int) ModelOption {
+	return func(m *Model) { m.contextLines = k }
+}
+
+// toggleContext opens the context view for the cursor entry, or closes it.
+func (m *Model) toggleContext() {
+	if m.showContext {
+		m.closeContext()
+		return
+	}
+	i := m.cursorIndex()
+	if i < 0 {
+		return
+	}
+	m.contextIndex = i
+	m.showContext = true
+}
+
+// closeContext leaves the context view.
+func (m *Model) closeContext() {
+	m.contextIndex = -1
+	m.showContext = false
+}
+
+// contextRange returns the buffer range [start, end) the context view shows
+// around buffer index i, ignoring any filter.
+func (m Model) contextRange(i int) (start, end int) {
+	k := max(m.contextLines, 0)
+	return max(i-k, 0), min(i+k+1, len(m.entries))
+}
+
+// renderContext renders the context view rows: the unfiltered buffer lines
+// around the selected entry, which is highlighted.
+func (m Model) renderContext() []string {
+	i := m.contextIndex
+	start, end := m.contextRange(i)
+	numWidth := len(fmt.Sprint(m.lineNumber(end - 1)))
+	width := 0
+	if m.width > 0 {
+		width = max(m.width-numWidth-3, 1)
+	}
+
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ Context #%d (±%d lines)", m.lineNumber(i), m.contextLines)),
+		"",
+	}
+	indexes := make([]int, 0, end-start)
+	for j := start; j < end; j++ {
+		indexes = append(indexes, j)
+	}
+	m.fitTable(indexes)
+	for j := start; j < end; j++ {
+		line := truncateToWidth(m.lineText(j), width)
+		marker := "  "
+		if j == i {
+			marker = "▶ "
+			line = cursorStyle.Render(line)
+		}
+		num := lineNumberStyle.Render(fmt.Sprintf("%*d", numWidth, m.lineNumber(j)))
+		rows = append(rows, marker+num+" "+line)
+	}
+	return rows
+}
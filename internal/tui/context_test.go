@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// contextModel buffers 20 entries of which only "error 10" passes the filter.
+func contextModel(opts ...ModelOption) Model {
+	opts = append(opts, WithFilter(func(e parser.LogEntry) bool { return e.Level == "ERROR" }))
+	var entries []parser.LogEntry
+	for i := 0; i < 20; i++ {
+		e := parser.LogEntry{Level: "INFO", Message: fmt.Sprintf("info %d", i)}
+		if i == 10 {
+			e = parser.LogEntry{Level: "ERROR", Message: "error 10"}
+		}
+		entries = append(entries, e)
+	}
+	return applyMsgs(setupModel(80, 30, 0, opts...), logMsgs(entries...))
+}
+
+func TestContext_ShowsUnfilteredNeighbours(t *testing.T) {
+	m := contextModel(WithContextLines(3))
+	if m.viewLen() != 1 {
+		t.Fatalf("viewLen = %d, want 1", m.viewLen())
+	}
+
+	m = press(m, "X")
+	if !m.showContext || m.contextIndex != 10 {
+		t.Fatalf("context view not opened on buffer index 10: %v, %d", m.showContext, m.contextIndex)
+	}
+	v := StripANSI(m.View())
+	for i := 7; i <= 13; i++ {
+		if i != 10 && !contains(v, fmt.Sprintf("info %d", i)) {
+			t.Errorf("context should include hidden line %d:\n%s", i, v)
+		}
+	}
+	for _, i := range []int{6, 14} {
+		if contains(v, fmt.Sprintf("info %d\n", i)) {
+			t.Errorf("context should not include line %d", i)
+		}
+	}
+	if !contains(v, "▶ 11 error 10") {
+		t.Errorf("selected line should be marked:\n%s", v)
+	}
+
+	m = press(m, "X")
+	if m.showContext || contains(StripANSI(m.View()), "info 9") {
+		t.Error("C should close the context view")
+	}
+}
+
+func TestContext_ClampedAtBufferEdges(t *testing.T) {
+	m := contextModel(WithContextLines(15))
+	start, end := m.contextRange(10)
+	if start != 0 || end != 20 {
+		t.Errorf("contextRange(10) = %d, %d; want 0, 20", start, end)
+	}
+	rows := press(m, "X").renderContext()
+	if got := len(rows) - 2; got != 20 {
+		t.Errorf("got %d context rows, want 20", got)
+	}
+}
+
+func TestContext_EscClosesAndDropCloses(t *testing.T) {
+	m := press(contextModel(), "X")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = updated.(Model)
+	if m.showContext {
+		t.Error("esc should close the context view")
+	}
+
+	m = contextModel(WithMaxLines(20))
+	m = press(m, "X")
+	m.dropOldest(11)
+	if m.showContext || m.contextIndex != -1 {
+		t.Error("context view should close when its entry is dropped")
+	}
+	if !strings.Contains(m.View(), "No entries match") {
+		t.Error("expected the filtered view back after the context entry was dropped")
+	}
+}
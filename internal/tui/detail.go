@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// setDetail opens or closes the detail pane. Opening it gives it the focus,
+// so the scrolling keys move through the entry's fields until Ctrl+W hands
+// them back to the log.
+func (m *Model) setDetail(open bool) {
+	m.showDetail = open
+	m.detailFocus = open
+}
+
+// scrollDetailKey handles act while the detail pane has the focus,
+// reporting whether it was a scrolling action.
+func (m *Model) scrollDetailKey(act action) bool {
+	body := m.detailBodyHeight()
+	switch act {
+	case actDown:
+		m.scrollDetail(1)
+	case actUp:
+		m.scrollDetail(-1)
+	case actPageDown:
+		m.scrollDetail(body)
+	case actPageUp:
+		m.scrollDetail(-body)
+	case actHalfPageDown:
+		m.scrollDetail(body / 2)
+	case actHalfPageUp:
+		m.scrollDetail(-body / 2)
+	case actTop:
+		m.scrollDetail(-m.detailRowCount())
+	case actBottom:
+		m.scrollDetail(m.detailRowCount())
+	default:
+		return false
+	}
+	return true
+}
+
+// detailBodyHeight returns the number of rows the detail pane shows below
+// its header.
+func (m Model) detailBodyHeight() int {
+	return m.detailPaneHeight() - 1
+}
+
+// detailRowCount returns the number of detail rows of the entry under the
+// cursor.
+func (m Model) detailRowCount() int {
+	i := m.cursorIndex()
+	if i < 0 || i >= len(m.entries) {
+		return 0
+	}
+	return len(detailRows(m.detailEntry(i), m.filterRe))
+}
+
+// detailEntry returns entry i as the detail pane shows it: redacted,
+// sanitized and limited to the fields the renderer displays.
+func (m Model) detailEntry(i int) parser.LogEntry {
+	entry := m.entries[i]
+	if m.renderer != nil {
+		entry = m.renderer.Sanitize(m.renderer.SelectFields(m.renderer.Redact(entry)))
+		entry.Timestamp = m.renderer.DisplayTime(entry.Timestamp)
+	}
+	return entry
+}
+
+// clampedDetailOffset returns the detail scroll offset for the entry under
+// the cursor, given that it has n rows.
+func (m Model) clampedDetailOffset(n int) int {
+	if m.detailFor != m.cursorIndex() {
+		return 0
+	}
+	off := m.detailOffset
+	if max := n - m.detailBodyHeight(); off > max {
+		off = max
+	}
+	if off < 0 {
+		off = 0
+	}
+	return off
+}
+
+// scrollDetail scrolls the detail pane of the entry under the cursor by n
+// rows, keeping the last row at the bottom of the pane at most.
+func (m *Model) scrollDetail(n int) {
+	rows := m.detailRowCount()
+	m.detailOffset = m.clampedDetailOffset(rows) + n
+	m.detailFor = m.cursorIndex()
+	m.detailOffset = m.clampedDetailOffset(rows)
+}
+
+// detailRows renders the rows of the detail pane for entry: timestamp,
+// level, every message line, format and then the fields in sorted order.
+// The matches of query, the active filter's pattern, are highlighted.
+func detailRows(entry parser.LogEntry, query *regexp.Regexp) []string {
+	var rows []string
+	if !entry.Timestamp.IsZero() {
+		rows = append(rows, detailKeyStyle.Render("  timestamp")+" "+HighlightMatches(entry.Timestamp.Format("2006-01-02 15:04:05.000"), query, detailValStyle))
+	}
+	if entry.Level != "" {
+		rows = append(rows, detailKeyStyle.Render("  level    ")+" "+HighlightMatches(entry.Level, query, detailValStyle))
+	}
+	if entry.Message != "" {
+		label := detailKeyStyle.Render("  message  ")
+		for _, line := range strings.Split(strings.TrimRight(entry.Message, "\n"), "\n") {
+			rows = append(rows, label+" "+HighlightMatches(line, query, detailValStyle))
+			label = strings.Repeat(" ", len("  message  "))
+		}
+	}
+	rows = append(rows, detailKeyStyle.Render("  format   ")+" "+detailValStyle.Render(entry.Format.String()))
+	label := detailKeyStyle.Render("  issues   ")
+	for _, issue := range entry.Issues {
+		rows = append(rows, label+" "+detailIssueStyle.Render(SanitizeControl(issue, false)))
+		label = strings.Repeat(" ", len("  issues   "))
+	}
+
+	if len(entry.Fields) > 0 {
+		keys := make([]string, 0, len(entry.Fields))
+		for k := range entry.Fields {
+			keys = append(keys, k)
+		}
+		sortDetailKeys(keys)
+		errs := parser.ExtractErrorDetails(entry)
+		for _, k := range keys {
+			rows = append(rows, detailFieldRows(k, entry.Fields[k], errs, query)...)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// manyFieldsModel returns a model whose two entries have 40 fields each,
+// f00..f39, far more than the detail pane can show at once.
+func manyFieldsModel() Model {
+	m := setupModel(80, 40, 2)
+	m.autoScroll = false
+	m.cursor = 0
+	for i := 0; i < 2; i++ {
+		fields := make(map[string]string)
+		for f := 0; f < 40; f++ {
+			fields[fmt.Sprintf("f%02d", f)] = fmt.Sprintf("v%d.%d", i, f)
+		}
+		m.entries[i] = parser.LogEntry{Level: "info", Message: "many", Fields: fields, Format: parser.FormatJSON}
+	}
+	return m
+}
+
+func TestDetailScroll_RevealsLaterFields(t *testing.T) {
+	m := press(manyFieldsModel(), "enter")
+	if !m.showDetail || !m.detailFocus {
+		t.Fatalf("Enter should open and focus the detail pane: show=%v focus=%v", m.showDetail, m.detailFocus)
+	}
+	v := StripANSI(m.View())
+	if !contains(v, "f00") || contains(v, "f39") {
+		t.Fatalf("unscrolled pane should show f00 but not f39:\n%s", v)
+	}
+	// 3 header rows (level, message, format) + 40 fields; 11 rows fit.
+	if !contains(v, "1–11 of 43 ↓") {
+		t.Errorf("header should show the visible range:\n%s", v)
+	}
+
+	for i := 0; i < 10; i++ {
+		m = press(m, "j")
+	}
+	if m.cursor != 0 {
+		t.Errorf("j moved the log cursor to %d while the pane had the focus", m.cursor)
+	}
+	v = StripANSI(m.View())
+	if contains(v, "f00") || !contains(v, "f07") {
+		t.Errorf("after 10 rows the pane should start at f07:\n%s", v)
+	}
+	if !contains(v, "11–21 of 43 ↑ ↓") {
+		t.Errorf("header should show the scrolled range:\n%s", v)
+	}
+
+	m = press(m, "G")
+	v = StripANSI(m.View())
+	if !contains(v, "f39") || !contains(v, "33–43 of 43 ↑") {
+		t.Errorf("G should scroll to the last field:\n%s", v)
+	}
+	m = press(m, "j")
+	if m.detailOffset != 32 {
+		t.Errorf("detailOffset = %d, want 32 (clamped)", m.detailOffset)
+	}
+	m = press(m, "g")
+	if m.detailOffset != 0 {
+		t.Errorf("g: detailOffset = %d, want 0", m.detailOffset)
+	}
+}
+
+func TestDetailScroll_FocusSwitch(t *testing.T) {
+	m := press(manyFieldsModel(), "enter")
+	m = press(m, "j")
+	m = press(m, "ctrl+w")
+	if m.detailFocus {
+		t.Fatal("Ctrl+W should give the focus back to the log")
+	}
+	m = press(m, "j")
+	if m.cursor != 1 {
+		t.Errorf("j with the log focused: cursor = %d, want 1", m.cursor)
+	}
+	// The next entry starts at the top of its fields.
+	if v := StripANSI(m.View()); !contains(v, "v1.0") {
+		t.Errorf("new entry should show its first field:\n%s", v)
+	}
+	m = press(m, "k")
+	if v := StripANSI(m.View()); !contains(v, "f00") {
+		t.Errorf("back on the first entry its offset is reset:\n%s", v)
+	}
+
+	m = press(m, "enter")
+	if m.showDetail || m.detailFocus {
+		t.Errorf("Enter should close the pane and drop the focus: show=%v focus=%v", m.showDetail, m.detailFocus)
+	}
+}
+
+func TestDetailScroll_MouseWheel(t *testing.T) {
+	m := press(manyFieldsModel(), "enter")
+	m = press(m, "ctrl+w")
+	// The pane starts below the log rows and the separator.
+	y := logTop + m.logPaneHeight() + 2
+	updated, _ := m.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress, Y: y})
+	m = updated.(Model)
+	if m.detailOffset != wheelLines || m.cursor != 0 {
+		t.Errorf("wheel over the pane: detailOffset = %d, cursor = %d; want %d, 0", m.detailOffset, m.cursor, wheelLines)
+	}
+}
+
+func TestDetail_SelectedFields(t *testing.T) {
+	m := manyFieldsModel()
+	m.renderer = plainRenderer(func(c *RenderConfig) {
+		c.IncludeFields = []string{"f00", "f01", "f39"}
+		c.ExcludeFields = []string{"f01"}
+	})
+	m = press(m, "enter")
+	v := StripANSI(m.View())
+	if !contains(v, "f00") || !contains(v, "f39") || contains(v, "f01") || contains(v, "f02") {
+		t.Errorf("detail pane should show only f00 and f39:\n%s", v)
+	}
+	if n := m.detailRowCount(); n != 5 {
+		t.Errorf("detailRowCount = %d, want 5", n)
+	}
+	if len(m.entries[0].Fields) != 40 {
+		t.Errorf("entry lost fields: %d left", len(m.entries[0].Fields))
+	}
+}
+
+func TestDetail_DisplayLocation(t *testing.T) {
+	m := setupModel(80, 40, 1)
+	m.entries[0] = parser.LogEntry{Timestamp: time.Date(2026, 2, 17, 5, 0, 0, 0, time.FixedZone("", -5*3600)), Message: "x"}
+	m.renderer = plainRenderer(func(c *RenderConfig) { c.DisplayLocation = time.UTC })
+	if v := StripANSI(press(m, "enter").View()); !contains(v, "2026-02-17 10:00:00.000") {
+		t.Errorf("detail pane should show the timestamp in UTC:\n%s", v)
+	}
+}
+
+func TestDetail_Issues(t *testing.T) {
+	m := setupModel(80, 30, 1)
+	m.autoScroll = false
+	m.cursor = 0
+	m.entries = []parser.LogEntry{{
+		Level:   "info",
+		Message: "checkout",
+		Format:  parser.FormatJSON,
+		Issues:  []string{`missing required field "request_id"`, `field "status": want integer, got "OK"`},
+	}}
+	m = press(m, "enter")
+	v := StripANSI(m.View())
+	if !contains(v, `issues    missing required field "request_id"`) || !contains(v, `field "status": want integer, got "OK"`) {
+		t.Errorf("detail pane should list the issues:\n%s", v)
+	}
+	if n := m.detailRowCount(); n != 5 {
+		t.Errorf("detailRowCount = %d, want 5", n)
+	}
+}
+
+func TestDetail_HighlightsFilterMatches(t *testing.T) {
+	withColors(t)
+	m := setupModel(100, 30, 2)
+	m.autoScroll = false
+	m.cursor = 0
+	m.entries = []parser.LogEntry{
+		{Level: "error", Message: "upstream timeout", Format: parser.FormatJSON,
+			Fields: map[string]string{"cause": "read timeout on 10.0.0.7", "host": "web1"}},
+		{Level: "info", Message: "ok", Format: parser.FormatJSON},
+	}
+	if err := m.setFilterText("timeout"); err != nil {
+		t.Fatal(err)
+	}
+	m = press(m, "enter")
+	v := m.View()
+	for _, want := range []string{
+		detailValStyle.Render("upstream ") + matchStyle.Render("timeout"),
+		detailValStyle.Render("read ") + matchStyle.Render("timeout") + detailValStyle.Render(" on 10.0.0.7"),
+		detailValStyle.Render("web1"),
+	} {
+		if !contains(v, want) {
+			t.Errorf("detail pane should contain %q:\n%q", want, v)
+		}
+	}
+
+	if err := m.setFilterText(""); err != nil {
+		t.Fatal(err)
+	}
+	if v := m.View(); contains(v, matchStyle.Render("timeout")) {
+		t.Errorf("highlight should go with the filter:\n%q", v)
+	}
+}
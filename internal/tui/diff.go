@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))  // green
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")) // red
+	diffChangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220")) // yellow
+)
+
+// FieldChange describes one differing key between two entries. A is empty
+// for added keys and B is empty for removed keys.
+type FieldChange struct {
+	Key string
+	A   string
+	B   string
+}
+
+// FieldDiff is the structured difference between two log entries. Level and
+// message are compared alongside the fields, under the keys "level" and
+// "message". Each slice is sorted by key.
+type FieldDiff struct {
+	Added   []FieldChange // present only in B
+	Removed []FieldChange // present only in A
+	Changed []FieldChange // present in both with different values
+}
+
+// Empty reports whether the two entries had no differences.
+func (d FieldDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffEntries compares the level, message, and fields of a and b.
+// Timestamps are ignored since they almost always differ.
+func DiffEntries(a, b parser.LogEntry) FieldDiff {
+	return diffFields(comparableFields(a), comparableFields(b))
+}
+
+// comparableFields flattens the compared parts of an entry into one map.
+func comparableFields(e parser.LogEntry) map[string]string {
+	m := make(map[string]string, len(e.Fields)+2)
+	for k, v := range e.Fields {
+		m[k] = v
+	}
+	if e.Level != "" {
+		m["level"] = e.Level
+	}
+	if e.Message != "" {
+		m["message"] = e.Message
+	}
+	return m
+}
+
+// diffFields classifies the keys of two field maps.
+func diffFields(a, b map[string]string) FieldDiff {
+	var d FieldDiff
+	for k, av := range a {
+		bv, ok := b[k]
+		switch {
+		case !ok:
+			d.Removed = append(d.Removed, FieldChange{Key: k, A: av})
+		case av != bv:
+			d.Changed = append(d.Changed, FieldChange{Key: k, A: av, B: bv})
+		}
+	}
+	for k, bv := range b {
+		if _, ok := a[k]; !ok {
+			d.Added = append(d.Added, FieldChange{Key: k, B: bv})
+		}
+	}
+	sortChanges(d.Added)
+	sortChanges(d.Removed)
+	sortChanges(d.Changed)
+	return d
+}
+
+func sortChanges(s []FieldChange) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j].Key < s[j-1].Key; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+// markCompare marks the cursor entry as A, or as B if A is already set, in
+// which case the diff overlay opens.
+func (m *Model) markCompare() {
+	i := m.cursorIndex()
+	if i < 0 || i >= len(m.entries) {
+		return
+	}
+	if m.compareA < 0 || m.showCompare {
+		m.compareA = i
+		m.compareB = -1
+		m.showCompare = false
+		return
+	}
+	if i == m.compareA {
+		return
+	}
+	m.compareB = i
+	m.showCompare = true
+}
+
+// clearCompare leaves compare mode.
+func (m *Model) clearCompare() {
+	m.compareA, m.compareB = -1, -1
+	m.showCompare = false
+}
+
+// renderCompare renders the diff overlay rows for the marked entries.
+func (m Model) renderCompare() []string {
+	a, b := m.entries[m.compareA], m.entries[m.compareB]
+	if m.renderer != nil {
+		a, b = m.renderer.Sanitize(m.renderer.Redact(a)), m.renderer.Sanitize(m.renderer.Redact(b))
+	}
+	d := DiffEntries(a, b)
+
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ Compare #%d (A) ↔ #%d (B)", m.lineNumber(m.compareA), m.lineNumber(m.compareB))),
+		"",
+	}
+	if d.Empty() {
+		return append(rows, "  (no differences)")
+	}
+	for _, c := range d.Removed {
+		rows = append(rows, diffRemovedStyle.Render(fmt.Sprintf("  - %-12s %s", c.Key, c.A)))
+	}
+	for _, c := range d.Added {
+		rows = append(rows, diffAddedStyle.Render(fmt.Sprintf("  + %-12s %s", c.Key, c.B)))
+	}
+	for _, c := range d.Changed {
+		rows = append(rows, diffChangedStyle.Render(fmt.Sprintf("  ~ %-12s %s → %s", c.Key, c.A, c.B)))
+	}
+	return rows
+}
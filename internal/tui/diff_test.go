@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestDiffEntries_Classifies(t *testing.T) {
+	good := parser.LogEntry{
+		Level:   "info",
+		Message: "request handled",
+		Fields:  map[string]string{"status": "200", "path": "/api", "cache": "hit"},
+	}
+	bad := parser.LogEntry{
+		Level:   "error",
+		Message: "request handled",
+		Fields:  map[string]string{"status": "500", "path": "/api", "error": "timeout"},
+	}
+
+	d := DiffEntries(good, bad)
+
+	if len(d.Added) != 1 || d.Added[0] != (FieldChange{Key: "error", B: "timeout"}) {
+		t.Errorf("Added = %+v, want [error=timeout]", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0] != (FieldChange{Key: "cache", A: "hit"}) {
+		t.Errorf("Removed = %+v, want [cache=hit]", d.Removed)
+	}
+	wantChanged := []FieldChange{
+		{Key: "level", A: "info", B: "error"},
+		{Key: "status", A: "200", B: "500"},
+	}
+	if len(d.Changed) != len(wantChanged) {
+		t.Fatalf("Changed = %+v, want %+v", d.Changed, wantChanged)
+	}
+	for i := range wantChanged {
+		if d.Changed[i] != wantChanged[i] {
+			t.Errorf("Changed[%d] = %+v, want %+v", i, d.Changed[i], wantChanged[i])
+		}
+	}
+}
+
+func TestDiffEntries_Identical(t *testing.T) {
+	e := parser.LogEntry{
+		Level:   "warn",
+		Message: "slow query",
+		Fields:  map[string]string{"ms": "900", "table": "users"},
+	}
+	later := e
+	later.Timestamp = e.Timestamp.Add(time.Minute)
+	if d := DiffEntries(e, later); !d.Empty() {
+		t.Errorf("identical entries differ: %+v", d)
+	}
+	if d := DiffEntries(parser.LogEntry{}, parser.LogEntry{}); !d.Empty() {
+		t.Errorf("empty entries differ: %+v", d)
+	}
+
+	// A key only missing on one side is added or removed, not changed.
+	d := DiffEntries(parser.LogEntry{Message: "x"}, parser.LogEntry{})
+	if len(d.Removed) != 1 || d.Removed[0].Key != "message" || len(d.Added)+len(d.Changed) != 0 {
+		t.Errorf("diff = %+v, want only message removed", d)
+	}
+}
+
+func TestCompareOverlay_NoDifferences(t *testing.T) {
+	m := setupModel(100, 30, 0)
+	for i := 0; i < 2; i++ {
+		e := parser.LogEntry{Level: "info", Message: "same", Fields: map[string]string{"k": "v"}}
+		m.lines = append(m.lines, e.Message)
+		m.entries = append(m.entries, e)
+	}
+	m.renderer = plainRenderer()
+	m.autoScroll = false
+	m.cursor = 0
+	m = press(m, "c")
+	m.cursor = 1
+	m = press(m, "c")
+	if v := m.View(); !contains(v, "(no differences)") {
+		t.Errorf("overlay should report no differences:\n%s", v)
+	}
+}
+
+func TestCompareFlow(t *testing.T) {
+	m := setupModel(100, 30, 0)
+	entries := []parser.LogEntry{
+		{Level: "info", Message: "ok", Fields: map[string]string{"status": "200"}},
+		{Level: "info", Message: "filler"},
+		{Level: "info", Message: "ok", Fields: map[string]string{"status": "503", "retry": "3"}},
+	}
+	for _, e := range entries {
+		m.lines = append(m.lines, e.Message)
+		m.entries = append(m.entries, e)
+	}
+	m.autoScroll = false
+
+	m.cursor = 0
+	m = press(m, "c")
+	if m.compareA != 0 || m.showCompare {
+		t.Fatalf("first 'c' should mark A only: A=%d show=%v", m.compareA, m.showCompare)
+	}
+	if !contains(m.View(), "Compare:") {
+		t.Error("status bar should indicate a pending comparison")
+	}
+
+	m.cursor = 2
+	m = press(m, "c")
+	if !m.showCompare || m.compareB != 2 {
+		t.Fatalf("second 'c' should mark B and open the overlay: B=%d show=%v", m.compareB, m.showCompare)
+	}
+	v := m.View()
+	for _, want := range []string{"Compare #1 (A) ↔ #3 (B)", "+ retry", "~ status", "200 → 503"} {
+		if !contains(v, want) {
+			t.Errorf("overlay missing %q:\n%s", want, v)
+		}
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	m = updated.(Model)
+	if m.showCompare || m.compareA != -1 {
+		t.Error("esc should leave compare mode")
+	}
+}
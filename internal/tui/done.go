@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// SourceDoneMsg tells the model that its source has ended: its lines
+// channel was closed, as it is once a file read without following is
+// exhausted, and no more lines will arrive.
+type SourceDoneMsg struct{}
+
+// countSources adds entries to the per-source line counts.
+func (m *Model) countSources(entries []parser.LogEntry) {
+	if len(entries) > 0 && m.sourceLines == nil {
+		m.sourceLines = make(map[string]int)
+	}
+	for _, e := range entries {
+		m.sourceLines[orDefault(e.Source, noLevel)]++
+	}
+}
+
+// SourceLines returns how many lines arrived from each source since the
+// last reload, counting those the buffer has dropped since. Lines without
+// a source count under "-".
+func (m Model) SourceLines() map[string]int {
+	return m.sourceLines
+}
+
+// SourceDone reports whether the source has ended.
+func (m Model) SourceDone() bool {
+	return m.sourceDone
+}
+
+// renderInput renders the input rows of the statistics overlay: the lines
+// received from each source and whether more may come.
+func (m Model) renderInput() []string {
+	keys := make([]string, 0, len(m.sourceLines))
+	width, total := len("Input"), 0
+	for k, n := range m.sourceLines {
+		keys = append(keys, k)
+		width = max(width, len(k))
+		total += n
+	}
+	sortStrings(keys)
+
+	rows := []string{detailKeyStyle.Render(fmt.Sprintf("  %-*s %8s", width, "Input", "Lines"))}
+	for _, k := range keys {
+		rows = append(rows, fmt.Sprintf("  %-*s %8d", width, k, m.sourceLines[k]))
+	}
+	state := "waiting for more lines"
+	if m.sourceDone {
+		state = fmt.Sprintf("done: the input ended after %d lines", total)
+	}
+	return append(rows, "  "+state)
+}
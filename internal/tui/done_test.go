@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+// waitForDone waits until the recorder has received a SourceDoneMsg.
+func (r *recorder) waitForDone(t *testing.T) []tea.Msg {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		msgs := r.snapshot()
+		if len(msgs) > 0 {
+			if _, ok := msgs[len(msgs)-1].(SourceDoneMsg); ok {
+				return msgs
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the end of the source")
+	return nil
+}
+
+func TestStreamLines_SourceDone(t *testing.T) {
+	src := newChanSource(10)
+	for i := 0; i < 3; i++ {
+		src.lines <- source.LogEntry{Line: fmt.Sprintf("a%d", i), Source: "a.log"}
+	}
+	for i := 0; i < 2; i++ {
+		src.lines <- source.LogEntry{Line: fmt.Sprintf("b%d", i), Source: "b.log"}
+	}
+
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), nil, rec)
+	msgs := rec.waitForLines(t, 5)
+	m := applyMsgs(NewModelWithSource(src, "logs"), msgs)
+	if m.SourceDone() {
+		t.Fatal("the source is done before its channel is closed")
+	}
+
+	close(src.lines)
+	m = applyMsgs(m, rec.waitForDone(t)[len(msgs):])
+	if !m.SourceDone() {
+		t.Error("the model should record that the source ended")
+	}
+	if got := m.SourceLines(); got["a.log"] != 3 || got["b.log"] != 2 || len(got) != 2 {
+		t.Errorf("source lines = %v, want a.log:3 b.log:2", got)
+	}
+}
+
+func TestSourceDone_Shown(t *testing.T) {
+	m := NewModelWithSource(nil, "app.log", WithMaxLines(2))
+	m = applyMsgs(m, []tea.Msg{
+		tea.WindowSizeMsg{Width: 120, Height: 30},
+		LogBatchMsg{Entries: []parser.LogEntry{{Message: "a", Source: "app.log"}, {Message: "b", Source: "app.log"}, {Message: "c"}}},
+	})
+	if v := StripANSI(m.View()); strings.Contains(v, "(done)") || !strings.Contains(StripANSI(strings.Join(m.renderInput(), "\n")), "waiting for more lines") {
+		t.Errorf("a running source is shown as done:\n%s", v)
+	}
+
+	m = applyMsgs(m, []tea.Msg{SourceDoneMsg{}})
+	if v := StripANSI(m.View()); !strings.Contains(v, "app.log (done)") {
+		t.Errorf("status bar should mark the source done:\n%s", v)
+	}
+	m = press(m, "s")
+	v := StripANSI(m.View())
+	// Lines the buffer dropped still count.
+	for _, want := range []string{"app.log        2", "-              1", "done: the input ended after 3 lines"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("stats overlay lacks %q:\n%s", want, v)
+		}
+	}
+}
+
+func TestSourceLines_ResetByReload(t *testing.T) {
+	m := applyMsgs(NewModel(), []tea.Msg{LogMsg{Entry: parser.LogEntry{Message: "a", Source: "x"}}, ResetMsg{}})
+	if n := len(m.SourceLines()); n != 0 {
+		t.Errorf("after a reload, %d sources are counted", n)
+	}
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/muesli/termenv"
+)
+
+// WithClipboard sets where the copy keys (y, Y, C) put text. By default it
+// goes to the terminal's clipboard with an OSC 52 escape sequence.
+func WithClipboard(clip func(text string) error) ModelOption {
+	return func(m *Model) { m.clipboard = clip }
+}
+
+// copyOSC52 copies text to the clipboard of the terminal, which may ignore
+// the request.
+func copyOSC52(text string) error {
+	termenv.Copy(text)
+	return nil
+}
+
+// jsonEntry is the EntryToJSON form of an entry.
+type jsonEntry struct {
+	Time    string            `json:"time,omitempty"`
+	Level   string            `json:"level,omitempty"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Format  string            `json:"format"`
+	Source  string            `json:"source,omitempty"`
+	Stream  string            `json:"stream,omitempty"`
+}
+
+// EntryToJSON returns entry as a normalized JSON object with "time" (RFC
+// 3339), "level", "message", "fields", "format", "source" and "stream"
+// keys, whatever format it was parsed from.
+func EntryToJSON(entry parser.LogEntry) string {
+	je := jsonEntry{
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  entry.Fields,
+		Format:  entry.Format.String(),
+		Source:  entry.Source,
+		Stream:  entry.Stream,
+	}
+	if !entry.Timestamp.IsZero() {
+		je.Time = entry.Timestamp.Format(time.RFC3339Nano)
+	}
+	b, _ := json.Marshal(je)
+	return string(b)
+}
+
+// Fields an HTTP request is reconstructed from by EntryToCurl, in order of
+// preference and compared case-insensitively.
+var (
+	curlMethodKeys = []string{"method", "http_method", "request_method", "verb"}
+	curlTargetKeys = []string{"url", "uri", "path", "request_uri", "request_path", "http_path"}
+	curlHostKeys   = []string{"host", "http_host", "server_name", "authority"}
+	curlSchemeKeys = []string{"scheme", "protocol"}
+	curlAgentKeys  = []string{"user_agent", "http_user_agent", "useragent"}
+)
+
+// requestLinePattern matches a request line such as "GET /index.html
+// HTTP/1.1", as access logs keep it in a "request" field.
+var requestLinePattern = regexp.MustCompile(`^([A-Z]+) (\S+)(?: HTTP/[\d.]+)?$`)
+
+// EntryToCurl reconstructs the HTTP request an access-log entry records as
+// a curl command. The method, path and host come from fields such as
+// "method", "path" and "host" (or a "request" field holding the request
+// line); a URL field with a scheme and host needs no host field. The
+// scheme is "http" unless a field names it, and a user agent field becomes
+// a header. It returns an error naming what is missing if the entry does
+// not describe a request.
+func EntryToCurl(entry parser.LogEntry) (string, error) {
+	method := lookupField(entry.Fields, curlMethodKeys)
+	target := lookupField(entry.Fields, curlTargetKeys)
+	if m := requestLinePattern.FindStringSubmatch(lookupField(entry.Fields, []string{"request"})); m != nil {
+		method = orDefault(method, m[1])
+		target = orDefault(target, m[2])
+	}
+	if target == "" {
+		return "", errors.New("entry has no URL or path field")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", target, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		host := lookupField(entry.Fields, curlHostKeys)
+		if host == "" {
+			return "", errors.New("entry has no host field")
+		}
+		u.Host = host
+		u.Scheme = "http"
+		if s := strings.ToLower(lookupField(entry.Fields, curlSchemeKeys)); s == "http" || s == "https" {
+			u.Scheme = s
+		}
+	}
+
+	args := []string{"curl"}
+	if method = strings.ToUpper(method); method != "" && method != "GET" {
+		args = append(args, "-X", method)
+	}
+	args = append(args, shellQuote(u.String()))
+	if ua := lookupField(entry.Fields, curlAgentKeys); ua != "" {
+		args = append(args, "-H", shellQuote("User-Agent: "+ua))
+	}
+	return strings.Join(args, " "), nil
+}
+
+// lookupField returns the value of the first of keys in fields, compared
+// case-insensitively, or "".
+func lookupField(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		for k, v := range fields {
+			if v != "" && strings.EqualFold(k, key) {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyEntry copies the selected entry to the clipboard in the form act
+// selects: the raw line, normalized JSON or a curl command. The entry
+// is redacted first, and the notice says what was copied or why nothing
+// was.
+func (m *Model) copyEntry(act action) {
+	i := m.cursorIndex()
+	if i < 0 || i >= len(m.entries) {
+		m.notice = "no entry selected"
+		return
+	}
+	entry := m.entries[i]
+	if m.renderer != nil {
+		entry = m.renderer.Redact(entry)
+	}
+
+	var text, what string
+	switch act {
+	case actCopyLine:
+		text, what = orDefault(entry.Raw, entry.Message), "line"
+	case actCopyJSON:
+		text, what = EntryToJSON(entry), "entry as JSON"
+	case actCopyCurl:
+		var err error
+		if text, err = EntryToCurl(entry); err != nil {
+			m.notice = "cannot copy as curl: " + err.Error()
+			return
+		}
+		what = "curl command"
+	}
+	clip := m.clipboard
+	if clip == nil {
+		clip = copyOSC52
+	}
+	if err := clip(text); err != nil {
+		m.notice = "copying failed: " + err.Error()
+		return
+	}
+	m.notice = "copied " + what
+}
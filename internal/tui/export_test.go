@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestEntryToJSON(t *testing.T) {
+	entry := parser.LogEntry{
+		Timestamp: time.Date(2026, 2, 17, 19, 58, 0, 250e6, time.UTC),
+		Level:     "ERROR",
+		Message:   "db timeout",
+		Fields:    map[string]string{"host": "db-1", "attempt": "3"},
+		Format:    parser.FormatLogfmt,
+		Source:    "app.log",
+		Raw:       `ts=... level=error msg="db timeout" host=db-1 attempt=3`,
+	}
+	want := `{"time":"2026-02-17T19:58:00.25Z","level":"ERROR","message":"db timeout","fields":{"attempt":"3","host":"db-1"},"format":"logfmt","source":"app.log"}`
+	if got := EntryToJSON(entry); got != want {
+		t.Errorf("EntryToJSON =\n%s\nwant\n%s", got, want)
+	}
+
+	got := EntryToJSON(parser.LogEntry{Message: `say "hi"`})
+	var back map[string]any
+	if err := json.Unmarshal([]byte(got), &back); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if back["message"] != `say "hi"` || back["time"] != nil || back["fields"] != nil {
+		t.Errorf("minimal entry = %s", got)
+	}
+}
+
+func TestEntryToCurl(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   string
+		err    string
+	}{
+		{"access log", map[string]string{"method": "POST", "path": "/api/users?page=2", "host": "api.example.com", "status": "500"},
+			"curl -X POST 'http://api.example.com/api/users?page=2'", ""},
+		{"GET needs no -X", map[string]string{"method": "get", "path": "/health", "host": "localhost:8080"},
+			"curl 'http://localhost:8080/health'", ""},
+		{"scheme and user agent", map[string]string{"http_method": "DELETE", "uri": "/items/7", "http_host": "shop.example", "scheme": "https", "user_agent": "it's me/1.0"},
+			`curl -X DELETE 'https://shop.example/items/7' -H 'User-Agent: it'\''s me/1.0'`, ""},
+		{"absolute URL", map[string]string{"method": "PUT", "url": "https://files.example/a b"},
+			"curl -X PUT 'https://files.example/a%20b'", ""},
+		{"request line", map[string]string{"request": "GET /index.html HTTP/1.1", "Host": "www.example.org"},
+			"curl 'http://www.example.org/index.html'", ""},
+		{"no path", map[string]string{"method": "GET", "host": "example.org"}, "", "no URL or path"},
+		{"no host", map[string]string{"method": "GET", "path": "/x"}, "", "no host"},
+	}
+	for _, tt := range tests {
+		got, err := EntryToCurl(parser.LogEntry{Fields: tt.fields})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: EntryToCurl = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestCopyKeys(t *testing.T) {
+	var copied []string
+	clip := func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	r := plainRenderer(func(c *RenderConfig) {
+		c.Redact = RedactConfig{Fields: []string{"token"}, Mode: RedactMask}
+	})
+	m := setupModel(120, 20, 0, WithRenderer(r), WithClipboard(clip))
+	m.entries = []parser.LogEntry{
+		{Level: "INFO", Message: "request", Raw: "method=GET path=/a host=h token=abc", Fields: map[string]string{"method": "GET", "path": "/a", "host": "h", "token": "abc"}},
+		{Level: "INFO", Message: "started", Raw: "started"},
+	}
+
+	m = press(press(press(press(m, "g"), "y"), "Y"), "C")
+	if len(copied) != 3 {
+		t.Fatalf("copied %q", copied)
+	}
+	if strings.Contains(copied[0], "abc") || !strings.Contains(copied[0], "path=/a") {
+		t.Errorf("y copied %q, want the redacted raw line", copied[0])
+	}
+	if !regexp.MustCompile(`^\{.*"message":"request".*\}$`).MatchString(copied[1]) || strings.Contains(copied[1], "abc") {
+		t.Errorf("Y copied %q", copied[1])
+	}
+	if copied[2] != "curl 'http://h/a'" || m.notice != "copied curl command" {
+		t.Errorf("U copied %q, notice %q", copied[2], m.notice)
+	}
+
+	// An entry that is no request copies nothing and says why.
+	m = press(press(m, "j"), "C")
+	if len(copied) != 3 || !strings.Contains(m.notice, "cannot copy as curl") {
+		t.Errorf("copied %q, notice %q", copied, m.notice)
+	}
+
+	m = setupModel(120, 20, 0, WithRenderer(r), WithClipboard(func(string) error { return errors.New("no clipboard") }))
+	m.entries = []parser.LogEntry{{Message: "x", Raw: "x"}}
+	if m = press(press(m, "g"), "y"); m.notice != "copying failed: no clipboard" {
+		t.Errorf("notice = %q", m.notice)
+	}
+}
@@ -0,0 +1,149 @@
+package tui
+
+import "fmt"
+
+// fieldEditor is the state of the field-order editor (key F): every field
+// known to the view in display order, which of them are hidden, and the
+// highlighted row.
+type fieldEditor struct {
+	fields []string
+	hidden map[string]bool
+	cursor int
+	// include is set when the renderer had IncludeFields, which the editor
+	// then keeps in step with the visible fields.
+	include bool
+}
+
+// openFieldEditor lists the fields of the configured field order followed
+// by the other fields of the buffered entries, alphabetically, and opens
+// the editor.
+func (m *Model) openFieldEditor() {
+	if m.renderer == nil {
+		m.notice = "field editor is not available"
+		return
+	}
+	cfg := m.renderer.Config()
+	seen := make(map[string]bool)
+	var fields []string
+	for _, k := range cfg.FieldOrder {
+		if !seen[k] {
+			fields = append(fields, k)
+			seen[k] = true
+		}
+	}
+	var rest []string
+	for _, e := range m.entries {
+		for k := range e.Fields {
+			if !seen[k] {
+				rest = append(rest, k)
+				seen[k] = true
+			}
+		}
+	}
+	sortStrings(rest)
+	fields = append(fields, rest...)
+	if len(fields) == 0 {
+		m.notice = "no fields to arrange"
+		return
+	}
+
+	ed := fieldEditor{fields: fields, hidden: make(map[string]bool), include: len(cfg.IncludeFields) > 0}
+	for _, k := range fields {
+		if !m.renderer.fieldShown(k) {
+			ed.hidden[k] = true
+		}
+	}
+	m.fieldEditor = ed
+	m.showFieldEditor = true
+}
+
+// updateFieldEditor handles a key press, key triggering act, while the
+// field editor is open: the scroll keys move the highlight, J/K move the
+// highlighted field down or up, space shows or hides it, and the close,
+// detail, fields or quit key closes the editor. Every change is applied to
+// the view at once.
+func (m *Model) updateFieldEditor(key string, act action) {
+	ed := &m.fieldEditor
+	last := len(ed.fields) - 1
+	switch act {
+	case actDown:
+		ed.cursor = min(ed.cursor+1, last)
+		return
+	case actUp:
+		ed.cursor = max(ed.cursor-1, 0)
+		return
+	case actClose, actDetail, actFields, actQuit:
+		m.showFieldEditor = false
+		return
+	}
+	switch key {
+	case "J", "shift+down":
+		if ed.cursor < last {
+			ed.move(ed.cursor, ed.cursor+1)
+			m.applyFieldEditor()
+		}
+	case "K", "shift+up":
+		if ed.cursor > 0 {
+			ed.move(ed.cursor, ed.cursor-1)
+			m.applyFieldEditor()
+		}
+	case " ", "x":
+		k := ed.fields[ed.cursor]
+		ed.hidden[k] = !ed.hidden[k]
+		m.applyFieldEditor()
+	}
+}
+
+// move swaps the fields at i and j, keeping the highlight on the moved
+// field.
+func (ed *fieldEditor) move(i, j int) {
+	ed.fields[i], ed.fields[j] = ed.fields[j], ed.fields[i]
+	ed.cursor = j
+}
+
+// applyFieldEditor re-renders the buffer with the editor's field order and
+// visibility. Fields are then shown inline, or there would be nothing to
+// see of the change.
+func (m *Model) applyFieldEditor() {
+	ed := m.fieldEditor
+	var visible, hidden []string
+	for _, k := range ed.fields {
+		if ed.hidden[k] {
+			hidden = append(hidden, k)
+		} else {
+			visible = append(visible, k)
+		}
+	}
+	m.setRenderer(m.renderer.With(func(c *RenderConfig) {
+		c.FieldOrder = visible
+		c.ExcludeFields = hidden
+		if ed.include {
+			c.IncludeFields = visible
+		}
+		c.ShowAllFields = true
+	}))
+}
+
+// renderFieldEditor renders the field editor in at most height rows,
+// keeping the highlighted field in view.
+func (m Model) renderFieldEditor(height int) []string {
+	ed := m.fieldEditor
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ Fields (%d) — J/K move, space shows/hides, esc closes", len(ed.fields))),
+		"",
+	}
+	start := max(ed.cursor-(height-len(rows))+1, 0)
+	for i := start; i < len(ed.fields); i++ {
+		k := ed.fields[i]
+		box := "[x]"
+		if ed.hidden[k] {
+			box = "[ ]"
+		}
+		row := fmt.Sprintf("  %s %s", box, SanitizeControl(k, false))
+		if i == ed.cursor {
+			row = cursorStyle.Render(row)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFieldEditor_Reorder(t *testing.T) {
+	m := presetModel()
+	m.renderer = plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.FieldOrder = []string{"svc", "host"}
+	})
+	if !strings.HasSuffix(m.renderer.RenderEntryPlain(m.entries[0]), "svc=api host=a") {
+		t.Fatalf("unexpected initial order: %q", m.renderer.RenderEntryPlain(m.entries[0]))
+	}
+
+	m = press(m, "F")
+	if !m.showFieldEditor {
+		t.Fatal("F should open the field editor")
+	}
+	if got := strings.Join(m.fieldEditor.fields, ","); got != "svc,host" {
+		t.Fatalf("editor fields = %s, want svc,host", got)
+	}
+	v := StripANSI(m.View())
+	if !contains(v, "[x] svc") || !contains(v, "[x] host") {
+		t.Errorf("editor should list the fields:\n%s", v)
+	}
+
+	// Move svc below host.
+	m = press(m, "J")
+	if got := strings.Join(m.renderer.Config().FieldOrder, ","); got != "host,svc" {
+		t.Errorf("FieldOrder = %s, want host,svc", got)
+	}
+	if m.fieldEditor.cursor != 1 {
+		t.Errorf("cursor = %d, want it to follow the moved field", m.fieldEditor.cursor)
+	}
+	if !strings.HasSuffix(m.lineText(0), "host=a svc=api") {
+		t.Errorf("buffer not re-rendered in the new order: %q", m.lineText(0))
+	}
+
+	// And back up again.
+	m = press(m, "K")
+	if !strings.HasSuffix(m.lineText(0), "svc=api host=a") {
+		t.Errorf("after K: %q, want svc before host", m.lineText(0))
+	}
+
+	m = press(m, "esc")
+	if m.showFieldEditor {
+		t.Error("esc should close the field editor")
+	}
+}
+
+func TestFieldEditor_ToggleVisibility(t *testing.T) {
+	m := presetModel()
+	m = press(m, "F")
+	if got := strings.Join(m.fieldEditor.fields, ","); got != "host,svc" {
+		t.Fatalf("editor fields = %s, want the buffer's fields alphabetically", got)
+	}
+
+	m = press(m, "j")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(Model)
+	cfg := m.renderer.Config()
+	if strings.Join(cfg.ExcludeFields, ",") != "svc" || strings.Join(cfg.FieldOrder, ",") != "host" {
+		t.Errorf("ExcludeFields = %v, FieldOrder = %v", cfg.ExcludeFields, cfg.FieldOrder)
+	}
+	if !strings.HasSuffix(m.lineText(0), "db timeout │ host=a") {
+		t.Errorf("svc should be hidden and host shown: %q", m.lineText(0))
+	}
+	if !contains(StripANSI(m.View()), "[ ] svc") {
+		t.Errorf("editor should mark svc hidden:\n%s", m.View())
+	}
+
+	// The hidden field is kept by a saved preset.
+	quiet := m.currentPreset("quiet")
+	if strings.Join(quiet.HiddenFields, ",") != "svc" {
+		t.Errorf("preset HiddenFields = %v, want svc", quiet.HiddenFields)
+	}
+
+	m = press(m, "x")
+	if len(m.renderer.Config().ExcludeFields) != 0 || !strings.HasSuffix(m.lineText(0), "host=a svc=api") {
+		t.Errorf("x should show svc again: %q", m.lineText(0))
+	}
+
+	// Loading the preset hides it once more.
+	if err := m.ApplyPreset(quiet); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(m.renderer.Config().ExcludeFields, ",") != "svc" {
+		t.Errorf("ApplyPreset: ExcludeFields = %v, want svc", m.renderer.Config().ExcludeFields)
+	}
+}
+
+func TestFieldEditor_IncludeList(t *testing.T) {
+	m := presetModel()
+	m.renderer = plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.IncludeFields = []string{"host"}
+	})
+	m = press(m, "F")
+	if !m.fieldEditor.hidden["svc"] || m.fieldEditor.hidden["host"] {
+		t.Fatalf("hidden = %v, want svc hidden by the include list", m.fieldEditor.hidden)
+	}
+	m = press(m, "j") // svc
+	m = press(m, "x")
+	if got := strings.Join(m.renderer.Config().IncludeFields, ","); got != "host,svc" {
+		t.Errorf("IncludeFields = %s, want host,svc", got)
+	}
+	if !strings.HasSuffix(m.lineText(0), "host=a svc=api") {
+		t.Errorf("svc should be shown: %q", m.lineText(0))
+	}
+}
+
+func TestFieldEditor_NoFields(t *testing.T) {
+	m := setupModel(80, 20, 3)
+	m.renderer = plainRenderer()
+	m = press(m, "F")
+	if m.showFieldEditor || m.notice == "" {
+		t.Errorf("editor opened without fields (notice %q)", m.notice)
+	}
+}
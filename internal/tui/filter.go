@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"regexp"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// EntryFilter reports whether an entry should be shown.
+type EntryFilter func(parser.LogEntry) bool
+
+// WithFilter hides entries the filter rejects. Multiple filters combine:
+// only entries accepted by all of them are shown. Hidden entries stay in the
+// buffer.
+func WithFilter(f EntryFilter) ModelOption {
+	return func(m *Model) { m.filters = append(m.filters, f) }
+}
+
+// TimeWindowFilter returns a filter showing entries whose timestamp lies
+// within w.
+func TimeWindowFilter(w parser.TimeWindow) EntryFilter {
+	return func(e parser.LogEntry) bool { return w.Contains(e.Timestamp) }
+}
+
+// levelRanks orders the canonical levels from least to most severe.
+var levelRanks = map[string]int{"trace": 0, "debug": 1, "info": 2, "warn": 3, "error": 4, "fatal": 5}
+
+// ValidLevel reports whether s names a known level for MinLevelFilter.
+func ValidLevel(s string) bool {
+	_, ok := levelRanks[normalizeLevel(s)]
+	return ok
+}
+
+// MinLevelFilter returns a filter hiding entries less severe than level.
+// Entries without a recognized level are always shown.
+func MinLevelFilter(level string) EntryFilter {
+	min := levelRanks[normalizeLevel(level)]
+	return func(e parser.LogEntry) bool {
+		rank, ok := levelRanks[normalizeLevel(e.Level)]
+		return !ok || rank >= min
+	}
+}
+
+// setFilterText filters the view by text, a regular expression matched
+// against messages, on top of the filters the model was created with. Empty
+// text removes the filter.
+func (m *Model) setFilterText(text string) error {
+	var f EntryFilter
+	var re *regexp.Regexp
+	if text != "" {
+		fm, err := parser.NewFieldMatcher(text, nil, false)
+		if err != nil {
+			return err
+		}
+		f, re = fm.Match, fm.Pattern
+	}
+	keep := m.cursorIndex()
+	m.filterText, m.textFilter, m.filterRe = text, f, re
+	m.refilter(keep)
+	return nil
+}
+
+// updateFilterPrompt handles a key press while the filter prompt is open:
+// typing edits the pattern, enter applies it, esc cancels.
+func (m *Model) updateFilterPrompt(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filterPrompt = false
+		if err := m.setFilterText(m.filterInput); err != nil {
+			m.notice = err.Error()
+		}
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.filterPrompt = false
+	default:
+		m.filterInput = editInput(m.filterInput, msg)
+	}
+}
+
+// filtered reports whether a filter is active, i.e. whether m.view is in use.
+func (m Model) filtered() bool {
+	return len(m.filters) > 0 || m.textFilter != nil || m.levelsHidden()
+}
+
+// entryAt returns the parsed entry for buffer line i, or a zero entry for
+// lines that have none.
+func (m Model) entryAt(i int) parser.LogEntry {
+	if i < 0 || i >= len(m.entries) {
+		return parser.LogEntry{}
+	}
+	return m.entries[i]
+}
+
+// passes reports whether buffer line i is accepted by every filter and its
+// level is toggled on.
+func (m Model) passes(i int) bool {
+	e := m.entryAt(i)
+	if !m.levelShown(e) {
+		return false
+	}
+	if m.textFilter != nil && !m.textFilter(e) {
+		return false
+	}
+	for _, f := range m.filters {
+		if !f(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// viewLen returns the number of lines in the current view.
+func (m Model) viewLen() int {
+	if m.filtered() {
+		return len(m.view)
+	}
+	return len(m.entries)
+}
+
+// bufIndex converts a view position to a buffer index.
+func (m Model) bufIndex(pos int) int {
+	if m.filtered() {
+		return m.view[pos]
+	}
+	return pos
+}
+
+// viewPos returns the view position of buffer index i. If i is hidden, it
+// returns the position of the next visible line and false.
+func (m Model) viewPos(i int) (int, bool) {
+	if !m.filtered() {
+		return i, true
+	}
+	lo, hi := 0, len(m.view)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if m.view[mid] < i {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, lo < len(m.view) && m.view[lo] == i
+}
+
+// cursorIndex returns the buffer index under the cursor, or -1 when the view
+// is empty.
+func (m Model) cursorIndex() int {
+	if m.cursor < 0 || m.cursor >= m.viewLen() {
+		return -1
+	}
+	return m.bufIndex(m.cursor)
+}
+
+// extendView adds buffer lines from index start onwards to the view.
+func (m *Model) extendView(start int) {
+	if !m.filtered() {
+		return
+	}
+	for i := start; i < len(m.entries); i++ {
+		if m.passes(i) {
+			m.view = append(m.view, i)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func windowModel() Model {
+	base := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+	w := parser.TimeWindow{Since: base.Add(10 * time.Minute), Until: base.Add(20 * time.Minute)}
+	var entries []parser.LogEntry
+	for i := 0; i < 30; i++ {
+		ts := base.Add(time.Duration(i) * time.Minute)
+		entries = append(entries, parser.LogEntry{Message: ts.Format("15:04"), Timestamp: ts})
+	}
+	return applyMsgs(setupModel(80, 24, 0, WithFilter(TimeWindowFilter(w))), logMsgs(entries...))
+}
+
+func TestFilter_TimeWindowHidesEntries(t *testing.T) {
+	m := windowModel()
+	if m.viewLen() != 11 {
+		t.Fatalf("viewLen = %d, want 11 (20:10..20:20 inclusive)", m.viewLen())
+	}
+	if len(m.entries) != 30 {
+		t.Errorf("hidden lines should stay buffered, len = %d", len(m.entries))
+	}
+	v := m.View()
+	if contains(v, "20:09") || contains(v, "20:21") || !contains(v, "20:10") || !contains(v, "20:20") {
+		t.Errorf("view should show only the window:\n%s", v)
+	}
+	if !contains(v, "11/30") {
+		t.Error("status bar should show shown/total lines")
+	}
+}
+
+func TestFilter_CursorUsesBufferIndices(t *testing.T) {
+	m := windowModel()
+	m = press(m, "g")
+	if got := m.cursorIndex(); got != 10 {
+		t.Errorf("cursorIndex at top = %d, want buffer index 10", got)
+	}
+	m.showLineNumbers = true
+	if rows := m.viewportRows(m.viewHeight()); !contains(StripANSI(rows[0]), "11 20:10") {
+		t.Errorf("line numbers should be buffer positions, row 0 = %q", StripANSI(rows[0]))
+	}
+	m = press(m, "enter")
+	if !contains(m.View(), "20:10:00") {
+		t.Error("detail pane should show the entry under the cursor")
+	}
+}
+
+func TestFilter_EmptyResult(t *testing.T) {
+	m := setupModel(80, 24, 0, WithFilter(func(parser.LogEntry) bool { return false }))
+	updated, _ := m.Update(LogMsg{Rendered: "hidden", Entry: parser.LogEntry{Message: "hidden"}})
+	m = updated.(Model)
+	if v := m.View(); !contains(v, "No entries match") || contains(v, "hidden") {
+		t.Errorf("expected no-match message:\n%s", v)
+	}
+}
+
+func TestFilter_DropOldestShiftsView(t *testing.T) {
+	m := windowModel()
+	m.maxLines = 25
+	m.appendLines([]string{"x"}, []parser.LogEntry{{}}) // untimed, passes
+	// 31 lines over a cap of 25 drops buffer lines 0..5, none of them visible.
+	if m.viewLen() != 12 || m.view[0] != 4 {
+		t.Errorf("view = %v, want 12 entries starting at index 4", m.view)
+	}
+}
@@ -0,0 +1,47 @@
+package tui
+
+import "fmt"
+
+// WithSmartFollow decouples the cursor from following. While the view
+// follows the end of the log, new lines still scroll it to the bottom, but
+// the cursor only moves along if it was on the last line; otherwise it stays
+// on the entry being read and the status bar counts the lines arriving
+// below it. Moving the cursor within the bottom page keeps following on.
+func WithSmartFollow(on bool) ModelOption {
+	return func(m *Model) { m.smartFollow = on }
+}
+
+// follow scrolls to the bottom after n entries were appended, if the view
+// is following. The cursor goes to the last line, unless smart follow is on
+// and it was not on the last line before (wasLast), in which case it stays
+// put.
+func (m *Model) follow(n int, wasLast bool) {
+	if !m.autoScroll {
+		return
+	}
+	m.offset = m.maxOffset()
+	if m.smartFollow && !wasLast {
+		for i := max(len(m.entries)-n, 0); i < len(m.entries); i++ {
+			if m.passes(i) {
+				m.newBelow++
+			}
+		}
+		return
+	}
+	m.cursor = max(m.viewLen()-1, 0)
+}
+
+// onLastLine reports whether the cursor is on the last line of the view, or
+// the view is empty.
+func (m Model) onLastLine() bool {
+	return m.cursor >= m.viewLen()-1
+}
+
+// renderNewBelow returns the status bar hint for lines that arrived below
+// the cursor under smart follow, or "" if there are none.
+func (m Model) renderNewBelow() string {
+	if m.newBelow == 0 {
+		return ""
+	}
+	return statusItem("↓", fmt.Sprintf("%d new below", m.newBelow))
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// followBatch returns a batch of n entries.
+func followBatch(n int) LogBatchMsg {
+	var msg LogBatchMsg
+	for i := 0; i < n; i++ {
+		text := fmt.Sprintf("new %d", i)
+		msg.Lines = append(msg.Lines, text)
+		msg.Entries = append(msg.Entries, parser.LogEntry{Message: text})
+	}
+	return msg
+}
+
+// followModel returns a model with 100 lines, following the end of the
+// log, with the cursor three lines above the bottom.
+func followModel(opts ...ModelOption) Model {
+	m := applyMsgs(setupModel(80, 24, 0, opts...), []tea.Msg{followBatch(100)})
+	m.cursor = 96
+	return m
+}
+
+func TestFollow_AlwaysJump(t *testing.T) {
+	m := applyMsgs(followModel(), []tea.Msg{followBatch(50)})
+	if m.cursor != 149 {
+		t.Errorf("cursor = %d, want 149 (the new last line)", m.cursor)
+	}
+	if !m.isAtBottom() || m.newBelow != 0 {
+		t.Errorf("isAtBottom = %v, newBelow = %d", m.isAtBottom(), m.newBelow)
+	}
+}
+
+func TestFollow_SmartKeepsCursor(t *testing.T) {
+	m := applyMsgs(followModel(WithSmartFollow(true)), []tea.Msg{followBatch(50)})
+	if m.cursor != 96 {
+		t.Errorf("cursor = %d, want it to stay on 96", m.cursor)
+	}
+	if !m.isAtBottom() {
+		t.Error("the viewport should still stick to the bottom")
+	}
+	if m.newBelow != 50 {
+		t.Errorf("newBelow = %d, want 50", m.newBelow)
+	}
+	if v := StripANSI(m.View()); !contains(v, "50 new below") {
+		t.Errorf("expected a new-lines hint in the status bar:\n%s", v)
+	}
+
+	// Going to the end clears the hint.
+	m = press(m, "G")
+	if m.cursor != 149 || m.newBelow != 0 {
+		t.Errorf("after G: cursor = %d, newBelow = %d", m.cursor, m.newBelow)
+	}
+}
+
+func TestFollow_SmartOnLastLine(t *testing.T) {
+	m := followModel(WithSmartFollow(true))
+	m.cursor = 99
+	m = applyMsgs(m, []tea.Msg{followBatch(50)})
+	if m.cursor != 149 || m.newBelow != 0 {
+		t.Errorf("cursor = %d, newBelow = %d; want the cursor to follow from the last line", m.cursor, m.newBelow)
+	}
+}
+
+func TestFollow_SmartCursorMovesKeepFollowing(t *testing.T) {
+	m := followModel(WithSmartFollow(true))
+	m.cursor = 99
+	m = press(m, "k")
+	m = press(m, "k")
+	if !m.autoScroll {
+		t.Fatal("moving the cursor within the bottom page should keep following")
+	}
+	m = applyMsgs(m, []tea.Msg{followBatch(5)})
+	if m.cursor != 97 || !m.isAtBottom() || m.newBelow != 5 {
+		t.Errorf("cursor = %d, at bottom = %v, newBelow = %d", m.cursor, m.isAtBottom(), m.newBelow)
+	}
+
+	// Scrolling the viewport up stops following, as without smart follow.
+	m = press(m, "g")
+	if m.autoScroll {
+		t.Fatal("g should stop following")
+	}
+	m = applyMsgs(m, []tea.Msg{followBatch(5)})
+	if m.cursor != 0 || m.offset != 0 {
+		t.Errorf("cursor = %d, offset = %d; want the view left alone", m.cursor, m.offset)
+	}
+}
+
+func TestFollow_SmartCountsVisibleOnly(t *testing.T) {
+	m := followModel(WithSmartFollow(true))
+	m.levelVisible["debug"] = false
+	m.refilter(m.cursorIndex())
+	m.cursor = 96
+	batch := followBatch(4)
+	batch.Entries[0].Level = "debug"
+	batch.Entries[2].Level = "debug"
+	m = applyMsgs(m, []tea.Msg{batch})
+	if m.newBelow != 2 {
+		t.Errorf("newBelow = %d, want the 2 visible lines", m.newBelow)
+	}
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var gapStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+
+// WithGapMarker sets the silence between two displayed entries above which
+// a "──── gap 4m12s ────" row is shown between them. Zero, the default,
+// disables gap markers.
+func WithGapMarker(d time.Duration) ModelOption {
+	return func(m *Model) { m.gapMarker = d }
+}
+
+// gapBefore reports the time between the entry at view position pos and
+// the entry displayed above it, if it exceeds the gap marker threshold.
+// Entries without a timestamp never form a gap, and a session boundary,
+// whose separator already names the gap, takes precedence.
+func (m Model) gapBefore(pos int) (time.Duration, bool) {
+	if m.gapMarker <= 0 || pos <= 0 || pos >= m.viewLen() {
+		return 0, false
+	}
+	i := m.bufIndex(pos)
+	if _, ok := m.sessionBoundary(i); ok {
+		return 0, false
+	}
+	prev, cur := m.entries[m.bufIndex(pos-1)].Timestamp, m.entries[i].Timestamp
+	if prev.IsZero() || cur.IsZero() {
+		return 0, false
+	}
+	gap := cur.Sub(prev)
+	if gap <= m.gapMarker {
+		return 0, false
+	}
+	return gap, true
+}
+
+// renderGapMarker renders the row shown between two entries gap apart.
+func renderGapMarker(gap time.Duration) string {
+	return gapStyle.Render("──── gap " + formatGap(gap) + " ────")
+}
+
+// formatGap formats d with its two most significant units, e.g. 4m12s,
+// 2h5m or 3d1h.
+func formatGap(d time.Duration) string {
+	d = d.Round(time.Second)
+	days, hours := int(d/(24*time.Hour)), int(d/time.Hour)%24
+	mins, secs := int(d/time.Minute)%60, int(d/time.Second)%60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, mins)
+	case mins > 0:
+		return fmt.Sprintf("%dm%ds", mins, secs)
+	default:
+		return fmt.Sprintf("%ds", secs)
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGapMarker(t *testing.T) {
+	m := sessionModel(0, 10*time.Second, 4*time.Minute+22*time.Second, -1, 20*time.Minute, 20*time.Minute+30*time.Second)
+	m.gapMarker = time.Minute
+
+	want := map[int]bool{2: true} // entries 3 and 4 lack a timestamp on one side
+	for pos := range m.entries {
+		if _, ok := m.gapBefore(pos); ok != want[pos] {
+			t.Errorf("gapBefore(%d) = %v, want %v", pos, ok, want[pos])
+		}
+	}
+
+	v := StripANSI(m.View())
+	if !contains(v, "──── gap 4m12s ────") {
+		t.Errorf("expected a gap marker in view:\n%s", v)
+	}
+	if strings.Count(v, " gap ") != 1 {
+		t.Errorf("expected exactly one gap marker in view:\n%s", v)
+	}
+}
+
+func TestGapMarkerDisabled(t *testing.T) {
+	m := sessionModel(0, 10*time.Minute)
+	if _, ok := m.gapBefore(1); ok {
+		t.Error("gap markers should be off by default")
+	}
+	if contains(m.View(), " gap ") {
+		t.Error("no gap marker expected without a threshold")
+	}
+}
+
+func TestGapMarkerDoesNotShiftSelection(t *testing.T) {
+	m := sessionModel(0, 5*time.Minute, 5*time.Minute+time.Second)
+	m.gapMarker = time.Minute
+
+	rows, positions := m.viewportLayout(m.logPaneHeight())
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 3 entries and a marker:\n%s", len(rows), strings.Join(rows, "\n"))
+	}
+	if want := []int{0, -1, 1, 2}; !reflect.DeepEqual(positions, want) {
+		t.Errorf("positions = %v, want %v", positions, want)
+	}
+
+	m.cursor = 0
+	m = press(m, "j")
+	if m.cursor != 1 || m.cursorIndex() != 1 {
+		t.Errorf("after j: cursor = %d (entry %d), want the entry below the marker", m.cursor, m.cursorIndex())
+	}
+}
+
+func TestGapMarkerFollowsFilter(t *testing.T) {
+	// Entry 1 is hidden, so entries 0 and 2 are adjacent on screen and two
+	// minutes apart, though no two buffer neighbours are.
+	m := sessionModel(0, time.Minute, 2*time.Minute)
+	m.gapMarker = 90 * time.Second
+	m.entries[1].Level = "debug"
+	m.levelVisible["debug"] = false
+	m.refilter(0)
+
+	if _, ok := m.gapBefore(1); !ok {
+		t.Error("expected a gap between the displayed entries")
+	}
+	if !contains(StripANSI(m.View()), "gap 2m0s") {
+		t.Errorf("expected a gap marker in view:\n%s", m.View())
+	}
+}
+
+func TestGapMarkerYieldsToSession(t *testing.T) {
+	m := sessionModel(0, 2*time.Hour)
+	m.gapMarker = time.Minute
+	v := StripANSI(m.View())
+	if !contains(v, "new session") || contains(v, "────") {
+		t.Errorf("expected only the session separator:\n%s", v)
+	}
+}
+
+func TestFormatGap(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{42 * time.Second, "42s"},
+		{4*time.Minute + 12*time.Second, "4m12s"},
+		{2*time.Hour + 5*time.Minute + 59*time.Second, "2h5m"},
+		{75 * time.Hour, "3d3h"},
+	}
+	for _, tt := range tests {
+		if got := formatGap(tt.d); got != tt.want {
+			t.Errorf("formatGap(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// DefaultGroupField is the field the group view (key T) groups entries by.
+const DefaultGroupField = "trace_id"
+
+// WithGroupField sets the field the group view (key T) groups entries by,
+// DefaultGroupField unless set. "message" groups by the message unless the
+// entries have a field of that name.
+func WithGroupField(key string) ModelOption {
+	return func(m *Model) { m.groupField = key }
+}
+
+// GroupByField returns the indices of the entries whose field key has the
+// given value, ordered by timestamp. Values are compared after norm is
+// applied to both. Entries with the same timestamp keep their order, and
+// entries without one come first.
+func GroupByField(entries []parser.LogEntry, key, value string, norm KeyNormalization) []int {
+	var group []int
+	value = normalizeKey(value, norm)
+	for i, e := range entries {
+		if v, ok := groupFieldValue(e, key); ok && normalizeKey(v, norm) == value {
+			group = append(group, i)
+		}
+	}
+	for i := 1; i < len(group); i++ {
+		for j := i; j > 0 && entries[group[j]].Timestamp.Before(entries[group[j-1]].Timestamp); j-- {
+			group[j], group[j-1] = group[j-1], group[j]
+		}
+	}
+	return group
+}
+
+// groupFieldValue returns the value of the field key of e, falling back to
+// the message for "message".
+func groupFieldValue(e parser.LogEntry, key string) (string, bool) {
+	if v, ok := e.Fields[key]; ok {
+		return v, true
+	}
+	if key == "message" {
+		return e.Message, true
+	}
+	return "", false
+}
+
+// groupKey returns the field the group view groups by.
+func (m Model) groupKey() string {
+	if m.groupField == "" {
+		return DefaultGroupField
+	}
+	return m.groupField
+}
+
+// toggleGroup opens the group view for the value of the group field of the
+// cursor entry, or closes it.
+func (m *Model) toggleGroup() {
+	if m.showGroup {
+		m.closeGroup()
+		return
+	}
+	i := m.cursorIndex()
+	if i < 0 || i >= len(m.entries) {
+		return
+	}
+	value, ok := groupFieldValue(m.entries[i], m.groupKey())
+	if !ok || value == "" {
+		m.notice = "entry has no " + m.groupKey() + " field"
+		return
+	}
+	m.showGroup = true
+	m.groupValue = value
+	m.groupEntry = m.dropped + i
+	m.groupOffset = 0
+}
+
+// closeGroup leaves the group view.
+func (m *Model) closeGroup() {
+	m.showGroup = false
+	m.groupValue = ""
+}
+
+// scrollGroup scrolls the group view if act is one of the scroll actions,
+// and reports whether it was.
+func (m *Model) scrollGroup(act action) bool {
+	switch act {
+	case actDown:
+		m.groupOffset++
+	case actUp:
+		m.groupOffset--
+	case actTop:
+		m.groupOffset = 0
+	default:
+		return false
+	}
+	n := len(GroupByField(m.entries, m.groupKey(), m.groupValue, m.groupNorm))
+	m.groupOffset = max(min(m.groupOffset, n-1), 0)
+	return true
+}
+
+// renderGroup renders the group view rows: every buffered entry whose group
+// field has the selected value, ignoring any filter, in timestamp order.
+// The entry the view was opened on is highlighted; entries arriving while it
+// is open join in.
+func (m Model) renderGroup() []string {
+	group := GroupByField(m.entries, m.groupKey(), m.groupValue, m.groupNorm)
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ %s=%s (%d entries)", m.groupKey(), m.groupValue, len(group))),
+		"",
+	}
+	if len(group) == 0 {
+		return rows
+	}
+	last := 0
+	for _, i := range group {
+		last = max(last, i)
+	}
+	numWidth := len(fmt.Sprint(m.lineNumber(last)))
+	width := 0
+	if m.width > 0 {
+		width = max(m.width-numWidth-3, 1)
+	}
+	start := min(m.groupOffset, len(group)-1)
+	m.fitTable(group[start:])
+	for _, i := range group[start:] {
+		line := truncateToWidth(m.lineText(i), width)
+		marker := "  "
+		if m.dropped+i == m.groupEntry {
+			marker = "▶ "
+			line = cursorStyle.Render(line)
+		}
+		num := lineNumberStyle.Render(fmt.Sprintf("%*d", numWidth, m.lineNumber(i)))
+		rows = append(rows, marker+num+" "+line)
+	}
+	return rows
+}
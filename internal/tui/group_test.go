@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// traceEntries interleaves two traces, logged out of timestamp order.
+func traceEntries() []parser.LogEntry {
+	at := func(sec int) time.Time { return time.Date(2026, 2, 17, 19, 0, sec, 0, time.UTC) }
+	trace := func(id string) map[string]string { return map[string]string{"trace_id": id} }
+	return []parser.LogEntry{
+		{Timestamp: at(3), Message: "api response", Fields: trace("t1")},
+		{Timestamp: at(1), Message: "api request", Fields: trace("t1")},
+		{Timestamp: at(2), Message: "other request", Fields: trace("t2")},
+		{Message: "unrelated"},
+		{Timestamp: at(2), Message: "db query", Fields: trace("t1")},
+		{Timestamp: at(4), Message: "other response", Fields: trace("t2")},
+		{Timestamp: at(2), Message: "cache miss", Fields: trace("t1")},
+	}
+}
+
+func TestGroupByField(t *testing.T) {
+	entries := traceEntries()
+	tests := []struct {
+		key, value string
+		want       []int
+	}{
+		{"trace_id", "t1", []int{1, 4, 6, 0}},
+		{"trace_id", "t2", []int{2, 5}},
+		{"trace_id", "t3", nil},
+		{"span_id", "t1", nil},
+	}
+	for _, tt := range tests {
+		got := GroupByField(entries, tt.key, tt.value, KeyNormalization{})
+		if len(got) != len(tt.want) {
+			t.Errorf("GroupByField(%s=%s) = %v, want %v", tt.key, tt.value, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("GroupByField(%s=%s) = %v, want %v", tt.key, tt.value, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestGroupView(t *testing.T) {
+	m := applyMsgs(setupModel(80, 30, 0), logMsgs(traceEntries()...))
+	m = press(press(m, "g"), "j") // "api request"
+
+	m = press(m, "T")
+	if !m.showGroup || m.groupValue != "t1" {
+		t.Fatalf("group view not opened on t1: %v, %q", m.showGroup, m.groupValue)
+	}
+	v := StripANSI(m.View())
+	if !contains(v, "trace_id=t1 (4 entries)") {
+		t.Errorf("header missing:\n%s", v)
+	}
+	if contains(v, "other request") || contains(v, "unrelated") {
+		t.Errorf("group view shows entries of other traces:\n%s", v)
+	}
+	order := []string{"▶ 2 api request", "5 db query", "7 cache miss", "1 api response"}
+	last := -1
+	for _, s := range order {
+		i := strings.Index(v, s)
+		if i < last {
+			t.Errorf("%q out of order:\n%s", s, v)
+		}
+		last = i
+	}
+
+	// j scrolls the group view, not the cursor.
+	if m = press(m, "j"); m.groupOffset != 1 || m.cursor != 1 || contains(StripANSI(m.View()), "api request") {
+		t.Errorf("j should scroll the group view: offset %d, cursor %d", m.groupOffset, m.cursor)
+	}
+
+	m = press(m, "esc")
+	if m.showGroup || !contains(StripANSI(m.View()), "unrelated") {
+		t.Error("esc should return to the full view")
+	}
+
+	// Other fields group too, and an entry without the field says so.
+	m = press(NewModel(WithGroupField("span_id")), "T")
+	if m.showGroup {
+		t.Error("T on an empty buffer should not open the group view")
+	}
+	m = presetModel(WithGroupField("svc"))
+	if m = press(press(m, "g"), "T"); !m.showGroup || !contains(StripANSI(m.View()), "svc=api (2 entries)") {
+		t.Errorf("grouping by svc failed:\n%s", StripANSI(m.View()))
+	}
+	if m = press(press(press(m, "T"), "G"), "T"); m.showGroup || m.notice != "entry has no svc field" {
+		t.Errorf("group view opened on an entry without svc; notice %q", m.notice)
+	}
+}
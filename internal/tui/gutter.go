@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var lineNumberStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240"))
+
+// WithLineNumbers shows or hides the line-number gutter.
+func WithLineNumbers(show bool) ModelOption {
+	return func(m *Model) { m.showLineNumbers = show }
+}
+
+// lineNumber returns the 1-based absolute number of buffer line i. Numbers
+// stay stable when old lines are dropped from a capped buffer.
+func (m Model) lineNumber(i int) int {
+	return m.dropped + i + 1
+}
+
+// lineNumberWidth returns the digits needed for the largest line number in
+// the visible range ending (exclusive) at buffer index end.
+func (m Model) lineNumberWidth(end int) int {
+	if !m.showLineNumbers {
+		return 0
+	}
+	return len(fmt.Sprint(m.lineNumber(max(end-1, 0))))
+}
+
+// gutterWidth returns the total width of the gutters in front of each line
+// for a visible range ending at end.
+func (m Model) gutterWidth(end int) int {
+	w := 0
+	if m.showLineNumbers {
+		w += m.lineNumberWidth(end) + 1
+	}
+	if len(m.marks) > 0 {
+		w += lipgloss.Width(markGutter)
+	}
+	return w
+}
+
+// contentWidth returns the width left for log text next to the gutters, or
+// 0 when the terminal size is not known yet.
+func (m Model) contentWidth(end int) int {
+	if m.width <= 0 {
+		return 0
+	}
+	return max(m.width-m.gutterWidth(end), 1)
+}
+
+// lineNumberGutter renders the right-aligned number for buffer line i.
+func (m Model) lineNumberGutter(i, width int) string {
+	if !m.showLineNumbers {
+		return ""
+	}
+	return lineNumberStyle.Render(fmt.Sprintf("%*d", width, m.lineNumber(i))) + " "
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLineNumbers_Toggle(t *testing.T) {
+	m := setupModel(80, 24, 5)
+	if contains(m.View(), " 1 line 0") {
+		t.Error("line numbers should be off by default")
+	}
+	m = press(m, "#")
+	rows := m.viewportRows(m.viewHeight())
+	if StripANSI(rows[0]) != "1 line 0" {
+		t.Errorf("row 0 = %q, want %q", StripANSI(rows[0]), "1 line 0")
+	}
+	m = press(m, "#")
+	if rows := m.viewportRows(m.viewHeight()); StripANSI(rows[0]) != "line 0" {
+		t.Errorf("second # should hide numbers, row 0 = %q", StripANSI(rows[0]))
+	}
+}
+
+func TestLineNumbers_RightAligned(t *testing.T) {
+	m := setupModel(80, 24, 120)
+	m.showLineNumbers = true
+	m.offset = 95 // visible lines 96..116
+	rows := m.viewportRows(m.viewHeight())
+
+	first, last := StripANSI(rows[0]), StripANSI(rows[len(rows)-1])
+	if first != " 96 line 95" {
+		t.Errorf("first row = %q, want padded to 3 digits", first)
+	}
+	if !strings.HasPrefix(last, "116 ") {
+		t.Errorf("last row = %q", last)
+	}
+	if w := m.lineNumberWidth(m.offset + m.viewHeight()); w != 3 {
+		t.Errorf("lineNumberWidth = %d, want 3", w)
+	}
+}
+
+func TestLineNumbers_StableAcrossDrops(t *testing.T) {
+	m := setupModel(80, 24, 0, WithMaxLines(10), WithLineNumbers(true))
+	m = feed(m, 15)
+	rows := m.viewportRows(m.viewHeight())
+	if got := StripANSI(rows[0]); !strings.HasPrefix(got, " 6 ") {
+		t.Errorf("first row = %q, want absolute line 6", got)
+	}
+}
+
+func TestLineNumbers_ContentWidthShrinks(t *testing.T) {
+	long := strings.Repeat("x", 100)
+	m := setupModel(40, 10, 0)
+	m.appendLines([]string{long}, nil)
+
+	if w := m.contentWidth(1); w != 40 {
+		t.Errorf("contentWidth without gutter = %d, want 40", w)
+	}
+	m.showLineNumbers = true
+	if w := m.contentWidth(1); w != 38 {
+		t.Errorf("contentWidth with gutter = %d, want 38", w)
+	}
+
+	row := m.viewportRows(m.viewHeight())[0]
+	if w := lipgloss.Width(row); w != 40 {
+		t.Errorf("row width = %d, want it to fit the 40-column terminal", w)
+	}
+	if !strings.HasSuffix(StripANSI(row), "…") {
+		t.Errorf("long line should be truncated: %q", StripANSI(row))
+	}
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// matchStyle picks out the text the active filter matches.
+var matchStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("0")).
+	Background(lipgloss.Color("220"))
+
+// HighlightMatches renders s in base, with the non-empty matches of re in
+// the match highlight style instead. A nil re renders all of s in base.
+func HighlightMatches(s string, re *regexp.Regexp, base lipgloss.Style) string {
+	if re == nil {
+		return base.Render(s)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		if loc[0] > last {
+			b.WriteString(base.Render(s[last:loc[0]]))
+		}
+		b.WriteString(matchStyle.Render(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	if last == 0 {
+		return base.Render(s)
+	}
+	if last < len(s) {
+		b.WriteString(base.Render(s[last:]))
+	}
+	return b.String()
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// withColors makes lipgloss emit colors for the rest of the test, as it
+// would on a terminal.
+func withColors(t *testing.T) {
+	t.Helper()
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+	if matchStyle.Render("x") == "x" {
+		t.Fatal("lipgloss still renders without colors")
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	withColors(t)
+	base := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	tests := []struct {
+		s, pattern, want string
+	}{
+		{"connection timeout after 5s", "timeout",
+			base.Render("connection ") + matchStyle.Render("timeout") + base.Render(" after 5s")},
+		{"timeout", "timeout", matchStyle.Render("timeout")},
+		{"a1b22", `\d+`, base.Render("a") + matchStyle.Render("1") + base.Render("b") + matchStyle.Render("22")},
+		{"no match here", "timeout", base.Render("no match here")},
+		{"empty matches", "x*", base.Render("empty matches")},
+	}
+	for _, tt := range tests {
+		if got := HighlightMatches(tt.s, regexp.MustCompile(tt.pattern), base); got != tt.want {
+			t.Errorf("HighlightMatches(%q, %q) = %q, want %q", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+	if got, want := HighlightMatches("text", nil, base), base.Render("text"); got != want {
+		t.Errorf("nil pattern: %q, want %q", got, want)
+	}
+}
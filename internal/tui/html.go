@@ -0,0 +1,245 @@
+package tui
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/muesli/termenv"
+)
+
+// DefaultExportPath is the file the export prompt (key E) suggests.
+const DefaultExportPath = "logpilot-export.html"
+
+// htmlEntry is one entry of an HTML export, prepared for htmlTemplate.
+type htmlEntry struct {
+	Class     string // level class, e.g. "level-error"
+	Timestamp string
+	Level     string
+	Message   string
+	Fields    []htmlField
+}
+
+// htmlField is one field of an htmlEntry; Style colors its value and
+// KeyStyle, with RenderConfig.ColorKeysByName, its key.
+type htmlField struct {
+	Key, Value      string
+	Style, KeyStyle template.CSS
+}
+
+var htmlTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>LogPilot export</title>
+<style>
+body { margin: 1em; font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; {{.Page}} }
+table { border-collapse: collapse; }
+td { padding: 1px 8px 1px 0; vertical-align: top; white-space: pre-wrap; }
+td.ts, td.level { white-space: nowrap; }
+.meta { {{.Separator}} }
+.ts { {{.Timestamp}} }
+.msg { {{.Message}} }
+.key { {{.Key}} }
+.sep { {{.Separator}} }
+{{range .Levels}}.{{.Class}} .level { {{.Style}} }
+{{end}}</style>
+</head>
+<body>
+<p class="meta">{{len .Entries}} entries</p>
+<table>
+{{range .Entries}}<tr class="entry {{.Class}}"><td class="ts">{{.Timestamp}}</td><td class="level">{{.Level}}</td><td class="msg">{{.Message}}</td><td class="fields">{{range $i, $f := .Fields}}{{if $i}} {{end}}<span class="key"{{with $f.KeyStyle}} style="{{.}}"{{end}}>{{$f.Key}}</span><span class="sep">=</span><span class="val" style="{{$f.Style}}">{{$f.Value}}</span>{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// htmlLevels are the level classes of an export, in their canonical form.
+var htmlLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// ExportHTML writes entries to w as a self-contained HTML page, one table
+// row per entry, colored like r renders them: each level has a CSS class
+// such as "level-error", and the theme's styles become inline CSS. Entries
+// are redacted and their fields selected as r would; unlike on a terminal
+// line, the whole message and every field value are kept, and timestamps
+// are absolute.
+func ExportHTML(w io.Writer, entries []parser.LogEntry, r *Renderer) error {
+	bg, fg := "#1c1c1c", "#d0d0d0"
+	if r.config.Theme == ThemeLight || r.config.Theme == ThemeSolarizedLight {
+		bg, fg = "#ffffff", "#1c1c1c"
+	}
+	if r.config.Theme == ThemeSolarizedDark {
+		bg = string(solBase03)
+	}
+
+	type levelCSS struct {
+		Class string
+		Style template.CSS
+	}
+	data := struct {
+		Page, Timestamp, Message, Key, Separator template.CSS
+		Levels                                   []levelCSS
+		Entries                                  []htmlEntry
+	}{
+		Page:      template.CSS("background-color:" + bg + ";color:" + fg),
+		Timestamp: styleCSS(r.styles.timestamp, bg),
+		Message:   styleCSS(r.styles.message, bg),
+		Key:       styleCSS(r.styles.fieldKey, bg),
+		Separator: styleCSS(r.styles.separator, bg),
+	}
+	for _, level := range htmlLevels {
+		data.Levels = append(data.Levels, levelCSS{"level-" + level, styleCSS(r.levelStyle(level), bg)})
+	}
+	for _, e := range entries {
+		data.Entries = append(data.Entries, r.htmlEntry(e, bg))
+	}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("writing HTML: %w", err)
+	}
+	return nil
+}
+
+// htmlEntry prepares entry for the HTML export.
+func (r *Renderer) htmlEntry(entry parser.LogEntry, bg string) htmlEntry {
+	entry = r.SelectFields(r.Redact(entry))
+	text := func(s string) string { return r.display(StripANSI(s)) }
+
+	var h htmlEntry
+	if !entry.Timestamp.IsZero() {
+		h.Timestamp = r.DisplayTime(entry.Timestamp).Format("2006-01-02 15:04:05.000")
+	}
+	if entry.Level != "" {
+		norm := normalizeLevel(entry.Level)
+		h.Level = r.levelLabel(norm)
+		h.Class = "level-" + htmlLevelClass(norm)
+	}
+	msg := entry.Message
+	if msg == "" {
+		msg = entry.Raw
+	}
+	h.Message = text(strings.TrimRight(msg, "\n"))
+	for _, k := range r.orderedFieldKeys(entry.Fields) {
+		v := entry.Fields[k]
+		f := htmlField{Key: text(k), Value: text(v), Style: styleCSS(r.valueStyle(k, v), bg)}
+		if r.config.ColorKeysByName {
+			f.KeyStyle = styleCSS(r.keyStyle(k), bg)
+		}
+		h.Fields = append(h.Fields, f)
+	}
+	return h
+}
+
+// htmlLevelClass returns the level class suffix for a normalized level.
+func htmlLevelClass(norm string) string {
+	switch norm {
+	case "trace", "debug", "info", "warn", "error":
+		return norm
+	case "fatal", "panic", "critical":
+		return "fatal"
+	default:
+		return "other"
+	}
+}
+
+// styleCSS converts the colors and attributes of st to CSS declarations.
+// bg is the page background, which a reversed style takes as its color.
+func styleCSS(st lipgloss.Style, bg string) template.CSS {
+	fg, back := cssColor(st.GetForeground()), cssColor(st.GetBackground())
+	if st.GetReverse() {
+		fg, back = back, fg
+		if fg == "" {
+			fg = bg
+		}
+	}
+	var decls []string
+	if fg != "" {
+		decls = append(decls, "color:"+fg)
+	}
+	if back != "" {
+		decls = append(decls, "background-color:"+back)
+	}
+	if st.GetBold() {
+		decls = append(decls, "font-weight:bold")
+	}
+	if st.GetItalic() {
+		decls = append(decls, "font-style:italic")
+	}
+	if st.GetUnderline() {
+		decls = append(decls, "text-decoration:underline")
+	}
+	if st.GetFaint() {
+		decls = append(decls, "opacity:0.7")
+	}
+	return template.CSS(strings.Join(decls, ";"))
+}
+
+// cssColor returns c as a CSS hex color, or "" if it is not set. ANSI color
+// numbers are converted with the xterm palette.
+func cssColor(c lipgloss.TerminalColor) string {
+	col, ok := c.(lipgloss.Color)
+	if !ok || col == "" {
+		return ""
+	}
+	if strings.HasPrefix(string(col), "#") {
+		return string(col)
+	}
+	n, err := strconv.Atoi(string(col))
+	if err != nil || n < 0 || n > 255 {
+		return ""
+	}
+	return termenv.ConvertToRGB(termenv.ANSI256Color(n)).Hex()
+}
+
+// exportHTML writes the entries of the view, filtered as shown, to path.
+func (m *Model) exportHTML(path string) error {
+	var entries []parser.LogEntry
+	for pos := 0; pos < m.viewLen(); pos++ {
+		if i := m.bufIndex(pos); i < len(m.entries) {
+			entries = append(entries, m.entries[i])
+		}
+	}
+	r := m.renderer
+	if r == nil {
+		r = NewRenderer(DefaultConfig())
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ExportHTML(f, entries, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	m.notice = fmt.Sprintf("exported %d entries to %s", len(entries), path)
+	return nil
+}
+
+// updateExportPrompt handles a key press while the export prompt is open:
+// typing edits the path, enter writes the file, esc cancels.
+func (m *Model) updateExportPrompt(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.exportPrompt = false
+		path := strings.TrimSpace(m.exportInput)
+		if path == "" {
+			m.notice = "no file name given"
+			return
+		}
+		if err := m.exportHTML(path); err != nil {
+			m.notice = err.Error()
+		}
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.exportPrompt = false
+	default:
+		m.exportInput = editInput(m.exportInput, msg)
+	}
+}
@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// checkHTMLStructure fails the test unless every element of page is closed
+// in order.
+func checkHTMLStructure(t *testing.T, page string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(page))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	var open []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid HTML: %v\n%s", err, page)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			open = append(open, tok.Name.Local)
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != tok.Name.Local {
+				t.Fatalf("unexpected </%s> with %v open\n%s", tok.Name.Local, open, page)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		t.Fatalf("unclosed elements %v\n%s", open, page)
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	ts := time.Date(2026, 2, 17, 19, 58, 0, 250e6, time.UTC)
+	entries := []parser.LogEntry{
+		{Timestamp: ts, Level: "ERROR", Message: "db <timeout> & retry", Fields: map[string]string{"svc": "api", "code": "500"}},
+		{Level: "warning", Message: "slow request"},
+		{Level: "INFO", Message: "started"},
+		{Raw: "plain line without a level"},
+	}
+	var buf bytes.Buffer
+	if err := ExportHTML(&buf, entries, plainRenderer()); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	checkHTMLStructure(t, page)
+
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") {
+		t.Errorf("page does not start with a doctype:\n%s", page)
+	}
+	for _, want := range []string{
+		"db &lt;timeout&gt; &amp; retry",
+		"slow request",
+		"started",
+		"plain line without a level",
+		`<tr class="entry level-error">`,
+		`<tr class="entry level-warn">`,
+		`<tr class="entry level-info">`,
+		`<tr class="entry ">`,
+		`<td class="ts">2026-02-17 19:58:00.250</td>`,
+		`<span class="key">code</span><span class="sep">=</span>`,
+		".level-error .level {",
+		"4 entries",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<timeout>") {
+		t.Error("message is not escaped")
+	}
+	if got := strings.Count(page, "<tr "); got != len(entries) {
+		t.Errorf("page has %d rows, want %d", got, len(entries))
+	}
+}
+
+func TestExportHTML_RedactsAndSelectsFields(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ExcludeFields = []string{"host"}
+		c.Redact = RedactConfig{Patterns: []*regexp.Regexp{regexp.MustCompile(`secret-\w+`)}}
+	})
+	var buf bytes.Buffer
+	entries := []parser.LogEntry{{Level: "INFO", Message: "token secret-abc", Fields: map[string]string{"host": "db1", "svc": "api"}}}
+	if err := ExportHTML(&buf, entries, r); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if strings.Contains(page, "secret-abc") || strings.Contains(page, "db1") {
+		t.Errorf("page shows redacted or hidden values:\n%s", page)
+	}
+	if !strings.Contains(page, "api") {
+		t.Errorf("page lacks the svc field:\n%s", page)
+	}
+}
+
+func TestStyleCSS(t *testing.T) {
+	tests := []struct {
+		style lipgloss.Style
+		want  string
+	}{
+		{lipgloss.NewStyle(), ""},
+		{lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true), "color:#ff0000;font-weight:bold"},
+		{lipgloss.NewStyle().Foreground(lipgloss.Color("#268bd2")).Italic(true), "color:#268bd2;font-style:italic"},
+		{lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Reverse(true), "color:#000000;background-color:#800000"},
+	}
+	for _, tt := range tests {
+		if got := string(styleCSS(tt.style, "#000000")); got != tt.want {
+			t.Errorf("styleCSS(%v) = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestExportKey(t *testing.T) {
+	m := presetModel()
+	m = typeText(press(m, "/"), "timeout")
+	path := filepath.Join(t.TempDir(), "out.html")
+
+	m = press(m, "E")
+	if !m.exportPrompt || m.exportInput != DefaultExportPath || !contains(m.View(), "Export HTML to:") {
+		t.Fatalf("export prompt not open with the default path (input %q)", m.exportInput)
+	}
+	m.exportInput = ""
+	m = typeText(m, path)
+	if m.exportPrompt || !strings.Contains(m.notice, "exported 3 entries") {
+		t.Fatalf("prompt open = %v, notice = %q", m.exportPrompt, m.notice)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := string(data); !strings.Contains(page, "db timeout") || strings.Contains(page, "slow request") {
+		t.Errorf("export should hold only the filtered entries:\n%s", page)
+	}
+}
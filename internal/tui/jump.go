@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// updateTimePrompt handles a key press while the jump-to-time prompt is
+// open: typing edits the input, enter jumps, esc cancels.
+func (m *Model) updateTimePrompt(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.timePrompt = false
+		target, err := m.parseJumpTarget(m.timeInput)
+		if err != nil {
+			m.notice = err.Error()
+			return
+		}
+		m.jumpToTime(target)
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.timePrompt = false
+	default:
+		m.timeInput = editInput(m.timeInput, msg)
+	}
+}
+
+// editInput applies a key press to the text typed into a prompt: runes are
+// appended and backspace deletes the last one.
+func editInput(input string, msg tea.KeyMsg) string {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if r := []rune(input); len(r) > 0 {
+			return string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		return input + string(msg.Runes)
+	}
+	return input
+}
+
+// parseJumpTarget parses the jump-to-time input: an RFC 3339 time, a
+// duration before now such as "5m" or "-5m", or a clock time ("14:30",
+// "14:30:05") on the day of the newest timestamped entry.
+func (m Model) parseJumpTarget(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("no time given")
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		clock, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		day := m.now()
+		for i := len(m.entries) - 1; i >= 0; i-- {
+			if t := m.entries[i].Timestamp; !t.IsZero() {
+				day = t
+				break
+			}
+		}
+		y, mo, d := day.Date()
+		return time.Date(y, mo, d, clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location()), nil
+	}
+	return parser.ParseTimeBound(strings.TrimPrefix(s, "-"), m.now())
+}
+
+// now returns the current time, from the renderer's clock when there is one.
+func (m Model) now() time.Time {
+	if m.renderer != nil {
+		return m.renderer.Config().Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var jumpBase = time.Date(2026, 2, 17, 8, 0, 0, 0, time.UTC)
+
+func TestJumpToTime_Ordered(t *testing.T) {
+	// Entry 3 has no timestamp; entries 4 and 5 share one.
+	m := sessionModel(0, time.Minute, 2*time.Minute, -1, 5*time.Minute, 5*time.Minute, 9*time.Minute)
+
+	tests := []struct {
+		name   string
+		target time.Time
+		want   int
+	}{
+		{"exact match", jumpBase.Add(time.Minute), 1},
+		{"between entries", jumpBase.Add(90 * time.Second), 2},
+		{"skips untimed entry", jumpBase.Add(3 * time.Minute), 4},
+		{"first of equal timestamps", jumpBase.Add(5 * time.Minute), 4},
+		{"before range", jumpBase.Add(-time.Hour), 0},
+		{"last entry", jumpBase.Add(9 * time.Minute), 6},
+	}
+	for _, tt := range tests {
+		m.cursor = 3
+		m.notice = ""
+		m.jumpToTime(tt.target)
+		if m.cursor != tt.want {
+			t.Errorf("%s: cursor = %d, want %d", tt.name, m.cursor, tt.want)
+		}
+		if m.notice != "" {
+			t.Errorf("%s: unexpected notice %q", tt.name, m.notice)
+		}
+	}
+
+	m.jumpToTime(jumpBase.Add(time.Hour))
+	if m.cursor != 6 || !strings.Contains(m.notice, "no entry at or after") {
+		t.Errorf("after range: cursor = %d, notice = %q", m.cursor, m.notice)
+	}
+}
+
+func TestJumpToTime_Unordered(t *testing.T) {
+	m := sessionModel(0, 5*time.Minute, time.Minute, 2*time.Minute, 6*time.Minute, 7*time.Minute)
+
+	m.jumpToTime(jumpBase.Add(4 * time.Minute))
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 (first entry at or after the target)", m.cursor)
+	}
+	if !strings.Contains(m.notice, "scanned linearly") {
+		t.Errorf("notice = %q, want the linear-scan note", m.notice)
+	}
+}
+
+func TestJumpToTime_Filtered(t *testing.T) {
+	m := sessionModel(0, time.Minute, 2*time.Minute, 3*time.Minute)
+	m.entries[2].Level = "DEBUG"
+	m.toggleLevel(3) // hide debug
+
+	m.jumpToTime(jumpBase.Add(90 * time.Second))
+	if got := m.cursorIndex(); got != 3 {
+		t.Errorf("cursor on buffer index %d, want 3 (2 is hidden)", got)
+	}
+}
+
+func TestJumpToTime_Prompt(t *testing.T) {
+	m := sessionModel(0, time.Minute, 2*time.Minute, 3*time.Minute)
+	m.renderer = plainRenderer(func(c *RenderConfig) {
+		c.Now = func() time.Time { return jumpBase.Add(4 * time.Minute) }
+	})
+
+	m = press(m, "t")
+	if !m.timePrompt {
+		t.Fatal("t should open the prompt")
+	}
+	m = press(m, "-3m")
+	if !strings.Contains(m.View(), "Jump to time:") {
+		t.Error("prompt not shown in the status bar")
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.timePrompt || m.cursor != 1 {
+		t.Errorf("after -3m: prompt open = %v, cursor = %d, want 1", m.timePrompt, m.cursor)
+	}
+
+	// Clock times refer to the day of the newest entry.
+	m = press(m, "t")
+	m = press(m, "08:02")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = updated.(Model); m.cursor != 2 {
+		t.Errorf("after 08:02: cursor = %d, want 2", m.cursor)
+	}
+
+	// Typed keys go to the prompt, not to the key bindings; esc cancels.
+	m = press(m, "t")
+	m = press(m, "q")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = updated.(Model); m.timePrompt || cmd != nil || m.cursor != 2 {
+		t.Errorf("after esc: prompt open = %v, cursor = %d", m.timePrompt, m.cursor)
+	}
+
+	m = press(m, "t")
+	m = press(m, "soon")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = updated.(Model); m.notice == "" || m.cursor != 2 {
+		t.Errorf("invalid input: notice = %q, cursor = %d", m.notice, m.cursor)
+	}
+}
@@ -0,0 +1,387 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// action is something a key press does in the log view.
+type action int
+
+const (
+	actNone action = iota
+	actDown
+	actUp
+	actPageDown
+	actPageUp
+	actHalfPageDown
+	actHalfPageUp
+	actTop
+	actBottom
+	actDetail
+	actFocusDetail
+	actClose
+	actFilter
+	actJumpToTime
+	actContext
+	actGroup
+	actCompare
+	actMark
+	actNextMark
+	actPrevMark
+	actNextSession
+	actPrevSession
+	actToggleError // the five level toggles follow levelToggles' order
+	actToggleWarn
+	actToggleInfo
+	actToggleDebug
+	actToggleTrace
+	actStats
+	actWrap
+	actRaw
+	actLayout
+	actLineNumbers
+	actANSI
+	actFields
+	actSavePreset
+	actPresets
+	actCopyLine
+	actCopyJSON
+	actCopyCurl
+	actExport
+	actReload
+	actHelp
+	actQuit
+)
+
+// KeyMap binds the actions of the log view to the keys that trigger them,
+// named as Bubble Tea names them ("j", "ctrl+f", "pgdown", " "). An action
+// may have several keys or none; a key may trigger only one action. Ctrl+C
+// quits whatever Quit holds.
+type KeyMap struct {
+	Down         []string
+	Up           []string
+	PageDown     []string
+	PageUp       []string
+	HalfPageDown []string
+	HalfPageUp   []string
+	Top          []string
+	Bottom       []string
+	Detail       []string
+	FocusDetail  []string
+	Close        []string
+	Filter       []string
+	JumpToTime   []string
+	Context      []string
+	Group        []string
+	Compare      []string
+	Mark         []string
+	NextMark     []string
+	PrevMark     []string
+	NextSession  []string
+	PrevSession  []string
+	ToggleError  []string
+	ToggleWarn   []string
+	ToggleInfo   []string
+	ToggleDebug  []string
+	ToggleTrace  []string
+	Stats        []string
+	Wrap         []string
+	Raw          []string
+	Layout       []string
+	LineNumbers  []string
+	ANSI         []string
+	Fields       []string
+	SavePreset   []string
+	Presets      []string
+	CopyLine     []string
+	CopyJSON     []string
+	CopyCurl     []string
+	Export       []string
+	Reload       []string
+	Help         []string
+	Quit         []string
+}
+
+// DefaultKeyMap returns the key map the log view uses unless told
+// otherwise.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Down:         []string{"j", "down"},
+		Up:           []string{"k", "up"},
+		PageDown:     []string{"pgdown", "f", "ctrl+f"},
+		PageUp:       []string{"pgup", "b", "ctrl+b"},
+		HalfPageDown: []string{"d", "ctrl+d"},
+		HalfPageUp:   []string{"u", "ctrl+u"},
+		Top:          []string{"g", "home"},
+		Bottom:       []string{"G", "end"},
+		Detail:       []string{"enter"},
+		FocusDetail:  []string{"ctrl+w"},
+		Close:        []string{"esc"},
+		Filter:       []string{"/"},
+		JumpToTime:   []string{"t"},
+		Context:      []string{"X"},
+		Group:        []string{"T"},
+		Compare:      []string{"c"},
+		Mark:         []string{"m"},
+		NextMark:     []string{"'", "tab"},
+		PrevMark:     []string{"shift+tab"},
+		NextSession:  []string{"]"},
+		PrevSession:  []string{"["},
+		ToggleError:  []string{"1"},
+		ToggleWarn:   []string{"2"},
+		ToggleInfo:   []string{"3"},
+		ToggleDebug:  []string{"4"},
+		ToggleTrace:  []string{"5"},
+		Stats:        []string{"s"},
+		Wrap:         []string{"w"},
+		Raw:          []string{"R"},
+		Layout:       []string{"L"},
+		LineNumbers:  []string{"#"},
+		ANSI:         []string{"a"},
+		Fields:       []string{"F"},
+		SavePreset:   []string{"P"},
+		Presets:      []string{"p"},
+		CopyLine:     []string{"y"},
+		CopyJSON:     []string{"Y"},
+		CopyCurl:     []string{"C"},
+		Export:       []string{"E"},
+		Reload:       []string{"r"},
+		Help:         []string{"?"},
+		Quit:         []string{"q", "ctrl+c"},
+	}
+}
+
+// keyActions lists the actions in the order the help overlay shows them,
+// with the name a configuration file uses for each and the KeyMap field
+// holding its keys.
+var keyActions = []struct {
+	act  action
+	name string
+	help string
+	keys func(*KeyMap) *[]string
+}{
+	{actDown, "down", "scroll down", func(km *KeyMap) *[]string { return &km.Down }},
+	{actUp, "up", "scroll up", func(km *KeyMap) *[]string { return &km.Up }},
+	{actPageDown, "page_down", "page down", func(km *KeyMap) *[]string { return &km.PageDown }},
+	{actPageUp, "page_up", "page up", func(km *KeyMap) *[]string { return &km.PageUp }},
+	{actHalfPageDown, "half_page_down", "half a page down", func(km *KeyMap) *[]string { return &km.HalfPageDown }},
+	{actHalfPageUp, "half_page_up", "half a page up", func(km *KeyMap) *[]string { return &km.HalfPageUp }},
+	{actTop, "top", "jump to the top", func(km *KeyMap) *[]string { return &km.Top }},
+	{actBottom, "bottom", "jump to the bottom and follow", func(km *KeyMap) *[]string { return &km.Bottom }},
+	{actDetail, "detail", "open / close the detail pane", func(km *KeyMap) *[]string { return &km.Detail }},
+	{actFocusDetail, "focus_detail", "focus the detail pane / the log", func(km *KeyMap) *[]string { return &km.FocusDetail }},
+	{actClose, "close", "close the pane or overlay", func(km *KeyMap) *[]string { return &km.Close }},
+	{actFilter, "filter", "filter by a regular expression", func(km *KeyMap) *[]string { return &km.Filter }},
+	{actJumpToTime, "jump_to_time", "jump to a time", func(km *KeyMap) *[]string { return &km.JumpToTime }},
+	{actContext, "context", "lines around the entry", func(km *KeyMap) *[]string { return &km.Context }},
+	{actGroup, "group", "entries sharing the group field", func(km *KeyMap) *[]string { return &km.Group }},
+	{actCompare, "compare", "mark for comparison", func(km *KeyMap) *[]string { return &km.Compare }},
+	{actMark, "mark", "toggle a mark", func(km *KeyMap) *[]string { return &km.Mark }},
+	{actNextMark, "next_mark", "next mark", func(km *KeyMap) *[]string { return &km.NextMark }},
+	{actPrevMark, "prev_mark", "previous mark", func(km *KeyMap) *[]string { return &km.PrevMark }},
+	{actNextSession, "next_session", "next session", func(km *KeyMap) *[]string { return &km.NextSession }},
+	{actPrevSession, "prev_session", "previous session", func(km *KeyMap) *[]string { return &km.PrevSession }},
+	{actToggleError, "toggle_error", "show / hide errors", func(km *KeyMap) *[]string { return &km.ToggleError }},
+	{actToggleWarn, "toggle_warn", "show / hide warnings", func(km *KeyMap) *[]string { return &km.ToggleWarn }},
+	{actToggleInfo, "toggle_info", "show / hide info", func(km *KeyMap) *[]string { return &km.ToggleInfo }},
+	{actToggleDebug, "toggle_debug", "show / hide debug", func(km *KeyMap) *[]string { return &km.ToggleDebug }},
+	{actToggleTrace, "toggle_trace", "show / hide trace", func(km *KeyMap) *[]string { return &km.ToggleTrace }},
+	{actStats, "stats", "statistics", func(km *KeyMap) *[]string { return &km.Stats }},
+	{actWrap, "wrap", "line wrap", func(km *KeyMap) *[]string { return &km.Wrap }},
+	{actRaw, "raw", "original input lines", func(km *KeyMap) *[]string { return &km.Raw }},
+	{actLayout, "layout", "inline / table layout", func(km *KeyMap) *[]string { return &km.Layout }},
+	{actLineNumbers, "line_numbers", "line numbers", func(km *KeyMap) *[]string { return &km.LineNumbers }},
+	{actANSI, "ansi", "strip / keep ANSI colors", func(km *KeyMap) *[]string { return &km.ANSI }},
+	{actFields, "fields", "edit the field order", func(km *KeyMap) *[]string { return &km.Fields }},
+	{actSavePreset, "save_preset", "save a preset", func(km *KeyMap) *[]string { return &km.SavePreset }},
+	{actPresets, "presets", "load a preset", func(km *KeyMap) *[]string { return &km.Presets }},
+	{actCopyLine, "copy_line", "copy the line", func(km *KeyMap) *[]string { return &km.CopyLine }},
+	{actCopyJSON, "copy_json", "copy the entry as JSON", func(km *KeyMap) *[]string { return &km.CopyJSON }},
+	{actCopyCurl, "copy_curl", "copy the request as curl", func(km *KeyMap) *[]string { return &km.CopyCurl }},
+	{actExport, "export", "export to HTML", func(km *KeyMap) *[]string { return &km.Export }},
+	{actReload, "reload", "reload the files", func(km *KeyMap) *[]string { return &km.Reload }},
+	{actHelp, "help", "this help", func(km *KeyMap) *[]string { return &km.Help }},
+	{actQuit, "quit", "quit", func(km *KeyMap) *[]string { return &km.Quit }},
+}
+
+// Bind replaces the keys of the action a configuration file calls name,
+// e.g. "down" or "toggle_error". An empty keys unbinds the action.
+func (km *KeyMap) Bind(name string, keys []string) error {
+	for _, a := range keyActions {
+		if a.name == name {
+			*a.keys(km) = keys
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", name)
+}
+
+// Alias binds each key of aliases to the action the key it maps to
+// triggers, e.g. {"x": "q"} quits on x as well. The keys mapped to are
+// looked up before any alias is added, so aliases do not chain.
+func (km *KeyMap) Alias(aliases map[string]string) error {
+	bound := km.actions()
+	keys := make([]string, 0, len(aliases))
+	for k := range aliases {
+		keys = append(keys, k)
+	}
+	sortStrings(keys)
+	for _, key := range keys {
+		act, ok := bound[aliases[key]]
+		if key == "" || !ok {
+			return fmt.Errorf("cannot map %q to %q: not a bound key", key, aliases[key])
+		}
+		for _, a := range keyActions {
+			if a.act == act {
+				*a.keys(km) = append(*a.keys(km), key)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate reports an error if a key is empty or triggers more than one
+// action. Ctrl+C may only be bound to quit.
+func (km KeyMap) Validate() error {
+	bound := map[string]string{"ctrl+c": "quit"}
+	for _, a := range keyActions {
+		for _, k := range *a.keys(&km) {
+			if k == "" {
+				return fmt.Errorf("%s: empty key", a.name)
+			}
+			if other, ok := bound[k]; ok && other != a.name {
+				return fmt.Errorf("key %q is bound to both %s and %s", k, other, a.name)
+			}
+			bound[k] = a.name
+		}
+	}
+	return nil
+}
+
+// actions returns the action each bound key triggers. Should a key be
+// bound twice, the action listed first in keyActions wins.
+func (km KeyMap) actions() map[string]action {
+	m := map[string]action{"ctrl+c": actQuit}
+	for _, a := range keyActions {
+		for _, k := range *a.keys(&km) {
+			if _, ok := m[k]; !ok {
+				m[k] = a.act
+			}
+		}
+	}
+	return m
+}
+
+// WithKeyMap sets the keys the log view responds to.
+func WithKeyMap(km KeyMap) ModelOption {
+	return func(m *Model) {
+		m.keyMap = km
+		m.keyActions = km.actions()
+	}
+}
+
+// keyAction returns the action key triggers, or actNone.
+func (m Model) keyAction(key string) action {
+	return m.keyActions[key]
+}
+
+// hasKey reports whether keys holds key.
+func hasKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// helpRows returns the rows of the help overlay (key ?) below its title:
+// the actions with the keys currently bound to them, in as many columns as
+// fit the width.
+func (m Model) helpRows() []string {
+	var keys []string
+	var help []string
+	keyWidth, cellWidth := 0, 0
+	for _, a := range keyActions {
+		bound := *a.keys(&m.keyMap)
+		if a.act == actQuit && !hasKey(bound, "ctrl+c") {
+			// Ctrl+C quits even when Quit does not list it.
+			bound = append(bound[:len(bound):len(bound)], "ctrl+c")
+		}
+		if len(bound) == 0 {
+			continue
+		}
+		keys = append(keys, strings.Join(bound, " "))
+		help = append(help, a.help)
+		keyWidth = max(keyWidth, len(keys[len(keys)-1]))
+	}
+	for _, h := range help {
+		cellWidth = max(cellWidth, keyWidth+2+len(h))
+	}
+
+	columns := max(m.width/(cellWidth+2), 1)
+	perColumn := (len(keys) + columns - 1) / columns
+	rows := make([]string, perColumn)
+	for i := range rows {
+		var b strings.Builder
+		for j := i; j < len(keys); j += perColumn {
+			cell := fmt.Sprintf("%-*s  %s", keyWidth, keys[j], help[j])
+			fmt.Fprintf(&b, "  %s%s", detailKeyStyle.Render(cell[:keyWidth]), cell[keyWidth:])
+			if j+perColumn < len(keys) {
+				b.WriteString(strings.Repeat(" ", cellWidth-len(cell)))
+			}
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
+
+// helpBodyHeight returns the number of help rows shown below the title.
+func (m Model) helpBodyHeight() int {
+	return max(m.logPaneHeight()-2, 1)
+}
+
+// scrollHelp scrolls the help overlay if it is longer than the pane and
+// act is one of the scroll actions, and reports whether it did.
+func (m *Model) scrollHelp(act action) bool {
+	body := m.helpBodyHeight()
+	if len(m.helpRows()) <= body {
+		return false
+	}
+	switch act {
+	case actDown:
+		m.helpOffset++
+	case actUp:
+		m.helpOffset--
+	case actPageDown:
+		m.helpOffset += body
+	case actPageUp:
+		m.helpOffset -= body
+	case actHalfPageDown:
+		m.helpOffset += body / 2
+	case actHalfPageUp:
+		m.helpOffset -= body / 2
+	case actTop:
+		m.helpOffset = 0
+	case actBottom:
+		m.helpOffset = len(m.helpRows())
+	default:
+		return false
+	}
+	m.helpOffset = max(min(m.helpOffset, len(m.helpRows())-body), 0)
+	return true
+}
+
+// renderHelp renders the help overlay in at most height rows, from the
+// row it is scrolled to.
+func (m Model) renderHelp(height int) []string {
+	body := m.helpRows()
+	title := "▼ Keys — any key closes"
+	if visible := height - 2; len(body) > visible {
+		title = fmt.Sprintf("▼ Keys (%d–%d of %d) — scroll keys scroll, any other key closes",
+			m.helpOffset+1, min(m.helpOffset+visible, len(body)), len(body))
+	}
+	rows := []string{detailBorderStyle.Render(title), ""}
+	return append(rows, body[min(m.helpOffset, len(body)):]...)
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyMap_RemapScrollDown(t *testing.T) {
+	km := DefaultKeyMap()
+	if err := km.Bind("down", []string{"n"}); err != nil {
+		t.Fatal(err)
+	}
+	m := setupModel(80, 24, 5, WithKeyMap(km))
+	m.autoScroll = false
+
+	if m = press(m, "n"); m.cursor != 1 {
+		t.Errorf("cursor = %d after n, want 1", m.cursor)
+	}
+	if m = press(m, "j"); m.cursor != 1 {
+		t.Errorf("cursor = %d after j, want 1: j is no longer bound", m.cursor)
+	}
+	if m = press(m, "k"); m.cursor != 0 {
+		t.Errorf("cursor = %d after k, want 0", m.cursor)
+	}
+}
+
+func TestKeyMap_Alias(t *testing.T) {
+	km := DefaultKeyMap()
+	if err := km.Alias(map[string]string{"x": "q", "J": "j", "z": "x"}); err == nil {
+		t.Error("an alias of an alias should fail")
+	}
+	km = DefaultKeyMap()
+	if err := km.Alias(map[string]string{"x": "q", "J": "j"}); err != nil {
+		t.Fatal(err)
+	}
+	m := setupModel(80, 24, 5, WithKeyMap(km))
+	m.autoScroll = false
+
+	if m = press(m, "J"); m.cursor != 1 {
+		t.Error("J should act like j")
+	}
+	if m = press(m, "j"); m.cursor != 2 {
+		t.Error("j should keep working")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}); cmd == nil {
+		t.Error("x should act like q and quit")
+	}
+}
+
+func TestKeyMap_CtrlCAlwaysQuits(t *testing.T) {
+	km := DefaultKeyMap()
+	km.Quit = []string{"q"}
+	if err := km.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, cmd := NewModel(WithKeyMap(km)).Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Error("ctrl+c should quit when quit is bound to q alone")
+	}
+	km.Stats = []string{"ctrl+c"}
+	if err := km.Validate(); err == nil {
+		t.Error("binding ctrl+c to another action should fail")
+	}
+}
+
+func TestKeyMap_Validate(t *testing.T) {
+	if err := DefaultKeyMap().Validate(); err != nil {
+		t.Fatalf("default key map: %v", err)
+	}
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{"down", []string{"k"}, `key "k" is bound to both down and up`},
+		{"stats", []string{"s", "j"}, `key "j" is bound to both down and stats`},
+		{"filter", []string{""}, "filter: empty key"},
+		{"stats", []string{"ctrl+c"}, `key "ctrl+c" is bound to both quit and stats`},
+	}
+	for _, tt := range tests {
+		km := DefaultKeyMap()
+		if err := km.Bind(tt.name, tt.keys); err != nil {
+			t.Fatal(err)
+		}
+		if err := km.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: %v: Validate() = %v, want %q", tt.name, tt.keys, err, tt.want)
+		}
+	}
+	km := DefaultKeyMap()
+	if err := km.Bind("scroll", []string{"x"}); err == nil {
+		t.Error("binding an unknown action should fail")
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	km := DefaultKeyMap()
+	km.Down = []string{"n"}
+	km.Stats = nil
+	m := setupModel(120, 30, 0, WithKeyMap(km))
+	m = press(m, "?")
+	v := StripANSI(m.View())
+	for _, want := range []string{"Keys", "n                scroll down", "q ctrl+c         quit"} {
+		if !strings.Contains(v, want) {
+			t.Errorf("help lacks %q:\n%s", want, v)
+		}
+	}
+	if strings.Contains(v, "statistics") {
+		t.Errorf("help lists the unbound stats action:\n%s", v)
+	}
+
+	if m = press(m, "x"); m.showHelp {
+		t.Error("any key should close the help")
+	}
+}
+
+func TestHelpOverlay_Narrow(t *testing.T) {
+	km := DefaultKeyMap()
+	km.Quit = []string{"q"}
+	m := setupModel(80, 24, 0, WithKeyMap(km))
+	m = press(m, "?")
+
+	// Every binding can be scrolled to, and none is cut off at the edge.
+	seen := make(map[string]bool)
+	for i := 0; i < len(keyActions); i++ {
+		for _, line := range strings.Split(StripANSI(m.View()), "\n") {
+			if strings.Contains(line, "…") && !strings.Contains(line, "Lines:") {
+				t.Fatalf("help row cut off: %q", line)
+			}
+			seen[strings.TrimSpace(line)] = true
+		}
+		m = press(m, "j")
+	}
+	if !m.showHelp {
+		t.Fatal("the scroll keys should scroll a help longer than the pane")
+	}
+	for _, want := range []string{"E                export to HTML", "q ctrl+c         quit"} {
+		if !seen[want] {
+			t.Errorf("scrolling the help never showed %q", want)
+		}
+	}
+	if m = press(m, "x"); m.showHelp {
+		t.Error("a key other than the scroll keys should close the help")
+	}
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// levelToggles lists the levels the keys 1–5 toggle, in key order. Fatal
+// entries follow the error toggle.
+var levelToggles = []string{"error", "warn", "info", "debug", "trace"}
+
+// defaultLevelVisibility returns a visibility map with every level shown.
+func defaultLevelVisibility() map[string]bool {
+	visible := make(map[string]bool, len(levelRanks))
+	for level := range levelRanks {
+		visible[level] = true
+	}
+	return visible
+}
+
+// levelsHidden reports whether any level is toggled off.
+func (m Model) levelsHidden() bool {
+	for _, v := range m.levelVisible {
+		if !v {
+			return true
+		}
+	}
+	return false
+}
+
+// levelShown reports whether e's level is toggled on. Entries without a
+// recognized level are always shown.
+func (m Model) levelShown(e parser.LogEntry) bool {
+	visible, ok := m.levelVisible[normalizeLevel(e.Level)]
+	return !ok || visible
+}
+
+// toggleLevel flips the visibility of the n-th entry of levelToggles
+// (0-based) and rebuilds the view.
+func (m *Model) toggleLevel(n int) {
+	if n < 0 || n >= len(levelToggles) {
+		return
+	}
+	level := levelToggles[n]
+	keep := m.cursorIndex()
+	m.levelVisible[level] = !m.levelVisible[level]
+	if level == "error" {
+		m.levelVisible["fatal"] = m.levelVisible[level]
+	}
+	m.refilter(keep)
+}
+
+// refilter rebuilds the view after the filter set changed, moving the cursor
+// to buffer index keep or, if that is now hidden, the next visible line. keep
+// must be taken before the change, while the old view still applies.
+func (m *Model) refilter(keep int) {
+	m.view = nil
+	m.extendView(0)
+	if keep >= 0 {
+		m.cursor, _ = m.viewPos(keep)
+	}
+	m.clampCursor()
+	m.scrollToCursor()
+	if !m.isAtBottom() {
+		m.autoScroll = false
+	}
+}
+
+// levelLegend renders the level toggles compactly, e.g. "E-I-T" when warn
+// and debug are hidden.
+func (m Model) levelLegend() string {
+	var b strings.Builder
+	for _, level := range levelToggles {
+		if m.levelVisible[level] {
+			b.WriteString(strings.ToUpper(level[:1]))
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func levelModel() Model {
+	var entries []parser.LogEntry
+	for _, level := range []string{"ERROR", "WARN", "INFO", "DEBUG", "INFO", "WARN", "ERROR", "DEBUG"} {
+		entries = append(entries, parser.LogEntry{Level: level, Message: level})
+	}
+	return applyMsgs(setupModel(80, 24, 0), logMsgs(entries...))
+}
+
+func visibleLevels(m Model) []string {
+	var levels []string
+	for pos := 0; pos < m.viewLen(); pos++ {
+		levels = append(levels, m.entries[m.bufIndex(pos)].Level)
+	}
+	return levels
+}
+
+func TestLevelToggles_HideAndRestore(t *testing.T) {
+	m := levelModel()
+	m = press(m, "2") // warn
+	m = press(m, "3") // info
+
+	for _, level := range visibleLevels(m) {
+		if level != "ERROR" && level != "DEBUG" {
+			t.Errorf("level %s should be hidden", level)
+		}
+	}
+	if m.viewLen() != 4 {
+		t.Errorf("viewLen = %d, want 4", m.viewLen())
+	}
+	v := m.View()
+	if !contains(v, "E--DT") || !contains(v, "4/8") {
+		t.Errorf("status bar should show the level legend and count:\n%s", v)
+	}
+
+	m = press(m, "2")
+	m = press(m, "3")
+	if m.viewLen() != 8 || m.filtered() {
+		t.Errorf("re-enabling should restore all lines, viewLen = %d", m.viewLen())
+	}
+	if contains(m.View(), "Levels:") {
+		t.Error("legend should be hidden when every level is shown")
+	}
+}
+
+func TestLevelToggles_CombineWithFilters(t *testing.T) {
+	m := setupModel(80, 24, 0, WithFilter(func(e parser.LogEntry) bool { return e.Message != "skip" }))
+	m = applyMsgs(m, logMsgs(
+		parser.LogEntry{Level: "ERROR", Message: "keep"},
+		parser.LogEntry{Level: "ERROR", Message: "skip"},
+		parser.LogEntry{Level: "INFO", Message: "keep"},
+		parser.LogEntry{Message: "no level"},
+	))
+
+	m = press(m, "3")
+	if got := visibleLevels(m); len(got) != 2 || got[0] != "ERROR" || got[1] != "" {
+		t.Errorf("visible levels = %q, want [ERROR \"\"]", got)
+	}
+}
+
+func TestLevelToggles_KeepsCursorEntry(t *testing.T) {
+	m := levelModel()
+	m = press(m, "g")
+	m = press(m, "j") // WARN at buffer index 1
+	m = press(m, "j") // INFO at buffer index 2
+	m = press(m, "2")
+	if got := m.cursorIndex(); got != 2 {
+		t.Errorf("cursor moved to buffer index %d, want 2", got)
+	}
+	m = press(m, "3") // hides the cursor line: move to the next visible one
+	if got := m.cursorIndex(); got != 3 {
+		t.Errorf("cursor at buffer index %d, want 3", got)
+	}
+}
+
+func TestLevelToggles_ErrorIncludesFatal(t *testing.T) {
+	m := setupModel(80, 24, 0)
+	updated, _ := m.Update(LogMsg{Rendered: "boom", Entry: parser.LogEntry{Level: "FATAL"}})
+	m = press(updated.(Model), "1")
+	if m.viewLen() != 0 {
+		t.Errorf("fatal entries should follow the error toggle, viewLen = %d", m.viewLen())
+	}
+}
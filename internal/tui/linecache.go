@@ -0,0 +1,98 @@
+package tui
+
+import "container/list"
+
+// lineCacheSize is how many rendered lines a model keeps. It covers a tall
+// screen several times over, so scrolling back and forth rarely renders a
+// line twice.
+const lineCacheSize = 1024
+
+// lineKey identifies a rendered line: the renderer, which stands for the
+// render settings such as the layout; the terminal width; the generation of
+// the renderer's table column widths; and the entry's index in the stream
+// (its buffer index plus the lines dropped before it), which stays the same
+// as older lines are dropped.
+type lineKey struct {
+	r     *Renderer
+	width int
+	table int
+	index int
+}
+
+type cachedLine struct {
+	key  lineKey
+	text string
+}
+
+// lineCache is a least-recently-used cache of rendered lines. Copies of a
+// Model share it; a nil lineCache caches nothing.
+type lineCache struct {
+	size  int
+	order *list.List // of cachedLine, most recently used first
+	items map[lineKey]*list.Element
+}
+
+func newLineCache(size int) *lineCache {
+	return &lineCache{size: size, order: list.New(), items: make(map[lineKey]*list.Element)}
+}
+
+// get returns the line cached under key.
+func (c *lineCache) get(key lineKey) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(cachedLine).text, true
+}
+
+// put caches text under key, evicting the least recently used line if the
+// cache is full.
+func (c *lineCache) put(key lineKey, text string) {
+	if c == nil {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value = cachedLine{key, text}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(cachedLine{key, text})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(cachedLine).key)
+	}
+}
+
+// clear empties the cache.
+func (c *lineCache) clear() {
+	if c == nil {
+		return
+	}
+	c.order.Init()
+	clear(c.items)
+}
+
+// renderLine returns buffer line i as the model shows it outside raw mode.
+// Lines are rendered on demand by the model's renderer, and the latest ones
+// are cached. A model without a renderer shows the text that came with the
+// entry, or else the entry's input.
+func (m Model) renderLine(i int) string {
+	if m.renderer == nil {
+		if i < len(m.lines) && m.lines[i] != "" {
+			return m.lines[i]
+		}
+		return orDefault(m.entries[i].Raw, m.entries[i].Message)
+	}
+	key := lineKey{m.renderer, m.width, m.renderer.table.generation(), m.dropped + i}
+	if text, ok := m.renderCache.get(key); ok {
+		return text
+	}
+	text := m.renderer.RenderEntry(m.entries[i])
+	m.renderCache.put(key, text)
+	return text
+}
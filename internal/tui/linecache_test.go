@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// bufferEntries returns n entries with varying levels, messages and fields.
+func bufferEntries(n int) []parser.LogEntry {
+	levels := []string{"INFO", "WARN", "ERROR", "DEBUG"}
+	base := time.Date(2026, 2, 17, 19, 0, 0, 0, time.UTC)
+	entries := make([]parser.LogEntry, n)
+	for i := range entries {
+		entries[i] = parser.LogEntry{
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Level:     levels[i%len(levels)],
+			Message:   fmt.Sprintf("request %d %s", i, strings.Repeat("x", i%50)),
+			Fields:    map[string]string{"svc": "api", "status": fmt.Sprint(200 + i%5), "id": fmt.Sprint(i)},
+			Raw:       fmt.Sprintf("line %d", i),
+		}
+	}
+	return entries
+}
+
+func TestRenderLine_MatchesEagerRendering(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) { c.TerminalWidth = 100 })
+	entries := bufferEntries(300)
+	lazy := NewModel(WithRenderer(r))
+	lazy.appendLines(nil, entries)
+
+	// A model without a renderer shows the lines it was given, as the
+	// model did when every line was rendered on arrival.
+	rendered := make([]string, len(entries))
+	for i, e := range entries {
+		rendered[i] = r.RenderEntry(e)
+	}
+	eager := NewModel()
+	eager.appendLines(rendered, entries)
+
+	for _, m := range []*Model{&lazy, &eager} {
+		m.width, m.height, m.ready = 100, 30, true
+		m.autoScroll = false
+	}
+	for _, offset := range []int{0, 1, 150, 150, 299, 0} {
+		lazy.offset, eager.offset = offset, offset
+		got, want := lazy.viewportRows(lazy.viewHeight()), eager.viewportRows(eager.viewHeight())
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Fatalf("offset %d: lazy rows\n%s\nwant\n%s", offset, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+	}
+	if len(lazy.lines) != 0 {
+		t.Errorf("a model with a renderer keeps %d rendered lines", len(lazy.lines))
+	}
+}
+
+func TestRenderLine_CacheFollowsBuffer(t *testing.T) {
+	r := plainRenderer()
+	m := NewModel(WithRenderer(r), WithMaxLines(3))
+	m.appendLines(nil, []parser.LogEntry{{Message: "a"}, {Message: "b"}, {Message: "c"}})
+	if got := StripANSI(m.lineText(0)); !strings.HasSuffix(got, "a") {
+		t.Fatalf("line 0 = %q", got)
+	}
+
+	// Dropping the oldest line shifts the buffer but not the cache keys.
+	m.appendLines(nil, []parser.LogEntry{{Message: "d"}})
+	for i, want := range []string{"b", "c", "d"} {
+		if got := StripANSI(m.lineText(i)); !strings.HasSuffix(got, want) {
+			t.Errorf("after drop, line %d = %q, want %s", i, got, want)
+		}
+	}
+
+	// After a reload the same indices hold new lines.
+	m.reset()
+	m.appendLines(nil, []parser.LogEntry{{Message: "e"}})
+	if got := StripANSI(m.lineText(0)); !strings.HasSuffix(got, "e") {
+		t.Errorf("after reset, line 0 = %q, want e", got)
+	}
+}
+
+func TestLineCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLineCache(2)
+	a, b, d := lineKey{index: 1}, lineKey{index: 2}, lineKey{index: 3}
+	c.put(a, "a")
+	c.put(b, "b")
+	c.get(a)
+	c.put(d, "d")
+	if _, ok := c.get(b); ok {
+		t.Error("b was used least recently and should be evicted")
+	}
+	if got, ok := c.get(a); !ok || got != "a" {
+		t.Errorf("get(a) = %q, %v", got, ok)
+	}
+	if got, ok := c.get(d); !ok || got != "d" {
+		t.Errorf("get(d) = %q, %v", got, ok)
+	}
+	c.clear()
+	if _, ok := c.get(a); ok {
+		t.Error("clear should empty the cache")
+	}
+}
+
+// benchmarkBuffer fills a model with a 100k-entry buffer, rendering every
+// line on arrival if eager, and shows one screen of it.
+func benchmarkBuffer(b *testing.B, eager bool) {
+	r := plainRenderer()
+	entries := bufferEntries(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var m Model
+		var rendered []string
+		if eager {
+			m = NewModel()
+			rendered = make([]string, len(entries))
+			for j, e := range entries {
+				rendered[j] = r.RenderEntry(e)
+			}
+		} else {
+			m = NewModel(WithRenderer(r))
+		}
+		m.appendLines(rendered, entries)
+		m.width, m.height, m.ready = 120, 50, true
+		m.offset = m.maxOffset()
+		_ = m.View()
+	}
+}
+
+func BenchmarkBuffer100k_Eager(b *testing.B) { benchmarkBuffer(b, true) }
+func BenchmarkBuffer100k_Lazy(b *testing.B)  { benchmarkBuffer(b, false) }
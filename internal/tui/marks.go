@@ -0,0 +1,105 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// markGutter is the gutter shown next to marked lines; unmarked lines get an
+// equally wide blank so the text stays aligned.
+const (
+	markGutter  = "◆ "
+	blankGutter = "  "
+)
+
+var markStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#F25D94")).
+	Bold(true)
+
+// toggleMark sets or clears the mark on the cursor line.
+func (m *Model) toggleMark() {
+	i := m.cursorIndex()
+	if i < 0 {
+		return
+	}
+	if m.marks[i] {
+		delete(m.marks, i)
+		return
+	}
+	if m.marks == nil {
+		m.marks = make(map[int]bool)
+	}
+	m.marks[i] = true
+}
+
+// nextMark moves the cursor to the next visible marked line, wrapping to the
+// first mark after the last one.
+func (m *Model) nextMark() {
+	cur := m.cursorIndex()
+	next, first := -1, -1
+	for i := range m.marks {
+		if _, visible := m.viewPos(i); !visible {
+			continue
+		}
+		if first < 0 || i < first {
+			first = i
+		}
+		if i > cur && (next < 0 || i < next) {
+			next = i
+		}
+	}
+	if next < 0 {
+		next = first
+	}
+	if next >= 0 {
+		m.jumpTo(next)
+	}
+}
+
+// prevMark moves the cursor to the previous visible marked line, wrapping to
+// the last mark before the first one.
+func (m *Model) prevMark() {
+	cur := m.cursorIndex()
+	prev, last := -1, -1
+	for i := range m.marks {
+		if _, visible := m.viewPos(i); !visible {
+			continue
+		}
+		if i > last {
+			last = i
+		}
+		if i < cur && i > prev {
+			prev = i
+		}
+	}
+	if prev < 0 {
+		prev = last
+	}
+	if prev >= 0 {
+		m.jumpTo(prev)
+	}
+}
+
+// shiftMarks re-keys marks after n lines were dropped from the front of the
+// buffer, discarding marks on the dropped lines.
+func (m *Model) shiftMarks(n int) {
+	if len(m.marks) == 0 {
+		return
+	}
+	shifted := make(map[int]bool, len(m.marks))
+	for i := range m.marks {
+		if i >= n {
+			shifted[i-n] = true
+		}
+	}
+	m.marks = shifted
+}
+
+// markGutterFor returns the gutter for buffer line i, or "" when no line is
+// marked and the gutter is hidden.
+func (m Model) markGutterFor(i int) string {
+	if len(m.marks) == 0 {
+		return ""
+	}
+	if m.marks[i] {
+		return markStyle.Render(markGutter)
+	}
+	return blankGutter
+}
@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// feed appends n lines to m through Update, as a source would.
+func feed(m Model, n int) Model {
+	for i := 0; i < n; i++ {
+		text := fmt.Sprintf("line %d", len(m.entries))
+		updated, _ := m.Update(LogMsg{Rendered: text, Entry: parser.LogEntry{Message: text}})
+		m = updated.(Model)
+	}
+	return m
+}
+
+func TestMarks_ToggleAndCycle(t *testing.T) {
+	m := setupModel(80, 24, 0)
+	m = feed(m, 50)
+
+	for _, i := range []int{30, 5, 12} {
+		m.jumpTo(i)
+		m = press(m, "m")
+	}
+	if len(m.marks) != 3 {
+		t.Fatalf("marks = %v, want 3 marks", m.marks)
+	}
+
+	// New lines must not disturb existing marks.
+	m = feed(m, 20)
+
+	m.jumpTo(0)
+	var order []int
+	for i := 0; i < 4; i++ {
+		m = press(m, "'")
+		order = append(order, m.cursor)
+	}
+	if want := []int{5, 12, 30, 5}; fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("' order = %v, want %v", order, want)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	if m.cursor != 12 {
+		t.Errorf("tab moved cursor to %d, want 12", m.cursor)
+	}
+
+	order = order[:0]
+	for i := 0; i < 3; i++ {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+		m = updated.(Model)
+		order = append(order, m.cursor)
+	}
+	if want := []int{5, 30, 12}; fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("shift+tab order = %v, want %v", order, want)
+	}
+
+	// Toggling again removes the mark.
+	m = press(m, "m")
+	if m.marks[12] || len(m.marks) != 2 {
+		t.Errorf("second m should unmark line 12, marks = %v", m.marks)
+	}
+}
+
+func TestMarks_GutterIndicator(t *testing.T) {
+	m := setupModel(80, 24, 5)
+	if contains(m.View(), markGutter) {
+		t.Error("no gutter expected without marks")
+	}
+	m.cursor = 2
+	m = press(m, "m")
+	v := m.View()
+	if !contains(v, markGutter) {
+		t.Errorf("marked line should show gutter indicator:\n%s", v)
+	}
+	if !contains(v, blankGutter+"line 1") {
+		t.Errorf("unmarked lines should get a blank gutter:\n%s", v)
+	}
+	if !contains(v, "Marks:") {
+		t.Error("status bar should show mark count")
+	}
+}
+
+func TestMarks_PrunedWhenBufferDrops(t *testing.T) {
+	m := setupModel(80, 24, 0, WithMaxLines(10))
+	m = feed(m, 10)
+	for _, i := range []int{2, 7} {
+		m.jumpTo(i)
+		m = press(m, "m")
+	}
+
+	// Five more lines push out lines 0–4: the mark on line 2 goes away and
+	// the mark on old line 7 now lives at index 2.
+	m = feed(m, 5)
+	if len(m.entries) != 10 {
+		t.Fatalf("buffer len = %d, want 10", len(m.entries))
+	}
+	if len(m.marks) != 1 || !m.marks[2] {
+		t.Errorf("marks = %v, want only index 2", m.marks)
+	}
+	if m.lineText(2) != "line 7" {
+		t.Errorf("lines[2] = %q, want line 7", m.lineText(2))
+	}
+	m.jumpTo(9)
+	m = press(m, "'")
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2", m.cursor)
+	}
+}
+
+func TestMaxLines_ShiftsCompareSelection(t *testing.T) {
+	m := setupModel(80, 24, 0, WithMaxLines(10))
+	m = feed(m, 10)
+	m.jumpTo(6)
+	m = press(m, "c")
+	m = feed(m, 3)
+	if m.compareA != 3 {
+		t.Errorf("compareA = %d, want 3", m.compareA)
+	}
+	// The overlay numbers lines like the gutter does, not by buffer index.
+	m.jumpTo(9)
+	if v := press(m, "c").View(); !contains(v, "Compare #7 (A) ↔ #13 (B)") {
+		t.Errorf("compare header should use absolute line numbers:\n%s", v)
+	}
+	m = feed(m, 5)
+	if m.compareA != -1 {
+		t.Errorf("compareA = %d, want cleared once its line is dropped", m.compareA)
+	}
+}
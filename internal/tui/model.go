@@ -2,7 +2,9 @@ package tui
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -32,7 +34,7 @@ var (
 			Background(lipgloss.Color("#3C3C5C"))
 
 	detailBorderStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4"))
+				Foreground(lipgloss.Color("#7D56F4"))
 
 	detailKeyStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#117")).
@@ -40,15 +42,21 @@ var (
 
 	detailValStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#252"))
+
+	detailIssueStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("196"))
 )
 
-// LogMsg carries a new parsed and rendered log line into the TUI.
+// LogMsg carries a new parsed log line into the TUI. A model with a renderer
+// renders the entry itself when it is shown; Rendered is the text shown by a
+// model without one.
 type LogMsg struct {
 	Rendered string
 	Entry    parser.LogEntry
 }
 
-// LogBatchMsg carries multiple rendered log lines at once.
+// LogBatchMsg carries multiple log lines at once. Lines, which may be nil,
+// are the texts shown by a model without a renderer, as in LogMsg.
 type LogBatchMsg struct {
 	Lines   []string
 	Entries []parser.LogEntry
@@ -65,38 +73,205 @@ type Model struct {
 	height int
 	ready  bool
 
-	// Log buffer — stores rendered strings for display.
-	lines   []string
-	entries []parser.LogEntry // parallel to lines; stores parsed entries
+	// Log buffer. Entries are rendered when shown (see renderLine), and the
+	// latest rendered lines are kept in renderCache. lines holds the texts
+	// the messages brought along, and is only filled while the model has no
+	// renderer.
+	entries     []parser.LogEntry
+	lines       []string
+	renderCache *lineCache
+
+	// Filtered view: buffer indices of the lines that pass every filter, in
+	// order. Only used while filters are set; otherwise all lines are shown.
+	filters []EntryFilter
+	view    []int
 
-	// Virtual scrolling state.
+	// Per-level visibility toggled with 1–5, keyed by canonical level.
+	levelVisible map[string]bool
+
+	// Virtual scrolling state. offset and cursor are positions in the view,
+	// which equal buffer indices when no filter is active.
 	offset     int  // index of the first visible line
 	autoScroll bool // stick to bottom when new lines arrive
+	maxLines   int  // buffer cap; 0 means unlimited
+	dropped    int  // lines dropped from the front of the buffer so far
+
+	// Line-number gutter.
+	showLineNumbers bool
+
+	// Wrap long lines over several display rows instead of truncating them.
+	wrap bool
+
+	// Show the original input lines instead of the rendered ones.
+	raw bool
+
+	// Marked lines, keyed by buffer index.
+	marks map[int]bool
 
 	// Cursor and detail pane.
-	cursor    int  // index of the highlighted line
+	cursor     int  // index of the highlighted line
 	showDetail bool // whether the detail pane is visible
+	// detailFocus routes the scrolling keys to the detail pane. The pane
+	// is scrolled by detailOffset rows while it shows buffer index
+	// detailFor; any other entry starts at the top.
+	detailFocus  bool
+	detailOffset int
+	detailFor    int
+
+	// Statistics overlay.
+	showStats bool
+
+	// Arrivals per second, for the status bar sparkline.
+	rate rateCounter
+
+	// Unseen errors: entries at or above alertLevel that arrived while the
+	// view was scrolled up. Reaching the bottom clears the count.
+	alertLevel   string
+	alertBell    bool
+	unseenErrors int
+
+	// Session boundaries: a gap longer than sessionGap starts a new session.
+	sessionGap time.Duration
+
+	// Smart follow (see WithSmartFollow) and the lines it let arrive below
+	// the cursor since the cursor was last on the last line.
+	smartFollow bool
+	newBelow    int
+
+	// Gap markers: a separator row between displayed entries more than
+	// gapMarker apart (0 disables them).
+	gapMarker time.Duration
+
+	// Compare mode: buffer indices of the two entries being diffed (-1 when
+	// unset) and whether the diff overlay is open.
+	compareA    int
+	compareB    int
+	showCompare bool
+
+	// Context view: the unfiltered lines around buffer index contextIndex
+	// (-1 when closed), contextLines before and after it.
+	contextIndex int
+	contextLines int
+	showContext  bool
+
+	// Group view (key T): the entries whose groupField (DefaultGroupField
+	// if empty) is groupValue, scrolled by groupOffset rows. groupEntry is
+	// the stream index (buffer index plus dropped) of the entry it was
+	// opened on. groupNorm is how values are compared.
+	groupField  string
+	groupValue  string
+	groupEntry  int
+	groupOffset int
+	showGroup   bool
+	groupNorm   KeyNormalization
 
 	// Source info for status bar.
 	sourceName string
+	// src is reloaded by the r key. May be nil.
+	src source.Source
+	// srcStats is the latest snapshot of src's statistics.
+	srcStats source.SourceStats
+	// Lines received from each source since the last reload, keyed by
+	// entry source ("-" for none), and whether the source has ended.
+	sourceLines map[string]int
+	sourceDone  bool
+
+	// Filter typed at the filter prompt (key /) or loaded from a preset,
+	// the filter compiled from it and its pattern, which the detail pane
+	// highlights (both nil when empty).
+	filterText   string
+	textFilter   EntryFilter
+	filterRe     *regexp.Regexp
+	filterPrompt bool
+	filterInput  string
+
+	// Presets: where they are kept, the save prompt (key P), and the picker
+	// (key p) with the presets loaded when it opened.
+	presetStore  PresetStore
+	presetPrompt bool
+	presetInput  string
+	showPresets  bool
+	presets      []Preset
+	presetCursor int
+
+	// Where the copy keys (y, Y, C) put text; nil uses OSC 52.
+	clipboard func(text string) error
+
+	// HTML export prompt (key E): whether it is open and the path typed.
+	exportPrompt bool
+	exportInput  string
+
+	// Field-order editor (key F).
+	showFieldEditor bool
+	fieldEditor     fieldEditor
+
+	// renderer is used for anything the model renders itself (e.g. the
+	// detail pane). May be nil, in which case entries are shown as-is.
+	renderer *Renderer
+	// keyMap binds the actions to keys, and keyActions maps each bound key
+	// back to its action.
+	keyMap     KeyMap
+	keyActions map[string]action
+	// Help overlay (key ?): whether it is open and the first row shown.
+	showHelp   bool
+	helpOffset int
+
+	// Jump-to-time prompt (key t): whether it is open and the text typed.
+	timePrompt bool
+	timeInput  string
+
+	// Quit confirmation: whether q and ctrl+c ask first, and whether the
+	// question is showing.
+	quitConfirm bool
+	quitPrompt  bool
+
+	// Last left click, for double-click detection.
+	lastClick    time.Time
+	lastClickPos int
+
+	// notice is a one-off message for the status bar, cleared by the next
+	// key press.
+	notice string
+}
+
+// ModelOption configures a Model.
+type ModelOption func(*Model)
 
-	// Filter status for status bar.
-	filterText string
+// WithRenderer sets the renderer the model uses for its own output, so that
+// settings such as redaction apply to the detail pane as well.
+func WithRenderer(r *Renderer) ModelOption {
+	return func(m *Model) { m.renderer = r }
 }
 
 // NewModel creates a new LogPilot TUI model with no sources.
-func NewModel() Model {
-	return Model{
+func NewModel(opts ...ModelOption) Model {
+	keys := DefaultKeyMap()
+	m := Model{
 		autoScroll: true,
+		sessionGap: DefaultSessionGap,
+		compareA:   -1,
+		compareB:   -1,
+
+		contextIndex: -1,
+		contextLines: DefaultContextLines,
+		levelVisible: defaultLevelVisibility(),
+		alertLevel:   DefaultAlertLevel,
+		renderCache:  newLineCache(lineCacheSize),
+		keyMap:       keys,
+		keyActions:   keys.actions(),
+	}
+	for _, o := range opts {
+		o(&m)
 	}
+	return m
 }
 
 // NewModelWithSource creates a TUI model wired to a log source.
-func NewModelWithSource(src source.Source, sourceName string) Model {
-	return Model{
-		autoScroll: true,
-		sourceName: sourceName,
-	}
+func NewModelWithSource(src source.Source, sourceName string, opts ...ModelOption) Model {
+	m := NewModel(opts...)
+	m.sourceName = sourceName
+	m.src = src
+	return m
 }
 
 // viewHeight returns the number of lines available for log display
@@ -122,22 +297,38 @@ func (m Model) detailPaneHeight() int {
 	return h
 }
 
-// logPaneHeight returns the log viewport height when detail pane is visible.
+// minLogPaneHeight is the fewest log rows kept when the detail pane is open;
+// with less room the pane is not shown.
+const minLogPaneHeight = 3
+
+// Below minWidth x minHeight the View only says the terminal is too small.
+const (
+	minWidth  = 10
+	minHeight = 3
+)
+
+// detailShown reports whether the detail pane is open and there is room for
+// it besides minLogPaneHeight log rows.
+func (m Model) detailShown() bool {
+	return m.showDetail && m.viewHeight()-m.detailPaneHeight()-1 >= minLogPaneHeight
+}
+
+// logPaneHeight returns the log viewport height, which is reduced while the
+// detail pane is shown.
 func (m Model) logPaneHeight() int {
-	if !m.showDetail {
+	if !m.detailShown() {
 		return m.viewHeight()
 	}
 	// detail pane takes detailPaneHeight + 1 (border line)
-	h := m.viewHeight() - m.detailPaneHeight() - 1
-	if h < 3 {
-		return 3
-	}
-	return h
+	return m.viewHeight() - m.detailPaneHeight() - 1
 }
 
 // maxOffset returns the maximum valid scroll offset.
 func (m Model) maxOffset() int {
-	max := len(m.lines) - m.viewHeight()
+	if m.wrap && m.viewLen() > 0 {
+		return m.firstFitting(m.viewLen()-1, m.viewHeight())
+	}
+	max := m.viewLen() - m.viewHeight()
 	if max < 0 {
 		return 0
 	}
@@ -149,7 +340,7 @@ func (m *Model) clampCursor() {
 	if m.cursor < 0 {
 		m.cursor = 0
 	}
-	if max := len(m.lines) - 1; m.cursor > max {
+	if max := m.viewLen() - 1; m.cursor > max {
 		if max < 0 {
 			m.cursor = 0
 		} else {
@@ -160,14 +351,13 @@ func (m *Model) clampCursor() {
 
 // scrollToCursor adjusts offset so the cursor is visible.
 func (m *Model) scrollToCursor() {
-	vh := m.viewHeight()
-	if m.showDetail {
-		vh = m.logPaneHeight()
-	}
+	vh := m.logPaneHeight()
 	if m.cursor < m.offset {
 		m.offset = m.cursor
 	}
-	if m.cursor >= m.offset+vh {
+	if m.wrap {
+		m.offset = max(m.offset, m.firstFitting(m.cursor, vh))
+	} else if m.cursor >= m.offset+vh {
 		m.offset = m.cursor - vh + 1
 	}
 	m.clampOffset()
@@ -183,32 +373,223 @@ func (m *Model) clampOffset() {
 	}
 }
 
+// jumpTo moves the cursor to buffer index i (or, if it is filtered out, the
+// next visible line) and scrolls it into view.
+func (m *Model) jumpTo(i int) {
+	m.autoScroll = false
+	m.cursor, _ = m.viewPos(i)
+	m.clampCursor()
+	m.scrollToCursor()
+}
+
+// jumpToTime moves the cursor to the first visible entry whose timestamp is
+// at or after target. If the timestamps are in order the view is binary
+// searched; otherwise it is scanned linearly and a notice says so. Entries
+// without a timestamp are skipped. If every entry is older than target, the
+// cursor moves to the last entry.
+func (m *Model) jumpToTime(target time.Time) {
+	n := m.viewLen()
+	if n == 0 {
+		return
+	}
+	pos, found := -1, false
+	if m.timeOrdered() {
+		lo, hi := 0, n
+		for lo < hi {
+			mid := int(uint(lo+hi) >> 1)
+			p, ok := m.timedPos(mid, hi)
+			if !ok || !m.entryTime(p).Before(target) {
+				hi = mid
+			} else {
+				lo = p + 1
+			}
+		}
+		pos, found = m.timedPos(lo, n)
+	} else {
+		m.notice = "timestamps out of order; scanned linearly"
+		for p := 0; p < n && !found; p++ {
+			if t := m.entryTime(p); !t.IsZero() && !t.Before(target) {
+				pos, found = p, true
+			}
+		}
+	}
+	if !found {
+		m.notice = "no entry at or after " + target.Format("2006-01-02 15:04:05")
+		pos = n - 1
+	}
+	m.jumpTo(m.bufIndex(pos))
+}
+
+// entryTime returns the timestamp of the entry at view position pos.
+func (m Model) entryTime(pos int) time.Time {
+	if i := m.bufIndex(pos); i < len(m.entries) {
+		return m.entries[i].Timestamp
+	}
+	return time.Time{}
+}
+
+// timedPos returns the first view position in [pos, end) whose entry has a
+// timestamp.
+func (m Model) timedPos(pos, end int) (int, bool) {
+	for ; pos < end; pos++ {
+		if !m.entryTime(pos).IsZero() {
+			return pos, true
+		}
+	}
+	return end, false
+}
+
+// timeOrdered reports whether the timestamps in the view never decrease.
+func (m Model) timeOrdered() bool {
+	var prev time.Time
+	for pos := 0; pos < m.viewLen(); pos++ {
+		t := m.entryTime(pos)
+		if t.IsZero() {
+			continue
+		}
+		if t.Before(prev) {
+			return false
+		}
+		prev = t
+	}
+	return true
+}
+
 // isAtBottom returns true if the viewport is scrolled to the bottom.
 func (m Model) isAtBottom() bool {
 	return m.offset >= m.maxOffset()
 }
 
-// Init initializes the model.
+// Init initializes the model and starts polling the source's statistics.
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.pollStats()
 }
 
 // Update handles messages and updates the model.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			return m, tea.Quit
-		case "enter":
-			if len(m.lines) > 0 {
-				m.showDetail = !m.showDetail
+		m.notice = ""
+		if m.quitPrompt {
+			return m, m.updateQuitPrompt(msg.String())
+		}
+		if m.timePrompt {
+			m.updateTimePrompt(msg)
+			return m, nil
+		}
+		if m.filterPrompt {
+			m.updateFilterPrompt(msg)
+			return m, nil
+		}
+		if m.presetPrompt {
+			m.updatePresetPrompt(msg)
+			return m, nil
+		}
+		if m.exportPrompt {
+			m.updateExportPrompt(msg)
+			return m, nil
+		}
+		key := msg.String()
+		act := m.keyAction(key)
+		if m.showHelp {
+			if m.scrollHelp(act) {
+				return m, nil
+			}
+			// Any other key closes the help.
+			m.showHelp = false
+			if act == actQuit {
+				return m, m.requestQuit()
+			}
+			return m, nil
+		}
+		if m.showPresets {
+			m.updatePresetPicker(act)
+			return m, nil
+		}
+		if m.showFieldEditor {
+			m.updateFieldEditor(key, act)
+			return m, nil
+		}
+		if m.detailFocus && m.detailShown() && m.scrollDetailKey(act) {
+			return m, nil
+		}
+		if m.showGroup && m.scrollGroup(act) {
+			return m, nil
+		}
+		switch act {
+		case actQuit:
+			return m, m.requestQuit()
+		case actHelp:
+			m.showHelp = true
+			m.helpOffset = 0
+		case actDetail:
+			if m.viewLen() > 0 {
+				m.setDetail(!m.showDetail)
 			}
-		case "esc":
+		case actFocusDetail:
 			if m.showDetail {
-				m.showDetail = false
+				m.detailFocus = !m.detailFocus
 			}
-		case "j", "down":
+		case actClose:
+			if m.showDetail {
+				m.setDetail(false)
+			}
+			m.showStats = false
+			m.clearCompare()
+			m.closeContext()
+			m.closeGroup()
+		case actContext:
+			m.toggleContext()
+		case actGroup:
+			m.toggleGroup()
+		case actJumpToTime:
+			m.timePrompt = true
+			m.timeInput = ""
+		case actFilter:
+			m.filterPrompt = true
+			m.filterInput = m.filterText
+		case actSavePreset:
+			m.presetPrompt = true
+			m.presetInput = ""
+		case actPresets:
+			m.openPresets()
+		case actExport:
+			m.exportPrompt = true
+			m.exportInput = DefaultExportPath
+		case actCopyLine, actCopyJSON, actCopyCurl:
+			m.copyEntry(act)
+		case actFields:
+			m.openFieldEditor()
+		case actReload:
+			return m, m.reloadCmd()
+		case actCompare:
+			m.markCompare()
+		case actMark:
+			m.toggleMark()
+		case actLineNumbers:
+			m.showLineNumbers = !m.showLineNumbers
+		case actANSI:
+			m.toggleANSI()
+		case actLayout:
+			m.toggleLayout()
+		case actWrap:
+			m.toggleWrap()
+		case actRaw:
+			m.toggleRaw()
+		case actNextMark:
+			m.nextMark()
+		case actPrevMark:
+			m.prevMark()
+		case actToggleError, actToggleWarn, actToggleInfo, actToggleDebug, actToggleTrace:
+			m.toggleLevel(int(act - actToggleError))
+		case actStats:
+			m.showStats = !m.showStats
+		case actNextSession:
+			m.nextSession()
+		case actPrevSession:
+			m.prevSession()
+		case actDown:
 			m.autoScroll = false
 			m.cursor++
 			m.clampCursor()
@@ -216,23 +597,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.isAtBottom() {
 				m.autoScroll = true
 			}
-		case "k", "up":
+		case actUp:
 			m.autoScroll = false
 			m.cursor--
 			m.clampCursor()
 			m.scrollToCursor()
-		case "g", "home":
+		case actTop:
 			m.autoScroll = false
 			m.cursor = 0
 			m.offset = 0
-		case "G", "end":
-			m.cursor = len(m.lines) - 1
+		case actBottom:
+			m.cursor = m.viewLen() - 1
 			if m.cursor < 0 {
 				m.cursor = 0
 			}
 			m.offset = m.maxOffset()
 			m.autoScroll = true
-		case "pgdown", "f", "ctrl+f":
+		case actPageDown:
 			m.autoScroll = false
 			m.cursor += m.viewHeight()
 			m.clampCursor()
@@ -241,13 +622,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.isAtBottom() {
 				m.autoScroll = true
 			}
-		case "pgup", "b", "ctrl+b":
+		case actPageUp:
 			m.autoScroll = false
 			m.cursor -= m.viewHeight()
 			m.clampCursor()
 			m.offset -= m.viewHeight()
 			m.clampOffset()
-		case "d", "ctrl+d":
+		case actHalfPageDown:
 			m.autoScroll = false
 			m.cursor += m.viewHeight() / 2
 			m.clampCursor()
@@ -256,53 +637,114 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.isAtBottom() {
 				m.autoScroll = true
 			}
-		case "u", "ctrl+u":
+		case actHalfPageUp:
 			m.autoScroll = false
 			m.cursor -= m.viewHeight() / 2
 			m.clampCursor()
 			m.offset -= m.viewHeight() / 2
 			m.clampOffset()
 		}
+		if m.smartFollow {
+			// Following depends on the viewport alone, not the cursor.
+			m.autoScroll = m.isAtBottom()
+		}
+
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+		if m.smartFollow {
+			m.autoScroll = m.isAtBottom()
+		}
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
+		if m.renderer != nil && msg.Width > 0 && m.renderer.config.TerminalWidth != msg.Width {
+			// The renderer truncates lines and sizes table columns to the
+			// terminal width.
+			m.setRenderer(m.renderer.With(func(c *RenderConfig) { c.TerminalWidth = msg.Width }))
+		}
 		if m.autoScroll {
 			m.offset = m.maxOffset()
 		}
 		m.clampOffset()
 
 	case LogMsg:
-		m.lines = append(m.lines, msg.Rendered)
-		m.entries = append(m.entries, msg.Entry)
-		if m.autoScroll {
-			m.offset = m.maxOffset()
-			m.cursor = len(m.lines) - 1
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
-		}
+		wasLast := m.onLastLine()
+		m.appendLines([]string{msg.Rendered}, []parser.LogEntry{msg.Entry})
+		m.countSources([]parser.LogEntry{msg.Entry})
+		m.rate.add(m.now(), 1)
+		cmd = m.noteUnseen(len(m.entries) - 1)
+		m.follow(1, wasLast)
 
 	case LogBatchMsg:
-		m.lines = append(m.lines, msg.Lines...)
-		m.entries = append(m.entries, msg.Entries...)
-		if m.autoScroll {
-			m.offset = m.maxOffset()
-			m.cursor = len(m.lines) - 1
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
-		}
+		wasLast := m.onLastLine()
+		m.appendLines(msg.Lines, msg.Entries)
+		m.countSources(msg.Entries)
+		m.rate.add(m.now(), len(msg.Entries))
+		cmd = m.noteUnseen(max(len(m.entries)-len(msg.Entries), 0))
+		m.follow(len(msg.Entries), wasLast)
+
+	case ResetMsg:
+		m.reset()
+
+	case SourceDoneMsg:
+		m.sourceDone = true
+
+	case sourceStatsMsg:
+		m.srcStats = source.SourceStats(msg)
+		cmd = m.pollStats()
 
 	case ErrMsg:
 		// Show error as a log line.
-		m.lines = append(m.lines, fmt.Sprintf("ERROR: %v", msg.Err))
+		text := fmt.Sprintf("ERROR: %v", msg.Err)
+		m.appendLines([]string{text}, []parser.LogEntry{{Level: "ERROR", Message: msg.Err.Error(), Raw: text}})
 		if m.autoScroll {
 			m.offset = m.maxOffset()
 		}
 	}
-	return m, nil
+	if m.isAtBottom() {
+		m.unseenErrors = 0
+	}
+	if m.onLastLine() {
+		m.newBelow = 0
+	}
+	return m, cmd
+}
+
+// overlayRows returns the rows of the overlay shown in place of the log in
+// a pane of height rows, reporting false if none is open.
+func (m Model) overlayRows(height int) ([]string, bool) {
+	switch {
+	case m.showHelp:
+		return m.renderHelp(height), true
+	case m.showPresets:
+		return m.renderPresets(height), true
+	case m.showFieldEditor:
+		return m.renderFieldEditor(height), true
+	case m.showCompare:
+		return m.renderCompare(), true
+	case m.showContext:
+		return m.renderContext(), true
+	case m.showGroup:
+		return m.renderGroup(), true
+	case m.showStats:
+		// Recomputed on every render so it stays current as lines arrive.
+		rows := append(renderStats(ComputeStats(m.entries)), "")
+		return append(rows, m.renderInput()...), true
+	}
+	return nil, false
+}
+
+// writeRows writes height lines to b: rows, cut or padded with empty lines
+// to fit.
+func writeRows(b *strings.Builder, rows []string, height int) {
+	for i := 0; i < height; i++ {
+		if i < len(rows) {
+			b.WriteString(rows[i])
+		}
+		b.WriteByte('\n')
+	}
 }
 
 // View renders the TUI.
@@ -310,6 +752,9 @@ func (m Model) View() string {
 	if !m.ready {
 		return "Loading..."
 	}
+	if m.width < minWidth || m.height < minHeight {
+		return m.viewTooSmall()
+	}
 
 	var b strings.Builder
 
@@ -320,49 +765,46 @@ func (m Model) View() string {
 
 	// Log viewport — virtual scrolling: only render visible slice.
 	vh := m.logPaneHeight()
-	if len(m.lines) == 0 {
+	if rows, ok := m.overlayRows(vh); ok {
+		writeRows(&b, rows, vh)
+	} else if len(m.entries) == 0 {
 		// Empty state.
 		for i := 0; i < vh; i++ {
 			if i == vh/2-1 {
 				b.WriteString("  No log entries yet.")
+			} else if i == vh/2 && m.srcStats.Loading {
+				b.WriteString("  Loading " + formatBytes(m.srcStats.Scanned) + " scanned...")
 			} else if i == vh/2 {
 				b.WriteString("  Waiting for input...")
 			}
 			b.WriteByte('\n')
 		}
-	} else {
-		end := m.offset + vh
-		if end > len(m.lines) {
-			end = len(m.lines)
-		}
-		start := m.offset
-		if start < 0 {
-			start = 0
-		}
-		// Render visible lines with cursor highlight.
-		rendered := 0
-		for i := start; i < end; i++ {
-			line := m.lines[i]
-			if i == m.cursor {
-				line = cursorStyle.Render(line)
+	} else if m.viewLen() == 0 {
+		for i := 0; i < vh; i++ {
+			if i == vh/2-1 {
+				b.WriteString("  No entries match the current filter.")
 			}
-			b.WriteString(line)
 			b.WriteByte('\n')
-			rendered++
+		}
+	} else {
+		rows := m.viewportRows(vh)
+		for _, row := range rows {
+			b.WriteString(row)
+			b.WriteByte('\n')
 		}
 		// Pad remaining lines.
-		for i := rendered; i < vh; i++ {
+		for i := len(rows); i < vh; i++ {
 			b.WriteByte('\n')
 		}
 	}
 
 	// Detail pane.
-	if m.showDetail && len(m.entries) > 0 && m.cursor < len(m.entries) {
+	if i := m.cursorIndex(); m.detailShown() && i >= 0 && i < len(m.entries) {
 		b.WriteString(m.renderDetailPane())
 	}
 
 	// Status bar.
-	total := len(m.lines)
+	total := m.viewLen()
 	scrollInfo := "bottom"
 	if total > 0 && !m.isAtBottom() {
 		pct := 0
@@ -377,26 +819,195 @@ func (m Model) View() string {
 		src = "stdin"
 	}
 
-	left := statusKeyStyle.Render("Lines:") + statusBarStyle.Render(fmt.Sprintf(" %d ", total))
-	right := statusKeyStyle.Render("Pos:") + statusBarStyle.Render(fmt.Sprintf(" %s ", scrollInfo))
-	srcInfo := statusKeyStyle.Render("Src:") + statusBarStyle.Render(fmt.Sprintf(" %s ", src))
+	lineCount := fmt.Sprintf("%d", total)
+	if m.filtered() {
+		lineCount = fmt.Sprintf("%d/%d", total, len(m.entries))
+	}
+	left := statusItem("Lines:", lineCount)
+	right := statusItem("Pos:", scrollInfo)
+	if m.sourceDone {
+		src += " (done)"
+	}
+	srcInfo := statusItem("Src:", src)
 
-	// Filter status.
-	filterInfo := ""
-	if m.filterText != "" {
-		filterInfo = statusKeyStyle.Render("Filter:") + statusBarStyle.Render(fmt.Sprintf(" %s ", m.filterText))
+	// Optional status segments.
+	var info []string
+	if alert := m.renderAlert(); alert != "" {
+		info = append(info, alert)
+	}
+	if hint := m.renderNewBelow(); hint != "" {
+		info = append(info, hint)
+	}
+	if m.quitPrompt {
+		info = append(info, statusItem("Quit?", "(y/n)"))
+	}
+	if m.timePrompt {
+		info = append(info, statusItem("Jump to time:", m.timeInput+"▏"))
+	}
+	if m.presetPrompt {
+		info = append(info, statusItem("Save preset as:", m.presetInput+"▏"))
+	}
+	if m.exportPrompt {
+		info = append(info, statusItem("Export HTML to:", m.exportInput+"▏"))
+	}
+	if m.notice != "" {
+		info = append(info, statusItem("Note:", m.notice))
+	}
+	if m.filterPrompt {
+		info = append(info, statusItem("Filter:", m.filterInput+"▏"))
+	} else if m.filterText != "" {
+		info = append(info, statusItem("Filter:", m.filterText))
+	}
+	if m.levelsHidden() {
+		info = append(info, statusItem("Levels:", m.levelLegend()))
+	}
+	if m.compareA >= 0 && !m.showCompare {
+		info = append(info, statusItem("Compare:", fmt.Sprintf("A=#%d, press c on B", m.lineNumber(m.compareA))))
+	}
+	if m.renderer != nil {
+		info = append(info, statusItem("ANSI:", m.renderer.Config().ANSIMode.String()))
 	}
+	if m.renderer != nil && m.renderer.Config().Layout == LayoutTable {
+		info = append(info, statusItem("Layout:", "table"))
+	}
+	if m.wrap {
+		info = append(info, statusItem("Wrap:", "on"))
+	}
+	if m.raw {
+		info = append(info, statusItem("View:", "raw"))
+	}
+	if len(m.marks) > 0 {
+		info = append(info, statusItem("Marks:", fmt.Sprintf("%d", len(m.marks))))
+	}
+	if loading := m.renderLoading(); loading != "" {
+		info = append(info, loading)
+	}
+	if gauge := m.renderGauge(); gauge != "" {
+		info = append(info, gauge)
+	}
+	middle := strings.Join(info, "")
+	middle += m.renderRate(m.width - lipgloss.Width(left) - lipgloss.Width(right) - lipgloss.Width(srcInfo) - lipgloss.Width(middle))
 
-	gap := m.width - lipgloss.Width(left) - lipgloss.Width(right) - lipgloss.Width(srcInfo) - lipgloss.Width(filterInfo)
+	gap := m.width - lipgloss.Width(left) - lipgloss.Width(right) - lipgloss.Width(srcInfo) - lipgloss.Width(middle)
 	if gap < 0 {
 		gap = 0
 	}
-	statusLine := left + srcInfo + filterInfo + strings.Repeat(" ", gap) + right
+	statusLine := left + srcInfo + middle + strings.Repeat(" ", gap) + right
 	// Fill background.
 	statusLine = statusBarStyle.Render(statusLine)
 	b.WriteString(statusLine)
 
-	return b.String()
+	// Nothing may be wider than the terminal, or it would wrap and push
+	// the layout out of place.
+	return clipLines(b.String(), m.width)
+}
+
+// viewTooSmall is shown instead of the UI when the terminal is smaller than
+// minWidth x minHeight.
+func (m Model) viewTooSmall() string {
+	msg := []string{
+		"Terminal too small",
+		fmt.Sprintf("%dx%d, need %dx%d", m.width, m.height, minWidth, minHeight),
+	}
+	return clipLines(strings.Join(msg[:min(len(msg), max(m.height, 1))], "\n"), m.width)
+}
+
+// clipLines truncates every line of s to width display cells.
+func clipLines(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = truncateToWidth(line, max(width, 1))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statusItem renders a labelled status bar segment.
+func statusItem(key, val string) string {
+	return statusKeyStyle.Render(key) + statusBarStyle.Render(" "+val+" ")
+}
+
+// viewportRows renders the visible slice of the buffer as display rows,
+// including separator rows such as session boundaries and gap markers.
+// Separators don't consume buffer indices, so the result is trimmed back to
+// vh rows while keeping the cursor line in view.
+func (m Model) viewportRows(vh int) []string {
+	rows, _ := m.viewportLayout(vh)
+	return rows
+}
+
+// viewportLayout returns viewportRows together with the view position each
+// row belongs to, or -1 for separator rows.
+func (m Model) viewportLayout(vh int) ([]string, []int) {
+	start := m.offset
+	if start < 0 {
+		start = 0
+	}
+	end := start + vh
+	if end > m.viewLen() {
+		end = m.viewLen()
+	}
+
+	var rows []string
+	var positions []int
+	cursorRow := -1
+	lastIndex := 0
+	if end > start {
+		lastIndex = m.bufIndex(end-1) + 1
+	}
+	indexes := make([]int, 0, end-start)
+	for pos := start; pos < end; pos++ {
+		indexes = append(indexes, m.bufIndex(pos))
+	}
+	m.fitTable(indexes)
+	numWidth := m.lineNumberWidth(lastIndex)
+	textWidth := m.contentWidth(lastIndex)
+	if m.wrap {
+		textWidth = m.wrapWidth()
+		numWidth = m.lineNumberWidth(len(m.entries))
+	}
+	for pos := start; pos < end; pos++ {
+		i := m.bufIndex(pos)
+		if gap, ok := m.sessionBoundary(i); ok {
+			rows = append(rows, m.renderSessionSeparator(gap))
+			positions = append(positions, -1)
+		} else if gap, ok := m.gapBefore(pos); ok {
+			rows = append(rows, renderGapMarker(gap))
+			positions = append(positions, -1)
+		}
+		if pos == m.cursor {
+			cursorRow = len(rows)
+		}
+		gutter := m.lineNumberGutter(i, numWidth) + m.markGutterFor(i)
+		for j, line := range m.displayRows(i, textWidth) {
+			line = truncateToWidth(line, textWidth)
+			if pos == m.cursor {
+				line = cursorStyle.Render(line)
+			}
+			if j > 0 {
+				// Continuation rows get a blank gutter.
+				gutter = strings.Repeat(" ", lipgloss.Width(gutter))
+			}
+			rows = append(rows, gutter+line)
+			positions = append(positions, pos)
+		}
+	}
+
+	if len(rows) <= vh {
+		return rows, positions
+	}
+	first := 0
+	if m.isAtBottom() {
+		first = len(rows) - vh
+	}
+	if cursorRow >= 0 {
+		if cursorRow < first {
+			first = cursorRow
+		}
+		if cursorRow >= first+vh {
+			first = cursorRow - vh + 1
+		}
+	}
+	return rows[first : first+vh], positions[first : first+vh]
 }
 
 // renderDetailPane renders the detail pane for the selected log entry.
@@ -408,69 +1019,60 @@ func (m Model) renderDetailPane() string {
 	b.WriteString(sep)
 	b.WriteByte('\n')
 
-	entry := m.entries[m.cursor]
-	dh := m.detailPaneHeight()
-	rendered := 0
-
-	// Header.
-	header := detailBorderStyle.Render("▼ Detail")
-	b.WriteString(header)
-	b.WriteByte('\n')
-	rendered++
+	rows := detailRows(m.detailEntry(m.cursorIndex()), m.filterRe)
+	body := m.detailBodyHeight()
+	off := m.clampedDetailOffset(len(rows))
 
-	// Timestamp.
-	if !entry.Timestamp.IsZero() && rendered < dh {
-		b.WriteString(detailKeyStyle.Render("  timestamp") + " " + detailValStyle.Render(entry.Timestamp.Format("2006-01-02 15:04:05.000")))
-		b.WriteByte('\n')
-		rendered++
+	// Header, with the visible range when the fields do not fit.
+	header := "▼ Detail"
+	if m.detailFocus {
+		header += " · focused"
 	}
-
-	// Level.
-	if entry.Level != "" && rendered < dh {
-		b.WriteString(detailKeyStyle.Render("  level    ") + " " + detailValStyle.Render(entry.Level))
-		b.WriteByte('\n')
-		rendered++
+	if len(rows) > body {
+		end := off + body
+		header += fmt.Sprintf("  %d–%d of %d", off+1, end, len(rows))
+		if off > 0 {
+			header += " ↑"
+		}
+		if end < len(rows) {
+			header += " ↓"
+		}
 	}
+	b.WriteString(detailBorderStyle.Render(header))
+	b.WriteByte('\n')
 
-	// Message.
-	if entry.Message != "" && rendered < dh {
-		b.WriteString(detailKeyStyle.Render("  message  ") + " " + detailValStyle.Render(entry.Message))
+	for i := 0; i < body; i++ {
+		if off+i < len(rows) {
+			b.WriteString(rows[off+i])
+		}
 		b.WriteByte('\n')
-		rendered++
 	}
 
-	// Format.
-	if rendered < dh {
-		b.WriteString(detailKeyStyle.Render("  format   ") + " " + detailValStyle.Render(entry.Format.String()))
-		b.WriteByte('\n')
-		rendered++
-	}
+	return b.String()
+}
 
-	// Fields.
-	if len(entry.Fields) > 0 {
-		keys := make([]string, 0, len(entry.Fields))
-		for k := range entry.Fields {
-			keys = append(keys, k)
+// detailFieldRows renders field k of the detail pane, highlighting the
+// matches of query. A wrapped error is shown one link per row as error[0],
+// error[1], ...; a stack trace keeps its line breaks. Other fields take a
+// single row.
+func detailFieldRows(k, v string, errs parser.ErrorDetails, query *regexp.Regexp) []string {
+	label := func(s string) string { return detailKeyStyle.Render(fmt.Sprintf("  %-10s", s)) }
+	var rows []string
+	switch {
+	case k == errs.ErrorKey && k != "":
+		for i, link := range errs.Chain {
+			rows = append(rows, label(fmt.Sprintf("%s[%d]", k, i))+" "+HighlightMatches(link, query, detailValStyle))
 		}
-		sortDetailKeys(keys)
-		for _, k := range keys {
-			if rendered >= dh {
-				break
-			}
-			label := fmt.Sprintf("  %-10s", k)
-			b.WriteString(detailKeyStyle.Render(label) + " " + detailValStyle.Render(entry.Fields[k]))
-			b.WriteByte('\n')
-			rendered++
+	case k == errs.StackKey && k != "":
+		l := label(k)
+		for _, line := range strings.Split(strings.TrimRight(errs.Stack, "\n"), "\n") {
+			rows = append(rows, l+" "+HighlightMatches(line, query, detailValStyle))
+			l = strings.Repeat(" ", lipgloss.Width(l))
 		}
+	default:
+		rows = append(rows, label(k)+" "+HighlightMatches(v, query, detailValStyle))
 	}
-
-	// Pad remaining.
-	for rendered < dh {
-		b.WriteByte('\n')
-		rendered++
-	}
-
-	return b.String()
+	return rows
 }
 
 // sortDetailKeys sorts keys alphabetically (simple insertion sort).
@@ -483,28 +1085,100 @@ func sortDetailKeys(s []string) {
 }
 
 // WaitForLines returns a tea.Cmd that reads from a source and sends LogMsg
-// messages to the TUI. Call this to wire a source into the model.
+// messages to the TUI. Call this to wire a source into the model. As with
+// StreamLines, r may be nil if the model has a renderer.
 func WaitForLines(src source.Source, p *parser.AutoParser, r *Renderer) tea.Cmd {
 	return func() tea.Msg {
 		line, ok := <-src.Lines()
 		if !ok {
 			return nil
 		}
-		entry := p.Parse(line.Line)
-		rendered := r.RenderEntry(entry)
-		return LogMsg{Rendered: rendered, Entry: entry}
+		if line.Reset {
+			return ResetMsg{}
+		}
+		return entriesMsg(processLine(line, p, r))
+	}
+}
+
+// waitingLines blocks for the first line from lines and then takes those
+// already queued behind it, up to n in total.
+func waitingLines(lines <-chan source.LogEntry, n int) []source.LogEntry {
+	line, ok := <-lines
+	if !ok {
+		return nil
+	}
+	got := []source.LogEntry{line}
+	for len(got) < n {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return got
+			}
+			got = append(got, line)
+		default:
+			return got
+		}
 	}
+	return got
 }
 
-// ListenForLines returns a tea.Cmd that continuously reads from a source
-// and sends lines to the program. Use with tea.Program.Send from a goroutine.
-func ListenForLines(src source.Source, p *parser.AutoParser, r *Renderer, prog *tea.Program) {
+// processLine parses a raw source line, attaches its source name and tags,
+// and renders it with r unless r is nil. A line holding a batch of entries
+// (see parser.AutoParser.ParseMulti) yields several.
+func processLine(line source.LogEntry, p *parser.AutoParser, r *Renderer) ([]string, []parser.LogEntry) {
+	entries := p.ParseMulti(line.Line)
+	var rendered []string
+	if r != nil {
+		rendered = make([]string, len(entries))
+	}
+	for i := range entries {
+		entries[i].Source = line.Source
+		entries[i].Stream = orDefault(line.Stream, entries[i].Stream)
+		entries[i].Fields = line.ApplyTags(entries[i].Fields)
+		if r != nil {
+			rendered[i] = r.RenderEntry(entries[i])
+		}
+	}
+	return rendered, entries
+}
+
+// entriesMsg returns the message adding the entries of one source line: a
+// LogMsg, or a LogBatchMsg if the line held several.
+func entriesMsg(rendered []string, entries []parser.LogEntry) tea.Msg {
+	if len(entries) == 1 {
+		msg := LogMsg{Entry: entries[0]}
+		if len(rendered) > 0 {
+			msg.Rendered = rendered[0]
+		}
+		return msg
+	}
+	return LogBatchMsg{Lines: rendered, Entries: entries}
+}
+
+// ListenForLines starts a goroutine that reads from src and sends each line
+// to prog as one message, then SourceDoneMsg when the source ends. See
+// StreamLines for a batching variant suited to high-volume sources. Like
+// StreamLines, it pins the format of the lines already waiting when the
+// first one arrives if they agree on one.
+func ListenForLines(src source.Source, p *parser.AutoParser, r *Renderer, prog Sender) {
 	go func() {
-		for line := range src.Lines() {
-			entry := p.Parse(line.Line)
-			rendered := r.RenderEntry(entry)
-			prog.Send(LogMsg{Rendered: rendered, Entry: entry})
+		lines := src.Lines()
+		first := waitingLines(lines, parser.DefaultDetectSample)
+		p = pinFormat(p, first)
+		send := func(line source.LogEntry) {
+			if line.Reset {
+				prog.Send(ResetMsg{})
+				return
+			}
+			prog.Send(entriesMsg(processLine(line, p, r)))
+		}
+		for _, line := range first {
+			send(line)
+		}
+		for line := range lines {
+			send(line)
 		}
+		prog.Send(SourceDoneMsg{})
 	}()
 	go func() {
 		for err := range src.Errors() {
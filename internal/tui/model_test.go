@@ -2,21 +2,25 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/clarabennettdev/logpilot/internal/parser"
 )
 
-func setupModel(width, height int, lines int) Model {
-	m := NewModel()
+// setupModel returns a sized model built with opts, holding lines
+// numbered plain lines.
+func setupModel(width, height int, lines int, opts ...ModelOption) Model {
+	m := NewModel(opts...)
 	// Simulate window size.
 	m.width = width
 	m.height = height
 	m.ready = true
 	// Add lines.
 	for i := 0; i < lines; i++ {
-		m.lines = append(m.lines, fmt.Sprintf("line %d", i))
+		m.appendLines([]string{fmt.Sprintf("line %d", i)}, nil)
 	}
 	if m.autoScroll {
 		m.offset = m.maxOffset()
@@ -29,8 +33,8 @@ func TestNewModel(t *testing.T) {
 	if !m.autoScroll {
 		t.Error("expected autoScroll to be true by default")
 	}
-	if len(m.lines) != 0 {
-		t.Error("expected empty lines buffer")
+	if len(m.entries) != 0 {
+		t.Error("expected empty buffer")
 	}
 }
 
@@ -172,8 +176,8 @@ func TestAutoScrollOnNewLine(t *testing.T) {
 	if m.offset != m.maxOffset() {
 		t.Errorf("offset = %d, want %d (auto-scroll to bottom)", m.offset, m.maxOffset())
 	}
-	if len(m.lines) != 11 {
-		t.Errorf("lines count = %d, want 11", len(m.lines))
+	if len(m.entries) != 11 {
+		t.Errorf("lines count = %d, want 11", len(m.entries))
 	}
 }
 
@@ -197,8 +201,8 @@ func TestLogBatchMsg(t *testing.T) {
 	updated, _ := m.Update(batch)
 	m = updated.(Model)
 
-	if len(m.lines) != 3 {
-		t.Errorf("lines count = %d, want 3", len(m.lines))
+	if len(m.entries) != 3 {
+		t.Errorf("lines count = %d, want 3", len(m.entries))
 	}
 }
 
@@ -264,7 +268,7 @@ func TestViewWithLines(t *testing.T) {
 func TestAutoScrollReenableAtBottom(t *testing.T) {
 	m := setupModel(80, 24, 100)
 	m.autoScroll = false
-	m.cursor = len(m.lines) - 2
+	m.cursor = len(m.entries) - 2
 	m.offset = m.maxOffset() - 1
 
 	// Scroll down to bottom.
@@ -281,11 +285,11 @@ func TestErrMsg(t *testing.T) {
 	updated, _ := m.Update(ErrMsg{Err: fmt.Errorf("test error")})
 	m = updated.(Model)
 
-	if len(m.lines) != 1 {
-		t.Fatalf("lines count = %d, want 1", len(m.lines))
+	if len(m.entries) != 1 {
+		t.Fatalf("lines count = %d, want 1", len(m.entries))
 	}
-	if !contains(m.lines[0], "test error") {
-		t.Errorf("error line = %q, should contain 'test error'", m.lines[0])
+	if !contains(m.lineText(0), "test error") {
+		t.Errorf("error line = %q, should contain 'test error'", m.lineText(0))
 	}
 }
 
@@ -369,6 +373,104 @@ func TestViewWithDetailPane(t *testing.T) {
 	}
 }
 
+func TestDetailPaneShowsAllJoinedLines(t *testing.T) {
+	m := setupModel(80, 40, 1)
+	m.entries = []parser.LogEntry{{
+		Level:     "error",
+		Message:   "boom\n\tat a.b(c.go:1)\n\tat d.e(f.go:2)",
+		LineCount: 3,
+	}}
+	m.showDetail = true
+
+	v := m.View()
+	for _, want := range []string{"boom", "a.b(c.go:1)", "d.e(f.go:2)"} {
+		if !contains(v, want) {
+			t.Errorf("detail pane missing %q:\n%s", want, v)
+		}
+	}
+}
+
+func TestDetailPaneErrorChain(t *testing.T) {
+	m := setupModel(100, 40, 1)
+	m.entries = []parser.LogEntry{parser.NewAutoParser().Parse(
+		`{"level":"error","msg":"failed","error":"serve: load user: sql: no rows","stacktrace":"main.serve\n\t/app/main.go:42"}`)}
+	m.showDetail = true
+
+	v := m.View()
+	for _, want := range []string{"error[0]", "serve", "error[1]", "load user", "error[2]", "sql", "error[3]", "no rows", "main.serve", "/app/main.go:42"} {
+		if !contains(v, want) {
+			t.Errorf("detail pane missing %q:\n%s", want, v)
+		}
+	}
+	if contains(v, "serve: load user") {
+		t.Error("wrapped error should be split, not shown raw")
+	}
+
+	m.entries = []parser.LogEntry{parser.NewAutoParser().Parse(`{"level":"error","msg":"failed","error":"connection refused"}`)}
+	v = m.View()
+	if !contains(v, "error[0]") || !contains(v, "connection refused") || contains(v, "error[1]") {
+		t.Errorf("single error should be one entry:\n%s", v)
+	}
+}
+
+func TestView_TinyTerminal(t *testing.T) {
+	for _, size := range [][2]int{{1, 1}, {5, 2}, {10, 3}, {12, 4}, {40, 8}} {
+		w, h := size[0], size[1]
+		for _, mode := range []string{"plain", "detail", "stats", "context", "empty"} {
+			n := 20
+			if mode == "empty" {
+				n = 0
+			}
+			m := setupModel(w, h, n)
+			for i := 0; i < n; i++ {
+				m.entries = append(m.entries, parser.LogEntry{
+					Level:   "error",
+					Message: fmt.Sprintf("a rather long message number %d", i),
+					Fields:  map[string]string{"request_id": "0123456789abcdef"},
+				})
+			}
+			m.cursor = max(n-1, 0)
+			switch mode {
+			case "detail":
+				m.showDetail = true
+			case "stats":
+				m.showStats = true
+			case "context":
+				m.toggleContext()
+			}
+
+			v := m.View()
+			lines := strings.Split(v, "\n")
+			if len(lines) > h {
+				t.Errorf("%dx%d %s: %d lines, want at most %d:\n%s", w, h, mode, len(lines), h, v)
+			}
+			for _, line := range lines {
+				if lw := lipgloss.Width(line); lw > w {
+					t.Errorf("%dx%d %s: line %q is %d wide", w, h, mode, StripANSI(line), lw)
+				}
+			}
+			if w < minWidth || h < minHeight {
+				if strings.TrimSpace(v) == "" {
+					t.Errorf("%dx%d: want the too-small message, got %q", w, h, v)
+				}
+				continue
+			}
+			if !strings.Contains(v, "LogPilot") {
+				t.Errorf("%dx%d %s: title missing:\n%s", w, h, mode, v)
+			}
+			if mode == "detail" && strings.Contains(v, "Detail") {
+				t.Errorf("%dx%d: detail pane should be hidden when there is no room", w, h)
+			}
+		}
+	}
+
+	m := setupModel(30, 3, 0)
+	m.width, m.height = 30, 2
+	if v := m.View(); !strings.Contains(v, "Terminal too small") || !strings.Contains(v, "30x2") {
+		t.Errorf("too-small message = %q", v)
+	}
+}
+
 func TestFilterTextInStatusBar(t *testing.T) {
 	m := setupModel(80, 24, 5)
 	m.filterText = "error"
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// wheelLines is how many lines one wheel notch scrolls.
+	wheelLines = 3
+	// doubleClickInterval is the longest gap between two clicks on the same
+	// line that still counts as a double-click.
+	doubleClickInterval = 400 * time.Millisecond
+	// logTop is the screen row of the first log row, below the title bar.
+	logTop = 1
+)
+
+// handleMouse scrolls on wheel events and selects the line under the pointer
+// on a left click; a double-click also toggles the detail pane. The wheel
+// scrolls the detail pane instead when the pointer is over it. Overlays
+// such as the stats view ignore the mouse.
+func (m *Model) handleMouse(msg tea.MouseMsg) {
+	if m.showCompare || m.showContext || m.showGroup || m.showStats || m.viewLen() == 0 {
+		return
+	}
+	overDetail := m.detailShown() && msg.Y >= logTop+m.logPaneHeight()
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if overDetail {
+			m.scrollDetail(-wheelLines)
+		} else {
+			m.scrollBy(-wheelLines)
+		}
+	case tea.MouseButtonWheelDown:
+		if overDetail {
+			m.scrollDetail(wheelLines)
+		} else {
+			m.scrollBy(wheelLines)
+		}
+	case tea.MouseButtonLeft:
+		if msg.Action == tea.MouseActionPress {
+			m.click(msg.Y)
+		}
+	}
+}
+
+// scrollBy moves the viewport by n lines, dragging the cursor along so it
+// stays visible.
+func (m *Model) scrollBy(n int) {
+	m.autoScroll = false
+	m.offset += n
+	m.clampOffset()
+	vh := m.logPaneHeight()
+	if m.cursor < m.offset {
+		m.cursor = m.offset
+	} else if !m.wrap && m.cursor >= m.offset+vh {
+		m.cursor = m.offset + vh - 1
+	}
+	m.clampCursor()
+	if m.isAtBottom() {
+		m.autoScroll = true
+	}
+}
+
+// click selects the line shown on screen row y, giving the log the focus. A
+// second click on the same line within doubleClickInterval toggles the
+// detail pane.
+func (m *Model) click(y int) {
+	row := y - logTop
+	vh := m.logPaneHeight()
+	if row < 0 || row >= vh {
+		return
+	}
+	_, positions := m.viewportLayout(vh)
+	if row >= len(positions) || positions[row] < 0 {
+		return
+	}
+	pos := positions[row]
+
+	now := m.now()
+	double := pos == m.lastClickPos && now.Sub(m.lastClick) <= doubleClickInterval
+	m.lastClick, m.lastClickPos = now, pos
+	if double {
+		// A third click starts over rather than toggling again.
+		m.lastClick = time.Time{}
+	}
+
+	m.autoScroll = false
+	m.cursor = pos
+	m.detailFocus = false
+	if double {
+		m.setDetail(!m.showDetail)
+	}
+	m.scrollToCursor()
+}
@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func mouse(m Model, button tea.MouseButton, y int) Model {
+	updated, _ := m.Update(tea.MouseMsg{Button: button, Action: tea.MouseActionPress, Y: y})
+	return updated.(Model)
+}
+
+func TestMouse_Wheel(t *testing.T) {
+	m := setupModel(80, 13, 50) // 10 log rows
+	m.autoScroll = false
+	m.offset, m.cursor = 20, 25
+
+	m = mouse(m, tea.MouseButtonWheelUp, 5)
+	if m.offset != 17 || m.cursor != 25 {
+		t.Errorf("wheel up: offset = %d, cursor = %d; want 17, 25", m.offset, m.cursor)
+	}
+	m = mouse(m, tea.MouseButtonWheelDown, 5)
+	m = mouse(m, tea.MouseButtonWheelDown, 5)
+	if m.offset != 23 || m.cursor != 25 {
+		t.Errorf("wheel down: offset = %d, cursor = %d; want 23, 25", m.offset, m.cursor)
+	}
+
+	// The cursor is dragged along when it would scroll out of view.
+	m = mouse(m, tea.MouseButtonWheelDown, 5)
+	if m.offset != 26 || m.cursor != 26 {
+		t.Errorf("cursor not dragged: offset = %d, cursor = %d; want 26, 26", m.offset, m.cursor)
+	}
+
+	for i := 0; i < 20; i++ {
+		m = mouse(m, tea.MouseButtonWheelDown, 5)
+	}
+	if m.offset != m.maxOffset() || !m.autoScroll {
+		t.Errorf("scrolling to the end: offset = %d, autoScroll = %v", m.offset, m.autoScroll)
+	}
+}
+
+func TestMouse_ClickSelects(t *testing.T) {
+	m := setupModel(80, 13, 50)
+	m.autoScroll = false
+	m.offset, m.cursor = 20, 20
+
+	m = mouse(m, tea.MouseButtonLeft, logTop+4)
+	if m.cursor != 24 || m.offset != 20 {
+		t.Errorf("cursor = %d, offset = %d; want 24, 20", m.cursor, m.offset)
+	}
+	if m.showDetail {
+		t.Error("a single click should not open the detail pane")
+	}
+
+	// The title bar and rows below the log pane are ignored.
+	for _, y := range []int{0, logTop + m.logPaneHeight(), 12} {
+		if got := mouse(m, tea.MouseButtonLeft, y); got.cursor != 24 {
+			t.Errorf("click on row %d moved the cursor to %d", y, got.cursor)
+		}
+	}
+}
+
+func TestMouse_ClickSkipsSeparators(t *testing.T) {
+	// Entry 2 starts a new session, so a separator row precedes it.
+	m := sessionModel(0, time.Minute, 3*time.Hour, 3*time.Hour+time.Minute)
+
+	m = mouse(m, tea.MouseButtonLeft, logTop+3)
+	if m.cursor != 2 {
+		t.Errorf("cursor = %d, want 2 (row 3 is entry 2 below the separator)", m.cursor)
+	}
+	if got := mouse(m, tea.MouseButtonLeft, logTop+2); got.cursor != 2 {
+		t.Errorf("click on the separator moved the cursor to %d", got.cursor)
+	}
+}
+
+func TestMouse_DoubleClickTogglesDetail(t *testing.T) {
+	now := time.Date(2026, 2, 17, 8, 0, 0, 0, time.UTC)
+	m := sessionModel(0, time.Minute, 2*time.Minute)
+	m.renderer = plainRenderer(func(c *RenderConfig) { c.Now = func() time.Time { return now } })
+
+	m = mouse(m, tea.MouseButtonLeft, logTop+1)
+	now = now.Add(200 * time.Millisecond)
+	m = mouse(m, tea.MouseButtonLeft, logTop+1)
+	if !m.showDetail || m.cursor != 1 {
+		t.Fatalf("double-click: showDetail = %v, cursor = %d", m.showDetail, m.cursor)
+	}
+
+	// Slow clicks, or clicks on different lines, are single clicks.
+	now = now.Add(time.Second)
+	m = mouse(m, tea.MouseButtonLeft, logTop+2)
+	now = now.Add(100 * time.Millisecond)
+	m = mouse(m, tea.MouseButtonLeft, logTop+1)
+	now = now.Add(time.Second)
+	m = mouse(m, tea.MouseButtonLeft, logTop+1)
+	if !m.showDetail || m.cursor != 1 {
+		t.Errorf("single clicks toggled the pane: showDetail = %v, cursor = %d", m.showDetail, m.cursor)
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	m = mouse(m, tea.MouseButtonLeft, logTop+1)
+	if m.showDetail {
+		t.Error("second double-click should close the detail pane")
+	}
+}
+
+func TestMouse_DetailPaneGeometry(t *testing.T) {
+	m := setupModel(80, 30, 50)
+	m.autoScroll = false
+	m.offset, m.cursor = 0, 0
+	m.showDetail = true
+
+	// Rows of the detail pane are not log lines.
+	m = mouse(m, tea.MouseButtonLeft, logTop+m.logPaneHeight()+1)
+	if m.cursor != 0 {
+		t.Errorf("click in the detail pane moved the cursor to %d", m.cursor)
+	}
+	m = mouse(m, tea.MouseButtonLeft, logTop+m.logPaneHeight()-1)
+	if want := m.logPaneHeight() - 1; m.cursor != want {
+		t.Errorf("cursor = %d, want %d", m.cursor, want)
+	}
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+)
+
+// KeyNormalization says which differences to ignore when entries are
+// grouped by a value, so that near-identical messages fall into one group.
+// It only affects the comparison; the values are shown as they are. The
+// zero KeyNormalization compares values exactly.
+type KeyNormalization struct {
+	IgnoreCase         bool           // compare case-insensitively
+	CollapseWhitespace bool           // treat runs of whitespace as one space and ignore it at the ends
+	StripPattern       *regexp.Regexp // remove matches, e.g. request IDs, before comparing
+}
+
+// normalizeKey returns the key msg is grouped under with opts: msg with
+// the matches of the strip pattern removed, its whitespace collapsed and
+// lower-cased, as far as opts ask for each.
+func normalizeKey(msg string, opts KeyNormalization) string {
+	if opts.StripPattern != nil {
+		msg = opts.StripPattern.ReplaceAllString(msg, "")
+	}
+	if opts.CollapseWhitespace {
+		msg = strings.Join(strings.Fields(msg), " ")
+	}
+	if opts.IgnoreCase {
+		msg = strings.ToLower(msg)
+	}
+	return msg
+}
+
+// WithGroupNormalization sets the differences the group view (key T)
+// ignores between values of the group field.
+func WithGroupNormalization(norm KeyNormalization) ModelOption {
+	return func(m *Model) { m.groupNorm = norm }
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+var uuidPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+func TestNormalizeKey(t *testing.T) {
+	all := KeyNormalization{IgnoreCase: true, CollapseWhitespace: true, StripPattern: uuidPattern}
+	tests := []struct {
+		msg  string
+		opts KeyNormalization
+		want string
+	}{
+		{"Request  Failed ", KeyNormalization{}, "Request  Failed "},
+		{"Request  Failed ", KeyNormalization{IgnoreCase: true}, "request  failed "},
+		{" Request \t Failed ", KeyNormalization{CollapseWhitespace: true}, "Request Failed"},
+		{"req 0b0e3c52-2f1a-4c8e-9d3b-5a6f7e8d9c0a done", KeyNormalization{StripPattern: uuidPattern}, "req  done"},
+		{"Req 0b0e3c52-2f1a-4c8e-9d3b-5a6f7e8d9c0a  DONE", all, "req done"},
+	}
+	for _, tt := range tests {
+		if got := normalizeKey(tt.msg, tt.opts); got != tt.want {
+			t.Errorf("normalizeKey(%q, %+v) = %q, want %q", tt.msg, tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByField_Normalized(t *testing.T) {
+	entries := []parser.LogEntry{
+		{Message: "lookup 0b0e3c52-2f1a-4c8e-9d3b-5a6f7e8d9c0a failed"},
+		{Message: "lookup 7c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f failed"},
+		{Message: "Cache  Miss"},
+		{Message: "cache miss"},
+		{Message: "lookup done"},
+	}
+	tests := []struct {
+		value string
+		norm  KeyNormalization
+		want  int
+	}{
+		{entries[0].Message, KeyNormalization{}, 1},
+		{entries[0].Message, KeyNormalization{StripPattern: uuidPattern}, 2},
+		{entries[2].Message, KeyNormalization{}, 1},
+		{entries[2].Message, KeyNormalization{IgnoreCase: true}, 1},
+		{entries[2].Message, KeyNormalization{IgnoreCase: true, CollapseWhitespace: true}, 2},
+	}
+	for _, tt := range tests {
+		if got := GroupByField(entries, "message", tt.value, tt.norm); len(got) != tt.want {
+			t.Errorf("group of %q with %+v = %v, want %d entries", tt.value, tt.norm, got, tt.want)
+		}
+	}
+}
+
+func TestGroupView_Normalized(t *testing.T) {
+	m := setupModel(80, 20, 0, WithGroupField("message"), WithGroupNormalization(KeyNormalization{IgnoreCase: true}))
+	m.appendLines(nil, []parser.LogEntry{{Message: "Disk full"}, {Message: "ok"}, {Message: "disk FULL"}})
+	m = press(press(m, "g"), "T")
+	v := StripANSI(m.View())
+	// The header and rows show the messages as they are.
+	if !contains(v, "message=Disk full (2 entries)") || !contains(v, "disk FULL") {
+		t.Errorf("group view should hold both spellings:\n%s", v)
+	}
+}
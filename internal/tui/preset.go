@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Preset is a named set of view settings: the filter text, the level
+// toggles, the field order and hidden fields, and the theme.
+type Preset struct {
+	Name         string
+	Filter       string   // regular expression matched against messages; empty shows all
+	HiddenLevels []string // levels toggled off, e.g. "debug"
+	FieldOrder   []string
+	HiddenFields []string // fields not displayed; see RenderConfig.ExcludeFields
+	Theme        Theme
+}
+
+// PresetStore persists presets for the preset picker (key p) and the save
+// prompt (key P).
+type PresetStore interface {
+	// Presets returns the saved presets in display order.
+	Presets() ([]Preset, error)
+	// SavePreset saves p, replacing any preset with the same name.
+	SavePreset(p Preset) error
+}
+
+// WithPresetStore sets where presets are loaded from and saved to. Without
+// one the preset keys only show a notice.
+func WithPresetStore(s PresetStore) ModelOption {
+	return func(m *Model) { m.presetStore = s }
+}
+
+// ApplyPreset switches the view to p's settings: its filter text replaces
+// the current one, exactly its hidden levels are toggled off, and its field
+// order, hidden fields and theme re-render the buffer. An invalid filter
+// leaves the model unchanged.
+func (m *Model) ApplyPreset(p Preset) error {
+	if err := m.setFilterText(p.Filter); err != nil {
+		return fmt.Errorf("preset %q: %w", p.Name, err)
+	}
+	keep := m.cursorIndex()
+	m.levelVisible = defaultLevelVisibility()
+	for _, level := range p.HiddenLevels {
+		if level = normalizeLevel(level); level == "fatal" {
+			level = "error" // fatal follows the error toggle
+		}
+		if _, ok := m.levelVisible[level]; ok {
+			m.levelVisible[level] = false
+		}
+	}
+	m.levelVisible["fatal"] = m.levelVisible["error"]
+	if m.renderer != nil {
+		m.setRenderer(m.renderer.With(func(c *RenderConfig) {
+			c.FieldOrder = p.FieldOrder
+			c.ExcludeFields = p.HiddenFields
+			c.Theme = p.Theme
+		}))
+	}
+	m.refilter(keep)
+	return nil
+}
+
+// currentPreset captures the model's view settings as a preset called name.
+func (m Model) currentPreset(name string) Preset {
+	p := Preset{Name: name, Filter: m.filterText}
+	for _, level := range levelToggles {
+		if !m.levelVisible[level] {
+			p.HiddenLevels = append(p.HiddenLevels, level)
+		}
+	}
+	if m.renderer != nil {
+		p.FieldOrder = m.renderer.Config().FieldOrder
+		p.HiddenFields = m.renderer.Config().ExcludeFields
+		p.Theme = m.renderer.Config().Theme
+	}
+	return p
+}
+
+// updatePresetPrompt handles a key press while the save-preset prompt is
+// open: typing edits the name, enter saves, esc cancels.
+func (m *Model) updatePresetPrompt(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.presetPrompt = false
+		name := strings.TrimSpace(m.presetInput)
+		if name == "" {
+			m.notice = "no preset name given"
+			return
+		}
+		if m.presetStore == nil {
+			m.notice = "presets are not available"
+			return
+		}
+		if err := m.presetStore.SavePreset(m.currentPreset(name)); err != nil {
+			m.notice = err.Error()
+			return
+		}
+		m.notice = fmt.Sprintf("saved preset %q", name)
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.presetPrompt = false
+	default:
+		m.presetInput = editInput(m.presetInput, msg)
+	}
+}
+
+// openPresets loads the saved presets and opens the picker.
+func (m *Model) openPresets() {
+	if m.presetStore == nil {
+		m.notice = "presets are not available"
+		return
+	}
+	presets, err := m.presetStore.Presets()
+	if err != nil {
+		m.notice = err.Error()
+		return
+	}
+	if len(presets) == 0 {
+		m.notice = "no presets saved (P saves one)"
+		return
+	}
+	m.presets = presets
+	m.presetCursor = 0
+	m.showPresets = true
+}
+
+// updatePresetPicker handles a key press while the preset picker is open:
+// the scroll keys move, the detail key (enter) applies the highlighted
+// preset, and the close, presets or quit key closes it.
+func (m *Model) updatePresetPicker(act action) {
+	switch act {
+	case actDown:
+		m.presetCursor = min(m.presetCursor+1, len(m.presets)-1)
+	case actUp:
+		m.presetCursor = max(m.presetCursor-1, 0)
+	case actDetail:
+		m.showPresets = false
+		p := m.presets[m.presetCursor]
+		if err := m.ApplyPreset(p); err != nil {
+			m.notice = err.Error()
+			return
+		}
+		m.notice = fmt.Sprintf("loaded preset %q", p.Name)
+	case actClose, actPresets, actQuit:
+		m.showPresets = false
+	}
+}
+
+// renderPresets renders the preset picker in at most height rows, keeping
+// the highlighted preset in view.
+func (m Model) renderPresets(height int) []string {
+	width := 0
+	for _, p := range m.presets {
+		width = max(width, len(p.Name))
+	}
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ Presets (%d) — enter loads, esc closes", len(m.presets))),
+		"",
+	}
+	start := max(m.presetCursor-(height-len(rows))+1, 0)
+	for i := start; i < len(m.presets); i++ {
+		p := m.presets[i]
+		row := fmt.Sprintf("  %-*s  %s", width, SanitizeControl(p.Name, false), SanitizeControl(describePreset(p), false))
+		if i == m.presetCursor {
+			row = cursorStyle.Render(row)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// describePreset summarizes a preset's settings for the picker.
+func describePreset(p Preset) string {
+	parts := []string{"theme " + p.Theme.String()}
+	if p.Filter != "" {
+		parts = append(parts, "filter /"+p.Filter+"/")
+	}
+	if len(p.HiddenLevels) > 0 {
+		parts = append(parts, "hiding "+strings.Join(p.HiddenLevels, ","))
+	}
+	if len(p.FieldOrder) > 0 {
+		parts = append(parts, "fields "+strings.Join(p.FieldOrder, ","))
+	}
+	if len(p.HiddenFields) > 0 {
+		parts = append(parts, "hiding fields "+strings.Join(p.HiddenFields, ","))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,177 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// memPresets is an in-memory PresetStore.
+type memPresets struct{ presets []Preset }
+
+func (s *memPresets) Presets() ([]Preset, error) { return s.presets, nil }
+
+func (s *memPresets) SavePreset(p Preset) error {
+	for i := range s.presets {
+		if s.presets[i].Name == p.Name {
+			s.presets[i] = p
+			return nil
+		}
+	}
+	s.presets = append(s.presets, p)
+	return nil
+}
+
+// presetModel builds a model holding one entry per level.
+func presetModel(opts ...ModelOption) Model {
+	m := setupModel(120, 20, 0, append([]ModelOption{WithRenderer(plainRenderer())}, opts...)...)
+	return applyMsgs(m, logMsgs(
+		parser.LogEntry{Level: "ERROR", Message: "db timeout", Fields: map[string]string{"svc": "api", "host": "a"}},
+		parser.LogEntry{Level: "WARN", Message: "slow request", Fields: map[string]string{"svc": "api"}},
+		parser.LogEntry{Level: "INFO", Message: "request timeout retried", Fields: map[string]string{"host": "b"}},
+		parser.LogEntry{Level: "DEBUG", Message: "timeout config loaded"},
+	))
+}
+
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updated.(Model)
+}
+
+func viewMessages(m Model) []string {
+	var msgs []string
+	for pos := 0; pos < m.viewLen(); pos++ {
+		msgs = append(msgs, m.entries[m.bufIndex(pos)].Message)
+	}
+	return msgs
+}
+
+func TestApplyPreset(t *testing.T) {
+	p := Preset{Name: "timeouts", Filter: "timeout", HiddenLevels: []string{"debug"}, FieldOrder: []string{"host", "svc"}, Theme: ThemeLight}
+	m := presetModel()
+	if err := m.ApplyPreset(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(viewMessages(m), ","); got != "db timeout,request timeout retried" {
+		t.Errorf("view = %q, want the non-debug timeout entries", got)
+	}
+	if m.levelLegend() != "EWI-T" {
+		t.Errorf("levels = %q, want debug hidden", m.levelLegend())
+	}
+	cfg := m.renderer.Config()
+	if cfg.Theme != ThemeLight || strings.Join(cfg.FieldOrder, ",") != "host,svc" {
+		t.Errorf("render config theme = %v, field order = %v", cfg.Theme, cfg.FieldOrder)
+	}
+	// Lines already received are re-rendered with the preset's field order.
+	if line := StripANSI(m.lineText(0)); strings.Index(line, "host=") > strings.Index(line, "svc=") {
+		t.Errorf("line %q does not follow the preset's field order", line)
+	}
+
+	// Capturing the applied state reproduces the preset.
+	got := m.currentPreset("timeouts")
+	if got.Filter != p.Filter || strings.Join(got.HiddenLevels, ",") != "debug" ||
+		strings.Join(got.FieldOrder, ",") != "host,svc" || got.Theme != p.Theme {
+		t.Errorf("currentPreset = %+v, want %+v", got, p)
+	}
+
+	// A preset without hidden levels or filter shows everything again.
+	if err := m.ApplyPreset(Preset{Name: "all"}); err != nil {
+		t.Fatal(err)
+	}
+	if m.viewLen() != 4 || m.filtered() {
+		t.Errorf("view has %d entries (filtered %v), want all 4", m.viewLen(), m.filtered())
+	}
+}
+
+func TestApplyPreset_InvalidFilter(t *testing.T) {
+	m := presetModel()
+	if err := m.ApplyPreset(Preset{Name: "bad", Filter: "(", HiddenLevels: []string{"info"}}); err == nil {
+		t.Fatal("expected an error for an invalid filter")
+	}
+	if m.levelsHidden() || m.filterText != "" {
+		t.Error("a failed preset should leave the model unchanged")
+	}
+}
+
+func TestFilterPrompt(t *testing.T) {
+	m := presetModel()
+	m = typeText(press(m, "/"), "slow|db")
+	if m.filterPrompt || m.filterText != "slow|db" {
+		t.Fatalf("prompt open = %v, filter = %q", m.filterPrompt, m.filterText)
+	}
+	if got := strings.Join(viewMessages(m), ","); got != "db timeout,slow request" {
+		t.Errorf("view = %q", got)
+	}
+	if !contains(StripANSI(m.View()), "slow|db") {
+		t.Error("status bar should show the filter")
+	}
+
+	// An invalid pattern keeps the old filter and says why.
+	m = typeText(press(m, "/"), "(")
+	if m.filterText != "slow|db" || !strings.Contains(m.notice, "invalid pattern") {
+		t.Errorf("filter = %q, notice = %q", m.filterText, m.notice)
+	}
+
+	// Clearing the text removes the filter.
+	m = press(m, "/")
+	for range "slow|db" {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = updated.(Model)
+	}
+	m = typeText(m, "")
+	if m.filtered() || m.viewLen() != 4 {
+		t.Errorf("view has %d entries after clearing the filter", m.viewLen())
+	}
+}
+
+func TestPresetKeys_SaveAndPick(t *testing.T) {
+	store := &memPresets{presets: []Preset{{Name: "errors only", HiddenLevels: []string{"warn", "info", "debug", "trace"}, Theme: ThemeDark}}}
+	m := presetModel(WithPresetStore(store))
+
+	// Save the current view: filtered, with trace hidden.
+	m = typeText(press(m, "/"), "timeout")
+	m = press(m, "5")
+	m = typeText(press(m, "P"), "mine")
+	if len(store.presets) != 2 || !strings.Contains(m.notice, `saved preset "mine"`) {
+		t.Fatalf("presets = %+v, notice = %q", store.presets, m.notice)
+	}
+	if saved := store.presets[1]; saved.Filter != "timeout" || strings.Join(saved.HiddenLevels, ",") != "trace" {
+		t.Errorf("saved preset = %+v", saved)
+	}
+
+	// Pick the first preset from the picker.
+	m = press(m, "p")
+	if !m.showPresets || !contains(m.View(), "errors only") || !contains(m.View(), "mine") {
+		t.Fatalf("picker should list both presets:\n%s", m.View())
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.showPresets || m.filterText != "" {
+		t.Errorf("picker open = %v, filter = %q", m.showPresets, m.filterText)
+	}
+	if got := strings.Join(viewMessages(m), ","); got != "db timeout" {
+		t.Errorf("view = %q, want only the error", got)
+	}
+
+	// Then back to the saved one.
+	m = press(press(m, "p"), "j")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if m.filterText != "timeout" || m.levelLegend() != "EWID-" {
+		t.Errorf("filter = %q, levels = %q after loading mine", m.filterText, m.levelLegend())
+	}
+}
+
+func TestPresetKeys_NoStore(t *testing.T) {
+	m := press(presetModel(), "p")
+	if m.showPresets || m.notice == "" {
+		t.Errorf("without a store the picker should not open (notice %q)", m.notice)
+	}
+}
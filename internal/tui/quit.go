@@ -0,0 +1,30 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// WithQuitConfirm makes q and ctrl+c ask "Quit? (y/n)" in the status bar
+// before quitting. A second ctrl+c quits without waiting for the answer.
+func WithQuitConfirm(on bool) ModelOption {
+	return func(m *Model) { m.quitConfirm = on }
+}
+
+// requestQuit quits, or opens the quit prompt if quitting is to be
+// confirmed.
+func (m *Model) requestQuit() tea.Cmd {
+	if !m.quitConfirm {
+		return tea.Quit
+	}
+	m.quitPrompt = true
+	return nil
+}
+
+// updateQuitPrompt handles a key press while the quit prompt is open: y or
+// a second ctrl+c quits, any other key cancels.
+func (m *Model) updateQuitPrompt(key string) tea.Cmd {
+	m.quitPrompt = false
+	switch key {
+	case "y", "Y", "ctrl+c":
+		return tea.Quit
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quits reports whether cmd quits the program.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func quitModel() Model {
+	return setupModel(80, 24, 2, WithQuitConfirm(true))
+}
+
+func sendKey(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
+
+func TestQuitConfirm(t *testing.T) {
+	q := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}
+	m, cmd := sendKey(quitModel(), q)
+	if quits(cmd) || !m.quitPrompt {
+		t.Fatal("q should ask before quitting")
+	}
+	if v := StripANSI(m.View()); !contains(v, "Quit?") || !contains(v, "(y/n)") {
+		t.Errorf("status bar should show the prompt:\n%s", m.View())
+	}
+
+	m, cmd = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if quits(cmd) || m.quitPrompt {
+		t.Fatal("n should cancel")
+	}
+	if m.cursor != 0 {
+		t.Error("the answer should not reach the view")
+	}
+
+	m, _ = sendKey(m, q)
+	if _, cmd = sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}); !quits(cmd) {
+		t.Error("y should quit")
+	}
+}
+
+func TestQuitConfirm_DoubleCtrlC(t *testing.T) {
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+	m, cmd := sendKey(quitModel(), ctrlC)
+	if quits(cmd) {
+		t.Fatal("the first ctrl+c should ask")
+	}
+	if _, cmd = sendKey(m, ctrlC); !quits(cmd) {
+		t.Error("a second ctrl+c should quit")
+	}
+}
+
+func TestQuitConfirm_Off(t *testing.T) {
+	m := quitModel()
+	m.quitConfirm = false
+	if _, cmd := sendKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); !quits(cmd) {
+		t.Error("q should quit at once without confirmation")
+	}
+}
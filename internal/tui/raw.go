@@ -0,0 +1,26 @@
+package tui
+
+// toggleRaw switches the viewport between the rendered lines and the
+// original input lines.
+func (m *Model) toggleRaw() {
+	m.raw = !m.raw
+	if m.autoScroll {
+		m.offset = m.maxOffset()
+	} else {
+		m.scrollToCursor()
+	}
+}
+
+// lineText returns the text shown for buffer line i: the rendered line, or
+// in raw mode the entry's original input. Joined multi-line input shows its
+// first line with a count of the rest, as rendered entries do.
+func (m Model) lineText(i int) string {
+	if m.raw && i < len(m.entries) && m.entries[i].Raw != "" {
+		first, more := firstLine(m.entries[i].Raw, m.entries[i].LineCount)
+		if m.renderer != nil {
+			first = m.renderer.display(first)
+		}
+		return first + more
+	}
+	return m.renderLine(i)
+}
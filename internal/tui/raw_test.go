@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestRawToggle(t *testing.T) {
+	m := setupModel(120, 10, 0)
+	r := plainRenderer()
+	p := parser.NewAutoParser()
+	raws := []string{
+		`{"level":"info","msg":"started","port":8080}`,
+		`level=warn msg="disk low" free=3%`,
+		`2026-02-17 19:59:00 ERROR   oops   `,
+	}
+	for _, raw := range raws {
+		e := p.Parse(raw)
+		updated, _ := m.Update(LogMsg{Rendered: r.RenderEntry(e), Entry: e})
+		m = updated.(Model)
+	}
+
+	rows := m.viewportRows(m.viewHeight())
+	if strings.Contains(StripANSI(rows[0]), `"msg"`) {
+		t.Fatalf("rendered view already shows the raw line: %q", rows[0])
+	}
+
+	m = press(m, "R")
+	rows = m.viewportRows(m.viewHeight())
+	for i, raw := range raws {
+		if got := strings.TrimRight(StripANSI(rows[i]), " "); got != strings.TrimRight(raw, " ") {
+			t.Errorf("row %d = %q, want raw %q", i, got, raw)
+		}
+	}
+	if !contains(m.View(), "raw") {
+		t.Error("status bar should indicate raw mode")
+	}
+
+	m = press(m, "R")
+	if rows = m.viewportRows(m.viewHeight()); strings.Contains(StripANSI(rows[0]), `"msg"`) {
+		t.Errorf("toggling back should restore the rendered view: %q", rows[0])
+	}
+}
+
+func TestRawToggle_MultiLine(t *testing.T) {
+	m := setupModel(120, 10, 0)
+	raw := "panic: boom\n\tat main.go:1\n\tat main.go:2"
+	m.lines = []string{"rendered"}
+	m.entries = []parser.LogEntry{{Message: raw, Raw: raw, LineCount: 3}}
+	m.raw = true
+
+	if got := StripANSI(m.viewportRows(m.viewHeight())[0]); !strings.HasPrefix(got, "panic: boom ⏎ +2 lines") {
+		t.Errorf("row = %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// RedactMode controls what a redacted value is replaced with.
+type RedactMode int
+
+const (
+	// RedactMask replaces sensitive values with "***".
+	RedactMask RedactMode = iota
+	// RedactHash replaces sensitive values with a short stable hash, so equal
+	// values can still be correlated without being revealed.
+	RedactHash
+)
+
+// redactMask is the replacement text used by RedactMask.
+const redactMask = "***"
+
+// Built-in value patterns for common sensitive data.
+var (
+	// RedactEmail matches email addresses.
+	RedactEmail = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// RedactCreditCard matches 13–19 digit card-like numbers, optionally
+	// grouped with spaces or dashes.
+	RedactCreditCard = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	// RedactIPv4 matches dotted-quad IPv4 addresses.
+	RedactIPv4 = regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)
+)
+
+// RedactConfig holds the redaction rules applied before an entry is displayed
+// or exported. The zero value disables redaction.
+type RedactConfig struct {
+	Fields   []string         // field names whose values are always redacted (case-insensitive)
+	Patterns []*regexp.Regexp // value patterns redacted wherever they appear
+	Mode     RedactMode
+}
+
+// DefaultRedactConfig returns rules covering common credentials and PII.
+func DefaultRedactConfig() RedactConfig {
+	return RedactConfig{
+		Fields:   []string{"authorization", "password", "passwd", "secret", "token", "api_key", "cookie"},
+		Patterns: []*regexp.Regexp{RedactEmail, RedactCreditCard, RedactIPv4},
+		Mode:     RedactMask,
+	}
+}
+
+// ParseRedactMode parses a redaction mode name: "mask" or "hash".
+func ParseRedactMode(s string) (RedactMode, error) {
+	switch strings.ToLower(s) {
+	case "mask":
+		return RedactMask, nil
+	case "hash":
+		return RedactHash, nil
+	default:
+		return 0, fmt.Errorf("unknown redact mode %q (want mask or hash)", s)
+	}
+}
+
+// enabled reports whether any redaction rule is configured.
+func (c RedactConfig) enabled() bool {
+	return len(c.Fields) > 0 || len(c.Patterns) > 0
+}
+
+// isSensitiveField reports whether the field name matches a configured rule.
+func (c RedactConfig) isSensitiveField(key string) bool {
+	for _, f := range c.Fields {
+		if strings.EqualFold(f, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// replacement returns the text that stands in for a redacted value.
+func (c RedactConfig) replacement(value string) string {
+	if c.Mode == RedactHash {
+		sum := sha256.Sum256([]byte(value))
+		return "#" + hex.EncodeToString(sum[:4])
+	}
+	return redactMask
+}
+
+// redactString applies the value patterns to s.
+func (c RedactConfig) redactString(s string) string {
+	for _, pat := range c.Patterns {
+		s = pat.ReplaceAllStringFunc(s, c.replacement)
+	}
+	return s
+}
+
+// Redact returns a copy of entry with the renderer's redaction rules applied
+// to the message, the raw line, and every field value. The original entry is
+// left untouched.
+func (r *Renderer) Redact(entry parser.LogEntry) parser.LogEntry {
+	rc := r.config.Redact
+	if !rc.enabled() {
+		return entry
+	}
+
+	raw := entry.Raw
+	msg := entry.Message
+	fields := make(map[string]string, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if rc.isSensitiveField(k) {
+			repl := rc.replacement(v)
+			// The value also appears verbatim in the raw line (and possibly
+			// the message), so scrub it there too.
+			if v != "" {
+				raw = strings.ReplaceAll(raw, v, repl)
+				msg = strings.ReplaceAll(msg, v, repl)
+			}
+			fields[k] = repl
+			continue
+		}
+		fields[k] = rc.redactString(v)
+	}
+
+	entry.Raw = rc.redactString(raw)
+	entry.Message = rc.redactString(msg)
+	entry.Fields = fields
+	return entry
+}
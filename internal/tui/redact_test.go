@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func redactingRenderer(mode RedactMode) *Renderer {
+	return plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.Redact = RedactConfig{
+			Fields:   []string{"password"},
+			Patterns: []*regexp.Regexp{RedactEmail},
+			Mode:     mode,
+		}
+	})
+}
+
+func sensitiveEntry() parser.LogEntry {
+	return parser.LogEntry{
+		Level:   "info",
+		Message: "login by alice@example.com",
+		Raw:     `{"level":"info","msg":"login by alice@example.com","Password":"hunter2","user":"alice@example.com"}`,
+		Fields:  map[string]string{"Password": "hunter2", "user": "alice@example.com", "method": "POST"},
+		Format:  parser.FormatJSON,
+	}
+}
+
+func TestRedact_FieldAndPattern(t *testing.T) {
+	r := redactingRenderer(RedactMask)
+	orig := sensitiveEntry()
+	got := r.Redact(orig)
+
+	if got.Fields["Password"] != "***" {
+		t.Errorf("Password = %q, want masked", got.Fields["Password"])
+	}
+	if got.Fields["user"] != "***" {
+		t.Errorf("user = %q, want email masked", got.Fields["user"])
+	}
+	if got.Fields["method"] != "POST" {
+		t.Errorf("method = %q, non-sensitive field should be untouched", got.Fields["method"])
+	}
+	for _, s := range []string{got.Message, got.Raw} {
+		if strings.Contains(s, "hunter2") || strings.Contains(s, "alice@example.com") {
+			t.Errorf("sensitive data leaked in %q", s)
+		}
+	}
+	// The original entry must not be modified.
+	if orig.Fields["Password"] != "hunter2" {
+		t.Error("Redact mutated the original entry's fields")
+	}
+}
+
+func TestRedact_HashIsStable(t *testing.T) {
+	r := redactingRenderer(RedactHash)
+	a := r.Redact(sensitiveEntry())
+	b := r.Redact(sensitiveEntry())
+	if a.Fields["Password"] == "hunter2" || !strings.HasPrefix(a.Fields["Password"], "#") {
+		t.Errorf("Password = %q, want hash", a.Fields["Password"])
+	}
+	if a.Fields["Password"] != b.Fields["Password"] {
+		t.Error("hash redaction should be stable across entries")
+	}
+}
+
+func TestRedact_DisabledByDefault(t *testing.T) {
+	r := plainRenderer()
+	entry := sensitiveEntry()
+	if got := r.Redact(entry); got.Fields["Password"] != "hunter2" {
+		t.Error("zero RedactConfig should not redact")
+	}
+}
+
+func TestRedact_AppliedEverywhere(t *testing.T) {
+	r := redactingRenderer(RedactMask)
+	entry := sensitiveEntry()
+
+	outputs := map[string]string{
+		"inline styled": r.RenderEntry(entry),
+		"inline plain":  r.RenderEntryPlain(entry),
+		"raw fallback":  r.RenderEntryPlain(parser.LogEntry{Raw: entry.Raw, Fields: entry.Fields}),
+	}
+
+	m := setupModel(120, 40, 0, WithRenderer(r))
+	updated, _ := m.Update(LogMsg{Rendered: r.RenderEntry(entry), Entry: entry})
+	m = updated.(Model)
+	m.showDetail = true
+	outputs["detail pane"] = m.renderDetailPane()
+
+	for where, out := range outputs {
+		if strings.Contains(out, "hunter2") {
+			t.Errorf("%s: password leaked: %q", where, out)
+		}
+		if strings.Contains(out, "alice@example.com") {
+			t.Errorf("%s: email leaked: %q", where, out)
+		}
+	}
+}
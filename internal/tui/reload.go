@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ResetMsg tells the model that its source was reloaded: the buffer is
+// cleared and the re-read lines arrive in the messages that follow.
+type ResetMsg struct{}
+
+// reloadCmd asks the source to re-read its input from the beginning. The
+// buffer is cleared when the source's Reset marker comes through as a
+// ResetMsg, so lines still in flight are never shown twice.
+func (m Model) reloadCmd() tea.Cmd {
+	src := m.src
+	if src == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := src.Reload(); err != nil {
+			return ErrMsg{Err: fmt.Errorf("reload: %w", err)}
+		}
+		return nil
+	}
+}
+
+// reset empties the buffer and everything that refers to buffer indices.
+// Filters and display settings are kept.
+func (m *Model) reset() {
+	m.lines, m.entries, m.view = nil, nil, nil
+	m.renderCache.clear()
+	m.marks = nil
+	m.dropped = 0
+	m.sourceLines = nil
+	m.unseenErrors = 0
+	m.cursor, m.offset = 0, 0
+	m.autoScroll = true
+	m.setDetail(false)
+	m.clearCompare()
+	m.closeContext()
+	m.closeGroup()
+}
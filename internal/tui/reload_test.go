@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+// applyMsgs feeds recorded messages to m in order.
+func applyMsgs(m Model, msgs []tea.Msg) Model {
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	return m
+}
+
+// logMsgs wraps entries in LogMsgs rendered as their messages.
+func logMsgs(entries ...parser.LogEntry) []tea.Msg {
+	msgs := make([]tea.Msg, len(entries))
+	for i, e := range entries {
+		msgs[i] = LogMsg{Rendered: e.Message, Entry: e}
+	}
+	return msgs
+}
+
+func TestStreamLines_ResetDropsPendingBatch(t *testing.T) {
+	src := newChanSource(4)
+	src.lines <- source.LogEntry{Line: "old1"}
+	src.lines <- source.LogEntry{Line: "old2"}
+	src.lines <- source.LogEntry{Reset: true}
+	src.lines <- source.LogEntry{Line: "new"}
+	close(src.lines)
+
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, WithBatchInterval(time.Hour))
+	msgs := rec.waitForLines(t, 1)
+
+	if _, ok := msgs[0].(ResetMsg); !ok {
+		t.Fatalf("first message = %T, want ResetMsg", msgs[0])
+	}
+	m := applyMsgs(NewModel(), msgs)
+	if len(m.entries) != 1 || m.entries[0].Message != "new" {
+		t.Errorf("buffer after reset = %v, want [new]", m.entries)
+	}
+}
+
+func TestReload_FileReEmittedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := source.NewFileSource(source.FileConfig{Patterns: []string{path}})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := src.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer src.Stop()
+
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, WithBatchInterval(time.Millisecond))
+	m := NewModelWithSource(src, path)
+	m.width, m.height, m.ready = 80, 24, true
+	before := rec.waitForLines(t, 3)
+	m = applyMsgs(m, before)
+	m.marks = map[int]bool{1: true}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("r should return a reload command")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("reload command returned %v", msg)
+	}
+	msgs := rec.waitForLines(t, 6)
+	m = applyMsgs(m, msgs[len(before):])
+
+	var got []string
+	for _, e := range m.entries {
+		got = append(got, e.Message)
+	}
+	if len(got) != 3 || got[0] != "one" || got[1] != "two" || got[2] != "three" {
+		t.Errorf("buffer after reload = %q, want each line exactly once", got)
+	}
+	if len(m.marks) != 0 {
+		t.Error("marks should be cleared on reload")
+	}
+}
+
+func TestReload_Unsupported(t *testing.T) {
+	if _, cmd := NewModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil {
+		t.Error("r without a source should do nothing")
+	}
+
+	m := NewModelWithSource(newChanSource(0), "stdin")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("expected a reload command")
+	}
+	if _, ok := cmd().(ErrMsg); !ok {
+		t.Error("reloading an unsupported source should report an error")
+	}
+}
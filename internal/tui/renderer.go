@@ -2,13 +2,18 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/muesli/termenv"
 )
 
 // TimestampFormat controls how timestamps are displayed.
@@ -23,14 +28,74 @@ const (
 	TimestampLocal
 )
 
+// ParseTimestampFormat parses a timestamp format name: "relative", "iso" or
+// "local".
+func ParseTimestampFormat(s string) (TimestampFormat, error) {
+	switch strings.ToLower(s) {
+	case "relative":
+		return TimestampRelative, nil
+	case "iso":
+		return TimestampISO, nil
+	case "local":
+		return TimestampLocal, nil
+	default:
+		return 0, fmt.Errorf("unknown timestamp format %q (want relative, iso or local)", s)
+	}
+}
+
 // Theme represents terminal color theme.
 type Theme int
 
 const (
 	ThemeDark Theme = iota
 	ThemeLight
+	ThemeSolarizedDark
+	ThemeSolarizedLight
+	// ThemeHighContrast uses bold, maximally distinct colors from the basic
+	// 16-color palette, so it works on any terminal and for colorblind users.
+	ThemeHighContrast
+	// ThemeAuto asks the terminal for its background color when the
+	// renderer is created and resolves to ThemeDark or ThemeLight. Without
+	// an answer (not a terminal, CI) it resolves to ThemeDark.
+	ThemeAuto
 )
 
+// themeNames maps theme names, as accepted by ParseTheme, to themes.
+var themeNames = []struct {
+	name  string
+	theme Theme
+}{
+	{"dark", ThemeDark},
+	{"light", ThemeLight},
+	{"solarized-dark", ThemeSolarizedDark},
+	{"solarized-light", ThemeSolarizedLight},
+	{"high-contrast", ThemeHighContrast},
+	{"auto", ThemeAuto},
+}
+
+// ParseTheme parses a theme name: "dark", "light", "solarized-dark",
+// "solarized-light", "high-contrast" or "auto".
+func ParseTheme(s string) (Theme, error) {
+	var names []string
+	for _, t := range themeNames {
+		if strings.EqualFold(s, t.name) {
+			return t.theme, nil
+		}
+		names = append(names, t.name)
+	}
+	return 0, fmt.Errorf("unknown theme %q (want %s)", s, strings.Join(names, ", "))
+}
+
+// String returns the theme's name as accepted by ParseTheme.
+func (t Theme) String() string {
+	for _, n := range themeNames {
+		if n.theme == t {
+			return n.name
+		}
+	}
+	return "unknown"
+}
+
 // ANSIMode controls how ANSI escape codes in source logs are handled.
 type ANSIMode int
 
@@ -39,6 +104,13 @@ const (
 	ANSIPassthrough
 )
 
+func (a ANSIMode) String() string {
+	if a == ANSIPassthrough {
+		return "keep"
+	}
+	return "strip"
+}
+
 // WrapMode controls how long lines are handled.
 type WrapMode int
 
@@ -50,32 +122,103 @@ const (
 // RenderConfig holds rendering configuration.
 type RenderConfig struct {
 	TimestampFormat TimestampFormat
-	Theme          Theme
-	ANSIMode       ANSIMode
-	WrapMode       WrapMode
-	TerminalWidth  int
-	FieldOrder     []string // ordered field names to display; empty = alphabetical
-	ShowAllFields  bool     // when false, extra fields are collapsed
-	Now            func() time.Time // for testing; defaults to time.Now
+	Theme           Theme
+	ANSIMode        ANSIMode
+	WrapMode        WrapMode
+	TerminalWidth   int
+	FieldOrder      []string     // ordered field names to display; empty = alphabetical
+	ShowAllFields   bool         // when false, extra fields are collapsed
+	Redact          RedactConfig // field/value redaction rules; zero value disables
+	// IncludeFields, when set, limits the fields shown inline and in the
+	// detail pane to those named; ExcludeFields hides the fields named.
+	// A field in both lists is hidden. Entries themselves keep every field.
+	IncludeFields []string
+	ExcludeFields []string
+	// FieldKeyStyles overrides the style of the values of the named fields,
+	// which are otherwise colored by the kind of value (number, IP, ...).
+	FieldKeyStyles map[string]lipgloss.Style
+	// MaxFieldValueLen shortens inline field values to this many runes,
+	// ending in "…". The detail pane still shows them in full. Zero means
+	// unlimited.
+	MaxFieldValueLen int
+	// Separator goes between the level, timestamp, message and fields.
+	// Empty means DefaultSeparator.
+	Separator string
+	// CompactMode packs more onto a line: the separator loses its padding,
+	// the level is abbreviated to one letter and timestamps are short
+	// relative ages such as "5m", whatever the TimestampFormat.
+	CompactMode bool
+	// RelativeTimePrecision is how many units relative timestamps show:
+	// one (the default, also for zero) gives "2h ago", two "2h5m ago" or
+	// "1d3h ago". Units that come out zero are left out.
+	RelativeTimePrecision int
+	// JustNowThreshold is how close to now, before or after, a relative
+	// timestamp has to be to read "just now" ("now" when compact). Zero
+	// means one second.
+	JustNowThreshold time.Duration
+	// DisplayLocation is the zone timestamps are shown in, so that entries
+	// from services logging in different zones line up on one timeline.
+	// Nil shows each timestamp in the zone it was parsed with.
+	DisplayLocation *time.Location
+	// Layout arranges the parts of an entry inline (the default) or in
+	// aligned table columns; see LayoutMode.
+	Layout LayoutMode
+	// TabWidth is the distance between the tab stops tabs in messages and
+	// fields are expanded to, before widths are measured for alignment and
+	// truncation. Zero means DefaultTabWidth.
+	TabWidth int
+	// SanitizeControl replaces control characters and invalid UTF-8 in
+	// messages and fields with visible escapes, so a stray byte cannot
+	// corrupt the display; see SanitizeControl. DefaultConfig enables it.
+	SanitizeControl bool
+	// ColorKeysByName gives each field key a color of its own, picked from
+	// the theme's key palette by a hash of the name, so a key has the same
+	// color on every line. Otherwise all keys share one color.
+	ColorKeysByName bool
+	// MarkIssues puts an IssueGutter in front of entries with schema
+	// issues (see parser.Validator) and an equally wide blank in front of
+	// the others.
+	MarkIssues bool
+	// MarkStderr puts a StderrGutter in the error color in front of
+	// entries written to standard error (parser.LogEntry.Stream), whatever
+	// their level, and an equally wide blank in front of the others.
+	MarkStderr bool
+	Now        func() time.Time // for testing; defaults to time.Now
 }
 
+// DefaultSeparator is the separator between the parts of a rendered entry.
+const DefaultSeparator = " │ "
+
+// IssueGutter marks entries that failed schema validation when
+// RenderConfig.MarkIssues is set.
+const IssueGutter = "! "
+
+// StderrGutter marks entries written to standard error when
+// RenderConfig.MarkStderr is set.
+const StderrGutter = "▌ "
+
 // DefaultConfig returns a sensible default configuration.
 func DefaultConfig() RenderConfig {
 	return RenderConfig{
 		TimestampFormat: TimestampLocal,
-		Theme:          ThemeDark,
-		ANSIMode:       ANSIStrip,
-		WrapMode:       WrapTruncate,
-		TerminalWidth:  120,
-		ShowAllFields:  false,
-		Now:            time.Now,
+		Theme:           ThemeDark,
+		ANSIMode:        ANSIStrip,
+		WrapMode:        WrapTruncate,
+		TerminalWidth:   120,
+		ShowAllFields:   false,
+		SanitizeControl: true,
+		Now:             time.Now,
 	}
 }
 
-// Renderer renders parsed log entries as styled terminal output.
+// Renderer renders parsed log entries as styled terminal output. It is
+// safe for concurrent use: its configuration is copied when it is created
+// and never changes, and the one piece of state rendering updates, the
+// table column widths, is guarded by a mutex.
 type Renderer struct {
 	config RenderConfig
 	styles themeStyles
+	table  *columnSampler // column widths for LayoutTable
 }
 
 type themeStyles struct {
@@ -89,6 +232,17 @@ type themeStyles struct {
 	fieldKey  lipgloss.Style
 	fieldVal  lipgloss.Style
 	separator lipgloss.Style
+
+	// Field values by kind; plain strings use fieldVal.
+	number   lipgloss.Style
+	boolean  lipgloss.Style
+	duration lipgloss.Style
+	ip       lipgloss.Style
+	url      lipgloss.Style
+
+	// keyColors holds the field key colors for ColorKeysByName, chosen
+	// apart from the level colors.
+	keyColors []lipgloss.Color
 }
 
 func darkStyles() themeStyles {
@@ -97,12 +251,20 @@ func darkStyles() themeStyles {
 		info:      lipgloss.NewStyle().Foreground(lipgloss.Color("39")),             // blue
 		warn:      lipgloss.NewStyle().Foreground(lipgloss.Color("220")),            // yellow
 		errLevel:  lipgloss.NewStyle().Foreground(lipgloss.Color("196")),            // red
-		fatal:     lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),  // red bold
+		fatal:     lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true), // red bold
 		timestamp: lipgloss.NewStyle().Foreground(lipgloss.Color("243")),            // dim gray
 		message:   lipgloss.NewStyle().Foreground(lipgloss.Color("255")),            // white
 		fieldKey:  lipgloss.NewStyle().Foreground(lipgloss.Color("117")),            // light blue
 		fieldVal:  lipgloss.NewStyle().Foreground(lipgloss.Color("252")),            // light gray
 		separator: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),            // dark gray
+		number:    lipgloss.NewStyle().Foreground(lipgloss.Color("141")),            // lavender
+		boolean:   lipgloss.NewStyle().Foreground(lipgloss.Color("208")),            // orange
+		duration:  lipgloss.NewStyle().Foreground(lipgloss.Color("114")),            // green
+		ip:        lipgloss.NewStyle().Foreground(lipgloss.Color("180")),            // tan
+		url:       lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Underline(true),
+		keyColors: []lipgloss.Color{
+			"73", "79", "110", "117", "139", "146", "150", "151", "152", "174", "181", "183",
+		},
 	}
 }
 
@@ -118,24 +280,186 @@ func lightStyles() themeStyles {
 		fieldKey:  lipgloss.NewStyle().Foreground(lipgloss.Color("25")),
 		fieldVal:  lipgloss.NewStyle().Foreground(lipgloss.Color("237")),
 		separator: lipgloss.NewStyle().Foreground(lipgloss.Color("249")),
+		number:    lipgloss.NewStyle().Foreground(lipgloss.Color("91")),
+		boolean:   lipgloss.NewStyle().Foreground(lipgloss.Color("130")),
+		duration:  lipgloss.NewStyle().Foreground(lipgloss.Color("28")),
+		ip:        lipgloss.NewStyle().Foreground(lipgloss.Color("94")),
+		url:       lipgloss.NewStyle().Foreground(lipgloss.Color("26")).Underline(true),
+		keyColors: []lipgloss.Color{
+			"23", "24", "29", "30", "53", "58", "60", "66", "89", "94", "96", "101",
+		},
 	}
 }
 
-// NewRenderer creates a new Renderer with the given config.
+// Solarized palette, see https://ethanschoonover.com/solarized/.
+const (
+	solBase03  = lipgloss.Color("#002b36")
+	solBase01  = lipgloss.Color("#586e75")
+	solBase00  = lipgloss.Color("#657b83")
+	solBase0   = lipgloss.Color("#839496")
+	solBase1   = lipgloss.Color("#93a1a1")
+	solYellow  = lipgloss.Color("#b58900")
+	solOrange  = lipgloss.Color("#cb4b16")
+	solRed     = lipgloss.Color("#dc322f")
+	solMagenta = lipgloss.Color("#d33682")
+	solBlue    = lipgloss.Color("#268bd2")
+	solCyan    = lipgloss.Color("#2aa198")
+	solViolet  = lipgloss.Color("#6c71c4")
+	solGreen   = lipgloss.Color("#859900")
+)
+
+func solarizedDarkStyles() themeStyles {
+	return themeStyles{
+		debug:     lipgloss.NewStyle().Foreground(solBase01),
+		info:      lipgloss.NewStyle().Foreground(solBlue),
+		warn:      lipgloss.NewStyle().Foreground(solYellow),
+		errLevel:  lipgloss.NewStyle().Foreground(solRed),
+		fatal:     lipgloss.NewStyle().Foreground(solMagenta).Bold(true),
+		timestamp: lipgloss.NewStyle().Foreground(solBase01),
+		message:   lipgloss.NewStyle().Foreground(solBase1),
+		fieldKey:  lipgloss.NewStyle().Foreground(solCyan),
+		fieldVal:  lipgloss.NewStyle().Foreground(solBase0),
+		separator: lipgloss.NewStyle().Foreground(solBase01),
+		number:    lipgloss.NewStyle().Foreground(solViolet),
+		boolean:   lipgloss.NewStyle().Foreground(solOrange),
+		duration:  lipgloss.NewStyle().Foreground(solGreen),
+		ip:        lipgloss.NewStyle().Foreground(solYellow),
+		url:       lipgloss.NewStyle().Foreground(solBlue).Underline(true),
+		keyColors: []lipgloss.Color{solCyan, solViolet, solGreen, solOrange},
+	}
+}
+
+func solarizedLightStyles() themeStyles {
+	return themeStyles{
+		debug:     lipgloss.NewStyle().Foreground(solBase1),
+		info:      lipgloss.NewStyle().Foreground(solBlue),
+		warn:      lipgloss.NewStyle().Foreground(solOrange),
+		errLevel:  lipgloss.NewStyle().Foreground(solRed),
+		fatal:     lipgloss.NewStyle().Foreground(solMagenta).Bold(true),
+		timestamp: lipgloss.NewStyle().Foreground(solBase1),
+		message:   lipgloss.NewStyle().Foreground(solBase03),
+		fieldKey:  lipgloss.NewStyle().Foreground(solCyan),
+		fieldVal:  lipgloss.NewStyle().Foreground(solBase00),
+		separator: lipgloss.NewStyle().Foreground(solBase1),
+		number:    lipgloss.NewStyle().Foreground(solViolet),
+		boolean:   lipgloss.NewStyle().Foreground(solOrange),
+		duration:  lipgloss.NewStyle().Foreground(solGreen),
+		ip:        lipgloss.NewStyle().Foreground(solYellow),
+		url:       lipgloss.NewStyle().Foreground(solBlue).Underline(true),
+		keyColors: []lipgloss.Color{solCyan, solViolet, solGreen, solYellow},
+	}
+}
+
+// highContrastStyles sticks to the 16 basic ANSI colors. Levels differ in
+// hue, weight and, for fatal, inverse video, so they stay distinguishable
+// without relying on red/green perception.
+func highContrastStyles() themeStyles {
+	return themeStyles{
+		debug:     lipgloss.NewStyle().Foreground(lipgloss.Color("7")),                           // white
+		info:      lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true),               // bright cyan
+		warn:      lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true),               // bright yellow
+		errLevel:  lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true),               // bright magenta
+		fatal:     lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true).Reverse(true), // inverse white
+		timestamp: lipgloss.NewStyle().Foreground(lipgloss.Color("7")),
+		message:   lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true),
+		fieldKey:  lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+		fieldVal:  lipgloss.NewStyle().Foreground(lipgloss.Color("15")),
+		separator: lipgloss.NewStyle().Foreground(lipgloss.Color("7")),
+		number:    lipgloss.NewStyle().Foreground(lipgloss.Color("12")), // bright blue
+		boolean:   lipgloss.NewStyle().Foreground(lipgloss.Color("11")), // bright yellow
+		duration:  lipgloss.NewStyle().Foreground(lipgloss.Color("10")), // bright green
+		ip:        lipgloss.NewStyle().Foreground(lipgloss.Color("13")), // bright magenta
+		url:       lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Underline(true),
+		// Bright green and blue, cyan and green: none is a level color.
+		keyColors: []lipgloss.Color{"10", "12", "6", "2"},
+	}
+}
+
+// stylesForTheme returns the styles for a theme. Unknown themes fall back to
+// the dark theme.
+func stylesForTheme(t Theme) themeStyles {
+	switch t {
+	case ThemeLight:
+		return lightStyles()
+	case ThemeSolarizedDark:
+		return solarizedDarkStyles()
+	case ThemeSolarizedLight:
+		return solarizedLightStyles()
+	case ThemeHighContrast:
+		return highContrastStyles()
+	default:
+		return darkStyles()
+	}
+}
+
+// errNoBackground reports that the terminal did not tell its background.
+var errNoBackground = errors.New("terminal background color unknown")
+
+// detectDarkBackground reports whether the terminal on stdout has a dark
+// background. It is a variable so tests can stub it.
+var detectDarkBackground = func() (bool, error) {
+	out := termenv.NewOutput(os.Stdout, termenv.WithColorCache(true))
+	if _, ok := out.BackgroundColor().(termenv.NoColor); ok {
+		return false, errNoBackground
+	}
+	return out.HasDarkBackground(), nil
+}
+
+// resolveTheme turns ThemeAuto into ThemeDark or ThemeLight. Other themes
+// are returned unchanged.
+func resolveTheme(t Theme) Theme {
+	if t != ThemeAuto {
+		return t
+	}
+	dark, err := detectDarkBackground()
+	if err != nil || dark {
+		return ThemeDark
+	}
+	return ThemeLight
+}
+
+// NewRenderer creates a new Renderer with the given config. ThemeAuto is
+// resolved here, so Config reports the theme actually used.
 func NewRenderer(config RenderConfig) *Renderer {
+	config.Theme = resolveTheme(config.Theme)
 	if config.Now == nil {
 		config.Now = time.Now
 	}
 	if config.TerminalWidth <= 0 {
 		config.TerminalWidth = 120
 	}
-	var styles themeStyles
-	if config.Theme == ThemeLight {
-		styles = lightStyles()
-	} else {
-		styles = darkStyles()
+	return &Renderer{config: config.clone(), styles: stylesForTheme(config.Theme), table: &columnSampler{}}
+}
+
+// clone returns a copy of c that shares no slices or maps with it, so the
+// caller changing its configuration later cannot race with rendering.
+func (c RenderConfig) clone() RenderConfig {
+	c.FieldOrder = append([]string(nil), c.FieldOrder...)
+	c.IncludeFields = append([]string(nil), c.IncludeFields...)
+	c.ExcludeFields = append([]string(nil), c.ExcludeFields...)
+	c.Redact.Fields = append([]string(nil), c.Redact.Fields...)
+	c.Redact.Patterns = append([]*regexp.Regexp(nil), c.Redact.Patterns...)
+	if c.FieldKeyStyles != nil {
+		styles := make(map[string]lipgloss.Style, len(c.FieldKeyStyles))
+		for k, v := range c.FieldKeyStyles {
+			styles[k] = v
+		}
+		c.FieldKeyStyles = styles
 	}
-	return &Renderer{config: config, styles: styles}
+	return c
+}
+
+// Config returns a copy of the renderer's configuration.
+func (r *Renderer) Config() RenderConfig {
+	return r.config.clone()
+}
+
+// With returns a new Renderer whose configuration is r's with fn applied.
+// r itself is not modified, so it stays safe to use from other goroutines.
+func (r *Renderer) With(fn func(*RenderConfig)) *Renderer {
+	cfg := r.config
+	fn(&cfg)
+	return NewRenderer(cfg)
 }
 
 // ansiRegex matches ANSI escape sequences.
@@ -148,21 +472,65 @@ func StripANSI(s string) string {
 
 // RenderEntry renders a single LogEntry as a styled string.
 func (r *Renderer) RenderEntry(entry parser.LogEntry) string {
-	var parts []string
-
-	// Level badge
-	levelStr := r.renderLevel(entry.Level)
-	if levelStr != "" {
-		parts = append(parts, levelStr)
+	entry = r.SelectFields(r.Redact(entry))
+	if r.config.Layout == LayoutTable {
+		return r.applyWrap(r.gutter(entry, true) + r.renderTable(entry, true))
 	}
+	return r.applyWrap(r.gutter(entry, true) + r.entryParts(entry).join(r.styles.separator.Render(r.separator())))
+}
 
-	// Timestamp
+// EntryParts are the styled components of an inline-rendered entry, for
+// callers laying them out themselves. A component the entry lacks is empty.
+type EntryParts struct {
+	Level     string // level badge
+	Timestamp string
+	Message   string // first line of the message, with any folded-lines indicator
+	Fields    string // key=value pairs; empty unless ShowAllFields is set
+}
+
+// RenderEntryParts renders entry's components separately, redacted and
+// with fields selected as by RenderEntry. The layout and wrapping settings
+// do not apply to them.
+func (r *Renderer) RenderEntryParts(entry parser.LogEntry) EntryParts {
+	return r.entryParts(r.SelectFields(r.Redact(entry)))
+}
+
+// entryParts renders the components of an already redacted entry.
+func (r *Renderer) entryParts(entry parser.LogEntry) EntryParts {
+	var p EntryParts
+	p.Level = r.renderLevel(entry.Level)
 	if !entry.Timestamp.IsZero() {
-		ts := r.renderTimestamp(entry.Timestamp)
-		parts = append(parts, ts)
+		p.Timestamp = r.renderTimestamp(entry.Timestamp)
+	}
+	if msg, more := r.message(entry); msg != "" {
+		p.Message = r.styles.message.Render(msg)
+		if more != "" {
+			p.Message += r.styles.separator.Render(more)
+		}
+	}
+	if r.config.ShowAllFields && len(entry.Fields) > 0 {
+		p.Fields = r.renderFields(entry.Fields)
 	}
+	return p
+}
 
-	// Message
+// join joins the non-empty components with sep.
+func (p EntryParts) join(sep string) string {
+	var parts []string
+	for _, s := range []string{p.Level, p.Timestamp, p.Message, p.Fields} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// RenderMessageOnly returns the entry's message, or its input line if it
+// has none, without any decoration: no level, timestamp, fields or styling.
+// It is redacted, and ANSI escape codes and control characters are handled
+// as for RenderEntry, but a multi-line message is returned in full.
+func (r *Renderer) RenderMessageOnly(entry parser.LogEntry) string {
+	entry = r.Redact(entry)
 	msg := entry.Message
 	if msg == "" {
 		msg = entry.Raw
@@ -170,36 +538,72 @@ func (r *Renderer) RenderEntry(entry parser.LogEntry) string {
 	if r.config.ANSIMode == ANSIStrip {
 		msg = StripANSI(msg)
 	}
-	if msg != "" {
-		parts = append(parts, r.styles.message.Render(msg))
-	}
-
-	// Fields
-	if r.config.ShowAllFields && len(entry.Fields) > 0 {
-		fieldStr := r.renderFields(entry.Fields)
-		if fieldStr != "" {
-			parts = append(parts, fieldStr)
-		}
-	}
-
-	line := strings.Join(parts, r.styles.separator.Render(" │ "))
-
-	// Truncate or wrap
-	line = r.applyWrap(line)
-
-	return line
+	return strings.TrimRight(r.sanitize(msg), "\n")
 }
 
 // RenderEntryPlain renders without styling (for piping/testing visible text).
 func (r *Renderer) RenderEntryPlain(entry parser.LogEntry) string {
+	entry = r.SelectFields(r.Redact(entry))
+	if r.config.Layout == LayoutTable {
+		return r.gutter(entry, false) + r.renderTable(entry, false)
+	}
 	var parts []string
 
 	if entry.Level != "" {
-		parts = append(parts, strings.ToUpper(normalizeLevel(entry.Level)))
+		parts = append(parts, r.levelLabel(normalizeLevel(entry.Level)))
 	}
 	if !entry.Timestamp.IsZero() {
 		parts = append(parts, r.formatTimestamp(entry.Timestamp))
 	}
+	msg, more := r.message(entry)
+	if msg != "" {
+		parts = append(parts, msg+more)
+	}
+	if r.config.ShowAllFields && len(entry.Fields) > 0 {
+		parts = append(parts, r.renderFieldsPlain(entry.Fields))
+	}
+	return r.gutter(entry, false) + strings.Join(parts, r.separator())
+}
+
+// gutter returns the gutters in front of entry: the stream's, then the
+// schema issues'.
+func (r *Renderer) gutter(entry parser.LogEntry, styled bool) string {
+	return r.streamGutter(entry, styled) + r.issueGutter(entry, styled)
+}
+
+// streamGutter returns StderrGutter if entry was written to standard
+// error, a blank if not, and nothing unless MarkStderr is set.
+func (r *Renderer) streamGutter(entry parser.LogEntry, styled bool) string {
+	switch {
+	case !r.config.MarkStderr:
+		return ""
+	case entry.Stream != "stderr":
+		return strings.Repeat(" ", ansi.StringWidth(StderrGutter))
+	case styled:
+		return r.styles.errLevel.Render(StderrGutter)
+	default:
+		return StderrGutter
+	}
+}
+
+// issueGutter returns the gutter in front of entry: IssueGutter if it has
+// schema issues, a blank if not, and nothing unless MarkIssues is set.
+func (r *Renderer) issueGutter(entry parser.LogEntry, styled bool) string {
+	switch {
+	case !r.config.MarkIssues:
+		return ""
+	case len(entry.Issues) == 0:
+		return strings.Repeat(" ", len(IssueGutter))
+	case styled:
+		return r.styles.errLevel.Bold(true).Render(IssueGutter)
+	default:
+		return IssueGutter
+	}
+}
+
+// message returns the first line of entry's message as displayed, falling
+// back to the raw line, and the indicator of any lines folded away.
+func (r *Renderer) message(entry parser.LogEntry) (string, string) {
 	msg := entry.Message
 	if msg == "" {
 		msg = entry.Raw
@@ -207,13 +611,46 @@ func (r *Renderer) RenderEntryPlain(entry parser.LogEntry) string {
 	if r.config.ANSIMode == ANSIStrip {
 		msg = StripANSI(msg)
 	}
-	if msg != "" {
-		parts = append(parts, msg)
+	return firstLine(r.display(msg), entry.LineCount)
+}
+
+// separator returns the separator between the parts of an entry.
+func (r *Renderer) separator() string {
+	sep := r.config.Separator
+	if sep == "" {
+		sep = DefaultSeparator
 	}
-	if r.config.ShowAllFields && len(entry.Fields) > 0 {
-		parts = append(parts, r.renderFieldsPlain(entry.Fields))
+	if r.config.CompactMode {
+		if sep = strings.TrimSpace(sep); sep == "" {
+			sep = " "
+		}
+	}
+	return sep
+}
+
+// levelLabel returns the level as shown: upper case, or only its first
+// letter in compact mode.
+func (r *Renderer) levelLabel(norm string) string {
+	label := r.sanitize(strings.ToUpper(norm))
+	if r.config.CompactMode && label != "" {
+		_, size := utf8.DecodeRuneInString(label)
+		return label[:size]
 	}
-	return strings.Join(parts, " │ ")
+	return label
+}
+
+// firstLine returns the first line of a joined multi-line message, plus an
+// indicator such as " ⏎ +12 lines" telling how many lines were folded away.
+// Single-line messages are returned unchanged with an empty indicator.
+func firstLine(msg string, lineCount int) (string, string) {
+	i := strings.IndexByte(msg, '\n')
+	if i < 0 {
+		return msg, ""
+	}
+	if lineCount < 2 {
+		lineCount = parser.CountLines(msg)
+	}
+	return strings.TrimRight(msg[:i], "\r"), fmt.Sprintf(" ⏎ +%d lines", lineCount-1)
 }
 
 // CollapsedFieldCount returns how many extra fields would be hidden.
@@ -222,24 +659,32 @@ func CollapsedFieldCount(entry parser.LogEntry) int {
 }
 
 func (r *Renderer) renderLevel(level string) string {
+	if level == "" {
+		return ""
+	}
 	norm := normalizeLevel(level)
-	label := fmt.Sprintf("%-5s", strings.ToUpper(norm))
+	label := r.levelLabel(norm)
+	if !r.config.CompactMode {
+		label = fmt.Sprintf("%-5s", label)
+	}
+	return r.levelStyle(norm).Render(label)
+}
+
+// levelStyle returns the style of a normalized level.
+func (r *Renderer) levelStyle(norm string) lipgloss.Style {
 	switch norm {
 	case "debug":
-		return r.styles.debug.Render(label)
+		return r.styles.debug
 	case "info":
-		return r.styles.info.Render(label)
+		return r.styles.info
 	case "warn", "warning":
-		return r.styles.warn.Render(label)
+		return r.styles.warn
 	case "error":
-		return r.styles.errLevel.Render(label)
+		return r.styles.errLevel
 	case "fatal", "panic", "critical":
-		return r.styles.fatal.Render(label)
+		return r.styles.fatal
 	default:
-		if level == "" {
-			return ""
-		}
-		return r.styles.message.Render(label)
+		return r.styles.message
 	}
 }
 
@@ -260,9 +705,13 @@ func (r *Renderer) renderTimestamp(t time.Time) string {
 }
 
 func (r *Renderer) formatTimestamp(t time.Time) string {
+	t = r.DisplayTime(t)
+	if r.config.CompactMode {
+		return shortRelativeTime(t, r.config.Now(), r.relativeUnits(), r.justNow())
+	}
 	switch r.config.TimestampFormat {
 	case TimestampRelative:
-		return relativeTime(t, r.config.Now())
+		return relativeTime(t, r.config.Now(), r.relativeUnits(), r.justNow())
 	case TimestampISO:
 		return t.Format(time.RFC3339)
 	case TimestampLocal:
@@ -272,33 +721,91 @@ func (r *Renderer) formatTimestamp(t time.Time) string {
 	}
 }
 
-func relativeTime(t time.Time, now time.Time) string {
-	d := now.Sub(t)
-	if d < 0 {
-		d = -d
-		return formatDuration(d) + " from now"
+// DisplayTime returns t in RenderConfig.DisplayLocation, if set.
+func (r *Renderer) DisplayTime(t time.Time) time.Time {
+	if r.config.DisplayLocation == nil {
+		return t
+	}
+	return t.In(r.config.DisplayLocation)
+}
+
+// relativeUnits returns RenderConfig.RelativeTimePrecision, or its default.
+func (r *Renderer) relativeUnits() int {
+	return max(r.config.RelativeTimePrecision, 1)
+}
+
+// justNow returns RenderConfig.JustNowThreshold, or its default.
+func (r *Renderer) justNow() time.Duration {
+	if r.config.JustNowThreshold <= 0 {
+		return time.Second
 	}
-	if d < time.Second {
+	return r.config.JustNowThreshold
+}
+
+// RelativeTime describes t relative to now: "5m ago", "2h from now" or
+// "just now" within a second.
+func RelativeTime(t time.Time, now time.Time) string {
+	return relativeTime(t, now, 1, time.Second)
+}
+
+// relativeTime is RelativeTime showing units units of the duration, and
+// "just now" within justNow of now.
+func relativeTime(t, now time.Time, units int, justNow time.Duration) string {
+	d := now.Sub(t)
+	switch {
+	case d.Abs() < justNow:
 		return "just now"
+	case d < 0:
+		return formatDuration(-d, units) + " from now"
 	}
-	return formatDuration(d) + " ago"
+	return formatDuration(d, units) + " ago"
 }
 
-func formatDuration(d time.Duration) string {
+// shortRelativeTime is relativeTime without the words: "5m" for five
+// minutes ago, "+5m" for five minutes ahead and "now" within justNow.
+func shortRelativeTime(t, now time.Time, units int, justNow time.Duration) string {
+	d := now.Sub(t)
 	switch {
-	case d < time.Minute:
-		s := int(d.Seconds())
-		return fmt.Sprintf("%ds", s)
-	case d < time.Hour:
-		m := int(d.Minutes())
-		return fmt.Sprintf("%dm", m)
-	case d < 24*time.Hour:
-		h := int(d.Hours())
-		return fmt.Sprintf("%dh", h)
-	default:
-		days := int(d.Hours() / 24)
-		return fmt.Sprintf("%dd", days)
+	case d.Abs() < justNow:
+		return "now"
+	case d < 0:
+		return "+" + formatDuration(-d, units)
 	}
+	return formatDuration(d, units)
+}
+
+// durationUnits are the units formatDuration writes, largest first.
+var durationUnits = []struct {
+	size time.Duration
+	name string
+}{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+}
+
+// formatDuration writes d in up to units consecutive units, starting with
+// the largest that fits and rounding down: "2h" or, with two units, "2h5m".
+// Units that come out zero are left out, so 2h0m is "2h".
+func formatDuration(d time.Duration, units int) string {
+	first := len(durationUnits) - 1
+	for i, u := range durationUnits {
+		if d >= u.size {
+			first = i
+			break
+		}
+	}
+	var b strings.Builder
+	for i := first; i < len(durationUnits) && i < first+max(units, 1); i++ {
+		u := durationUnits[i]
+		n := d / u.size
+		d -= n * u.size
+		if n > 0 || i == first {
+			fmt.Fprintf(&b, "%d%s", n, u.name)
+		}
+	}
+	return b.String()
 }
 
 func (r *Renderer) renderFields(fields map[string]string) string {
@@ -306,7 +813,7 @@ func (r *Renderer) renderFields(fields map[string]string) string {
 	var parts []string
 	for _, k := range ordered {
 		v := fields[k]
-		part := r.styles.fieldKey.Render(k) + r.styles.separator.Render("=") + r.styles.fieldVal.Render(v)
+		part := r.keyStyle(k).Render(r.sanitize(k)) + r.styles.separator.Render("=") + r.valueStyle(k, v).Render(truncateValue(r.display(v), r.config.MaxFieldValueLen))
 		parts = append(parts, part)
 	}
 	return strings.Join(parts, " ")
@@ -316,11 +823,61 @@ func (r *Renderer) renderFieldsPlain(fields map[string]string) string {
 	ordered := r.orderedFieldKeys(fields)
 	var parts []string
 	for _, k := range ordered {
-		parts = append(parts, k+"="+fields[k])
+		parts = append(parts, r.sanitize(k)+"="+truncateValue(r.display(fields[k]), r.config.MaxFieldValueLen))
 	}
 	return strings.Join(parts, " ")
 }
 
+// truncateValue shortens v to limit runes, the last of them an ellipsis. A
+// limit of zero or less leaves v unchanged.
+func truncateValue(v string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(v) <= limit {
+		return v
+	}
+	n := 0
+	for i := range v {
+		if n == limit-1 {
+			return v[:i] + "…"
+		}
+		n++
+	}
+	return v
+}
+
+// SelectFields returns entry with only the fields IncludeFields and
+// ExcludeFields let through. entry itself is not modified.
+func (r *Renderer) SelectFields(entry parser.LogEntry) parser.LogEntry {
+	if len(r.config.IncludeFields) == 0 && len(r.config.ExcludeFields) == 0 {
+		return entry
+	}
+	fields := make(map[string]string, len(entry.Fields))
+	for k, v := range entry.Fields {
+		if r.fieldShown(k) {
+			fields[k] = v
+		}
+	}
+	entry.Fields = fields
+	return entry
+}
+
+// fieldShown reports whether field k passes IncludeFields and ExcludeFields.
+func (r *Renderer) fieldShown(k string) bool {
+	for _, x := range r.config.ExcludeFields {
+		if x == k {
+			return false
+		}
+	}
+	if len(r.config.IncludeFields) == 0 {
+		return true
+	}
+	for _, in := range r.config.IncludeFields {
+		if in == k {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Renderer) orderedFieldKeys(fields map[string]string) []string {
 	if len(r.config.FieldOrder) > 0 {
 		var result []string
@@ -361,41 +918,26 @@ func sortStrings(s []string) {
 
 func (r *Renderer) applyWrap(line string) string {
 	if r.config.WrapMode == WrapTruncate && r.config.TerminalWidth > 0 {
-		// Strip ANSI to measure visible length, but truncate the raw string
-		visible := StripANSI(line)
-		if len(visible) > r.config.TerminalWidth {
-			// Truncate by visible chars. Rough approach: walk raw string.
-			return truncateToWidth(line, r.config.TerminalWidth-1) + "…"
-		}
+		return truncateToWidth(line, r.config.TerminalWidth)
 	}
-	// WrapWrap: lipgloss handles wrapping naturally, just return as-is
+	// WrapWrap: the line is returned whole; the TUI model splits it into
+	// display rows.
 	return line
 }
 
-// truncateToWidth truncates a string with ANSI codes to fit a visible width.
+// truncateToWidth shortens s to width display columns, ending it with "…",
+// if it is wider than that. Width is measured per grapheme cluster, so wide
+// CJK characters and emoji count as two columns and combining marks as none;
+// none of them are ever split. ANSI escape sequences are kept intact. When a
+// wide character does not fit in the last column, the result is padded with
+// a space so it is exactly width columns wide.
 func truncateToWidth(s string, width int) string {
-	visible := 0
-	inEscape := false
-	var result []byte
-	for i := 0; i < len(s); i++ {
-		b := s[i]
-		if b == '\x1b' {
-			inEscape = true
-			result = append(result, b)
-			continue
-		}
-		if inEscape {
-			result = append(result, b)
-			if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') {
-				inEscape = false
-			}
-			continue
-		}
-		if visible >= width {
-			break
-		}
-		result = append(result, b)
-		visible++
+	if width <= 0 || ansi.StringWidth(s) <= width {
+		return s
+	}
+	t := ansi.Truncate(s, width, "…")
+	if pad := width - ansi.StringWidth(t); pad > 0 {
+		t += strings.Repeat(" ", pad)
 	}
-	return string(result)
+	return t
 }
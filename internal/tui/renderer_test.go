@@ -1,10 +1,14 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/clarabennettdev/logpilot/internal/parser"
 )
 
@@ -78,6 +82,40 @@ func TestRenderTimestamp_ISO(t *testing.T) {
 	}
 }
 
+func TestRenderTimestamp_DisplayLocation(t *testing.T) {
+	// The same instant, logged in Tokyo and in New York.
+	tokyo := time.Date(2026, 2, 17, 19, 0, 0, 0, time.FixedZone("", 9*3600))
+	newYork := time.Date(2026, 2, 17, 5, 0, 0, 0, time.FixedZone("", -5*3600))
+	berlin := time.FixedZone("CET", 3600)
+	tests := []struct {
+		format TimestampFormat
+		loc    *time.Location
+		want   string
+	}{
+		{TimestampISO, time.UTC, "2026-02-17T10:00:00Z"},
+		{TimestampISO, berlin, "2026-02-17T11:00:00+01:00"},
+		{TimestampLocal, time.UTC, "10:00:00"},
+		{TimestampLocal, berlin, "11:00:00"},
+	}
+	for _, tt := range tests {
+		r := plainRenderer(func(c *RenderConfig) {
+			c.TimestampFormat = tt.format
+			c.DisplayLocation = tt.loc
+		})
+		for _, ts := range []time.Time{tokyo, newYork} {
+			if out := r.RenderEntryPlain(parser.LogEntry{Timestamp: ts, Message: "x"}); !strings.Contains(out, tt.want) {
+				t.Errorf("%v in %v: expected %q in %q", ts, tt.loc, tt.want, out)
+			}
+		}
+	}
+
+	// Without a display zone, each keeps its own.
+	r := plainRenderer(func(c *RenderConfig) { c.TimestampFormat = TimestampLocal })
+	if out := r.RenderEntryPlain(parser.LogEntry{Timestamp: tokyo, Message: "x"}); !strings.Contains(out, "19:00:00") {
+		t.Errorf("expected the logged zone in %q", out)
+	}
+}
+
 func TestRenderTimestamp_Local(t *testing.T) {
 	r := plainRenderer(func(c *RenderConfig) { c.TimestampFormat = TimestampLocal })
 	ts := time.Date(2026, 2, 17, 15, 30, 45, 0, time.UTC)
@@ -88,6 +126,256 @@ func TestRenderTimestamp_Local(t *testing.T) {
 	}
 }
 
+func TestRenderEntry_Separator(t *testing.T) {
+	entry := parser.LogEntry{Level: "info", Timestamp: fixedNow, Message: "hello", Fields: map[string]string{"k": "v"}}
+	r := plainRenderer(func(c *RenderConfig) {
+		c.Separator = " :: "
+		c.ShowAllFields = true
+	})
+	if got, want := r.RenderEntryPlain(entry), "INFO :: 20:00:00 :: hello :: k=v"; got != want {
+		t.Errorf("plain = %q, want %q", got, want)
+	}
+	styled := StripANSI(r.RenderEntry(entry))
+	if !strings.Contains(styled, " :: 20:00:00 :: hello :: ") || strings.Contains(styled, "│") {
+		t.Errorf("styled output should use the custom separator: %q", styled)
+	}
+}
+
+func TestRenderEntryParts(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.ExcludeFields = []string{"pid"}
+	})
+	entry := parser.LogEntry{
+		Level:     "warn",
+		Timestamp: fixedNow,
+		Message:   "disk low\nsda1 at 95%",
+		LineCount: 2,
+		Fields:    map[string]string{"dev": "sda", "pid": "42"},
+	}
+	p := r.RenderEntryParts(entry)
+	for _, c := range []struct{ name, got, want string }{
+		{"Level", p.Level, "WARN "},
+		{"Timestamp", p.Timestamp, "20:00:00"},
+		{"Message", p.Message, "disk low ⏎ +1 lines"},
+		{"Fields", p.Fields, "dev=sda"},
+	} {
+		if got := StripANSI(c.got); got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, got, c.want)
+		}
+	}
+	if want := strings.Join([]string{p.Level, p.Timestamp, p.Message, p.Fields}, r.styles.separator.Render(r.separator())); r.RenderEntry(entry) != want {
+		t.Errorf("RenderEntry = %q, want the parts joined: %q", r.RenderEntry(entry), want)
+	}
+
+	// Missing components are blank.
+	p = plainRenderer().RenderEntryParts(parser.LogEntry{Message: "bare", Fields: map[string]string{"k": "v"}})
+	if p.Level != "" || p.Timestamp != "" || p.Fields != "" || StripANSI(p.Message) != "bare" {
+		t.Errorf("got %+v, want only the message", p)
+	}
+}
+
+func TestRenderMessageOnly(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.SanitizeControl = true
+		c.Redact = RedactConfig{Fields: []string{"token"}, Mode: RedactMask}
+	})
+	tests := []struct {
+		entry parser.LogEntry
+		want  string
+	}{
+		{parser.LogEntry{Level: "warn", Timestamp: fixedNow, Message: "disk low", Fields: map[string]string{"dev": "sda"}}, "disk low"},
+		{parser.LogEntry{Message: "trace:\n\tat main.go:1\n", LineCount: 2}, "trace:\n\tat main.go:1"},
+		{parser.LogEntry{Message: "status \x1b[32mOK\x1b[0m\a"}, "status OK^G"},
+		{parser.LogEntry{Raw: "unparsed line"}, "unparsed line"},
+		{parser.LogEntry{Message: "login token=abc", Fields: map[string]string{"token": "abc"}}, "login token=***"},
+	}
+	for _, tt := range tests {
+		if got := r.RenderMessageOnly(tt.entry); got != tt.want {
+			t.Errorf("RenderMessageOnly(%q) = %q, want %q", tt.entry.Message, got, tt.want)
+		}
+	}
+}
+
+func TestRenderEntry_Compact(t *testing.T) {
+	entry := parser.LogEntry{Level: "warn", Timestamp: fixedNow.Add(-5 * time.Minute), Message: "disk low", Fields: map[string]string{"dev": "sda"}}
+	normal := plainRenderer(func(c *RenderConfig) { c.ShowAllFields = true })
+	compact := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.CompactMode = true
+	})
+
+	if got, want := compact.RenderEntryPlain(entry), "W│5m│disk low│dev=sda"; got != want {
+		t.Errorf("compact plain = %q, want %q", got, want)
+	}
+	for name, render := range map[string]func(*Renderer, parser.LogEntry) string{
+		"plain":  (*Renderer).RenderEntryPlain,
+		"styled": (*Renderer).RenderEntry,
+	} {
+		n := utf8.RuneCountInString(StripANSI(render(normal, entry)))
+		c := utf8.RuneCountInString(StripANSI(render(compact, entry)))
+		if c >= n {
+			t.Errorf("%s: compact line is %d runes, normal %d; want shorter", name, c, n)
+		}
+	}
+	if got := StripANSI(compact.RenderEntry(entry)); !strings.HasPrefix(got, "W│5m│") {
+		t.Errorf("compact styled = %q", got)
+	}
+
+	// A custom separator loses its padding too; a blank one becomes a space.
+	r := plainRenderer(func(c *RenderConfig) {
+		c.CompactMode = true
+		c.Separator = " | "
+	})
+	if got := r.RenderEntryPlain(parser.LogEntry{Level: "error", Message: "x"}); got != "E|x" {
+		t.Errorf("compact with custom separator = %q, want E|x", got)
+	}
+	r = plainRenderer(func(c *RenderConfig) {
+		c.CompactMode = true
+		c.Separator = "   "
+	})
+	if got := r.RenderEntryPlain(parser.LogEntry{Level: "error", Message: "x"}); got != "E x" {
+		t.Errorf("compact with blank separator = %q, want \"E x\"", got)
+	}
+}
+
+func TestShortRelativeTime(t *testing.T) {
+	for _, tt := range []struct {
+		offset time.Duration
+		want   string
+	}{
+		{0, "now"},
+		{500 * time.Millisecond, "now"},
+		{30 * time.Second, "30s"},
+		{3 * time.Hour, "3h"},
+		{-2 * time.Minute, "+2m"},
+	} {
+		if got := shortRelativeTime(fixedNow.Add(-tt.offset), fixedNow, 1, time.Second); got != tt.want {
+			t.Errorf("offset %v: got %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTimestamp_RelativePrecision(t *testing.T) {
+	tests := []struct {
+		offset       time.Duration
+		single, pair string
+	}{
+		{59 * time.Second, "59s ago", "59s ago"},
+		{90 * time.Second, "1m ago", "1m30s ago"},
+		{61 * time.Minute, "1h ago", "1h1m ago"},
+		{25 * time.Hour, "1d ago", "1d1h ago"},
+		{2 * time.Hour, "2h ago", "2h ago"},
+		{-90 * time.Second, "1m from now", "1m30s from now"},
+		{-25 * time.Hour, "1d from now", "1d1h from now"},
+	}
+	for _, units := range []int{0, 1, 2} {
+		r := plainRenderer(func(c *RenderConfig) {
+			c.TimestampFormat = TimestampRelative
+			c.RelativeTimePrecision = units
+		})
+		for _, tt := range tests {
+			want := tt.single
+			if units == 2 {
+				want = tt.pair
+			}
+			out := r.RenderEntryPlain(parser.LogEntry{Timestamp: fixedNow.Add(-tt.offset), Message: "test"})
+			if !strings.Contains(out, want) {
+				t.Errorf("precision %d, offset %v: expected %q in %q", units, tt.offset, want, out)
+			}
+		}
+	}
+}
+
+func TestRenderTimestamp_JustNowThreshold(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.TimestampFormat = TimestampRelative
+		c.JustNowThreshold = 10 * time.Second
+	})
+	for _, tt := range []struct {
+		offset time.Duration
+		want   string
+	}{
+		{9 * time.Second, "just now"},
+		{-9 * time.Second, "just now"},
+		{10 * time.Second, "10s ago"},
+		{-10 * time.Second, "10s from now"},
+	} {
+		out := r.RenderEntryPlain(parser.LogEntry{Timestamp: fixedNow.Add(-tt.offset), Message: "test"})
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("offset %v: expected %q in %q", tt.offset, tt.want, out)
+		}
+	}
+}
+
+func TestShortRelativeTime_TwoUnits(t *testing.T) {
+	for _, tt := range []struct {
+		offset time.Duration
+		want   string
+	}{
+		{59 * time.Second, "59s"},
+		{90 * time.Second, "1m30s"},
+		{61 * time.Minute, "1h1m"},
+		{25 * time.Hour, "1d1h"},
+		{-61 * time.Minute, "+1h1m"},
+		{3 * time.Second, "now"},
+	} {
+		if got := shortRelativeTime(fixedNow.Add(-tt.offset), fixedNow, 2, 5*time.Second); got != tt.want {
+			t.Errorf("offset %v: got %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestRenderFields_Include(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.IncludeFields = []string{"status", "method", "missing"}
+	})
+	entry := parser.LogEntry{
+		Message: "request handled",
+		Fields:  map[string]string{"method": "GET", "path": "/api", "status": "200", "pid": "42"},
+	}
+	for name, out := range map[string]string{
+		"plain":  r.RenderEntryPlain(entry),
+		"styled": StripANSI(r.RenderEntry(entry)),
+	} {
+		if !strings.HasSuffix(out, "method=GET status=200") {
+			t.Errorf("%s: %q, want only method and status", name, out)
+		}
+	}
+	if len(entry.Fields) != 4 {
+		t.Errorf("entry lost fields: %v", entry.Fields)
+	}
+}
+
+func TestRenderFields_Exclude(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.IncludeFields = []string{"method", "pid"}
+		c.ExcludeFields = []string{"pid", "caller"}
+	})
+	entry := parser.LogEntry{
+		Message: "request handled",
+		Fields:  map[string]string{"method": "GET", "pid": "42", "caller": "main.go:12"},
+	}
+	if out := r.RenderEntryPlain(entry); !strings.HasSuffix(out, " method=GET") {
+		t.Errorf("include and exclude: %q, want only method", out)
+	}
+
+	r = plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.ExcludeFields = []string{"pid", "caller"}
+	})
+	entry.Fields["path"] = "/api"
+	out := r.RenderEntryPlain(entry)
+	if strings.Contains(out, "pid=") || strings.Contains(out, "caller=") {
+		t.Errorf("excluded fields shown: %q", out)
+	}
+	if !strings.Contains(out, "method=GET path=/api") {
+		t.Errorf("other fields missing: %q", out)
+	}
+}
+
 func TestRenderFields_ShowAll(t *testing.T) {
 	r := plainRenderer(func(c *RenderConfig) { c.ShowAllFields = true })
 	entry := parser.LogEntry{
@@ -194,6 +482,48 @@ func TestTruncation(t *testing.T) {
 	}
 }
 
+func TestTruncateToWidth_DisplayWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		width int
+		want  string
+	}{
+		{"ascii", "abcdefgh", 5, "abcd…"},
+		{"fits", "abc", 3, "abc"},
+		{"cjk", "日本語のログ行", 7, "日本語…"},
+		{"cjk split padded", "日本語のログ行", 8, "日本語… "},
+		{"emoji", "🔥🔥🔥🔥 hot", 6, "🔥🔥… "},
+		{"combining", "café au lait", 5, "café…"},
+		{"ansi kept", "\x1b[31mred text\x1b[0m", 4, "\x1b[31mred…\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateToWidth(tt.in, tt.width)
+			if got != tt.want {
+				t.Errorf("truncateToWidth(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+			}
+			if w := lipgloss.Width(got); tt.in != got && w != tt.width {
+				t.Errorf("width = %d, want exactly %d", w, tt.width)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("result %q splits a rune", got)
+			}
+		})
+	}
+}
+
+func TestTruncation_WideCharacters(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.TerminalWidth = 20
+		c.WrapMode = WrapTruncate
+	})
+	out := StripANSI(r.RenderEntry(parser.LogEntry{Message: "接続がタイムアウトしました。再試行します"}))
+	if w := lipgloss.Width(out); w != 20 {
+		t.Errorf("truncated line is %d columns wide, want 20: %q", w, out)
+	}
+}
+
 func TestWrapMode(t *testing.T) {
 	r := plainRenderer(func(c *RenderConfig) {
 		c.TerminalWidth = 30
@@ -225,6 +555,91 @@ func TestLightTheme(t *testing.T) {
 	}
 }
 
+func TestThemes_DistinctLevelStyles(t *testing.T) {
+	themes := []Theme{ThemeDark, ThemeLight, ThemeSolarizedDark, ThemeSolarizedLight, ThemeHighContrast}
+	for _, theme := range themes {
+		st := stylesForTheme(theme)
+		levels := map[string]lipgloss.Style{
+			"debug": st.debug, "info": st.info, "warn": st.warn, "error": st.errLevel, "fatal": st.fatal,
+		}
+		seen := make(map[string]string)
+		for name, style := range levels {
+			if _, none := style.GetForeground().(lipgloss.NoColor); none {
+				t.Errorf("theme %d: %s has no color", theme, name)
+			}
+			key := fmt.Sprintf("%v/%v/%v", style.GetForeground(), style.GetBold(), style.GetReverse())
+			if other, dup := seen[key]; dup {
+				t.Errorf("theme %d: %s and %s are styled identically (%s)", theme, name, other, key)
+			}
+			seen[key] = name
+		}
+
+		r := NewRenderer(RenderConfig{Theme: theme, TerminalWidth: 200, Now: fixedTime})
+		for _, level := range []string{"debug", "info", "warn", "error", "fatal"} {
+			if out := r.RenderEntry(parser.LogEntry{Level: level, Message: "m"}); !strings.Contains(out, strings.ToUpper(level)) {
+				t.Errorf("theme %d: %s not rendered: %q", theme, level, out)
+			}
+		}
+	}
+}
+
+func TestThemes_UnknownFallsBackToDark(t *testing.T) {
+	got, want := stylesForTheme(Theme(99)), darkStyles()
+	if got.info.GetForeground() != want.info.GetForeground() || got.errLevel.GetForeground() != want.errLevel.GetForeground() {
+		t.Error("unknown theme should use dark styles")
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	for name, want := range map[string]Theme{
+		"dark": ThemeDark, "Light": ThemeLight, "solarized-dark": ThemeSolarizedDark,
+		"solarized-light": ThemeSolarizedLight, "high-contrast": ThemeHighContrast,
+		"auto": ThemeAuto,
+	} {
+		if got, err := ParseTheme(name); err != nil || got != want {
+			t.Errorf("ParseTheme(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseTheme("neon"); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+// stubBackground makes terminal background detection return dark and err
+// for the rest of the test.
+func stubBackground(t *testing.T, dark bool, err error) {
+	t.Helper()
+	orig := detectDarkBackground
+	detectDarkBackground = func() (bool, error) { return dark, err }
+	t.Cleanup(func() { detectDarkBackground = orig })
+}
+
+func TestThemeAuto_Resolves(t *testing.T) {
+	stubBackground(t, false, nil)
+	if got := NewRenderer(RenderConfig{Theme: ThemeAuto}).Config().Theme; got != ThemeLight {
+		t.Errorf("light background: theme = %v, want ThemeLight", got)
+	}
+	stubBackground(t, true, nil)
+	if got := NewRenderer(RenderConfig{Theme: ThemeAuto}).Config().Theme; got != ThemeDark {
+		t.Errorf("dark background: theme = %v, want ThemeDark", got)
+	}
+	// Explicit themes are not touched.
+	if got := NewRenderer(RenderConfig{Theme: ThemeSolarizedLight}).Config().Theme; got != ThemeSolarizedLight {
+		t.Errorf("explicit theme = %v, want ThemeSolarizedLight", got)
+	}
+}
+
+func TestThemeAuto_FallsBackToDark(t *testing.T) {
+	stubBackground(t, false, errNoBackground)
+	r := NewRenderer(RenderConfig{Theme: ThemeAuto})
+	if got := r.Config().Theme; got != ThemeDark {
+		t.Errorf("theme = %v, want ThemeDark when detection fails", got)
+	}
+	if r.styles.info.GetForeground() != darkStyles().info.GetForeground() {
+		t.Error("fallback should use dark styles")
+	}
+}
+
 func TestRenderEntry_EmptyEntry(t *testing.T) {
 	r := plainRenderer()
 	entry := parser.LogEntry{}
@@ -263,6 +678,30 @@ func TestRenderEntry_FullIntegration(t *testing.T) {
 	}
 }
 
+func TestMultiLineIndicator(t *testing.T) {
+	r := plainRenderer()
+	joined := parser.LogEntry{
+		Level:     "error",
+		Message:   "boom\n\tat a.b(c.go:1)\n\tat d.e(f.go:2)",
+		LineCount: 3,
+	}
+	got := r.RenderEntryPlain(joined)
+	if !strings.Contains(got, "boom ⏎ +2 lines") {
+		t.Errorf("expected multi-line indicator, got %q", got)
+	}
+	if strings.Contains(got, "\n") || strings.Contains(got, "c.go") {
+		t.Errorf("list view should show only the first line, got %q", got)
+	}
+	if styled := StripANSI(r.RenderEntry(joined)); !strings.Contains(styled, "⏎ +2 lines") {
+		t.Errorf("styled render missing indicator: %q", styled)
+	}
+
+	single := parser.LogEntry{Level: "info", Message: "all good", LineCount: 1}
+	if got := r.RenderEntryPlain(single); strings.Contains(got, "⏎") {
+		t.Errorf("single-line entry should have no indicator, got %q", got)
+	}
+}
+
 func TestRelativeTime_Future(t *testing.T) {
 	r := plainRenderer(func(c *RenderConfig) { c.TimestampFormat = TimestampRelative })
 	future := fixedNow.Add(5 * time.Minute)
@@ -288,3 +727,286 @@ func TestDefaultConfig(t *testing.T) {
 		t.Error("default should collapse fields")
 	}
 }
+
+func TestRenderFields_ValueStyles(t *testing.T) {
+	for _, theme := range []Theme{ThemeDark, ThemeLight, ThemeSolarizedDark, ThemeSolarizedLight, ThemeHighContrast} {
+		r := NewRenderer(RenderConfig{Theme: theme, TerminalWidth: 200, Now: fixedTime})
+		plain := r.valueStyle("user", "alice").GetForeground()
+		num := r.valueStyle("latency_ms", "250").GetForeground()
+		ip := r.valueStyle("client", "192.168.1.10").GetForeground()
+		if num == plain {
+			t.Errorf("theme %d: number styled like a string (%v)", theme, num)
+		}
+		if ip == plain {
+			t.Errorf("theme %d: IP styled like a string (%v)", theme, ip)
+		}
+		if ip == num {
+			t.Errorf("theme %d: IP styled like a number (%v)", theme, ip)
+		}
+	}
+}
+
+func TestRenderFields_FieldKeyStyles(t *testing.T) {
+	override := lipgloss.NewStyle().Foreground(lipgloss.Color("201"))
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.FieldKeyStyles = map[string]lipgloss.Style{"status": override}
+	})
+	if got := r.valueStyle("status", "200").GetForeground(); got != override.GetForeground() {
+		t.Errorf("override not applied: got %v", got)
+	}
+	if got := r.valueStyle("code", "200").GetForeground(); got != r.styles.number.GetForeground() {
+		t.Errorf("other keys should keep the type style, got %v", got)
+	}
+
+	out := r.RenderEntry(parser.LogEntry{Level: "info", Message: "m", Fields: map[string]string{"status": "200"}})
+	if !strings.Contains(out, "status=200") {
+		t.Errorf("field missing from output: %q", out)
+	}
+	plainOut := r.RenderEntryPlain(parser.LogEntry{Level: "info", Message: "m", Fields: map[string]string{"status": "200"}})
+	if !strings.Contains(plainOut, "status=200") {
+		t.Errorf("plain output changed: %q", plainOut)
+	}
+}
+
+func TestRenderFields_ColorKeysByName(t *testing.T) {
+	keys := []string{"host", "user", "request_id", "status", "path", "method", "latency_ms", "trace_id"}
+	for _, theme := range []Theme{ThemeDark, ThemeLight, ThemeSolarizedDark, ThemeSolarizedLight, ThemeHighContrast} {
+		r := NewRenderer(RenderConfig{Theme: theme, ColorKeysByName: true, TerminalWidth: 200, Now: fixedTime})
+		levels := map[lipgloss.TerminalColor]bool{}
+		for _, level := range []string{"debug", "info", "warn", "error", "fatal"} {
+			levels[r.levelStyle(level).GetForeground()] = true
+		}
+		colors := map[lipgloss.TerminalColor]bool{}
+		for _, k := range keys {
+			c := r.keyStyle(k).GetForeground()
+			if again := NewRenderer(r.Config()).keyStyle(k).GetForeground(); again != c {
+				t.Errorf("theme %v: key %q colored %v, then %v", theme, k, c, again)
+			}
+			if levels[c] {
+				t.Errorf("theme %v: key %q has a level color (%v)", theme, k, c)
+			}
+			colors[c] = true
+		}
+		if len(colors) < 3 {
+			t.Errorf("theme %v: %d keys share %d colors", theme, len(keys), len(colors))
+		}
+	}
+
+	// Same key, same styling on every line; different keys differ.
+	withColors(t)
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.ColorKeysByName = true
+	})
+	first := r.RenderEntry(parser.LogEntry{Message: "a", Fields: map[string]string{"host": "web-1"}})
+	second := r.RenderEntry(parser.LogEntry{Message: "b", Fields: map[string]string{"host": "web-2", "user": "ann"}})
+	host := r.keyStyle("host").Render("host")
+	if !strings.Contains(first, host) || !strings.Contains(second, host) {
+		t.Errorf("host key styled differently:\n%q\n%q", first, second)
+	}
+	if r.keyStyle("host").GetForeground() == r.keyStyle("user").GetForeground() {
+		t.Error("host and user should get different colors")
+	}
+
+	// Off, every key gets the theme's key style.
+	r = plainRenderer()
+	if r.keyStyle("host").GetForeground() != r.styles.fieldKey.GetForeground() {
+		t.Error("without ColorKeysByName keys should use the field key style")
+	}
+}
+
+func TestTruncateValue(t *testing.T) {
+	tests := []struct {
+		v     string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"SELECT * FROM users", 10, "SELECT * …"},
+		{"héllo wörld", 5, "héll…"},
+		{"日本語のテキスト", 4, "日本語…"},
+		{"anything", 0, "anything"},
+		{"ab", 1, "…"},
+	}
+	for _, tt := range tests {
+		got := truncateValue(tt.v, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncateValue(%q, %d) = %q, want %q", tt.v, tt.limit, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateValue(%q, %d) split a rune: %q", tt.v, tt.limit, got)
+		}
+	}
+}
+
+func TestRenderFields_MaxFieldValueLen(t *testing.T) {
+	blob := strings.Repeat("QUJD", 50)
+	entry := parser.LogEntry{
+		Level:   "info",
+		Message: "upload",
+		Fields:  map[string]string{"payload": blob, "user": "alice"},
+	}
+	r := plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.MaxFieldValueLen = 12
+	})
+
+	for name, out := range map[string]string{"styled": StripANSI(r.RenderEntry(entry)), "plain": r.RenderEntryPlain(entry)} {
+		if strings.Contains(out, blob) {
+			t.Errorf("%s: long value not shortened: %q", name, out)
+		}
+		if !strings.Contains(out, "payload="+blob[:11]+"…") || !strings.Contains(out, "user=alice") {
+			t.Errorf("%s: unexpected fields: %q", name, out)
+		}
+	}
+
+	m := setupModel(500, 40, 1)
+	m.renderer = r
+	m.entries = []parser.LogEntry{entry}
+	m.showDetail = true
+	if pane := m.renderDetailPane(); !strings.Contains(pane, blob) {
+		t.Errorf("detail pane should show the full value:\n%s", pane)
+	}
+}
+
+func TestRenderEntry_MarkIssues(t *testing.T) {
+	bad := parser.LogEntry{Level: "info", Message: "bad", Issues: []string{`missing required field "user"`}}
+	good := parser.LogEntry{Level: "info", Message: "good"}
+
+	r := plainRenderer(func(c *RenderConfig) { c.MarkIssues = true })
+	if got := r.RenderEntryPlain(bad); !strings.HasPrefix(got, "! INF") {
+		t.Errorf("entry with issues = %q, want the ! gutter", got)
+	}
+	if got := r.RenderEntryPlain(good); !strings.HasPrefix(got, "  INF") {
+		t.Errorf("valid entry = %q, want a blank gutter", got)
+	}
+	if got := StripANSI(r.RenderEntry(bad)); !strings.HasPrefix(got, "! ") {
+		t.Errorf("styled entry with issues = %q, want the ! gutter", got)
+	}
+
+	if got := plainRenderer().RenderEntryPlain(bad); strings.HasPrefix(got, "!") {
+		t.Errorf("without MarkIssues = %q, want no gutter", got)
+	}
+}
+
+func TestRenderEntry_MarkStderr(t *testing.T) {
+	withColors(t)
+	stderr := parser.LogEntry{Level: "info", Message: "retrying", Stream: "stderr"}
+	stdout := parser.LogEntry{Level: "info", Message: "retrying", Stream: "stdout"}
+	unleveled := parser.LogEntry{Message: "panic: oops", Stream: "stderr"}
+
+	r := plainRenderer(func(c *RenderConfig) { c.MarkStderr = true })
+	if got := r.RenderEntryPlain(stderr); !strings.HasPrefix(got, StderrGutter+"INF") {
+		t.Errorf("stderr entry = %q, want the stderr gutter", got)
+	}
+	if got := r.RenderEntryPlain(stdout); !strings.HasPrefix(got, "  INF") {
+		t.Errorf("stdout entry = %q, want a blank gutter", got)
+	}
+	if got := r.RenderEntryPlain(unleveled); !strings.HasPrefix(got, StderrGutter+"panic") {
+		t.Errorf("stderr entry without a level = %q, want the stderr gutter", got)
+	}
+
+	// The gutter comes in the error color, and the rest of the line,
+	// level badge included, is styled as it would be on stdout.
+	styledErr, styledOut := r.RenderEntry(stderr), r.RenderEntry(stdout)
+	gutter := r.styles.errLevel.Render(StderrGutter)
+	if !strings.HasPrefix(styledErr, gutter) || gutter == StderrGutter {
+		t.Errorf("styled stderr entry = %q, want it to start with %q", styledErr, gutter)
+	}
+	if strings.TrimPrefix(styledErr, gutter) != strings.TrimPrefix(styledOut, "  ") {
+		t.Errorf("stderr marking changed the entry's styling:\n%q\n%q", styledErr, styledOut)
+	}
+
+	// Issue gutters follow the stream's, and both are off by default.
+	stderr.Issues = []string{"missing"}
+	if got := plainRenderer(func(c *RenderConfig) { c.MarkStderr, c.MarkIssues = true, true }).RenderEntryPlain(stderr); !strings.HasPrefix(got, StderrGutter+IssueGutter+"INF") {
+		t.Errorf("stderr entry with issues = %q", got)
+	}
+	if got := plainRenderer().RenderEntryPlain(unleveled); got != "panic: oops" {
+		t.Errorf("without MarkStderr = %q, want no gutter", got)
+	}
+}
+
+// TestRenderer_ConcurrentUse renders from many goroutines through one
+// Renderer; run it with -race. Every goroutine must see the output a
+// single goroutine does.
+func TestRenderer_ConcurrentUse(t *testing.T) {
+	entries := []parser.LogEntry{
+		{Timestamp: fixedNow.Add(-time.Minute), Level: "error", Message: "login failed for ann@example.com",
+			Fields: map[string]string{"user": "ann", "password": "hunter2", "status": "401", "ip": "10.0.0.1"}},
+		{Level: "info", Message: "tabs\tand\x1b[31mcolor\x1b[0m", Fields: map[string]string{"latency": "1.5", "ok": "true"}},
+		{Level: "warn", Message: "multi\nline", Issues: []string{"missing"}, Fields: map[string]string{"long": strings.Repeat("x", 50)}},
+	}
+	cfg := DefaultConfig()
+	cfg.Now = fixedTime
+	cfg.TimestampFormat = TimestampRelative
+	cfg.ShowAllFields = true
+	cfg.MaxFieldValueLen = 20
+	cfg.MarkIssues = true
+	cfg.FieldOrder = []string{"user", "status"}
+	cfg.FieldKeyStyles = map[string]lipgloss.Style{"status": lipgloss.NewStyle().Bold(true)}
+	cfg.Redact = DefaultRedactConfig()
+	cfg.Redact.Mode = RedactHash
+
+	for _, layout := range []LayoutMode{LayoutInline, LayoutTable} {
+		cfg.Layout = layout
+		r := NewRenderer(cfg)
+		// Table column widths follow the entries rendered so far, so let
+		// them settle before taking the expected output.
+		r.sampleTable(entries)
+		var want []string
+		for _, e := range entries {
+			want = append(want, r.RenderEntry(e), r.RenderEntryPlain(e))
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan string, 64)
+		for g := 0; g < 16; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					for j, e := range entries {
+						if got := r.RenderEntry(e); got != want[2*j] {
+							errs <- fmt.Sprintf("%v: RenderEntry = %q, want %q", layout, got, want[2*j])
+							return
+						}
+						if got := r.RenderEntryPlain(e); got != want[2*j+1] {
+							errs <- fmt.Sprintf("%v: RenderEntryPlain = %q, want %q", layout, got, want[2*j+1])
+							return
+						}
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+	}
+}
+
+func TestNewRenderer_CopiesConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Now = fixedTime
+	cfg.ShowAllFields = true
+	cfg.ExcludeFields = []string{"secret"}
+	cfg.FieldKeyStyles = map[string]lipgloss.Style{}
+	r := NewRenderer(cfg)
+	entry := parser.LogEntry{Message: "m", Fields: map[string]string{"secret": "s", "user": "ann"}}
+	want := r.RenderEntryPlain(entry)
+
+	// Changing the caller's configuration must not reach the renderer.
+	cfg.ExcludeFields[0] = "user"
+	cfg.FieldKeyStyles["user"] = lipgloss.NewStyle().Bold(true)
+	if got := r.RenderEntryPlain(entry); got != want {
+		t.Errorf("after changing the config: %q, want %q", got, want)
+	}
+	r.Config().ExcludeFields[0] = "user"
+	if got := r.RenderEntryPlain(entry); got != want {
+		t.Errorf("after changing Config(): %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+// setRenderer switches to r. Lines are rendered when shown, so render
+// settings changed at runtime apply to lines already received.
+func (m *Model) setRenderer(r *Renderer) {
+	m.renderer = r
+	if r.config.Layout == LayoutTable {
+		r.sampleTable(m.entries[max(len(m.entries)-tableSampleSize, 0):])
+	}
+}
+
+// toggleANSI flips between stripping and passing through ANSI escape codes
+// found in log messages.
+func (m *Model) toggleANSI() {
+	if m.renderer == nil {
+		return
+	}
+	m.setRenderer(m.renderer.With(func(c *RenderConfig) {
+		if c.ANSIMode == ANSIStrip {
+			c.ANSIMode = ANSIPassthrough
+		} else {
+			c.ANSIMode = ANSIStrip
+		}
+	}))
+}
+
+// toggleLayout switches between the inline and the table layout.
+func (m *Model) toggleLayout() {
+	if m.renderer == nil {
+		return
+	}
+	m.setRenderer(m.renderer.With(func(c *RenderConfig) {
+		if c.Layout == LayoutTable {
+			c.Layout = LayoutInline
+		} else {
+			c.Layout = LayoutTable
+		}
+	}))
+}
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestToggleANSI_RerendersBuffer(t *testing.T) {
+	r := plainRenderer()
+	m := setupModel(120, 24, 0, WithRenderer(r))
+
+	colored := parser.LogEntry{Message: "status \x1b[32mOK\x1b[0m"}
+	updated, _ := m.Update(LogMsg{Rendered: r.RenderEntry(colored), Entry: colored})
+	m = updated.(Model)
+
+	if strings.Contains(m.lineText(0), "\x1b[32m") {
+		t.Fatal("default mode should strip embedded escape codes")
+	}
+	if !contains(m.View(), "ANSI:") || !contains(m.View(), "strip") {
+		t.Error("status bar should show the ANSI mode")
+	}
+
+	m = press(m, "a")
+	if !strings.Contains(m.lineText(0), "\x1b[32mOK") {
+		t.Errorf("passthrough should keep escape codes, got %q", m.lineText(0))
+	}
+	if !contains(m.View(), "keep") {
+		t.Error("status bar should show passthrough mode")
+	}
+
+	// Lines arriving after the toggle use the new mode too, even if the
+	// source pre-rendered them with the original renderer.
+	updated, _ = m.Update(LogMsg{Rendered: r.RenderEntry(colored), Entry: colored})
+	m = updated.(Model)
+	if !strings.Contains(m.lineText(1), "\x1b[32mOK") {
+		t.Errorf("new line should be rendered in passthrough mode, got %q", m.lineText(1))
+	}
+
+	m = press(m, "a")
+	for i := range m.entries {
+		if line := m.lineText(i); strings.Contains(line, "\x1b[32m") {
+			t.Errorf("line %d still has escape codes after toggling back: %q", i, line)
+		}
+	}
+	if r.Config().ANSIMode != ANSIStrip {
+		t.Error("toggling must not modify the shared renderer")
+	}
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// ansiPrefix matches an ANSI escape sequence at the start of a string.
+var ansiPrefix = regexp.MustCompile(`^\x1b\[[0-9;]*[a-zA-Z]`)
+
+// SanitizeControl makes s safe to print on a terminal. Control characters
+// are replaced with visible escapes: caret notation such as "^C" or "^[" for
+// C0 controls and DEL ("^?"), "\x00" for NUL and "\u0085" style escapes for
+// C1 controls. Invalid UTF-8 becomes U+FFFD. Tabs, newlines and the "\r" of
+// a "\r\n" are kept, as the renderer lays those out itself. With keepANSI,
+// ANSI escape sequences are kept as well.
+func SanitizeControl(s string, keepANSI bool) string {
+	if isPrintable(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c == 0x1b && keepANSI {
+			if loc := ansiPrefix.FindStringIndex(s[i:]); loc != nil {
+				b.WriteString(s[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(utf8.RuneError)
+		case r == '\t' || r == '\n':
+			b.WriteRune(r)
+		case r == '\r' && i+1 < len(s) && s[i+1] == '\n':
+			b.WriteRune(r)
+		case r == 0:
+			b.WriteString(`\x00`)
+		case r < 0x20:
+			b.WriteByte('^')
+			b.WriteByte(byte(r) + '@')
+		case r == 0x7f:
+			b.WriteString("^?")
+		case r >= 0x80 && r < 0xa0:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// isPrintable reports whether s is valid UTF-8 without control characters
+// other than tabs and newlines, so SanitizeControl would leave it unchanged.
+func isPrintable(s string) bool {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 0x80:
+			ascii = false
+		case c == 0x7f, c < 0x20 && c != '\t' && c != '\n':
+			return false
+		}
+	}
+	if ascii {
+		return true
+	}
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r >= 0x80 && r < 0xa0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitize applies SanitizeControl when the configuration asks for it. ANSI
+// sequences are kept only in ANSIPassthrough mode.
+func (r *Renderer) sanitize(s string) string {
+	if !r.config.SanitizeControl {
+		return s
+	}
+	return SanitizeControl(s, r.config.ANSIMode == ANSIPassthrough)
+}
+
+// Sanitize returns a copy of entry with control characters and invalid
+// UTF-8 in the message, the raw line and every field made safe to print,
+// when RenderConfig.SanitizeControl is set.
+func (r *Renderer) Sanitize(entry parser.LogEntry) parser.LogEntry {
+	if !r.config.SanitizeControl {
+		return entry
+	}
+	entry.Message = r.sanitize(entry.Message)
+	entry.Raw = r.sanitize(entry.Raw)
+	if len(entry.Fields) > 0 {
+		fields := make(map[string]string, len(entry.Fields))
+		for k, v := range entry.Fields {
+			fields[r.sanitize(k)] = r.sanitize(v)
+		}
+		entry.Fields = fields
+	}
+	return entry
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestSanitizeControl(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		keepANSI       bool
+	}{
+		{"clean", "hello wörld\tok", "hello wörld\tok", false},
+		{"nul", "a\x00b", `a\x00b`, false},
+		{"bell", "ding\x07", "ding^G", false},
+		{"ctrl-c and del", "\x03x\x7f", "^Cx^?", false},
+		{"lone cr", "progress\r50%", "progress^M50%", false},
+		{"crlf kept", "one\r\ntwo", "one\r\ntwo", false},
+		{"c1", "a\u0085b", `a\u0085b`, false},
+		{"invalid utf8", "bad\xff\xfebytes", "bad��bytes", false},
+		{"ansi escaped", "\x1b[31mred\x1b[0m", "^[[31mred^[[0m", false},
+		{"ansi kept", "\x1b[31mred\x1b[0m", "\x1b[31mred\x1b[0m", true},
+		{"bare esc escaped", "\x1b]0;title\x07", "^[]0;title^G", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeControl(tt.in, tt.keepANSI); got != tt.want {
+				t.Errorf("SanitizeControl(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// terminalSafe reports whether s, with ANSI styling removed, holds nothing
+// but valid UTF-8 free of control characters.
+func terminalSafe(s string) bool {
+	s = StripANSI(s)
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRenderEntry_SanitizesControl(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) { c.ShowAllFields = true })
+	e := parser.LogEntry{
+		Level:   "error",
+		Message: "boom\x00\x07 \xc3\x28",
+		Fields:  map[string]string{"k\x1b": "v\x01"},
+	}
+	for name, out := range map[string]string{"styled": r.RenderEntry(e), "plain": r.RenderEntryPlain(e)} {
+		if !terminalSafe(out) {
+			t.Errorf("%s output is not terminal-safe: %q", name, out)
+		}
+		for _, want := range []string{`boom\x00^G`, "�", "k^[", "v^A"} {
+			if !strings.Contains(StripANSI(out), want) {
+				t.Errorf("%s output %q lacks %q", name, out, want)
+			}
+		}
+	}
+
+	off := plainRenderer(func(c *RenderConfig) { c.SanitizeControl = false })
+	if out := off.RenderEntryPlain(e); !strings.Contains(out, "\x00\x07") {
+		t.Errorf("with SanitizeControl off, output %q should keep the raw bytes", out)
+	}
+}
+
+func TestRenderEntry_SanitizePassthroughKeepsANSI(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) { c.ANSIMode = ANSIPassthrough })
+	out := r.RenderEntryPlain(parser.LogEntry{Message: "\x1b[32mgreen\x1b[0m\x00"})
+	if !strings.Contains(out, "\x1b[32mgreen\x1b[0m") || !strings.Contains(out, `\x00`) {
+		t.Errorf("output = %q, want the ANSI sequences kept and NUL escaped", out)
+	}
+}
+
+func TestDetailPane_Sanitized(t *testing.T) {
+	m := setupModel(80, 30, 1)
+	m.renderer = plainRenderer()
+	m.autoScroll = false
+	m.cursor = 0
+	m.entries[0] = parser.LogEntry{Message: "bell\x07", Fields: map[string]string{"nul": "a\x00b"}}
+	m = press(m, "enter")
+	view := m.View()
+	if !contains(view, "bell^G") || !contains(view, `a\x00b`) {
+		t.Errorf("detail pane should show escaped control characters:\n%s", view)
+	}
+	if strings.ContainsAny(view, "\x00\x07") {
+		t.Error("view contains raw control characters")
+	}
+}
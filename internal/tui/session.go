@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultSessionGap is the silence after which a new session is assumed.
+const DefaultSessionGap = 30 * time.Minute
+
+var sessionStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#7D56F4")).
+	Bold(true)
+
+// WithSessionGap sets the inter-entry gap that marks a session boundary.
+// Zero disables session detection.
+func WithSessionGap(d time.Duration) ModelOption {
+	return func(m *Model) { m.sessionGap = d }
+}
+
+// sessionBoundary reports whether entry i starts a new session, i.e. the
+// time since the previous entry exceeds the session gap. Entries without a
+// timestamp never form a boundary.
+func (m Model) sessionBoundary(i int) (time.Duration, bool) {
+	if m.sessionGap <= 0 || i <= 0 || i >= len(m.entries) {
+		return 0, false
+	}
+	prev, cur := m.entries[i-1].Timestamp, m.entries[i].Timestamp
+	if prev.IsZero() || cur.IsZero() {
+		return 0, false
+	}
+	gap := cur.Sub(prev)
+	if gap <= m.sessionGap {
+		return 0, false
+	}
+	return gap, true
+}
+
+// renderSessionSeparator renders the row shown above the first entry of a
+// new session.
+func (m Model) renderSessionSeparator(gap time.Duration) string {
+	label := fmt.Sprintf(" new session (gap %s) ", formatDuration(gap, 1))
+	fill := m.width - lipgloss.Width(label) - 6
+	if fill < 0 {
+		fill = 0
+	}
+	return sessionStyle.Render("═══" + label + "═══" + strings.Repeat("═", fill))
+}
+
+// nextSession moves the cursor to the start of the next session, if any.
+func (m *Model) nextSession() {
+	for pos := m.cursor + 1; pos < m.viewLen(); pos++ {
+		i := m.bufIndex(pos)
+		if _, ok := m.sessionBoundary(i); ok {
+			m.jumpTo(i)
+			return
+		}
+	}
+}
+
+// prevSession moves the cursor to the start of the previous session, or to
+// the top of the buffer when the cursor is already in the first session.
+func (m *Model) prevSession() {
+	for pos := m.cursor - 1; pos > 0; pos-- {
+		i := m.bufIndex(pos)
+		if _, ok := m.sessionBoundary(i); ok {
+			m.jumpTo(i)
+			return
+		}
+	}
+	if m.cursor > 0 {
+		m.jumpTo(0)
+	}
+}
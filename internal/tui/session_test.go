@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// sessionModel builds a model whose entries have the given offsets from a
+// fixed base time. A negative offset means "no timestamp".
+func sessionModel(offsets ...time.Duration) Model {
+	m := setupModel(100, 24, 0)
+	base := time.Date(2026, 2, 17, 8, 0, 0, 0, time.UTC)
+	for i, off := range offsets {
+		e := parser.LogEntry{Message: fmt.Sprintf("entry %d", i)}
+		if off >= 0 {
+			e.Timestamp = base.Add(off)
+		}
+		m.lines = append(m.lines, e.Message)
+		m.entries = append(m.entries, e)
+	}
+	m.autoScroll = false
+	return m
+}
+
+func press(m Model, key string) Model {
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model)
+}
+
+func TestSessionBoundaries(t *testing.T) {
+	m := sessionModel(0, time.Minute, 3*time.Hour, 3*time.Hour+time.Minute, -1, 6*time.Hour)
+
+	want := map[int]bool{2: true}
+	for i := range m.entries {
+		if _, ok := m.sessionBoundary(i); ok != want[i] {
+			t.Errorf("sessionBoundary(%d) = %v, want %v", i, ok, want[i])
+		}
+	}
+
+	v := m.View()
+	// 00:01 → 03:00 is a 2h59m gap, shown in the largest whole unit.
+	if !contains(v, "new session (gap 2h)") {
+		t.Errorf("expected a session separator in view:\n%s", v)
+	}
+	if contains(v, "gap 1m") {
+		t.Error("small gaps should not produce a session separator")
+	}
+}
+
+func TestSessionBoundaryDisabled(t *testing.T) {
+	m := sessionModel(0, 5*time.Hour)
+	m.sessionGap = 0
+	if _, ok := m.sessionBoundary(1); ok {
+		t.Error("session detection should be off when the gap is zero")
+	}
+}
+
+func TestSessionJump(t *testing.T) {
+	m := sessionModel(0, time.Minute, 2*time.Hour, 2*time.Hour+time.Second, 5*time.Hour, 5*time.Hour+time.Minute)
+	m.cursor = 0
+
+	for _, want := range []int{2, 4, 4} {
+		m = press(m, "]")
+		if m.cursor != want {
+			t.Fatalf("after ']': cursor = %d, want %d", m.cursor, want)
+		}
+	}
+	m.cursor = 5
+	for _, want := range []int{4, 2, 0} {
+		m = press(m, "[")
+		if m.cursor != want {
+			t.Fatalf("after '[': cursor = %d, want %d", m.cursor, want)
+		}
+	}
+}
+
+func TestSessionSeparatorKeepsCursorVisible(t *testing.T) {
+	// A full viewport of entries, each a new session, so separators double
+	// the row count; the cursor line must still be rendered.
+	offsets := make([]time.Duration, 30)
+	for i := range offsets {
+		offsets[i] = time.Duration(i) * time.Hour
+	}
+	m := sessionModel(offsets...)
+	m.cursor = 29
+	m.scrollToCursor()
+
+	if rows := m.viewportRows(m.logPaneHeight()); len(rows) != m.logPaneHeight() {
+		t.Errorf("viewport has %d rows, want %d", len(rows), m.logPaneHeight())
+	}
+	if !contains(m.View(), "entry 29") {
+		t.Error("cursor entry should remain visible despite separator rows")
+	}
+}
@@ -0,0 +1,233 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// statsMinutes is how many minutes of history the lines-per-minute tally covers.
+const statsMinutes = 5
+
+// statsLevelOrder is the display order of canonical levels in the stats table.
+var statsLevelOrder = []string{"FATAL", "ERROR", "WARN", "INFO", "DEBUG", "TRACE"}
+
+// noLevel is the bucket used for entries without a level.
+const noLevel = "-"
+
+// Stats holds aggregate counts over a set of log entries.
+type Stats struct {
+	Total    int
+	ByLevel  map[string]int // canonical upper-case level; entries without one count under "-"
+	BySource map[string]int // entries without a source count under "-"
+	// PerMinute holds line counts for the statsMinutes minutes ending at the
+	// newest timestamp, oldest first. Entries without timestamps are skipped.
+	PerMinute []int
+}
+
+// ComputeStats tallies entries by level, by source, and by minute.
+func ComputeStats(entries []parser.LogEntry) Stats {
+	st := Stats{
+		Total:     len(entries),
+		ByLevel:   make(map[string]int),
+		BySource:  make(map[string]int),
+		PerMinute: make([]int, statsMinutes),
+	}
+
+	var newest time.Time
+	for _, e := range entries {
+		level := strings.ToUpper(normalizeLevel(e.Level))
+		if level == "" {
+			level = noLevel
+		}
+		st.ByLevel[level]++
+
+		src := e.Source
+		if src == "" {
+			src = noLevel
+		}
+		st.BySource[src]++
+
+		if e.Timestamp.After(newest) {
+			newest = e.Timestamp
+		}
+	}
+
+	if newest.IsZero() {
+		return st
+	}
+	end := newest.Truncate(time.Minute)
+	for _, e := range entries {
+		if e.Timestamp.IsZero() {
+			continue
+		}
+		ago := int(end.Sub(e.Timestamp.Truncate(time.Minute)) / time.Minute)
+		if ago >= 0 && ago < statsMinutes {
+			st.PerMinute[statsMinutes-1-ago]++
+		}
+	}
+	return st
+}
+
+// levelKeys returns the levels present in st in display order, followed by
+// any non-standard levels alphabetically and finally the no-level bucket.
+func (st Stats) levelKeys() []string {
+	var keys []string
+	known := make(map[string]bool, len(statsLevelOrder))
+	for _, l := range statsLevelOrder {
+		known[l] = true
+		if st.ByLevel[l] > 0 {
+			keys = append(keys, l)
+		}
+	}
+	var other []string
+	for l := range st.ByLevel {
+		if !known[l] && l != noLevel {
+			other = append(other, l)
+		}
+	}
+	sortStrings(other)
+	keys = append(keys, other...)
+	if st.ByLevel[noLevel] > 0 {
+		keys = append(keys, noLevel)
+	}
+	return keys
+}
+
+// sourceKeys returns the sources present in st, sorted alphabetically.
+func (st Stats) sourceKeys() []string {
+	keys := make([]string, 0, len(st.BySource))
+	for s := range st.BySource {
+		keys = append(keys, s)
+	}
+	sortStrings(keys)
+	return keys
+}
+
+// renderStats renders st as a small aligned table, one string per row.
+func renderStats(st Stats) []string {
+	width := len("Level")
+	for _, k := range st.levelKeys() {
+		width = max(width, len(k))
+	}
+	for _, k := range st.sourceKeys() {
+		width = max(width, len(k))
+	}
+
+	row := func(label string, n int) string {
+		return fmt.Sprintf("  %-*s %8d", width, label, n)
+	}
+
+	rows := []string{
+		detailBorderStyle.Render(fmt.Sprintf("▼ Statistics (%d lines)", st.Total)),
+		"",
+		detailKeyStyle.Render(fmt.Sprintf("  %-*s %8s", width, "Level", "Count")),
+	}
+	for _, k := range st.levelKeys() {
+		rows = append(rows, row(k, st.ByLevel[k]))
+	}
+	rows = append(rows, "", detailKeyStyle.Render(fmt.Sprintf("  %-*s %8s", width, "Source", "Count")))
+	for _, k := range st.sourceKeys() {
+		rows = append(rows, row(k, st.BySource[k]))
+	}
+
+	counts := make([]string, len(st.PerMinute))
+	for i, n := range st.PerMinute {
+		counts[i] = fmt.Sprintf("%d", n)
+	}
+	rows = append(rows, "", fmt.Sprintf("  Lines/min (last %dm): %s", statsMinutes, strings.Join(counts, " ")))
+	return rows
+}
+
+// rateSeconds is how many seconds of arrivals the status bar sparkline
+// covers.
+const rateSeconds = 60
+
+// minSparkCells is the narrowest the sparkline is shown; with less room in
+// the status bar it is left out.
+const minSparkCells = 8
+
+// rateCounter counts the entries arriving in each of the last rateSeconds
+// seconds. The zero value is ready to use.
+type rateCounter struct {
+	counts [rateSeconds]int // by Unix second modulo rateSeconds
+	first  int64            // Unix second of the first arrival; 0 before any
+	last   int64            // Unix second of the latest arrival
+}
+
+// add counts n entries arriving at now.
+func (c *rateCounter) add(now time.Time, n int) {
+	sec := now.Unix()
+	if c.first == 0 {
+		c.first, c.last = sec, sec
+	}
+	if sec < c.last {
+		sec = c.last // the clock went back; count towards the latest second
+	}
+	for s := c.last + 1; s <= sec && s <= c.last+rateSeconds; s++ {
+		c.counts[s%rateSeconds] = 0
+	}
+	c.last = sec
+	c.counts[sec%rateSeconds] += n
+}
+
+// series returns the per-second counts up to now, oldest first. It covers
+// the seconds since the first arrival, at most rateSeconds of them.
+func (c rateCounter) series(now time.Time) []int {
+	if c.first == 0 {
+		return nil
+	}
+	end := max(now.Unix(), c.last)
+	start := max(c.first, end-rateSeconds+1)
+	out := make([]int, 0, end-start+1)
+	for s := start; s <= end; s++ {
+		if s > c.last || s <= c.last-rateSeconds {
+			out = append(out, 0)
+		} else {
+			out = append(out, c.counts[s%rateSeconds])
+		}
+	}
+	return out
+}
+
+// sparkBlocks are the bar heights of a sparkline, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws counts as a row of block characters scaled to the
+// largest count, which gets a full block. Any non-zero count shows at least
+// the lowest block; zero is a space.
+func sparkline(counts []int) string {
+	peak := 0
+	for _, n := range counts {
+		peak = max(peak, n)
+	}
+	var b strings.Builder
+	for _, n := range counts {
+		if n <= 0 {
+			b.WriteByte(' ')
+			continue
+		}
+		level := (n*len(sparkBlocks) + peak - 1) / peak // 1..len(sparkBlocks)
+		b.WriteRune(sparkBlocks[level-1])
+	}
+	return b.String()
+}
+
+// renderRate returns the status bar segment with the arrival sparkline and
+// the count of the current second, fitted into width cells. It is empty
+// before any entry arrived or when there is too little room.
+func (m Model) renderRate(width int) string {
+	counts := m.rate.series(m.now())
+	if len(counts) == 0 {
+		return ""
+	}
+	suffix := fmt.Sprintf(" %d/s", counts[len(counts)-1])
+	cells := min(len(counts), width-lipgloss.Width(statusItem("Rate:", suffix)))
+	if cells < min(len(counts), minSparkCells) {
+		return ""
+	}
+	return statusItem("Rate:", sparkline(counts[len(counts)-cells:])+suffix)
+}
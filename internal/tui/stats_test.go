@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func mixedEntries() []parser.LogEntry {
+	base := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+	return []parser.LogEntry{
+		{Level: "INFO", Source: "api.log", Timestamp: base.Add(-4 * time.Minute)},
+		{Level: "info", Source: "api.log", Timestamp: base.Add(-3 * time.Minute)},
+		{Level: "WARNING", Source: "db.log", Timestamp: base.Add(-3 * time.Minute)},
+		{Level: "error", Source: "api.log", Timestamp: base.Add(-1 * time.Minute)},
+		{Level: "critical", Source: "db.log", Timestamp: base},
+		{Level: "", Source: "stdin"},
+		{Level: "notice", Timestamp: base.Add(-10 * time.Minute)},
+	}
+}
+
+func TestComputeStats_Levels(t *testing.T) {
+	st := ComputeStats(mixedEntries())
+	if st.Total != 7 {
+		t.Errorf("Total = %d, want 7", st.Total)
+	}
+	want := map[string]int{"INFO": 2, "WARN": 1, "ERROR": 1, "FATAL": 1, "NOTICE": 1, "-": 1}
+	for level, n := range want {
+		if st.ByLevel[level] != n {
+			t.Errorf("ByLevel[%s] = %d, want %d", level, st.ByLevel[level], n)
+		}
+	}
+}
+
+func TestComputeStats_Sources(t *testing.T) {
+	st := ComputeStats(mixedEntries())
+	want := map[string]int{"api.log": 3, "db.log": 2, "stdin": 1, "-": 1}
+	for src, n := range want {
+		if st.BySource[src] != n {
+			t.Errorf("BySource[%s] = %d, want %d", src, st.BySource[src], n)
+		}
+	}
+}
+
+func TestComputeStats_PerMinute(t *testing.T) {
+	st := ComputeStats(mixedEntries())
+	// Newest is 20:00; buckets cover 19:56..20:00, oldest first. The 19:50
+	// entry is out of range and the untimestamped one is skipped.
+	want := []int{1, 2, 0, 1, 1}
+	if len(st.PerMinute) != len(want) {
+		t.Fatalf("PerMinute len = %d, want %d", len(st.PerMinute), len(want))
+	}
+	for i := range want {
+		if st.PerMinute[i] != want[i] {
+			t.Errorf("PerMinute = %v, want %v", st.PerMinute, want)
+			break
+		}
+	}
+}
+
+func TestComputeStats_Empty(t *testing.T) {
+	st := ComputeStats(nil)
+	if st.Total != 0 || len(st.ByLevel) != 0 || len(st.BySource) != 0 {
+		t.Errorf("unexpected stats for empty input: %+v", st)
+	}
+}
+
+func TestStatsOverlayToggle(t *testing.T) {
+	m := setupModel(80, 24, 0)
+	for _, e := range mixedEntries() {
+		updated, _ := m.Update(LogMsg{Rendered: e.Level, Entry: e})
+		m = updated.(Model)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(Model)
+	v := m.View()
+	if !contains(v, "Statistics (7 lines)") || !contains(v, "api.log") {
+		t.Errorf("stats overlay missing from view:\n%s", v)
+	}
+
+	// New lines while open are reflected immediately.
+	updated, _ = m.Update(LogMsg{Rendered: "x", Entry: parser.LogEntry{Level: "debug", Source: "new.log"}})
+	m = updated.(Model)
+	if v := m.View(); !contains(v, "Statistics (8 lines)") || !contains(v, "new.log") {
+		t.Error("stats overlay should update as lines arrive")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(Model)
+	if contains(m.View(), "Statistics") {
+		t.Error("second 's' should close the stats overlay")
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts []int
+		want   string
+	}{
+		{nil, ""},
+		{[]int{0, 0}, "  "},
+		{[]int{0, 1, 2, 4, 8}, " ▁▂▄█"},
+		{[]int{3, 3, 3}, "███"},
+		{[]int{1, 100}, "▁█"},
+		{[]int{5}, "█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.counts); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+
+	// Scaling depends only on the proportions.
+	if a, b := sparkline([]int{1, 3, 5, 7}), sparkline([]int{10, 30, 50, 70}); a != b {
+		t.Errorf("scaled counts drew %q and %q", a, b)
+	}
+}
+
+func TestRateCounter(t *testing.T) {
+	var c rateCounter
+	if c.series(time.Now()) != nil {
+		t.Error("no arrivals should give no series")
+	}
+	t0 := time.Date(2026, 2, 17, 20, 0, 0, 0, time.UTC)
+	c.add(t0, 3)
+	c.add(t0.Add(1500*time.Millisecond), 1)
+	c.add(t0.Add(5*time.Second), 2)
+	if got := c.series(t0.Add(6 * time.Second)); fmt.Sprint(got) != "[3 1 0 0 0 2 0]" {
+		t.Errorf("series = %v", got)
+	}
+
+	// Only the last rateSeconds seconds are kept.
+	later := t0.Add(100 * time.Second)
+	c.add(later, 4)
+	got := c.series(later)
+	if len(got) != rateSeconds || got[len(got)-1] != 4 || got[0] != 0 {
+		t.Errorf("series after a gap = %v", got)
+	}
+}
+
+func TestStatusBar_RateSparkline(t *testing.T) {
+	m := setupModel(120, 10, 0)
+	m.renderer = plainRenderer()
+	m = applyMsgs(m, []tea.Msg{
+		LogBatchMsg{Lines: []string{"a", "b", "c"}, Entries: make([]parser.LogEntry, 3)},
+		LogMsg{Rendered: "d"},
+	})
+	if v := StripANSI(m.View()); !contains(v, "Rate:") || !contains(v, "█ 4/s") {
+		t.Errorf("status bar should show the rate:\n%s", v)
+	}
+
+	m.width = 40
+	if v := StripANSI(m.View()); contains(v, "Rate:") {
+		t.Errorf("the sparkline should be left out when it does not fit:\n%s", v)
+	}
+}
@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+// Default batching limits for StreamLines. 16ms is roughly one frame at 60Hz.
+const (
+	DefaultBatchInterval = 16 * time.Millisecond
+	DefaultBatchSize     = 1000
+)
+
+// Sender delivers messages to a running Bubble Tea program. *tea.Program
+// satisfies it.
+type Sender interface {
+	Send(msg tea.Msg)
+}
+
+type streamConfig struct {
+	interval time.Duration
+	maxLines int
+	noPin    bool
+}
+
+// StreamOption configures StreamLines.
+type StreamOption func(*streamConfig)
+
+// WithBatchInterval sets how long lines are accumulated before a batch is sent.
+func WithBatchInterval(d time.Duration) StreamOption {
+	return func(c *streamConfig) { c.interval = d }
+}
+
+// WithBatchSize sets the maximum number of lines per batch; a full batch is
+// sent immediately without waiting for the interval to elapse.
+func WithBatchSize(n int) StreamOption {
+	return func(c *streamConfig) { c.maxLines = n }
+}
+
+// WithFormatPinning controls whether StreamLines detects the format of the
+// first batch of lines and, if they agree on one, parses everything with
+// it; see parser.AutoParser.Pin. It is on by default.
+func WithFormatPinning(on bool) StreamOption {
+	return func(c *streamConfig) { c.noPin = !on }
+}
+
+// minPinSample is the fewest lines format pinning decides on. With fewer,
+// per-line detection is kept.
+const minPinSample = 20
+
+// pinFormat returns p pinned to the format of lines if they have one
+// clearly dominant format.
+func pinFormat(p *parser.AutoParser, lines []source.LogEntry) *parser.AutoParser {
+	if len(lines) < minPinSample {
+		return p
+	}
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.Line
+	}
+	return p.PinDetected(texts, parser.DefaultDetectSample, parser.DefaultMinConfidence)
+}
+
+// StreamLines reads lines from src, parses them, and sends them to prog as
+// LogBatchMsg values. Entries are rendered with r for a model without a
+// renderer; pass nil for one made WithRenderer, which renders the lines it
+// shows itself. Lines arriving within the batch interval, up
+// to the batch size, are coalesced into one message so that bursts do not
+// flood the program's message loop. Source errors are forwarded as ErrMsg, a
+// reload marker as ResetMsg, and the end of the source as SourceDoneMsg.
+//
+// The first batch, typically a file's existing content, is held back until
+// its format is known: if it is homogeneous, the rest of the stream is
+// parsed with that format pinned (see WithFormatPinning).
+func StreamLines(src source.Source, p *parser.AutoParser, r *Renderer, prog Sender, opts ...StreamOption) {
+	cfg := streamConfig{interval: DefaultBatchInterval, maxLines: DefaultBatchSize}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.interval <= 0 {
+		cfg.interval = DefaultBatchInterval
+	}
+	if cfg.maxLines <= 0 {
+		cfg.maxLines = DefaultBatchSize
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.interval)
+		defer ticker.Stop()
+
+		var batch LogBatchMsg
+		add := func(line source.LogEntry) {
+			rendered, entries := processLine(line, p, r)
+			batch.Lines = append(batch.Lines, rendered...)
+			batch.Entries = append(batch.Entries, entries...)
+		}
+		flush := func() {
+			if len(batch.Entries) == 0 {
+				return
+			}
+			prog.Send(batch)
+			batch = LogBatchMsg{}
+		}
+
+		// Raw lines of the first batch, held back while pinning.
+		pinning := !cfg.noPin
+		var first []source.LogEntry
+		endPinning := func() {
+			if !pinning {
+				return
+			}
+			pinning = false
+			p = pinFormat(p, first)
+			for _, line := range first {
+				add(line)
+			}
+			first = nil
+		}
+
+		lines := src.Lines()
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					endPinning()
+					flush()
+					prog.Send(SourceDoneMsg{})
+					return
+				}
+				if line.Reset {
+					// Lines batched so far are superseded.
+					batch, first = LogBatchMsg{}, nil
+					prog.Send(ResetMsg{})
+					continue
+				}
+				if pinning {
+					first = append(first, line)
+					if len(first) >= cfg.maxLines {
+						endPinning()
+						flush()
+					}
+					continue
+				}
+				add(line)
+				if len(batch.Entries) >= cfg.maxLines {
+					flush()
+				}
+			case <-ticker.C:
+				if pinning && len(first) == 0 {
+					// Nothing to decide on yet.
+					continue
+				}
+				endPinning()
+				flush()
+			}
+		}
+	}()
+	go func() {
+		for err := range src.Errors() {
+			prog.Send(ErrMsg{Err: err})
+		}
+	}()
+}
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+// chanSource is a Source backed by channels the test writes to directly.
+type chanSource struct {
+	source.NoStats
+	lines chan source.LogEntry
+	errs  chan error
+}
+
+func newChanSource(buf int) *chanSource {
+	return &chanSource{lines: make(chan source.LogEntry, buf), errs: make(chan error, 1)}
+}
+
+func (s *chanSource) Lines() <-chan source.LogEntry { return s.lines }
+func (s *chanSource) Errors() <-chan error          { return s.errs }
+func (s *chanSource) Start(context.Context) error   { return nil }
+func (s *chanSource) Stop() error                   { return nil }
+func (s *chanSource) Reload() error                 { return source.ErrReloadUnsupported }
+
+// recorder is a Sender that records every message it receives.
+type recorder struct {
+	mu   sync.Mutex
+	msgs []tea.Msg
+}
+
+func (r *recorder) Send(msg tea.Msg) {
+	r.mu.Lock()
+	r.msgs = append(r.msgs, msg)
+	r.mu.Unlock()
+}
+
+func (r *recorder) snapshot() []tea.Msg {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]tea.Msg(nil), r.msgs...)
+}
+
+// waitForLines waits until the recorder has received n lines in total.
+func (r *recorder) waitForLines(t *testing.T, n int) []tea.Msg {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		msgs := r.snapshot()
+		total := 0
+		for _, m := range msgs {
+			if b, ok := m.(LogBatchMsg); ok {
+				total += len(b.Entries)
+			}
+		}
+		if total >= n {
+			return msgs
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d lines", n)
+	return nil
+}
+
+func TestStreamLines_BatchesBurst(t *testing.T) {
+	const n = 10000
+	src := newChanSource(n)
+	for i := 0; i < n; i++ {
+		src.lines <- source.LogEntry{Line: fmt.Sprintf("level=info msg=line%d", i), Source: "burst"}
+	}
+	close(src.lines)
+
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, WithBatchSize(500), WithBatchInterval(time.Hour))
+	msgs := rec.waitForLines(t, n)
+
+	// One Send per line would be 10k calls; full batches of 500 need only 20.
+	if len(msgs) > n/500+1 {
+		t.Errorf("got %d Send calls for %d lines, want at most %d", len(msgs), n, n/500+1)
+	}
+	first := msgs[0].(LogBatchMsg)
+	if len(first.Lines) != len(first.Entries) {
+		t.Errorf("batch lines/entries out of sync: %d vs %d", len(first.Lines), len(first.Entries))
+	}
+	if first.Entries[0].Source != "burst" || first.Entries[0].Message != "line0" {
+		t.Errorf("first entry = %+v", first.Entries[0])
+	}
+}
+
+func TestStreamLines_FlushesOnInterval(t *testing.T) {
+	src := newChanSource(1)
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, WithBatchInterval(time.Millisecond))
+
+	// A lone line must not wait for the batch to fill up.
+	src.lines <- source.LogEntry{Line: "single"}
+	rec.waitForLines(t, 1)
+	close(src.lines)
+}
+
+func TestStreamLines_NilRendererLeavesRendering(t *testing.T) {
+	src := newChanSource(2)
+	src.lines <- source.LogEntry{Line: "level=info msg=a"}
+	src.lines <- source.LogEntry{Line: "level=info msg=b"}
+	close(src.lines)
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), nil, rec, WithBatchInterval(time.Millisecond))
+
+	for _, msg := range rec.waitForLines(t, 2) {
+		if b, ok := msg.(LogBatchMsg); ok && len(b.Lines) != 0 {
+			t.Errorf("batch has rendered lines %q, want none", b.Lines)
+		}
+	}
+}
+
+func TestStreamLines_SplitsJSONArray(t *testing.T) {
+	src := newChanSource(1)
+	src.lines <- source.LogEntry{Line: `[{"level":"info","msg":"a"},{"level":"warn","msg":"b"},{"level":"error","msg":"c"}]`, Source: "batch"}
+	close(src.lines)
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, WithBatchInterval(time.Millisecond))
+
+	var entries []parser.LogEntry
+	for _, msg := range rec.waitForLines(t, 3) {
+		if b, ok := msg.(LogBatchMsg); ok {
+			entries = append(entries, b.Entries...)
+		}
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if entries[i].Message != want || entries[i].Source != "batch" {
+			t.Errorf("entry %d = %q from %q, want %q from batch", i, entries[i].Message, entries[i].Source, want)
+		}
+	}
+}
+
+func TestStreamLines_ForwardsErrors(t *testing.T) {
+	src := newChanSource(0)
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec)
+	src.errs <- errors.New("disk on fire")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, m := range rec.snapshot() {
+			if e, ok := m.(ErrMsg); ok && e.Err.Error() == "disk on fire" {
+				close(src.lines)
+				return
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("error was not forwarded")
+}
+
+func BenchmarkStreamLines_10kBurst(b *testing.B) {
+	const n = 10000
+	for i := 0; i < b.N; i++ {
+		src := newChanSource(n)
+		for j := 0; j < n; j++ {
+			src.lines <- source.LogEntry{Line: "level=info msg=hello"}
+		}
+		close(src.lines)
+		done := make(chan struct{})
+		sender := &countingSender{want: n, done: done}
+		StreamLines(src, parser.NewAutoParser(), plainRenderer(), sender)
+		<-done
+		b.ReportMetric(float64(sender.sends), "sends/op")
+	}
+}
+
+// countingSender counts Send calls and closes done once want lines arrived.
+type countingSender struct {
+	sends, lines, want int
+	done               chan struct{}
+}
+
+func (c *countingSender) Send(msg tea.Msg) {
+	c.sends++
+	if b, ok := msg.(LogBatchMsg); ok {
+		c.lines += len(b.Entries)
+	}
+	if c.lines >= c.want {
+		close(c.done)
+	}
+}
+
+// gelfLine is valid JSON that per-line detection classifies as GELF.
+const gelfLine = `{"version":"1.1","host":"h","short_message":"odd one","level":3}`
+
+// streamFormats streams lines through StreamLines and returns the format of
+// each entry.
+func streamFormats(t *testing.T, lines []string, opts ...StreamOption) []parser.Format {
+	t.Helper()
+	src := newChanSource(len(lines))
+	for _, l := range lines {
+		src.lines <- source.LogEntry{Line: l}
+	}
+	close(src.lines)
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), plainRenderer(), rec, opts...)
+	var formats []parser.Format
+	for _, m := range rec.waitForLines(t, len(lines)) {
+		if b, ok := m.(LogBatchMsg); ok {
+			for _, e := range b.Entries {
+				formats = append(formats, e.Format)
+			}
+		}
+	}
+	return formats
+}
+
+func TestStreamLines_PinsHomogeneousFormat(t *testing.T) {
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, fmt.Sprintf(`{"level":"info","msg":"line %d"}`, i))
+	}
+	lines = append(lines, gelfLine, "panic: not json")
+
+	formats := streamFormats(t, lines)
+	if len(formats) != len(lines) {
+		t.Fatalf("got %d entries, want %d", len(formats), len(lines))
+	}
+	if f := formats[40]; f != parser.FormatJSON {
+		t.Errorf("GELF-shaped line in a JSON file: format = %v, want json (pinned)", f)
+	}
+	if f := formats[41]; f != parser.FormatPlain {
+		t.Errorf("non-JSON line: format = %v, want plain (fallback)", f)
+	}
+
+	formats = streamFormats(t, lines, WithFormatPinning(false))
+	if f := formats[40]; f != parser.FormatGELF {
+		t.Errorf("without pinning: format = %v, want gelf", f)
+	}
+}
+
+func TestStreamLines_MixedFormatNotPinned(t *testing.T) {
+	var lines []string
+	for i := 0; i < 20; i++ {
+		lines = append(lines, fmt.Sprintf(`{"level":"info","msg":"line %d"}`, i))
+		lines = append(lines, fmt.Sprintf("level=info msg=line%d", i))
+	}
+	lines = append(lines, gelfLine)
+
+	formats := streamFormats(t, lines)
+	if formats[0] != parser.FormatJSON || formats[1] != parser.FormatLogfmt {
+		t.Errorf("mixed lines should keep their own formats, got %v, %v", formats[0], formats[1])
+	}
+	if f := formats[len(formats)-1]; f != parser.FormatGELF {
+		t.Errorf("GELF line in a mixed file: format = %v, want gelf (per-line detection)", f)
+	}
+}
+
+func TestListenForLines_PinsQueuedLines(t *testing.T) {
+	src := newChanSource(50)
+	for i := 0; i < 30; i++ {
+		src.lines <- source.LogEntry{Line: fmt.Sprintf(`{"level":"info","msg":"line %d"}`, i)}
+	}
+	src.lines <- source.LogEntry{Line: gelfLine}
+	close(src.lines)
+
+	rec := &recorder{}
+	ListenForLines(src, parser.NewAutoParser(), plainRenderer(), rec)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.snapshot()) < 32 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	msgs := rec.snapshot()
+	if len(msgs) != 32 {
+		t.Fatalf("got %d messages, want 31 and the end of the source", len(msgs))
+	}
+	if _, ok := msgs[31].(SourceDoneMsg); !ok {
+		t.Errorf("last message = %T, want SourceDoneMsg", msgs[31])
+	}
+	if f := msgs[30].(LogMsg).Entry.Format; f != parser.FormatJSON {
+		t.Errorf("GELF-shaped line: format = %v, want json (pinned)", f)
+	}
+}
+
+func TestStreamLines_KeepsStream(t *testing.T) {
+	src := newChanSource(3)
+	src.lines <- source.LogEntry{Line: "plain output", Stream: "stdout"}
+	src.lines <- source.LogEntry{Line: "plain failure", Stream: "stderr"}
+	src.lines <- source.LogEntry{Line: `{"log":"from the runtime\n","stream":"stderr","time":"2024-01-15T10:30:00Z"}`}
+	close(src.lines)
+
+	rec := &recorder{}
+	StreamLines(src, parser.NewAutoParser(), nil, rec, WithFormatPinning(false))
+	var streams []string
+	for _, msg := range rec.waitForLines(t, 3) {
+		if b, ok := msg.(LogBatchMsg); ok {
+			for _, e := range b.Entries {
+				streams = append(streams, e.Stream)
+			}
+		}
+	}
+	if fmt.Sprint(streams) != "[stdout stderr stderr]" {
+		t.Errorf("streams = %v, want [stdout stderr stderr]", streams)
+	}
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+// LayoutMode controls how the parts of an entry are arranged on a line.
+type LayoutMode int
+
+const (
+	// LayoutInline puts the level, timestamp, message and key=value fields
+	// one after another.
+	LayoutInline LayoutMode = iota
+	// LayoutTable aligns the timestamp, level, message and the fields named
+	// in RenderConfig.FieldOrder into columns. Column widths follow the
+	// widest value among a sample of recent entries, widening for any entry
+	// that does not fit, and the message is elided to fit the terminal
+	// width.
+	LayoutTable
+)
+
+// ParseLayoutMode parses a layout name: "inline" or "table".
+func ParseLayoutMode(s string) (LayoutMode, error) {
+	switch strings.ToLower(s) {
+	case "inline":
+		return LayoutInline, nil
+	case "table":
+		return LayoutTable, nil
+	default:
+		return 0, fmt.Errorf("unknown layout %q (want inline or table)", s)
+	}
+}
+
+func (l LayoutMode) String() string {
+	if l == LayoutTable {
+		return "table"
+	}
+	return "inline"
+}
+
+// tableSampleSize is the number of recent entries the table column widths
+// are computed from.
+const tableSampleSize = 200
+
+// minTableMessageWidth is the narrowest the message column gets, however
+// wide the other columns are.
+const minTableMessageWidth = 10
+
+// columnSampler keeps the table column widths: the widest value of each
+// column over a sample of entries. The widths change only when an entry is
+// sampled, never just because one is rendered again, and each change bumps
+// gen. It is shared by copies of a Renderer and safe for concurrent use.
+type columnSampler struct {
+	mu     sync.Mutex
+	rows   [][]int // ring buffer of sampled per-entry column widths
+	next   int
+	widths []int // the widest value of each column over rows
+	gen    int   // bumped whenever widths change
+}
+
+// observe adds one entry's column widths to the sample.
+func (s *columnSampler) observe(widths []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(widths)
+}
+
+// fit returns the column widths, first adding widths to the sample if an
+// entry of those widths would not fit the columns.
+func (s *columnSampler) fit(widths []int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !fitsColumns(widths, s.widths) {
+		s.add(widths)
+	}
+	return append([]int(nil), s.widths...)
+}
+
+// generation returns a number that changes whenever the widths do.
+func (s *columnSampler) generation() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen
+}
+
+// add records widths in the sample and recomputes the column widths. The
+// caller holds s.mu.
+func (s *columnSampler) add(widths []int) {
+	if len(s.rows) < tableSampleSize {
+		s.rows = append(s.rows, widths)
+	} else {
+		s.rows[s.next] = widths
+		s.next = (s.next + 1) % tableSampleSize
+	}
+	out := make([]int, len(widths))
+	for _, row := range s.rows {
+		for i := range out {
+			if i < len(row) {
+				out[i] = max(out[i], row[i])
+			}
+		}
+	}
+	if !fitsColumns(out, s.widths) || !fitsColumns(s.widths, out) {
+		s.widths = out
+		s.gen++
+	}
+}
+
+// fitsColumns reports whether cells of the given widths fit columns.
+func fitsColumns(widths, columns []int) bool {
+	if len(widths) != len(columns) {
+		return false
+	}
+	for i, w := range widths {
+		if w > columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tableCell is the content of one table column, with and without styling.
+type tableCell struct {
+	plain, styled string
+}
+
+// tableCells returns entry's fixed-width columns: the timestamp, the level
+// and one per FieldOrder field. Missing values are empty cells.
+func (r *Renderer) tableCells(entry parser.LogEntry) []tableCell {
+	cells := make([]tableCell, 2, 2+len(r.config.FieldOrder))
+	if !entry.Timestamp.IsZero() {
+		ts := r.formatTimestamp(entry.Timestamp)
+		cells[0] = tableCell{ts, r.styles.timestamp.Render(ts)}
+	}
+	if entry.Level != "" {
+		norm := normalizeLevel(entry.Level)
+		label := r.levelLabel(norm)
+		cells[1] = tableCell{label, r.levelStyle(norm).Render(label)}
+	}
+	for _, k := range r.config.FieldOrder {
+		v, ok := entry.Fields[k]
+		if !ok {
+			cells = append(cells, tableCell{})
+			continue
+		}
+		key, val := r.sanitize(k), truncateValue(r.display(v), r.config.MaxFieldValueLen)
+		cells = append(cells, tableCell{
+			plain:  key + "=" + val,
+			styled: r.keyStyle(k).Render(key) + r.styles.separator.Render("=") + r.valueStyle(k, v).Render(val),
+		})
+	}
+	return cells
+}
+
+// cellWidths returns the display width of each cell.
+func cellWidths(cells []tableCell) []int {
+	widths := make([]int, len(cells))
+	for i, c := range cells {
+		widths[i] = ansi.StringWidth(c.plain)
+	}
+	return widths
+}
+
+// renderTable renders entry in LayoutTable. The columns are widened for
+// entry first if it does not fit them, so an entry always fits its columns.
+func (r *Renderer) renderTable(entry parser.LogEntry, styled bool) string {
+	cells := r.tableCells(entry)
+	widths := r.table.fit(cellWidths(cells))
+
+	sep := r.separator()
+	rendered := func(c tableCell) string {
+		if styled {
+			return c.styled
+		}
+		return c.plain
+	}
+	pad := func(c tableCell, width int) string {
+		return rendered(c) + strings.Repeat(" ", max(width-ansi.StringWidth(c.plain), 0))
+	}
+
+	// Columns no entry in the sample has are left out.
+	used, trailing := ansi.StringWidth(r.gutter(entry, false)), false
+	for i, w := range widths {
+		if w > 0 {
+			used += w + ansi.StringWidth(sep)
+			trailing = trailing || i >= 2
+		}
+	}
+	var rest map[string]string
+	if r.config.ShowAllFields {
+		rest = make(map[string]string)
+		for k, v := range entry.Fields {
+			rest[k] = v
+		}
+		for _, k := range r.config.FieldOrder {
+			delete(rest, k)
+		}
+	}
+	trailing = trailing || len(rest) > 0
+
+	msg, more := r.message(entry)
+	msg += more
+	width := max(r.config.TerminalWidth-used, minTableMessageWidth)
+	msg = truncateToWidth(msg, width)
+	if trailing {
+		msg += strings.Repeat(" ", max(width-ansi.StringWidth(msg), 0))
+	}
+
+	var parts []string
+	for i, w := range widths[:2] {
+		if w > 0 {
+			parts = append(parts, pad(cells[i], w))
+		}
+	}
+	parts = append(parts, rendered(tableCell{msg, r.styles.message.Render(msg)}))
+	for i := 2; i < len(cells); i++ {
+		if widths[i] == 0 {
+			continue
+		}
+		if i == len(cells)-1 && len(rest) == 0 {
+			parts = append(parts, rendered(cells[i]))
+		} else {
+			parts = append(parts, pad(cells[i], widths[i]))
+		}
+	}
+	if len(rest) > 0 {
+		if styled {
+			parts = append(parts, r.renderFields(rest))
+		} else {
+			parts = append(parts, r.renderFieldsPlain(rest))
+		}
+	}
+
+	if styled {
+		sep = r.styles.separator.Render(sep)
+	}
+	return strings.Join(parts, sep)
+}
+
+// sampleTable feeds entries into the table column widths without rendering
+// them, so lines rendered next line up with those entries.
+func (r *Renderer) sampleTable(entries []parser.LogEntry) {
+	for _, e := range entries {
+		r.table.observe(r.tableWidths(e))
+	}
+}
+
+// fitTable widens the table columns to fit each of entries.
+func (r *Renderer) fitTable(entries []parser.LogEntry) {
+	for _, e := range entries {
+		r.table.fit(r.tableWidths(e))
+	}
+}
+
+// tableWidths returns the widths of entry's table cells as RenderEntry lays
+// them out.
+func (r *Renderer) tableWidths(entry parser.LogEntry) []int {
+	return cellWidths(r.tableCells(r.SelectFields(r.Redact(entry))))
+}
+
+// fitTable widens the table columns to the entries at buffer indexes before
+// any of them is rendered, so rows shown together line up whatever order
+// they are rendered in.
+func (m Model) fitTable(indexes []int) {
+	if m.renderer == nil || m.raw || m.renderer.config.Layout != LayoutTable {
+		return
+	}
+	entries := make([]parser.LogEntry, len(indexes))
+	for j, i := range indexes {
+		entries[j] = m.entries[i]
+	}
+	m.renderer.fitTable(entries)
+}
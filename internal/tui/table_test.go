@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func tableRenderer(opts ...func(*RenderConfig)) *Renderer {
+	return plainRenderer(append([]func(*RenderConfig){func(c *RenderConfig) {
+		c.Layout = LayoutTable
+		c.FieldOrder = []string{"svc", "status"}
+		c.TerminalWidth = 100
+	}}, opts...)...)
+}
+
+func tableEntries() []parser.LogEntry {
+	ts := time.Date(2026, 2, 17, 8, 30, 0, 0, time.UTC)
+	return []parser.LogEntry{
+		{Timestamp: ts, Level: "info", Message: "request handled", Fields: map[string]string{"svc": "api", "status": "200"}},
+		{Timestamp: ts.Add(time.Second), Level: "error", Message: "db down", Fields: map[string]string{"svc": "billing-worker", "status": "503"}},
+		{Level: "warn", Message: "no timestamp here", Fields: map[string]string{"status": "429"}},
+		{Timestamp: ts.Add(2 * time.Second), Level: "debug", Message: "cache miss for key user:42", Fields: map[string]string{"svc": "api", "extra": "x"}},
+	}
+}
+
+// columnOffset returns the display column at which needle starts in line.
+func columnOffset(line, needle string) int {
+	i := strings.Index(line, needle)
+	if i < 0 {
+		return -1
+	}
+	return ansi.StringWidth(line[:i])
+}
+
+func TestRenderTable_LevelColumnAligned(t *testing.T) {
+	for name, render := range map[string]func(*Renderer, parser.LogEntry) string{
+		"plain":  (*Renderer).RenderEntryPlain,
+		"styled": func(r *Renderer, e parser.LogEntry) string { return StripANSI(r.RenderEntry(e)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := tableRenderer()
+			levels := []string{"INFO", "ERROR", "WARN", "DEBUG"}
+			want := -1
+			for i, e := range tableEntries() {
+				line := render(r, e)
+				off := columnOffset(line, levels[i])
+				if want < 0 {
+					want = off
+				}
+				if off != want || off < 0 {
+					t.Errorf("line %d: level column at %d, want %d\n%q", i, off, want, line)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderTable_FieldColumnsAligned(t *testing.T) {
+	r := tableRenderer()
+	entries := tableEntries()
+	r.sampleTable(entries)
+
+	msgCol, svcCol := -1, -1
+	for i, e := range entries {
+		line := r.RenderEntryPlain(e)
+		if w := ansi.StringWidth(line); w > 100 {
+			t.Errorf("line %d is %d columns wide, want at most 100", i, w)
+		}
+		if off := columnOffset(line, e.Message); msgCol < 0 {
+			msgCol = off
+		} else if off != msgCol {
+			t.Errorf("line %d: message at %d, want %d\n%q", i, off, msgCol, line)
+		}
+		if _, ok := e.Fields["svc"]; !ok {
+			continue
+		}
+		if off := columnOffset(line, "svc="); svcCol < 0 {
+			svcCol = off
+		} else if off != svcCol {
+			t.Errorf("line %d: svc column at %d, want %d\n%q", i, off, svcCol, line)
+		}
+	}
+	// status follows the widest svc value, billing-worker.
+	line := r.RenderEntryPlain(entries[0])
+	if got, want := columnOffset(line, "status="), svcCol+len("svc=billing-worker")+ansi.StringWidth(DefaultSeparator); got != want {
+		t.Errorf("status column at %d, want %d\n%q", got, want, line)
+	}
+	if strings.Contains(line, "extra=") || !strings.Contains(r.RenderEntryPlain(entries[3]), "│ svc=api") {
+		t.Error("only FieldOrder fields should be columns")
+	}
+}
+
+func TestRenderTable_ElidesMessage(t *testing.T) {
+	r := tableRenderer(func(c *RenderConfig) { c.TerminalWidth = 60 })
+	e := parser.LogEntry{Level: "info", Message: strings.Repeat("long message ", 10), Fields: map[string]string{"svc": "api", "status": "200"}}
+	line := r.RenderEntryPlain(e)
+	if ansi.StringWidth(line) > 60 || !strings.Contains(line, "…") {
+		t.Errorf("message should be elided to fit 60 columns: %q", line)
+	}
+	if !strings.HasSuffix(line, "status=200") {
+		t.Errorf("field columns should survive the elision: %q", line)
+	}
+}
+
+func TestRenderTable_ShowAllFieldsAppendsRest(t *testing.T) {
+	r := tableRenderer(func(c *RenderConfig) { c.ShowAllFields = true })
+	line := r.RenderEntryPlain(tableEntries()[3])
+	if !strings.HasSuffix(line, "extra=x") {
+		t.Errorf("fields without a column should follow the columns: %q", line)
+	}
+}
+
+func TestRenderTable_FollowsTerminalWidth(t *testing.T) {
+	m := NewModel(WithRenderer(tableRenderer()))
+	m = applyMsgs(m, append([]tea.Msg{tea.WindowSizeMsg{Width: 80, Height: 10}}, logMsgs(tableEntries()...)...))
+	if got := m.renderer.Config().TerminalWidth; got != 80 {
+		t.Fatalf("TerminalWidth = %d after resizing to 80 columns", got)
+	}
+	for i, e := range tableEntries() {
+		line := StripANSI(m.lineText(i))
+		if w := ansi.StringWidth(line); w > 80 {
+			t.Errorf("line %d is %d columns wide, want at most 80\n%q", i, w, line)
+		}
+		if status := e.Fields["status"]; status != "" && !strings.Contains(line, "status="+status) {
+			t.Errorf("line %d lost its status column\n%q", i, line)
+		}
+	}
+}
+
+// svcColumns returns the column at which "svc=" starts on each row of the
+// view that has one.
+func svcColumns(v string) []int {
+	var cols []int
+	for _, line := range strings.Split(StripANSI(v), "\n") {
+		if off := columnOffset(line, "svc="); off >= 0 {
+			cols = append(cols, off)
+		}
+	}
+	return cols
+}
+
+func TestRenderTable_ViewAligned(t *testing.T) {
+	m := setupModel(120, 10, 0, WithRenderer(tableRenderer()))
+	for _, svc := range []string{"a", "bb", "billing-worker"} {
+		m = applyMsgs(m, logMsgs(parser.LogEntry{Level: "info", Message: "msg", Fields: map[string]string{"svc": "x", "status": svc}}))
+		m = applyMsgs(m, logMsgs(parser.LogEntry{Level: "info", Message: "msg " + svc, Fields: map[string]string{"svc": svc}}))
+	}
+	// The wider values arrive last, so the rows above them are rendered
+	// first; they still line up with the rows below.
+	cols := svcColumns(m.View())
+	if len(cols) != 6 {
+		t.Fatalf("svc columns = %v, want 6 rows", cols)
+	}
+	for i, off := range cols {
+		if off != cols[0] {
+			t.Errorf("row %d: svc column at %d, want %d", i, off, cols[0])
+		}
+	}
+}
+
+func TestRenderTable_WidenedColumnsRerenderCachedRows(t *testing.T) {
+	m := setupModel(120, 10, 0, WithRenderer(tableRenderer()))
+	m = applyMsgs(m, logMsgs(parser.LogEntry{Level: "info", Message: "a", Fields: map[string]string{"svc": "api"}}))
+	_ = m.View() // caches the first row
+	m = applyMsgs(m, logMsgs(parser.LogEntry{Level: "info", Message: "b", Fields: map[string]string{"svc": "billing-worker"}}))
+	if cols := svcColumns(m.View()); len(cols) != 2 || cols[0] != cols[1] {
+		t.Errorf("svc columns = %v, want two equal offsets", cols)
+	}
+}
+
+func TestParseLayoutMode(t *testing.T) {
+	for name, want := range map[string]LayoutMode{"inline": LayoutInline, "TABLE": LayoutTable} {
+		if got, err := ParseLayoutMode(name); err != nil || got != want {
+			t.Errorf("ParseLayoutMode(%q) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := ParseLayoutMode("grid"); err == nil {
+		t.Error("expected an error for an unknown layout")
+	}
+}
+
+func TestToggleLayout(t *testing.T) {
+	m := setupModel(120, 20, 0)
+	m.renderer = plainRenderer(func(c *RenderConfig) { c.FieldOrder = []string{"svc"} })
+	m.entries = tableEntries()
+
+	m = press(m, "L")
+	if m.renderer.Config().Layout != LayoutTable || !contains(StripANSI(m.View()), "table") {
+		t.Fatal("L should switch to the table layout")
+	}
+	want := -1
+	for i := range m.entries {
+		off := columnOffset(StripANSI(m.lineText(i)), tableEntries()[i].Message)
+		if want < 0 {
+			want = off
+		}
+		if off != want {
+			t.Errorf("re-rendered line %d: message at %d, want %d", i, off, want)
+		}
+	}
+
+	if m = press(m, "L"); m.renderer.Config().Layout != LayoutInline {
+		t.Error("L again should switch back to inline")
+	}
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultTabWidth is the distance between tab stops when
+// RenderConfig.TabWidth is not set.
+const DefaultTabWidth = 8
+
+// tabWidth returns the configured distance between tab stops.
+func (r *Renderer) tabWidth() int {
+	if r.config.TabWidth <= 0 {
+		return DefaultTabWidth
+	}
+	return r.config.TabWidth
+}
+
+// display prepares text from a log line for an entry's line: control
+// characters are sanitized and tabs expanded, so that widths measured
+// afterwards are those on screen.
+func (r *Renderer) display(s string) string {
+	return expandTabs(r.sanitize(s), r.tabWidth())
+}
+
+// expandTabs replaces each tab in s with spaces up to the next multiple of
+// width columns, counting from the start of s and of each line in it. ANSI
+// escape sequences take no columns; wide characters take two.
+func expandTabs(s string, width int) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 2*width)
+	col := 0
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case '\t':
+			n := width - col%width
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			i++
+			continue
+		case '\n':
+			col = 0
+		case 0x1b:
+			if loc := ansiPrefix.FindStringIndex(s[i:]); loc != nil {
+				b.WriteString(s[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		col += ansi.StringWidth(s[i : i+size])
+		b.WriteString(s[i : i+size])
+		i += size
+	}
+	return b.String()
+}
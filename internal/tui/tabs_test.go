@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"no tabs", 8, "no tabs"},
+		{"\tx", 8, "        x"},
+		{"ab\tc", 8, "ab      c"},
+		{"abcdefgh\ti", 8, "abcdefgh        i"},
+		{"a\tb\tc", 4, "a   b   c"},
+		{"日本\tx", 4, "日本    x"}, // two wide characters fill a stop
+		{"one\n\ttwo", 4, "one\n    two"},
+		{"\x1b[31mred\x1b[0m\tx", 4, "\x1b[31mred\x1b[0m x"},
+	}
+	for _, tt := range tests {
+		if got := expandTabs(tt.in, tt.width); got != tt.want {
+			t.Errorf("expandTabs(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderEntry_ExpandsTabs(t *testing.T) {
+	entry := parser.LogEntry{Message: "id\tname\tstate", Fields: map[string]string{"k": "a\tb"}}
+
+	r := plainRenderer(func(c *RenderConfig) { c.ShowAllFields = true })
+	if got, want := r.RenderEntryPlain(entry), "id      name    state │ k=a       b"; got != want {
+		t.Errorf("default width: got %q, want %q", got, want)
+	}
+	r = plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.TabWidth = 4
+	})
+	if got, want := StripANSI(r.RenderEntry(entry)), "id  name    state │ k=a   b"; got != want {
+		t.Errorf("width 4: got %q, want %q", got, want)
+	}
+}
+
+func TestRenderEntry_TruncationCountsExpandedTabs(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) {
+		c.TerminalWidth = 20
+		c.WrapMode = WrapTruncate
+	})
+	got := StripANSI(r.RenderEntry(parser.LogEntry{Message: "a\tb\tc\td"}))
+	if w := ansi.StringWidth(got); w > 20 {
+		t.Errorf("line %q is %d columns wide, want at most 20", got, w)
+	}
+	if want := "a       b       c  …"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	r = plainRenderer(func(c *RenderConfig) {
+		c.ShowAllFields = true
+		c.MaxFieldValueLen = 6
+		c.TabWidth = 4
+	})
+	if got, want := r.RenderEntryPlain(parser.LogEntry{Message: "m", Fields: map[string]string{"k": "\tvalue"}}), "m │ k=    v…"; got != want {
+		t.Errorf("field limit: got %q, want %q", got, want)
+	}
+}
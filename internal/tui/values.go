@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"hash/fnv"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// valueKind is the type a field value looks like, used to color it.
+type valueKind int
+
+const (
+	valueString valueKind = iota
+	valueNumber
+	valueBool
+	valueDuration
+	valueIP
+	valueURL
+)
+
+// numberPattern matches decimal integers and floats, but not the "inf",
+// "nan" or hex forms strconv.ParseFloat would also accept.
+var numberPattern = regexp.MustCompile(`^[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?$`)
+
+// classifyValue guesses the type of a field value.
+func classifyValue(v string) valueKind {
+	switch {
+	case v == "":
+		return valueString
+	case numberPattern.MatchString(v):
+		return valueNumber
+	case strings.EqualFold(v, "true") || strings.EqualFold(v, "false"):
+		return valueBool
+	case isDuration(v):
+		return valueDuration
+	case isIP(v):
+		return valueIP
+	case isURL(v):
+		return valueURL
+	default:
+		return valueString
+	}
+}
+
+func isDuration(v string) bool {
+	_, err := time.ParseDuration(v)
+	return err == nil
+}
+
+// isIP reports whether v is an IP address, optionally with a port.
+func isIP(v string) bool {
+	if _, err := netip.ParseAddr(v); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddrPort(v)
+	return err == nil
+}
+
+func isURL(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// keyStyle returns the style for field key: with ColorKeysByName a color of
+// the theme's key palette picked by a hash of the name, otherwise the
+// theme's field key style.
+func (r *Renderer) keyStyle(key string) lipgloss.Style {
+	palette := r.styles.keyColors
+	if !r.config.ColorKeysByName || len(palette) == 0 {
+		return r.styles.fieldKey
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return lipgloss.NewStyle().Foreground(palette[h.Sum32()%uint32(len(palette))])
+}
+
+// valueStyle returns the style for the value of field key: the key's entry
+// in RenderConfig.FieldKeyStyles if there is one, otherwise the theme's style
+// for the kind of value.
+func (r *Renderer) valueStyle(key, value string) lipgloss.Style {
+	if st, ok := r.config.FieldKeyStyles[key]; ok {
+		return st
+	}
+	switch classifyValue(value) {
+	case valueNumber:
+		return r.styles.number
+	case valueBool:
+		return r.styles.boolean
+	case valueDuration:
+		return r.styles.duration
+	case valueIP:
+		return r.styles.ip
+	case valueURL:
+		return r.styles.url
+	default:
+		return r.styles.fieldVal
+	}
+}
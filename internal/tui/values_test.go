@@ -0,0 +1,36 @@
+package tui
+
+import "testing"
+
+func TestClassifyValue(t *testing.T) {
+	tests := map[string]valueKind{
+		"42":                     valueNumber,
+		"-7":                     valueNumber,
+		"3.14":                   valueNumber,
+		"1e9":                    valueNumber,
+		".5":                     valueNumber,
+		"true":                   valueBool,
+		"FALSE":                  valueBool,
+		"3.2s":                   valueDuration,
+		"150ms":                  valueDuration,
+		"1h30m":                  valueDuration,
+		"10.0.0.1":               valueIP,
+		"10.0.0.1:8080":          valueIP,
+		"::1":                    valueIP,
+		"[2001:db8::1]:443":      valueIP,
+		"https://example.com/a":  valueURL,
+		"postgres://db:5432/app": valueURL,
+		"":                       valueString,
+		"hello":                  valueString,
+		"NaN":                    valueString,
+		"0x1f":                   valueString,
+		"1.2.3":                  valueString,
+		"/var/log/app.log":       valueString,
+		"mailto:a@b.c":           valueString,
+	}
+	for v, want := range tests {
+		if got := classifyValue(v); got != want {
+			t.Errorf("classifyValue(%q) = %d, want %d", v, got, want)
+		}
+	}
+}
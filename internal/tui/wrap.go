@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// WithWrap turns wrapping of long lines on or off. When on, a line wider than
+// the terminal occupies as many display rows as it needs.
+func WithWrap(on bool) ModelOption {
+	return func(m *Model) { m.wrap = on }
+}
+
+// toggleWrap switches between truncating and wrapping long lines. Lines are
+// re-rendered so the renderer stops (or starts) truncating them.
+func (m *Model) toggleWrap() {
+	m.wrap = !m.wrap
+	if m.renderer != nil {
+		m.setRenderer(m.renderer.With(func(c *RenderConfig) {
+			if m.wrap {
+				c.WrapMode = WrapWrap
+			} else {
+				c.WrapMode = WrapTruncate
+			}
+		}))
+	}
+	if m.autoScroll {
+		m.offset = m.maxOffset()
+	} else {
+		m.scrollToCursor()
+	}
+}
+
+// wrapWidth returns the width lines are wrapped to. It assumes the widest
+// possible line-number gutter so that row counts do not change as the user
+// scrolls.
+func (m Model) wrapWidth() int {
+	return m.contentWidth(len(m.entries))
+}
+
+// displayRows splits buffer line i into the display rows it occupies at the
+// given width. Without wrapping every line is a single row.
+func (m Model) displayRows(i, width int) []string {
+	line := m.lineText(i)
+	if !m.wrap || width <= 0 || lipgloss.Width(line) <= width {
+		return []string{line}
+	}
+	return strings.Split(ansi.Hardwrap(line, width, true), "\n")
+}
+
+// rowCount returns how many display rows view position pos occupies.
+func (m Model) rowCount(pos int) int {
+	if !m.wrap {
+		return 1
+	}
+	return len(m.displayRows(m.bufIndex(pos), m.wrapWidth()))
+}
+
+// firstFitting returns the smallest view position from which the lines up to
+// and including last fit into vh display rows. The last line itself is
+// always included, even if it alone is taller than vh.
+func (m Model) firstFitting(last, vh int) int {
+	rows := 0
+	for pos := last; pos >= 0; pos-- {
+		rows += m.rowCount(pos)
+		if rows > vh {
+			return min(pos+1, last)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/clarabennettdev/logpilot/internal/parser"
+)
+
+func wrapModel(lines ...string) Model {
+	m := setupModel(80, 13, 0, WithWrap(true)) // 10 rows of log view
+	m.appendLines(lines, nil)
+	m.offset = m.maxOffset()
+	return m
+}
+
+func TestWrap_LongLineRows(t *testing.T) {
+	m := wrapModel(strings.Repeat("x", 300))
+	if got := m.rowCount(0); got != 4 {
+		t.Errorf("rowCount = %d, want 4 for 300 chars at width 80", got)
+	}
+	rows := m.viewportRows(m.viewHeight())
+	if len(rows) != 4 {
+		t.Fatalf("viewport rows = %d, want 4", len(rows))
+	}
+	var joined string
+	for _, r := range rows {
+		if w := lipgloss.Width(r); w > 80 {
+			t.Errorf("row width %d exceeds terminal", w)
+		}
+		joined += StripANSI(r)
+	}
+	if joined != strings.Repeat("x", 300) {
+		t.Error("wrapped rows should contain the whole line without truncation")
+	}
+}
+
+func TestWrap_ScrollingCountsRows(t *testing.T) {
+	long := strings.Repeat("y", 300) // 4 rows
+	lines := []string{"a", long, "b", long, "c"}
+	m := wrapModel(lines...)
+
+	// 11 display rows in total, 10 available: only the first line scrolls off.
+	if got := m.maxOffset(); got != 1 {
+		t.Errorf("maxOffset = %d, want 1", got)
+	}
+	rows := m.viewportRows(m.viewHeight())
+	if len(rows) != 10 || StripANSI(rows[len(rows)-1]) != "c" {
+		t.Errorf("bottom view should end with c, rows = %d", len(rows))
+	}
+
+	m = press(m, "g")
+	m.cursor = 4
+	m.scrollToCursor()
+	if m.offset != 1 {
+		t.Errorf("offset after moving to last line = %d, want 1", m.offset)
+	}
+}
+
+func TestWrap_Toggle(t *testing.T) {
+	r := plainRenderer(func(c *RenderConfig) { c.TerminalWidth = 80 })
+	m := setupModel(80, 13, 0, WithRenderer(r))
+	entry := parser.LogEntry{Message: strings.Repeat("z", 300)}
+	updated, _ := m.Update(LogMsg{Rendered: r.RenderEntry(entry), Entry: entry})
+	m = updated.(Model)
+
+	if !strings.HasSuffix(StripANSI(m.lineText(0)), "…") {
+		t.Fatal("truncate mode should cut the line")
+	}
+	m = press(m, "w")
+	if m.rowCount(0) != 4 {
+		t.Errorf("after toggling wrap, rowCount = %d, want 4", m.rowCount(0))
+	}
+	if strings.Contains(m.lineText(0), "…") {
+		t.Error("wrapped line should be re-rendered untruncated")
+	}
+	m = press(m, "w")
+	if m.rowCount(0) != 1 {
+		t.Errorf("after toggling back, rowCount = %d, want 1", m.rowCount(0))
+	}
+}
+
+func TestWrap_ShortLinesUnaffected(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	m := wrapModel(lines...)
+	if got, want := m.maxOffset(), 30-m.viewHeight(); got != want {
+		t.Errorf("maxOffset = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,333 @@
+// Package logpilot exposes LogPilot's parsing and rendering for use from
+// other Go programs, without the interactive TUI.
+//
+// A Pipeline reads lines from a Source, parses them and renders each entry
+// the way logpilot prints them when its output is not a terminal:
+//
+//	p, err := logpilot.NewPipeline(logpilot.NewReaderSource(os.Stdin),
+//		logpilot.WithMinLevel("warn"),
+//		logpilot.WithOutput(logpilot.OutputPlain))
+//	if err != nil {
+//		return err
+//	}
+//	go p.Run(ctx)
+//	for r := range p.Results() {
+//		fmt.Println(r.Rendered)
+//	}
+package logpilot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/clarabennettdev/logpilot/internal/parser"
+	"github.com/clarabennettdev/logpilot/internal/source"
+	"github.com/clarabennettdev/logpilot/internal/tui"
+)
+
+// Entry is a parsed log entry.
+type Entry = parser.LogEntry
+
+// Line is a raw line as emitted by a Source.
+type Line = source.LogEntry
+
+// Source produces raw log lines; see NewReaderSource and NewFileSource.
+type Source = source.Source
+
+// SourceStats is a snapshot of a Source's throughput and backpressure.
+type SourceStats = source.SourceStats
+
+// NoStats can be embedded in Source implementations that keep no
+// statistics.
+type NoStats = source.NoStats
+
+// Parser turns a raw line into an Entry.
+type Parser = parser.Parser
+
+// MultiParser is a Parser that may turn one line into several entries; a
+// Pipeline uses ParseMulti when its parser has it.
+type MultiParser = parser.MultiParser
+
+// RenderConfig controls how entries are rendered.
+type RenderConfig = tui.RenderConfig
+
+// OutputFormat selects how a Pipeline renders entries.
+type OutputFormat int
+
+const (
+	// OutputStyled renders entries with ANSI colors, as in the TUI.
+	OutputStyled OutputFormat = iota
+	// OutputPlain renders the same layout without styling.
+	OutputPlain
+	// OutputJSON renders each entry as a normalized JSON object with
+	// "time", "level", "message", "fields", "format" and "source" keys.
+	OutputJSON
+	// OutputMessage renders only the message of each entry, or its input
+	// line if it has none.
+	OutputMessage
+)
+
+// ParseOutputFormat parses an output format name: "styled", "plain",
+// "json" or "message".
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch strings.ToLower(s) {
+	case "styled":
+		return OutputStyled, nil
+	case "plain":
+		return OutputPlain, nil
+	case "json":
+		return OutputJSON, nil
+	case "message":
+		return OutputMessage, nil
+	default:
+		return 0, fmt.Errorf("unknown output format %q (want styled, plain, json or message)", s)
+	}
+}
+
+// DefaultResultBuffer is the capacity of the Results channel.
+const DefaultResultBuffer = 100
+
+// Result is one entry that made it through a Pipeline.
+type Result struct {
+	// Entry is the parsed entry.
+	Entry Entry
+	// Rendered is Entry in the pipeline's output format, without a
+	// trailing newline.
+	Rendered string
+}
+
+// Option configures a Pipeline.
+type Option func(*Pipeline) error
+
+// WithFormat parses every line as the named format ("json", "logfmt",
+// "plain", ...) instead of detecting the format of each line.
+func WithFormat(name string) Option {
+	return func(p *Pipeline) error {
+		f, err := parser.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+		p.parser = parser.NewParser(f)
+		return nil
+	}
+}
+
+// WithParser parses lines with prs. It overrides WithFormat.
+func WithParser(prs Parser) Option {
+	return func(p *Pipeline) error {
+		p.parser = prs
+		return nil
+	}
+}
+
+// WithMinLevel drops entries below level (trace, debug, info, warn, error,
+// fatal). Entries without a recognized level are kept.
+func WithMinLevel(level string) Option {
+	return func(p *Pipeline) error {
+		if !tui.ValidLevel(level) {
+			return fmt.Errorf("unknown level %q", level)
+		}
+		p.filters = append(p.filters, tui.MinLevelFilter(level))
+		return nil
+	}
+}
+
+// WithFilter drops entries for which keep returns false. Multiple filters
+// combine: an entry must pass all of them.
+func WithFilter(keep func(Entry) bool) Option {
+	return func(p *Pipeline) error {
+		p.filters = append(p.filters, keep)
+		return nil
+	}
+}
+
+// WithOutput sets the output format. The default is OutputStyled.
+func WithOutput(f OutputFormat) Option {
+	return func(p *Pipeline) error {
+		if f < OutputStyled || f > OutputMessage {
+			return fmt.Errorf("unknown output format %d", f)
+		}
+		p.output = f
+		return nil
+	}
+}
+
+// WithRenderConfig sets the renderer configuration for OutputStyled,
+// OutputPlain and OutputMessage. It replaces the default, which only enables
+// SanitizeControl.
+func WithRenderConfig(cfg RenderConfig) Option {
+	return func(p *Pipeline) error {
+		p.render = cfg
+		return nil
+	}
+}
+
+// WithResultBuffer sets the capacity of the Results channel.
+func WithResultBuffer(n int) Option {
+	return func(p *Pipeline) error {
+		if n < 0 {
+			return fmt.Errorf("negative result buffer %d", n)
+		}
+		p.bufSize = n
+		return nil
+	}
+}
+
+// Pipeline wires a Source through a parser and a renderer.
+type Pipeline struct {
+	src      Source
+	parser   Parser
+	filters  []func(Entry) bool
+	output   OutputFormat
+	render   RenderConfig
+	bufSize  int
+	renderer *tui.Renderer
+	results  chan Result
+}
+
+// NewPipeline creates a pipeline reading from src. By default the format
+// of each line is detected and entries are rendered with OutputStyled.
+func NewPipeline(src Source, opts ...Option) (*Pipeline, error) {
+	p := &Pipeline{src: src, bufSize: DefaultResultBuffer, render: RenderConfig{SanitizeControl: true}}
+	for _, o := range opts {
+		if err := o(p); err != nil {
+			return nil, err
+		}
+	}
+	if p.parser == nil {
+		p.parser = parser.NewAutoParser()
+	}
+	p.renderer = tui.NewRenderer(p.render)
+	p.results = make(chan Result, p.bufSize)
+	return p, nil
+}
+
+// NewReaderSource returns a Source reading lines from r.
+func NewReaderSource(r io.Reader) Source {
+	return source.NewStdinSource(source.WithReader(r))
+}
+
+// NewFileSource returns a Source reading the files matching the glob
+// patterns. With follow set it keeps tailing them like tail -F; otherwise
+// it stops at the end of the files.
+func NewFileSource(follow bool, patterns ...string) Source {
+	return source.NewFileSource(source.FileConfig{Patterns: patterns, NoFollow: !follow})
+}
+
+// Results returns the channel of rendered entries. It is closed when Run
+// returns.
+func (p *Pipeline) Results() <-chan Result { return p.results }
+
+// Run starts the source and processes its lines until the source is
+// exhausted or ctx is cancelled. It returns the source's error, if any, or
+// ctx.Err() after a cancellation. Run must be called only once.
+func (p *Pipeline) Run(ctx context.Context) error {
+	defer close(p.results)
+
+	started := make(chan error, 1)
+	go func() { started <- p.src.Start(ctx) }()
+
+	lines := p.src.Lines()
+	for {
+		select {
+		case err := <-started:
+			// Some sources return from Start once reading is under way,
+			// others only when it is over; either way an error means no
+			// more lines are coming.
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+			started = nil
+		case line, ok := <-lines:
+			if !ok {
+				return p.finish(ctx, started)
+			}
+			for _, r := range p.process(line) {
+				select {
+				case p.results <- r:
+				case <-ctx.Done():
+					p.src.Stop()
+					return ctx.Err()
+				}
+			}
+		case <-ctx.Done():
+			p.src.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// finish collects the outcome once the source has closed its lines.
+func (p *Pipeline) finish(ctx context.Context, started <-chan error) error {
+	if started != nil {
+		if err := <-started; err != nil && ctx.Err() == nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	select {
+	case err, ok := <-p.src.Errors():
+		if ok && err != nil {
+			return err
+		}
+	default:
+	}
+	return nil
+}
+
+// process parses and renders one line, returning the entries that pass the
+// filters. A parser implementing MultiParser may turn a line into several
+// entries.
+func (p *Pipeline) process(line Line) []Result {
+	if line.Reset {
+		return nil
+	}
+	var entries []Entry
+	if mp, ok := p.parser.(MultiParser); ok {
+		entries = mp.ParseMulti(line.Line)
+	} else {
+		entries = []Entry{p.parser.Parse(line.Line)}
+	}
+	var results []Result
+	for _, entry := range entries {
+		entry.Source = line.Source
+		if line.Stream != "" {
+			entry.Stream = line.Stream
+		}
+		entry.Fields = line.ApplyTags(entry.Fields)
+		if !p.keep(entry) {
+			continue
+		}
+		results = append(results, Result{Entry: entry, Rendered: p.Render(entry)})
+	}
+	return results
+}
+
+// keep reports whether entry passes every filter.
+func (p *Pipeline) keep(entry Entry) bool {
+	for _, keep := range p.filters {
+		if !keep(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+// Render renders entry in the pipeline's output format. It is safe to call
+// from several goroutines at once, and while Run is running.
+func (p *Pipeline) Render(entry Entry) string {
+	switch p.output {
+	case OutputPlain:
+		return p.renderer.RenderEntryPlain(entry)
+	case OutputJSON:
+		return tui.EntryToJSON(p.renderer.Redact(entry))
+	case OutputMessage:
+		return p.renderer.RenderMessageOnly(entry)
+	default:
+		return p.renderer.RenderEntry(entry)
+	}
+}
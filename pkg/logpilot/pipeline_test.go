@@ -0,0 +1,275 @@
+package logpilot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clarabennettdev/logpilot/internal/source"
+)
+
+// mockSource emits fixed lines from Start and then closes its channels,
+// optionally returning err.
+type mockSource struct {
+	NoStats
+	lines chan Line
+	errs  chan error
+	input []Line
+	err   error
+}
+
+func newMockSource(lines ...string) *mockSource {
+	m := &mockSource{lines: make(chan Line), errs: make(chan error, 1)}
+	for _, l := range lines {
+		m.input = append(m.input, Line{Line: l, Source: "mock"})
+	}
+	return m
+}
+
+func (m *mockSource) Lines() <-chan Line   { return m.lines }
+func (m *mockSource) Errors() <-chan error { return m.errs }
+func (m *mockSource) Stop() error          { return nil }
+func (m *mockSource) Reload() error        { return source.ErrReloadUnsupported }
+
+func (m *mockSource) Start(ctx context.Context) error {
+	defer close(m.lines)
+	for _, l := range m.input {
+		select {
+		case m.lines <- l:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return m.err
+}
+
+// runPipeline runs p to completion and returns its results and error.
+func runPipeline(t *testing.T, p *Pipeline) ([]Result, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+	var results []Result
+	for r := range p.Results() {
+		results = append(results, r)
+	}
+	return results, <-done
+}
+
+func TestPipeline_PlainOutput(t *testing.T) {
+	src := newMockSource(
+		`{"level":"info","msg":"started","port":"8080"}`,
+		`level=error msg="disk full" dev=sda`,
+		`just some text`,
+	)
+	p, err := NewPipeline(src, WithOutput(OutputPlain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for i, want := range []string{"started", "disk full", "just some text"} {
+		if results[i].Entry.Message != want {
+			t.Errorf("result %d message = %q, want %q", i, results[i].Entry.Message, want)
+		}
+		if !strings.Contains(results[i].Rendered, want) {
+			t.Errorf("result %d rendered %q lacks %q", i, results[i].Rendered, want)
+		}
+		if strings.Contains(results[i].Rendered, "\x1b[") {
+			t.Errorf("result %d plain output has escape codes: %q", i, results[i].Rendered)
+		}
+		if results[i].Entry.Source != "mock" {
+			t.Errorf("result %d source = %q, want mock", i, results[i].Entry.Source)
+		}
+	}
+}
+
+func TestPipeline_MinLevel(t *testing.T) {
+	src := newMockSource(
+		`level=debug msg=a`,
+		`level=warn msg=b`,
+		`level=error msg=c`,
+		`no level here`,
+	)
+	p, err := NewPipeline(src, WithMinLevel("warn"), WithOutput(OutputPlain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Entry.Message)
+	}
+	if strings.Join(got, ",") != "b,c,no level here" {
+		t.Errorf("messages = %v, want [b c no level here]", got)
+	}
+}
+
+func TestPipeline_FilterSeesTags(t *testing.T) {
+	src := source.NewTaggingSource(newMockSource(`level=info msg=a`, `level=info msg=b env=prod`),
+		source.TagConfig{Tags: map[string]string{"env": "staging"}})
+	p, err := NewPipeline(src, WithOutput(OutputPlain), WithFilter(func(e Entry) bool {
+		return e.Fields["env"] == "staging" && e.Source == "mock"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 1 || results[0].Entry.Message != "a" {
+		t.Errorf("results = %+v, want only the entry tagged env=staging", results)
+	}
+}
+
+func TestPipeline_JSONOutput(t *testing.T) {
+	src := newMockSource(`time=2024-01-15T10:30:00Z level=info msg=hello user=bob`)
+	p, err := NewPipeline(src, WithOutput(OutputJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Run = %d results, %v", len(results), err)
+	}
+	want := `{"time":"2024-01-15T10:30:00Z","level":"INFO","message":"hello","fields":{"user":"bob"},"format":"logfmt","source":"mock"}`
+	if results[0].Rendered != want {
+		t.Errorf("rendered =\n%s\nwant\n%s", results[0].Rendered, want)
+	}
+}
+
+func TestPipeline_MessageOutput(t *testing.T) {
+	src := newMockSource(`time=2024-01-15T10:30:00Z level=info msg=hello user=bob`)
+	p, err := NewPipeline(src, WithOutput(OutputMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Run = %d results, %v", len(results), err)
+	}
+	if results[0].Rendered != "hello" {
+		t.Errorf("rendered = %q, want the message alone", results[0].Rendered)
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for name, want := range map[string]OutputFormat{"styled": OutputStyled, "plain": OutputPlain, "json": OutputJSON, "Message": OutputMessage} {
+		if got, err := ParseOutputFormat(name); err != nil || got != want {
+			t.Errorf("ParseOutputFormat(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseOutputFormat("xml"); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+}
+
+func TestPipeline_FormatOverride(t *testing.T) {
+	src := newMockSource(`level=info msg=hello`)
+	p, err := NewPipeline(src, WithFormat("plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Run = %d results, %v", len(results), err)
+	}
+	if e := results[0].Entry; e.Message != "level=info msg=hello" {
+		t.Errorf("message = %q, want the whole line as plain text", e.Message)
+	}
+
+	if _, err := NewPipeline(src, WithFormat("xml")); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+	if _, err := NewPipeline(src, WithMinLevel("loud")); err == nil {
+		t.Error("unknown level should be rejected")
+	}
+}
+
+func TestPipeline_SourceError(t *testing.T) {
+	src := newMockSource("one")
+	src.err = errors.New("boom")
+	p, err := NewPipeline(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if len(results) != 1 {
+		t.Errorf("got %d results, want 1", len(results))
+	}
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("Run error = %v, want boom", err)
+	}
+}
+
+func TestPipeline_Cancel(t *testing.T) {
+	src := newMockSource("a", "b", "c")
+	p, err := NewPipeline(src, WithResultBuffer(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+	<-p.Results()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestPipeline_ReaderSource(t *testing.T) {
+	p, err := NewPipeline(NewReaderSource(strings.NewReader("first\nsecond\n")), WithOutput(OutputPlain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 2 || results[1].Entry.Message != "second" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestPipeline_JSONArrayBatch(t *testing.T) {
+	src := newMockSource(
+		`[{"level":"info","msg":"a"},{"level":"debug","msg":"b"},{"level":"error","msg":"c"}]`,
+		`level=info msg=after`,
+	)
+	p, err := NewPipeline(src, WithOutput(OutputPlain), WithMinLevel("info"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := runPipeline(t, p)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Entry.Message)
+		if r.Entry.Source != "mock" {
+			t.Errorf("%q: Source = %q, want mock", r.Entry.Message, r.Entry.Source)
+		}
+	}
+	if strings.Join(got, ",") != "a,c,after" {
+		t.Errorf("messages = %v, want the batch split and filtered", got)
+	}
+}